@@ -20,14 +20,17 @@ from typing import Any, Optional
 
 
 # --------------------------------------------------------------------------- #
-# Canonicalization (JCS-compatible; mirrors core/canonical.go)
+# Canonicalization (RFC 8785; mirrors core/canonical.go)
 # --------------------------------------------------------------------------- #
 
 def canonicalize(v: Any) -> str:
+    """RFC 8785 (JCS) canonical JSON: keys sorted by UTF-16 code units, numbers
+    serialized as ECMAScript does. Raises ValueError for values JSON cannot
+    carry exactly (NaN, infinities, lone surrogates)."""
     if isinstance(v, dict):
         return "{" + ",".join(
-            json.dumps(k, ensure_ascii=False) + ":" + canonicalize(v[k])
-            for k in sorted(v.keys())
+            _canonical_string(k) + ":" + canonicalize(v[k])
+            for k in sorted(v.keys(), key=lambda k: k.encode("utf-16-be", "surrogatepass"))
         ) + "}"
     if isinstance(v, list):
         return "[" + ",".join(canonicalize(x) for x in v) + "]"
@@ -35,15 +38,63 @@ def canonicalize(v: Any) -> str:
         return "true" if v else "false"
     if v is None:
         return "null"
-    if isinstance(v, int):
-        return str(v)
-    if isinstance(v, float):
-        return repr(v)  # avoided in signed content
+    if isinstance(v, (int, float)):
+        try:
+            return _es_number(float(v))
+        except OverflowError:
+            raise ValueError(f"canonicalize: number {v} out of range") from None
     if isinstance(v, str):
-        return json.dumps(v, ensure_ascii=False)
+        return _canonical_string(v)
     raise TypeError(f"canonicalize: unsupported type {type(v)!r}")
 
 
+def canonicalize_json(text: str) -> str:
+    """Canonicalize JSON text, rejecting duplicate keys as well."""
+    def pairs(items):
+        out = {}
+        for k, val in items:
+            if k in out:
+                raise ValueError(f"canonicalize: duplicate key {k!r}")
+            out[k] = val
+        return out
+
+    def constant(name):
+        raise ValueError(f"canonicalize: {name} is not a JSON number")
+
+    return canonicalize(json.loads(text, object_pairs_hook=pairs, parse_constant=constant))
+
+
+def _canonical_string(s: str) -> str:
+    if any(0xD800 <= ord(c) <= 0xDFFF for c in s):
+        raise ValueError("canonicalize: lone surrogate")
+    return json.dumps(s, ensure_ascii=False)
+
+
+def _es_number(f: float) -> str:
+    """ECMAScript Number.prototype.toString, which RFC 8785 adopts."""
+    if math.isnan(f) or math.isinf(f):
+        raise ValueError(f"canonicalize: {f} is not a JSON number")
+    if f == 0:
+        return "0"
+    sign = "-" if f < 0 else ""
+    r = repr(abs(f))  # shortest round-trip digits
+    mant, _, exp = r.partition("e")
+    ip, _, fp = mant.partition(".")
+    all_digits = ip + fp
+    digits = all_digits.lstrip("0")
+    n = len(ip) + int(exp or 0) - (len(all_digits) - len(digits))
+    digits = digits.rstrip("0")
+    k = len(digits)
+    if k <= n <= 21:
+        return sign + digits + "0" * (n - k)
+    if 0 < n <= 21:
+        return sign + digits[:n] + "." + digits[n:]
+    if -6 < n <= 0:
+        return sign + "0." + "0" * -n + digits
+    m = digits[0] + ("." + digits[1:] if k > 1 else "")
+    return sign + m + "e" + ("+" if n - 1 >= 0 else "-") + str(abs(n - 1))
+
+
 def canonicalize_without(v: dict, drop_keys: list[str]) -> str:
     clone = {k: val for k, val in v.items() if k not in drop_keys}
     return canonicalize(clone)
@@ -189,21 +240,95 @@ def verify_signature(signer_did: str, message: str, sig_hex: str) -> bool:
         return False
 
 
+def verify_owner_policy(policy: dict, payload: str, sigs: list) -> bool:
+    """Check that sigs over payload meet an M-of-N owner policy's threshold:
+    every listed signature valid and by a distinct member. That the policy
+    hashes to the record's owner DID needs BLAKE3 and is left to the registry /
+    `molt verify`, like chain linkage."""
+    if policy.get("spec") != "moltnet/owner-policy/v0.1":
+        return False
+    members = policy.get("members") or []
+    threshold = policy.get("threshold", 0)
+    if not 1 <= threshold <= len(members):
+        return False
+    if any(not members[i - 1] < members[i] for i in range(1, len(members))):
+        return False
+    seen = set()
+    for s in sigs:
+        signer = s.get("signer")
+        if signer not in members or signer in seen:
+            return False
+        seen.add(signer)
+        if not verify_signature(signer, payload, s.get("sig", "")):
+            return False
+    return len(seen) >= threshold
+
+
 def verify_card(card: dict) -> bool:
-    if card.get("spec") != "moltnet/card/v0.1" or not card.get("sig") or not card.get("owner_sig"):
+    if card.get("spec") != "moltnet/card/v0.1" or not card.get("sig"):
+        return False
+    payload = canonicalize_without(card, ["sig", "owner_sig", "owner_sigs"])
+    if not verify_signature(card["id"], payload, card["sig"]):
         return False
-    payload = canonicalize_without(card, ["sig", "owner_sig"])
-    return (
-        verify_signature(card["id"], payload, card["sig"])
-        and verify_signature(card["owner"], payload, card["owner_sig"])
-    )
+    if card["owner"].startswith("did:moltpolicy:"):
+        if not card.get("owner_policy") or card.get("owner_sig"):
+            return False
+        return verify_owner_policy(card["owner_policy"], payload, card.get("owner_sigs") or [])
+    if not card.get("owner_sig") or card.get("owner_policy") or card.get("owner_sigs"):
+        return False
+    return verify_signature(card["owner"], payload, card["owner_sig"])
 
 
 def verify_attestation(att: dict) -> bool:
     if not att.get("sig"):
         return False
-    payload = canonicalize_without(att, ["sig"])
-    return verify_signature(att["issuer"], payload, att["sig"])
+    payload = canonicalize_without(att, ["sig", "co_sigs"])
+    if not verify_signature(att["issuer"], payload, att["sig"]):
+        return False
+    return _verify_co_sigs(att, payload)
+
+
+def _verify_co_sigs(att: dict, payload: str) -> bool:
+    # Every co-issuer, distinct from the others and from the lead, and only
+    # they, sign the same payload as the lead (mirrors core.Attestation.Verify).
+    co, sigs = att.get("co_issuers") or [], att.get("co_sigs") or []
+    if not co:
+        return not sigs
+    if att.get("on_behalf_of"):
+        return False  # a co-signed attestation cannot be delegated
+    issuers = [att["issuer"]] + [c.get("issuer") for c in co]
+    if not all(issuers) or len(set(issuers)) != len(issuers) or len(sigs) != len(co):
+        return False
+    by_signer = {s.get("signer"): s.get("sig", "") for s in sigs}
+    return all(c["issuer"] in by_signer and verify_signature(c["issuer"], payload, by_signer[c["issuer"]]) for c in co)
+
+
+def _prev_of(att: dict, issuer: str):
+    """The link att follows on issuer's chain, or None if issuer did not issue
+    it (see core.Attestation.PrevOf)."""
+    if att.get("issuer") == issuer:
+        return att.get("prev", "")
+    for c in att.get("co_issuers") or []:
+        if c.get("issuer") == issuer:
+            return c.get("prev", "")
+    return None
+
+
+def verify_equivocation(proof: dict) -> bool:
+    """Check a proof that an issuer forked its own chain
+    (moltnet/equivocation/v0.1): two different, validly signed attestations
+    that both follow `prev` on `issuer`'s chain. That `a` and `b` are in hash
+    order needs BLAKE3 and is left to the registry / `molt verify`. Only
+    moltscore/v2 penalizes a proof; v1, which this client computes, does not."""
+    if proof.get("spec") != "moltnet/equivocation/v0.1":
+        return False
+    a, b = proof.get("a"), proof.get("b")
+    if not a or not b:
+        return False
+    for att in (a, b):
+        if _prev_of(att, proof.get("issuer")) != proof.get("prev") or not verify_attestation(att):
+            return False
+    return canonicalize_without(a, ["sig", "co_sigs"]) != canonicalize_without(b, ["sig", "co_sigs"])
 
 
 # --------------------------------------------------------------------------- #
@@ -225,6 +350,24 @@ def _decay(issued_at: str, now_sec: float, half_life_days: float) -> float:
     return 0.5 ** (days / half_life_days)
 
 
+def window(a: dict, t: datetime) -> str:
+    # Where t falls in the attestation's validity window (mirrors
+    # core.Attestation.Window): "not_yet_valid" before not_before, "expired"
+    # from expires_at on, "in_effect" otherwise. An unparseable bound leaves
+    # the record never in effect.
+    for key, state in (("not_before", "not_yet_valid"), ("expires_at", "expired")):
+        bound = a.get(key)
+        if not bound:
+            continue
+        try:
+            b = datetime.fromisoformat(bound.replace("Z", "+00:00"))
+        except Exception:
+            return state
+        if (t < b) if key == "not_before" else (t >= b):
+            return state
+    return "in_effect"
+
+
 def compute_score(
     atts: list[dict],
     issuer_weights: Optional[dict[str, float]] = None,
@@ -233,6 +376,44 @@ def compute_score(
 ) -> dict:
     if now is None:
         now = datetime.now(timezone.utc)
+    # A delegated attestation counts only under a delegation that covers it,
+    # and this client checks none: like score.Compute, it disregards them.
+    atts = [a for a in atts if window(a, now) == "in_effect" and not _delegated(a)]
+    out = _compute(atts, issuer_weights, owner_of, now)
+    caps = _capability_scores(atts, issuer_weights, owner_of, now)
+    if caps:
+        out["capabilities"] = caps
+    return out
+
+
+def _delegated(a: dict) -> bool:
+    return bool(a.get("delegation") or a.get("on_behalf_of"))
+
+
+def _capability_of(a: dict) -> str:
+    c = (a.get("body") or {}).get("capability")
+    return c if isinstance(c, str) else ""
+
+
+def _capability_scores(atts, issuer_weights, owner_of, now) -> dict:
+    # Per-capability vector (mirrors score/capability.go): each tag scored over
+    # its own attestations, plus untagged disputes and incidents.
+    out: dict = {}
+    for a in atts:
+        c = _capability_of(a)
+        if not c or c in out:
+            continue
+        subset = [
+            b for b in atts
+            if _capability_of(b) == c
+            or (_capability_of(b) == "" and b.get("type") in ("task.disputed", "incident"))
+        ]
+        o = _compute(subset, issuer_weights, owner_of, now)
+        out[c] = {"score": o["score"], "inputs": o["inputs"]}
+    return out
+
+
+def _compute(atts, issuer_weights, owner_of, now) -> dict:
     now_sec = now.timestamp()
 
     def weight_of(issuer: str) -> float:
@@ -250,23 +431,29 @@ def compute_score(
     issuers: set[str] = set()
 
     for a in atts:
-        if subject_owner is not None and owner_of.get(a.get("issuer", "")) == subject_owner:
+        # A co-signed attestation counts once, at the combined weight of the
+        # issuers the independence rule keeps, and for diversity under the
+        # first of them; it is self-dealing only if none are left (mirrors
+        # score/cosign.go).
+        kept = [p for p in _principals(a) if subject_owner is None or owner_of.get(p) != subject_owner]
+        if not kept:
             continue  # self-dealing
-        iw = weight_of(a.get("issuer", ""))
+        iw = _combined_weight([weight_of(p) for p in kept])
+        lead = kept[0]
         t = a.get("type")
         ts = a.get("issued_at", "")
         if t == "task.completed":
             inputs["completions"] += 1
             wc += iw * _decay(ts, now_sec, _HALF_LIFE_POS)
-            issuers.add(a["issuer"])
+            issuers.add(lead)
         elif t == "endorsement":
             inputs["endorsements"] += 1
             wc += 0.25 * iw * _decay(ts, now_sec, _HALF_LIFE_POS)
-            issuers.add(a["issuer"])
+            issuers.add(lead)
         elif t == "payment.receipt":
             inputs["receipts"] += 1
             wc += 0.5 * iw * _decay(ts, now_sec, _HALF_LIFE_POS)
-            issuers.add(a["issuer"])
+            issuers.add(lead)
         elif t == "task.disputed":
             inputs["disputes"] += 1
             wd += iw * _decay(ts, now_sec, _HALF_LIFE_POS)
@@ -281,6 +468,23 @@ def compute_score(
     return {"algorithm": "moltscore/v1", "score": score, "inputs": inputs}
 
 
+def _principals(a: dict) -> list[str]:
+    # The identities a counts for: its issuer, or for a co-signed attestation
+    # every issuer, lead first (mirrors core.Attestation.Principals).
+    return [a.get("issuer", "")] + [c.get("issuer", "") for c in a.get("co_issuers") or []]
+
+
+def _combined_weight(ws: list[float]) -> float:
+    # 1 − Π(1 − w) over the co-issuers' weights, each clamped to [0, 1]; one
+    # issuer keeps its weight exactly.
+    if len(ws) == 1:
+        return ws[0]
+    miss = 1.0
+    for w in ws:
+        miss *= 1 - min(max(w, 0.0), 1.0)
+    return 1 - miss
+
+
 # --------------------------------------------------------------------------- #
 # High-level: verify before invoke
 # --------------------------------------------------------------------------- #
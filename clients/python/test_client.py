@@ -50,6 +50,12 @@ class TestScore(unittest.TestCase):
         many = [{"type": "task.completed", "issuer": f"did:key:z{i}", "issued_at": iso} for i in range(8)]
         self.assertGreater(mc.compute_score(many, None, None, now)["score"], mc.compute_score(one, None, None, now)["score"])
 
+    def test_delegated_disregarded(self):
+        now = datetime.now(timezone.utc)
+        a = {"type": "task.completed", "issuer": "did:key:zW", "issued_at": now.isoformat(),
+             "on_behalf_of": "did:key:zP", "delegation": "blake3:00"}
+        self.assertEqual(mc.compute_score([a], None, None, now)["inputs"]["completions"], 0)
+
 
 class TestEd25519Interop(unittest.TestCase):
     def test_verifies_go_signed_card(self):
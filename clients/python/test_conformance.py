@@ -22,16 +22,48 @@ class TestConformance(unittest.TestCase):
         vectors = _load("canonical_vectors.json")
         self.assertGreater(len(vectors), 0)
         for v in vectors:
-            self.assertEqual(mc.canonicalize(v["input"]), v["expected"])
+            if v.get("error"):
+                with self.assertRaises(ValueError, msg=v["name"]):
+                    mc.canonicalize_json(v["input_text"])
+                continue
+            self.assertEqual(mc.canonicalize(v["input"]), v["expected"], v.get("name"))
 
     def test_score(self):
         vectors = _load("score_vectors.json")
         self.assertGreater(len(vectors), 0)
         for v in vectors:
             now = datetime.fromisoformat(v["now"].replace("Z", "+00:00"))
-            out = mc.compute_score(v.get("attestations") or [], None, None, now)
+            out = mc.compute_score(v.get("attestations") or [], v.get("issuer_weights"), v.get("owner_of"), now)
             self.assertAlmostEqual(out["score"], v["expected"]["score"], delta=0.05)
             self.assertEqual(out["inputs"], v["expected"]["inputs"])
+            caps = v["expected"].get("capabilities", {})
+            self.assertEqual(set(out.get("capabilities", {})), set(caps))
+            for tag, want in caps.items():
+                got = out["capabilities"][tag]
+                self.assertAlmostEqual(got["score"], want["score"], delta=0.05)
+                self.assertEqual(got["inputs"], want["inputs"])
+
+    def test_owner_policy(self):
+        vectors = _load("owner_policy_vectors.json")
+        self.assertGreater(len(vectors), 0)
+        for v in vectors:
+            rec = v["record"]
+            drop = ["sig", "owner_sig", "owner_sigs"] if v["kind"] == "card" else ["sig", "owner_sigs"]
+            payload = mc.canonicalize_without(rec, drop)
+            self.assertEqual(payload, v["signing_payload"], v["name"])
+            if v["kind"] == "card":
+                ok = mc.verify_card(rec)
+            else:
+                ok = mc.verify_owner_policy(rec["owner_policy"], payload, rec.get("owner_sigs") or [])
+            self.assertEqual(ok, v["valid"], v["name"])
+
+    def test_equivocation(self):
+        proofs = [e for v in _load("score_v2_vectors.json") for e in v.get("equivocations") or []]
+        self.assertGreater(len(proofs), 0)
+        for e in proofs:
+            self.assertTrue(mc.verify_equivocation(e))
+            self.assertFalse(mc.verify_equivocation(dict(e, prev="blake3:other")))
+            self.assertFalse(mc.verify_equivocation(dict(e, b=e["a"])))
 
 
 if __name__ == "__main__":
@@ -26,9 +26,15 @@ export interface Card {
   created_at: string;
   sig?: string;
   owner_sig?: string;
+  owner_policy?: OwnerPolicy;
+  owner_sigs?: OwnerSig[];
   [k: string]: unknown;
 }
 
+/** An M-of-N team owner (moltnet/owner-policy/v0.1); see core/ownerpolicy.go. */
+export interface OwnerPolicy { spec: string; threshold: number; members: string[] }
+export interface OwnerSig { signer: string; sig: string }
+
 export interface Attestation {
   spec?: string;
   type: string;
@@ -38,22 +44,37 @@ export interface Attestation {
   prev?: string;
   body?: Record<string, unknown>;
   issued_at: string;
+  not_before?: string;
+  expires_at?: string;
   sig?: string;
+  co_issuers?: { issuer: string; prev?: string }[];
+  co_sigs?: { signer: string; sig: string }[];
   [k: string]: unknown;
 }
 
-// ---- Canonicalization (JCS-compatible; mirrors core/canonical.go) ----
+// ---- Canonicalization (RFC 8785; mirrors core/canonical.go) ----
 
+// JSON.stringify already serializes numbers as RFC 8785 requires, and the
+// default sort compares UTF-16 code units; what is left is refusing values
+// JSON cannot carry exactly.
 export function canonicalize(v: unknown): string {
   if (Array.isArray(v)) return '[' + v.map(canonicalize).join(',') + ']';
   if (v && typeof v === 'object') {
     const obj = v as Record<string, unknown>;
     const keys = Object.keys(obj).sort();
-    return '{' + keys.map((k) => JSON.stringify(k) + ':' + canonicalize(obj[k])).join(',') + '}';
+    return '{' + keys.map((k) => canonicalString(k) + ':' + canonicalize(obj[k])).join(',') + '}';
   }
+  if (typeof v === 'number' && !Number.isFinite(v)) throw new Error(`canonicalize: ${v} is not a JSON number`);
+  if (typeof v === 'string') return canonicalString(v);
   return JSON.stringify(v);
 }
 
+function canonicalString(s: string): string {
+  // With the u flag a surrogate pair is one code point, so only lone halves match.
+  if (/[\uD800-\uDFFF]/u.test(s)) throw new Error('canonicalize: lone surrogate');
+  return JSON.stringify(s);
+}
+
 export function canonicalizeWithout(v: Record<string, unknown>, dropKeys: string[]): string {
   const clone: Record<string, unknown> = { ...v };
   for (const k of dropKeys) delete clone[k];
@@ -145,29 +166,117 @@ export async function verifySignature(signerDid: string, message: string, sigHex
   }
 }
 
-/** Verify a card's agent and owner signatures. */
+/**
+ * Verify that `sigs` over `payload` meet an owner policy's threshold: every
+ * listed signature valid and by a distinct member. That the policy hashes to
+ * the record's owner DID needs BLAKE3 and is left to the registry / `molt
+ * verify`, like chain linkage.
+ */
+export async function verifyOwnerPolicy(policy: OwnerPolicy, payload: string, sigs: OwnerSig[]): Promise<boolean> {
+  if (policy.spec !== 'moltnet/owner-policy/v0.1') return false;
+  const m = policy.members;
+  if (policy.threshold < 1 || policy.threshold > m.length) return false;
+  for (let i = 1; i < m.length; i++) if (!(m[i - 1] < m[i])) return false;
+  const seen = new Set<string>();
+  for (const s of sigs) {
+    if (!m.includes(s.signer) || seen.has(s.signer)) return false;
+    seen.add(s.signer);
+    if (!(await verifySignature(s.signer, payload, s.sig))) return false;
+  }
+  return seen.size >= policy.threshold;
+}
+
+/** Verify a card's agent and owner signatures (or owner policy threshold). */
 export async function verifyCard(card: Card): Promise<boolean> {
-  if (card.spec !== 'moltnet/card/v0.1' || !card.sig || !card.owner_sig) return false;
-  const payload = canonicalizeWithout(card as Record<string, unknown>, ['sig', 'owner_sig']);
-  const agentOk = await verifySignature(card.id, payload, card.sig);
-  const ownerOk = await verifySignature(card.owner, payload, card.owner_sig);
-  return agentOk && ownerOk;
+  if (card.spec !== 'moltnet/card/v0.1' || !card.sig) return false;
+  const payload = canonicalizeWithout(card as Record<string, unknown>, ['sig', 'owner_sig', 'owner_sigs']);
+  if (!(await verifySignature(card.id, payload, card.sig))) return false;
+  if (card.owner.startsWith('did:moltpolicy:')) {
+    if (!card.owner_policy || card.owner_sig) return false;
+    return verifyOwnerPolicy(card.owner_policy, payload, card.owner_sigs || []);
+  }
+  if (!card.owner_sig || card.owner_policy || card.owner_sigs) return false;
+  return verifySignature(card.owner, payload, card.owner_sig);
 }
 
-/** Verify an attestation's issuer signature. */
+/**
+ * Verify an attestation's issuer signature and, for a co-signed one, that
+ * every co-issuer, distinct from the others and from the lead, and only they,
+ * signed the same payload.
+ */
 export async function verifyAttestation(att: Attestation): Promise<boolean> {
   if (!att.sig) return false;
-  const payload = canonicalizeWithout(att as Record<string, unknown>, ['sig']);
-  return verifySignature(att.issuer, payload, att.sig);
+  const payload = canonicalizeWithout(att as Record<string, unknown>, ['sig', 'co_sigs']);
+  if (!(await verifySignature(att.issuer, payload, att.sig))) return false;
+  const cos = att.co_issuers ?? [], sigs = att.co_sigs ?? [];
+  if (cos.length === 0) return sigs.length === 0;
+  if (att.on_behalf_of) return false; // a co-signed attestation cannot be delegated
+  const issuers = principals(att);
+  if (issuers.some((i) => !i) || new Set(issuers).size !== issuers.length || sigs.length !== cos.length) return false;
+  for (const c of cos) {
+    const s = sigs.find((s) => s.signer === c.issuer);
+    if (!s || !(await verifySignature(c.issuer, payload, s.sig))) return false;
+  }
+  return true;
+}
+
+/** A proof that an issuer forked its own chain (moltnet/equivocation/v0.1). */
+export interface Equivocation { spec: string; issuer: string; prev: string; a: Attestation; b: Attestation }
+
+/** The link att follows on issuer's chain, or null if issuer did not issue it. */
+function prevOf(att: Attestation, issuer: string): string | null {
+  if (att.issuer === issuer) return att.prev ?? '';
+  const c = (att.co_issuers ?? []).find((c) => c.issuer === issuer);
+  return c ? c.prev ?? '' : null;
+}
+
+/**
+ * Verify an equivocation proof: two different, validly signed attestations
+ * that both follow `prev` on `issuer`'s chain. That `a` and `b` are in hash
+ * order needs BLAKE3 and is left to the registry / `molt verify`. Only
+ * moltscore/v2 penalizes a proof; v1, which this client computes, does not.
+ */
+export async function verifyEquivocation(proof: Equivocation): Promise<boolean> {
+  if (proof.spec !== 'moltnet/equivocation/v0.1' || !proof.a || !proof.b) return false;
+  for (const att of [proof.a, proof.b]) {
+    if (prevOf(att, proof.issuer) !== proof.prev || !(await verifyAttestation(att))) return false;
+  }
+  const body = (a: Attestation) => canonicalizeWithout(a as Record<string, unknown>, ['sig', 'co_sigs']);
+  return body(proof.a) !== body(proof.b);
 }
 
 // ---- MoltScore v1 (mirrors score/score.go) ----
 
+/** The identities att counts for: its issuer, or every issuer of a co-signed one, lead first. */
+function principals(att: Attestation): string[] {
+  return [att.issuer, ...(att.co_issuers ?? []).map((c) => c.issuer)];
+}
+
+/**
+ * The weight of one statement made jointly by issuers of weights ws,
+ * 1 − Π(1 − w) with each w clamped to [0, 1] (mirrors score/cosign.go). One
+ * issuer keeps its weight exactly.
+ */
+function combinedWeight(ws: number[]): number {
+  if (ws.length === 1) return ws[0];
+  let miss = 1;
+  for (const w of ws) miss *= 1 - Math.min(Math.max(w, 0), 1);
+  return 1 - miss;
+}
+
 export interface ScoreInputs {
   completions: number; disputes: number; incidents: number;
   endorsements: number; receipts: number; distinct_issuers: number;
 }
-export interface ScoreOutput { algorithm: string; score: number; inputs: ScoreInputs }
+export interface CapabilityScore { score: number; inputs: ScoreInputs }
+
+export interface ScoreOutput {
+  algorithm: string;
+  score: number;
+  inputs: ScoreInputs;
+  /** Per-capability vector keyed by body.capability; absent when nothing is tagged. */
+  capabilities?: Record<string, CapabilityScore>;
+}
 
 const HALF_LIFE_POS = 180, HALF_LIFE_INC = 365;
 
@@ -178,6 +287,24 @@ function decay(issuedAt: string, nowSec: number, halfLifeDays: number): number {
   return Math.pow(0.5, days / halfLifeDays);
 }
 
+export type WindowState = 'in_effect' | 'not_yet_valid' | 'expired';
+
+/**
+ * Where t falls in the attestation's validity window (mirrors
+ * core.Attestation.Window). An unparseable bound leaves it never in effect.
+ */
+export function window(a: Attestation, t: Date): WindowState {
+  if (a.not_before) {
+    const nb = Date.parse(a.not_before);
+    if (Number.isNaN(nb) || t.getTime() < nb) return 'not_yet_valid';
+  }
+  if (a.expires_at) {
+    const exp = Date.parse(a.expires_at);
+    if (Number.isNaN(exp) || t.getTime() >= exp) return 'expired';
+  }
+  return 'in_effect';
+}
+
 /**
  * Compute MoltScore v1. With no issuerWeights every issuer weighs 1.0 (the
  * correct trustless default); with a weights map, unknown issuers weigh 0.25.
@@ -187,6 +314,48 @@ export function computeScore(
   issuerWeights: Record<string, number> | null = null,
   ownerOf: Record<string, string> | null = null,
   now: Date = new Date()
+): ScoreOutput {
+  // A delegated attestation counts only under a delegation that covers it, and
+  // this client checks none: like score.Compute, it disregards them.
+  atts = atts.filter((a) => window(a, now) === 'in_effect' && !a.delegation && !a.on_behalf_of);
+  const out = computeV1(atts, issuerWeights, ownerOf, now);
+  const caps = capabilityScores(atts, issuerWeights, ownerOf, now);
+  if (caps) out.capabilities = caps;
+  return out;
+}
+
+const capabilityOf = (a: Attestation): string => {
+  const c = a.body?.capability;
+  return typeof c === 'string' ? c : '';
+};
+
+// Per-capability vector (mirrors score/capability.go): each tag scored over its
+// own attestations, plus untagged disputes and incidents.
+function capabilityScores(
+  atts: Attestation[],
+  issuerWeights: Record<string, number> | null,
+  ownerOf: Record<string, string> | null,
+  now: Date
+): Record<string, CapabilityScore> | undefined {
+  let out: Record<string, CapabilityScore> | undefined;
+  for (const a of atts) {
+    const c = capabilityOf(a);
+    if (!c || (out && c in out)) continue;
+    const subset = atts.filter((b) => {
+      const bc = capabilityOf(b);
+      return bc === c || (bc === '' && (b.type === 'task.disputed' || b.type === 'incident'));
+    });
+    const o = computeV1(subset, issuerWeights, ownerOf, now);
+    (out ??= {})[c] = { score: o.score, inputs: o.inputs };
+  }
+  return out;
+}
+
+function computeV1(
+  atts: Attestation[],
+  issuerWeights: Record<string, number> | null,
+  ownerOf: Record<string, string> | null,
+  now: Date
 ): ScoreOutput {
   const nowSec = now.getTime() / 1000;
   const weightOf = (issuer: string): number =>
@@ -202,12 +371,17 @@ export function computeScore(
   const issuers = new Set<string>();
 
   for (const a of atts) {
-    if (subjectOwner !== undefined && ownerOf![a.issuer] === subjectOwner) continue; // self-dealing
-    const iw = weightOf(a.issuer);
+    // A co-signed attestation counts once, at the combined weight of the
+    // issuers the independence rule keeps, and for diversity under the first
+    // of them; it is self-dealing only if none are left (mirrors score/cosign.go).
+    const kept = principals(a).filter((p) => subjectOwner === undefined || ownerOf![p] !== subjectOwner);
+    if (kept.length === 0) continue; // self-dealing
+    const iw = combinedWeight(kept.map(weightOf));
+    const lead = kept[0];
     switch (a.type) {
-      case 'task.completed': inputs.completions++; wc += iw * decay(a.issued_at, nowSec, HALF_LIFE_POS); issuers.add(a.issuer); break;
-      case 'endorsement': inputs.endorsements++; wc += 0.25 * iw * decay(a.issued_at, nowSec, HALF_LIFE_POS); issuers.add(a.issuer); break;
-      case 'payment.receipt': inputs.receipts++; wc += 0.5 * iw * decay(a.issued_at, nowSec, HALF_LIFE_POS); issuers.add(a.issuer); break;
+      case 'task.completed': inputs.completions++; wc += iw * decay(a.issued_at, nowSec, HALF_LIFE_POS); issuers.add(lead); break;
+      case 'endorsement': inputs.endorsements++; wc += 0.25 * iw * decay(a.issued_at, nowSec, HALF_LIFE_POS); issuers.add(lead); break;
+      case 'payment.receipt': inputs.receipts++; wc += 0.5 * iw * decay(a.issued_at, nowSec, HALF_LIFE_POS); issuers.add(lead); break;
       case 'task.disputed': inputs.disputes++; wd += iw * decay(a.issued_at, nowSec, HALF_LIFE_POS); break;
       case 'incident': inputs.incidents++; wi += iw * decay(a.issued_at, nowSec, HALF_LIFE_INC); break;
       // self.claim and key.rotation contribute nothing.
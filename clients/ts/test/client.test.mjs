@@ -42,6 +42,15 @@ test('computeScore: diversity beats volume', () => {
   assert.ok(computeScore(many, null, null, now).score > computeScore(one, null, null, now).score);
 });
 
+test('computeScore: delegated attestations are disregarded', () => {
+  const now = new Date();
+  const a = {
+    type: 'task.completed', issuer: 'did:key:zW', issued_at: now.toISOString(),
+    on_behalf_of: 'did:key:zP', delegation: 'blake3:00',
+  };
+  assert.equal(computeScore([a], null, null, now).inputs.completions, 0);
+});
+
 // Interop: verify a doubly-signed card produced by the Go `molt` CLI.
 test('verifies a Go-produced signed card (JS<->Go interop)', async () => {
   const path = process.env.MOLT_CARD;
@@ -1,7 +1,7 @@
 import { test } from 'node:test';
 import assert from 'node:assert';
 import { readFileSync } from 'node:fs';
-import { canonicalize, computeScore } from '../dist/index.js';
+import { canonicalize, canonicalizeWithout, computeScore, verifyCard, verifyEquivocation, verifyOwnerPolicy } from '../dist/index.js';
 
 // spec/conformance/ lives three levels up from clients/ts/test/.
 const dir = new URL('../../../spec/conformance/', import.meta.url);
@@ -10,16 +10,52 @@ const load = (f) => JSON.parse(readFileSync(new URL(f, dir), 'utf8'));
 test('canonicalization matches the shared conformance vectors', () => {
   const vectors = load('canonical_vectors.json');
   assert.ok(vectors.length > 0);
-  for (const v of vectors) assert.equal(canonicalize(v.input), v.expected);
+  for (const v of vectors) {
+    // JSON.parse keeps the last of duplicate keys, so text that must be
+    // rejected is checked by the Go and Python suites only.
+    if (v.error) continue;
+    assert.equal(canonicalize(v.input), v.expected, v.name);
+  }
 });
 
 test('MoltScore matches the shared conformance vectors', () => {
   const vectors = load('score_vectors.json');
   assert.ok(vectors.length > 0);
   for (const v of vectors) {
-    const out = computeScore(v.attestations || [], null, null, new Date(v.now));
+    const out = computeScore(v.attestations || [], v.issuer_weights ?? null, v.owner_of ?? null, new Date(v.now));
     assert.ok(Math.abs(out.score - v.expected.score) < 0.05,
       `score ${out.score} vs expected ${v.expected.score}`);
     assert.deepEqual(out.inputs, v.expected.inputs);
+    const caps = v.expected.capabilities || {};
+    assert.deepEqual(Object.keys(out.capabilities || {}).sort(), Object.keys(caps).sort());
+    for (const [tag, want] of Object.entries(caps)) {
+      assert.ok(Math.abs(out.capabilities[tag].score - want.score) < 0.05, `${tag}: ${out.capabilities[tag].score} vs ${want.score}`);
+      assert.deepEqual(out.capabilities[tag].inputs, want.inputs);
+    }
+  }
+});
+
+test('owner policies match the shared conformance vectors', async () => {
+  const vectors = load('owner_policy_vectors.json');
+  assert.ok(vectors.length > 0);
+  for (const v of vectors) {
+    const rec = v.record;
+    const drop = v.kind === 'card' ? ['sig', 'owner_sig', 'owner_sigs'] : ['sig', 'owner_sigs'];
+    const payload = canonicalizeWithout(rec, drop);
+    assert.equal(payload, v.signing_payload, v.name);
+    const ok = v.kind === 'card'
+      ? await verifyCard(rec)
+      : await verifyOwnerPolicy(rec.owner_policy, payload, rec.owner_sigs || []);
+    assert.equal(ok, v.valid, v.name);
+  }
+});
+
+test('equivocation proofs in the shared conformance vectors verify', async () => {
+  const proofs = load('score_v2_vectors.json').flatMap((v) => v.equivocations || []);
+  assert.ok(proofs.length > 0);
+  for (const e of proofs) {
+    assert.equal(await verifyEquivocation(e), true);
+    assert.equal(await verifyEquivocation({ ...e, prev: 'blake3:other' }), false);
+    assert.equal(await verifyEquivocation({ ...e, b: e.a }), false);
   }
 });
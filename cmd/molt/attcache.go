@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/moltnet/moltnet/core"
+)
+
+// attCache is a local copy of a registry's full attestation set, kept so
+// moltscore/v2 issuer weights — a property of the whole graph — can be
+// recomputed without running a federated moltnetd. It is refreshed
+// incrementally from GET /v1/attestations, resuming at the stored cursor.
+type attCache struct {
+	Registry     string              `json:"registry"`
+	Cursor       int64               `json:"cursor"`
+	Attestations []*core.Attestation `json:"attestations"`
+}
+
+// attCachePath names the cache file for a registry. The registry URL is hashed
+// so any URL maps to a safe file name and two registries never share a cache.
+func attCachePath(registry string) string {
+	h := strings.TrimPrefix(core.HashBytes([]byte(registry)), "blake3:")
+	return filepath.Join(moltDir(), "attestations", h[:16]+".json")
+}
+
+// syncAttestations brings the cache at path up to date with the registry and
+// returns it with the number of attestations added. Every downloaded record's
+// signature is checked before it is cached: the registry is trusted for
+// transport only, here as everywhere else.
+func syncAttestations(registry, path string) (*attCache, int, error) {
+	c := &attCache{Registry: registry}
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		if err := json.Unmarshal(data, c); err != nil {
+			return nil, 0, fmt.Errorf("attestation cache %s: %w", path, err)
+		}
+		if c.Registry != registry {
+			c = &attCache{Registry: registry}
+		}
+	case !errors.Is(err, os.ErrNotExist):
+		return nil, 0, err
+	}
+
+	seen := make(map[string]struct{}, len(c.Attestations))
+	for _, a := range c.Attestations {
+		h, _ := a.Hash()
+		seen[h] = struct{}{}
+	}
+	added := 0
+	for {
+		var page struct {
+			Attestations []*core.Attestation `json:"attestations"`
+			Cursor       int64               `json:"cursor"`
+			Latest       int64               `json:"latest"`
+		}
+		if err := httpGet(fmt.Sprintf("%s/v1/attestations?since=%d&limit=500", registry, c.Cursor), &page); err != nil {
+			return nil, 0, err
+		}
+		for _, a := range page.Attestations {
+			h, err := a.Hash()
+			if err != nil {
+				return nil, 0, err
+			}
+			if err := a.Verify(); err != nil {
+				return nil, 0, fmt.Errorf("registry served attestation %s with a bad signature: %w", h, err)
+			}
+			if _, dup := seen[h]; dup {
+				continue
+			}
+			seen[h] = struct{}{}
+			c.Attestations = append(c.Attestations, a)
+			added++
+		}
+		if page.Cursor <= c.Cursor || page.Cursor >= page.Latest {
+			c.Cursor = max(c.Cursor, page.Cursor)
+			break
+		}
+		c.Cursor = page.Cursor
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, 0, err
+	}
+	out, err := json.Marshal(c)
+	if err != nil {
+		return nil, 0, err
+	}
+	return c, added, os.WriteFile(path, out, 0o600)
+}
@@ -105,7 +105,10 @@ func cmdLogin(args []string) error {
 	}
 
 	// 2. sign the SIWK message locally with the owner key
-	sig := core.Sign(ownerKP.Private, []byte(chall.Message))
+	sig, err := core.Sign(ownerKP.Private, []byte(chall.Message))
+	if err != nil {
+		return err
+	}
 
 	// 3. submit the signature → receive a session token
 	var login struct {
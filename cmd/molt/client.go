@@ -55,6 +55,10 @@ func registryURL(flagVal string) string {
 
 var httpClient = &http.Client{Timeout: 15 * time.Second}
 
+// didWeb resolves the DID documents of did:web card owners, straight from
+// their domains — never through the registry.
+var didWeb = core.NewDIDWebResolver(httpClient)
+
 func httpGet(url string, out any) error {
 	resp, err := httpClient.Get(url)
 	if err != nil {
@@ -102,17 +106,138 @@ func fetchIssuerHead(registry, issuerDID string) (string, error) {
 
 // fetchAgent returns the card and raw attestations for a DID.
 func fetchAgent(registry, did string) (*core.Card, []*core.Attestation, error) {
+	card, err := fetchCard(registry, did)
+	if err != nil {
+		return nil, nil, err
+	}
+	atts, err := fetchAttestations(registry, did)
+	if err != nil {
+		return nil, nil, err
+	}
+	return card, atts, nil
+}
+
+// fetchCard returns the current card for a DID.
+func fetchCard(registry, did string) (*core.Card, error) {
 	var agentResp struct {
 		Card *core.Card `json:"card"`
 	}
 	if err := httpGet(registry+"/v1/agents/"+did, &agentResp); err != nil {
-		return nil, nil, err
+		return nil, err
 	}
+	return agentResp.Card, nil
+}
+
+// fetchAttestations returns the raw attestations about a DID.
+func fetchAttestations(registry, did string) ([]*core.Attestation, error) {
 	var attResp struct {
 		Attestations []*core.Attestation `json:"attestations"`
 	}
 	if err := httpGet(registry+"/v1/agents/"+did+"/attestations", &attResp); err != nil {
+		return nil, err
+	}
+	return attResp.Attestations, nil
+}
+
+// fetchRevocations returns every key revocation the registry holds.
+func fetchRevocations(registry string) ([]*core.Revocation, error) {
+	var resp struct {
+		Revocations []*core.Revocation `json:"revocations"`
+	}
+	if err := httpGet(registry+"/v1/revocations", &resp); err != nil {
+		return nil, err
+	}
+	return resp.Revocations, nil
+}
+
+// fetchDelegations returns every issuing-key delegation the registry holds,
+// as it claims them.
+func fetchDelegations(registry string) ([]*core.Delegation, error) {
+	var resp struct {
+		Delegations []*core.Delegation `json:"delegations"`
+	}
+	if err := httpGet(registry+"/v1/delegations", &resp); err != nil {
+		return nil, err
+	}
+	return resp.Delegations, nil
+}
+
+// fetchForkResolutions returns the card fork resolutions the registry applied
+// for did, as it claims them.
+func fetchForkResolutions(registry, did string) ([]*core.ForkResolution, error) {
+	var resp struct {
+		ForkResolutions []*core.ForkResolution `json:"fork_resolutions"`
+	}
+	if err := httpGet(registry+"/v1/fork-resolutions?did="+url.QueryEscape(did), &resp); err != nil {
+		return nil, err
+	}
+	return resp.ForkResolutions, nil
+}
+
+// fetchEquivocations returns the proofs the registry holds that issuer forked
+// its own attestation chain, as it claims them.
+func fetchEquivocations(registry, issuer string) ([]*core.Equivocation, error) {
+	var resp struct {
+		Equivocations []*core.Equivocation `json:"equivocations"`
+	}
+	if err := httpGet(registry+"/v1/equivocations?issuer="+url.QueryEscape(issuer), &resp); err != nil {
+		return nil, err
+	}
+	return resp.Equivocations, nil
+}
+
+// fetchOwnerRotations returns every owner rotation the registry holds, as it
+// claims them.
+func fetchOwnerRotations(registry string) ([]*core.OwnerRotation, error) {
+	var resp struct {
+		OwnerRotations []*core.OwnerRotation `json:"owner_rotations"`
+	}
+	if err := httpGet(registry+"/v1/owner-rotations", &resp); err != nil {
+		return nil, err
+	}
+	return resp.OwnerRotations, nil
+}
+
+// fetchGuardianSets returns the guardian sets of owner, or of every owner if
+// owner is "", oldest first.
+func fetchGuardianSets(registry, owner string) ([]*core.GuardianSet, error) {
+	var resp struct {
+		GuardianSets []*core.GuardianSet `json:"guardian_sets"`
+	}
+	if err := httpGet(registry+"/v1/guardian-sets?owner="+urlEscape(owner), &resp); err != nil {
+		return nil, err
+	}
+	return resp.GuardianSets, nil
+}
+
+// fetchRecoveries returns the recoveries of owner (or of every owner if owner
+// is ""), and the vetoes bearing on them, as signed records: the registry's
+// own judgement of them is dropped so the caller can make its own.
+func fetchRecoveries(registry, owner string) ([]*core.Recovery, []*core.RecoveryVeto, error) {
+	var resp struct {
+		Recoveries []core.RecoveryState `json:"recoveries"`
+		Vetoes     []*core.RecoveryVeto `json:"vetoes"`
+	}
+	if err := httpGet(registry+"/v1/recoveries?owner="+urlEscape(owner), &resp); err != nil {
 		return nil, nil, err
 	}
-	return agentResp.Card, attResp.Attestations, nil
+	recs := make([]*core.Recovery, 0, len(resp.Recoveries))
+	for _, st := range resp.Recoveries {
+		if st.Recovery != nil {
+			recs = append(recs, st.Recovery)
+		}
+	}
+	return recs, resp.Vetoes, nil
+}
+
+// fetchLineage returns the rotations that retired a DID's predecessor keys,
+// oldest first, as the registry claims them.
+func fetchLineage(registry, did string) ([]*core.Rotation, error) {
+	var resp struct {
+		Lineage []*core.Rotation `json:"lineage"`
+	}
+	if err := httpGet(registry+"/v1/agents/"+did+"/lineage", &resp); err != nil {
+		return nil, err
+	}
+	return resp.Lineage, nil
 }
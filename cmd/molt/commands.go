@@ -5,6 +5,7 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
@@ -17,24 +18,29 @@ import (
 func cmdKeygen(args []string) error {
 	fs := flag.NewFlagSet("keygen", flag.ExitOnError)
 	out := fs.String("out", "", "output keyfile path (default: <kind>.key)")
-	kind := fs.String("kind", "agent", "key kind: owner or agent")
+	kind := fs.String("kind", "agent", "whose key this is, owner or agent; names the default file")
+	keyType := fs.String("type", "ed25519", "key type: ed25519, p256 or secp256k1")
 	fs.Parse(args)
 
 	if *kind != "owner" && *kind != "agent" {
 		return fmt.Errorf("--kind must be owner or agent")
 	}
+	typ, err := core.ParseKeyType(*keyType)
+	if err != nil {
+		return err
+	}
 	path := *out
 	if path == "" {
 		path = *kind + ".key"
 	}
-	kp, err := core.GenerateKeyPair()
+	kp, err := core.GenerateKeyPairOf(typ)
 	if err != nil {
 		return err
 	}
-	if err := writeKeyfile(path, kp, *kind); err != nil {
+	if err := writeKeyfile(path, kp); err != nil {
 		return err
 	}
-	fmt.Printf("created %s keypair\n  DID:  %s\n  file: %s (keep private)\n", *kind, kp.DID, path)
+	fmt.Printf("created %s %s keypair\n  DID:  %s\n  file: %s (keep private)\n", *kind, typ, kp.DID, path)
 	return nil
 }
 
@@ -51,12 +57,20 @@ func cmdCard(args []string) error {
 	if len(args) > 0 && args[0] == "update" {
 		return cmdCardUpdate(args[1:])
 	}
+	if len(args) > 0 && args[0] == "sign" {
+		return cmdCardSign(args[1:])
+	}
+	if len(args) > 0 && args[0] == "resolve" {
+		return cmdCardResolve(args[1:])
+	}
 	if len(args) == 0 || args[0] != "new" {
-		return fmt.Errorf("usage: molt card <new|update> [flags]")
+		return fmt.Errorf("usage: molt card <new|update|sign|resolve> [flags]")
 	}
 	fs := flag.NewFlagSet("card new", flag.ExitOnError)
 	agentFile := fs.String("agent", "agent.key", "agent keyfile")
 	ownerFile := fs.String("owner", "owner.key", "owner keyfile")
+	policyFile := fs.String("owner-policy", "", "owner policy file: the card is owned by the policy and its members sign with `molt card sign`")
+	ownerWeb := fs.String("owner-did", "", "did:web the owner key signs for; the domain's DID document must list the key (see `molt did-doc`)")
 	name := fs.String("name", "", "agent name (required)")
 	desc := fs.String("desc", "", "agent description")
 	version := fs.String("agent-version", "0.1.0", "agent version string")
@@ -77,11 +91,30 @@ func cmdCard(args []string) error {
 	if err != nil {
 		return err
 	}
-	ownerKP, err := loadKeyfile(*ownerFile)
-	if err != nil {
-		return err
+	var ownerKP *core.KeyPair
+	var policy *core.OwnerPolicy
+	ownerDID := ""
+	if *policyFile != "" {
+		if policy, ownerDID, err = loadPolicy(*policyFile); err != nil {
+			return err
+		}
+	} else {
+		if ownerKP, err = loadKeyfile(*ownerFile); err != nil {
+			return err
+		}
+		ownerDID = ownerKP.DID
+	}
+	if *ownerWeb != "" {
+		if ownerKP == nil || !core.IsDIDWeb(*ownerWeb) {
+			return fmt.Errorf("--owner-did takes a did:web, signed for by the --owner key")
+		}
+		ownerDID = *ownerWeb
+	}
+	c := core.NewCard(agentKP.DID, ownerDID, *name)
+	c.OwnerPolicy = policy
+	if core.IsDIDWeb(ownerDID) {
+		c.OwnerKey = ownerKP.DID
 	}
-	c := core.NewCard(agentKP.DID, ownerKP.DID, *name)
 	c.Description = *desc
 	c.Version = *version
 	for _, tag := range caps {
@@ -108,6 +141,9 @@ func cmdCard(args []string) error {
 	if *livenessURL != "" {
 		c.Liveness = &core.Liveness{Enabled: true, URL: *livenessURL}
 	}
+	if policy != nil {
+		return writePolicyCard(c, agentKP, *out)
+	}
 	if err := c.Sign(agentKP.Private, ownerKP.Private); err != nil {
 		return err
 	}
@@ -141,10 +177,6 @@ func cmdCardUpdate(args []string) error {
 	if err != nil {
 		return err
 	}
-	ownerKP, err := loadKeyfile(*ownerFile)
-	if err != nil {
-		return err
-	}
 	reg := registryURL(*registry)
 
 	current, _, err := fetchAgent(reg, agentKP.DID)
@@ -161,7 +193,7 @@ func cmdCardUpdate(args []string) error {
 
 	// Start from the current card, apply overrides, link to the head.
 	next := *current
-	next.Sig, next.OwnerSig = "", ""
+	next.Sig, next.OwnerSig, next.OwnerSigs = "", "", nil
 	next.Prev = headHash
 	next.CreatedAt = time.Now().UTC().Format(time.RFC3339)
 	if *name != "" {
@@ -179,6 +211,18 @@ func cmdCardUpdate(args []string) error {
 			next.Capabilities = append(next.Capabilities, core.Capability{Tag: tag})
 		}
 	}
+	if next.OwnerPolicy != nil {
+		// A team-owned card cannot be submitted until its members have signed.
+		fmt.Printf("prev: %s\n", headHash)
+		return writePolicyCard(&next, agentKP, *out)
+	}
+	ownerKP, err := loadKeyfile(*ownerFile)
+	if err != nil {
+		return err
+	}
+	if core.IsDIDWeb(next.Owner) {
+		next.OwnerKey = ownerKP.DID
+	}
 	if err := next.Sign(agentKP.Private, ownerKP.Private); err != nil {
 		return err
 	}
@@ -200,6 +244,84 @@ func cmdCardUpdate(args []string) error {
 	return nil
 }
 
+// cmdCardResolve settles a card-version fork: the agent and owner keys sign a
+// fork resolution choosing the version to keep, and the registry moves the
+// agent's head to it.
+func cmdCardResolve(args []string) error {
+	fs := flag.NewFlagSet("card resolve", flag.ExitOnError)
+	agentFile := fs.String("agent", "agent.key", "agent keyfile")
+	ownerFile := fs.String("owner", "owner.key", "owner keyfile")
+	head := fs.String("head", "", "hash of the card version to keep (required)")
+	out := fs.String("out", "fork-resolution.json", "output path")
+	registry := fs.String("registry", "", "registry base URL")
+	noSubmit := fs.Bool("no-submit", false, "write the resolution but do not submit")
+	var rejected stringSlice
+	fs.Var(&rejected, "reject", "hash of a competing version to set aside (repeatable, required)")
+	fs.Parse(args)
+
+	if *head == "" || len(rejected) == 0 {
+		return fmt.Errorf("--head and at least one --reject are required")
+	}
+	agentKP, err := loadKeyfile(*agentFile)
+	if err != nil {
+		return err
+	}
+	reg := registryURL(*registry)
+	current, err := fetchCard(reg, agentKP.DID)
+	if err != nil {
+		return fmt.Errorf("fetch current card: %w", err)
+	}
+	if current == nil {
+		return fmt.Errorf("agent %s is not registered", agentKP.DID)
+	}
+	if current.OwnerPolicy != nil {
+		return fmt.Errorf("%s is owned by a policy; this command signs for a single owner key", agentKP.DID)
+	}
+	ownerKP, err := loadKeyfile(*ownerFile)
+	if err != nil {
+		return err
+	}
+	fr := core.NewForkResolution(current, *head, rejected...)
+	if core.IsDIDWeb(fr.Owner) {
+		fr.OwnerKey = ownerKP.DID
+	} else {
+		fr.Owner = ownerKP.DID // the card's owner, or the key it was rotated to
+	}
+	if err := fr.Sign(agentKP.Private, ownerKP.Private); err != nil {
+		return err
+	}
+	if err := writeJSONFile(*out, fr); err != nil {
+		return err
+	}
+	hash, _ := fr.Hash()
+	fmt.Printf("fork resolution written to %s\n  head: %s\n  hash: %s\n", *out, *head, hash)
+	if *noSubmit {
+		return nil
+	}
+	var resp map[string]any
+	if err := httpPostJSON(reg+"/v1/fork-resolutions", fr, &resp); err != nil {
+		return err
+	}
+	fmt.Printf("submitted: %s is now the head of %s\n", *head, agentKP.DID)
+	return nil
+}
+
+// writePolicyCard signs a policy-owned card with the agent key alone and writes
+// it for the policy's members to sign in turn.
+func writePolicyCard(c *core.Card, agentKP *core.KeyPair, out string) error {
+	if err := c.SignAgent(agentKP.Private); err != nil {
+		return err
+	}
+	if err := writeJSONFile(out, c); err != nil {
+		return err
+	}
+	hash, _ := c.Hash()
+	fmt.Printf("agent-signed card written to %s\n  agent: %s\n  owner: %s (policy, %d of %d)\n  hash:  %s\n",
+		out, c.ID, c.Owner, c.OwnerPolicy.Threshold, len(c.OwnerPolicy.Members), hash)
+	fmt.Printf("each member signs with `molt card sign --owner member.key --card %s`, then `molt register --card %s`\n", out, out)
+	return nil
+}
+
 func cmdRegister(args []string) error {
 	fs := flag.NewFlagSet("register", flag.ExitOnError)
 	cardFile := fs.String("card", "card.json", "signed card to submit")
@@ -227,6 +349,12 @@ func cmdRegister(args []string) error {
 }
 
 func cmdAttest(args []string) error {
+	if len(args) > 0 && args[0] == "sign" {
+		return cmdAttestSign(args[1:])
+	}
+	if len(args) > 0 && args[0] == "submit" {
+		return cmdAttestSubmit(args[1:])
+	}
 	fs := flag.NewFlagSet("attest", flag.ExitOnError)
 	typ := fs.String("type", core.TypeTaskCompleted, "attestation type")
 	issuerFile := fs.String("issuer", "agent.key", "issuer keyfile")
@@ -234,12 +362,39 @@ func cmdAttest(args []string) error {
 	outcome := fs.String("outcome", "success", "outcome for task.completed")
 	capability := fs.String("capability", "", "capability tag exercised")
 	note := fs.String("note", "", "free-text note / reason")
+	delegation := fs.String("delegation", "", "issue as a delegate: hash of the delegation from `molt delegate`")
+	retracts := fs.String("retracts", "", "withdraw one of your earlier attestations about --subject, by hash (a retraction; --note gives the reason)")
+	notBefore := fs.String("not-before", "", "RFC 3339 time the attestation takes effect")
+	expiresAt := fs.String("expires-at", "", "RFC 3339 time the attestation stops counting")
+	expiresIn := fs.String("expires-in", "", "validity period from now, e.g. 90d or 720h (instead of --expires-at)")
 	registry := fs.String("registry", "", "registry base URL")
+	out := fs.String("out", "attestation.json", "with --co-issuer, output attestation path")
+	var fields, coIssuers stringSlice
+	fs.Var(&fields, "body", "extra body field as key=value, e.g. amount=19.90 (repeatable)")
+	fs.Var(&coIssuers, "co-issuer", "co-issuer DID: write the attestation for it to sign with `molt attest sign` (repeatable)")
 	fs.Parse(args)
 
 	if *subject == "" {
 		return fmt.Errorf("--subject is required")
 	}
+	if len(coIssuers) > 0 && *delegation != "" {
+		return fmt.Errorf("a co-signed attestation cannot be delegated")
+	}
+	if *expiresIn != "" {
+		if *expiresAt != "" {
+			return fmt.Errorf("--expires-in and --expires-at are exclusive")
+		}
+		d, err := parseValidity(*expiresIn)
+		if err != nil {
+			return err
+		}
+		*expiresAt = time.Now().UTC().Add(d).Format(time.RFC3339)
+	}
+	if *retracts != "" {
+		*typ = core.TypeRetraction
+	} else if *typ == core.TypeRetraction {
+		return fmt.Errorf("--retracts is required for a retraction")
+	}
 	if !core.ValidType(*typ) {
 		return fmt.Errorf("unknown attestation type %q", *typ)
 	}
@@ -264,8 +419,16 @@ func cmdAttest(args []string) error {
 	}
 
 	a := core.NewAttestation(*typ, issuerKP.DID, *subject)
+	if *delegation != "" {
+		d, err := fetchDelegation(reg, issuerKP.DID, *delegation)
+		if err != nil {
+			return err
+		}
+		a.OnBehalfOf, a.Delegation = d.Principal, *delegation
+	}
 	a.SubjectCard = subjHash
 	a.Prev = head
+	a.NotBefore, a.ExpiresAt = *notBefore, *expiresAt
 	a.Body = map[string]any{}
 	if *capability != "" {
 		a.Body["capability"] = *capability
@@ -273,12 +436,49 @@ func cmdAttest(args []string) error {
 	if *typ == core.TypeTaskCompleted {
 		a.Body["outcome"] = *outcome
 	}
-	if *note != "" {
+	switch {
+	case *retracts != "":
+		a.Body["retracts"] = *retracts
+		if *note != "" {
+			a.Body["reason"] = *note
+		}
+	case *note != "":
 		a.Body["note"] = *note
 	}
+	for _, f := range fields {
+		k, v, ok := strings.Cut(f, "=")
+		if !ok || k == "" {
+			return fmt.Errorf("--body %q: want key=value", f)
+		}
+		a.Body[k] = v
+	}
+	// Check the body against its type's schema before anything is signed.
+	if err := core.ValidateBody(a.Type, a.Body); err != nil {
+		return err
+	}
+	// A co-signed attestation follows each co-issuer's chain head too, and
+	// goes to the co-issuers to sign rather than to the registry.
+	for _, did := range coIssuers {
+		h, err := fetchIssuerHead(reg, did)
+		if err != nil {
+			return fmt.Errorf("fetch co-issuer head: %w", err)
+		}
+		a.CoIssuers = append(a.CoIssuers, core.CoIssuer{Issuer: did, Prev: h})
+	}
 	if err := a.Sign(issuerKP.Private); err != nil {
 		return err
 	}
+	if len(coIssuers) > 0 {
+		if err := writeJSONFile(*out, a); err != nil {
+			return err
+		}
+		fmt.Printf("co-signed attestation written to %s\n  type:       %s\n  subject:    %s\n  co-issuers: %d\n", *out, *typ, *subject, len(coIssuers))
+		fmt.Printf("each co-issuer signs with `molt attest sign --issuer co.key --attestation %s`, then `molt attest submit --attestation %s`\n", *out, *out)
+		return nil
+	}
+	if err := a.Verify(); err != nil {
+		return err
+	}
 	var resp map[string]any
 	if err := httpPostJSON(reg+"/v1/attestations", a, &resp); err != nil {
 		return err
@@ -286,24 +486,128 @@ func cmdAttest(args []string) error {
 	hash, _ := a.Hash()
 	fmt.Printf("attestation %s issued\n  type:    %s\n  subject: %s\n  hash:    %s\n",
 		*typ, *typ, *subject, hash)
+	if a.ExpiresAt != "" {
+		fmt.Printf("  expires: %s\n", a.ExpiresAt)
+	}
 	return nil
 }
 
+// cmdAttestSign adds a co-issuer's signature to a co-signed attestation file.
+func cmdAttestSign(args []string) error {
+	fs := flag.NewFlagSet("attest sign", flag.ExitOnError)
+	issuerFile := fs.String("issuer", "agent.key", "your co-issuer keyfile")
+	attFile := fs.String("attestation", "attestation.json", "co-signed attestation to sign")
+	fs.Parse(args)
+
+	var a core.Attestation
+	if err := readJSONFile(*attFile, &a); err != nil {
+		return err
+	}
+	kp, err := loadKeyfile(*issuerFile)
+	if err != nil {
+		return err
+	}
+	if err := a.AddCoSig(kp); err != nil {
+		return err
+	}
+	if err := writeJSONFile(*attFile, &a); err != nil {
+		return err
+	}
+	fmt.Printf("signed %s as %s\n", *attFile, kp.DID)
+	if missing := a.MissingCoSigs(); len(missing) > 0 {
+		fmt.Printf("  waiting on %d co-issuer(s): %s\n", len(missing), strings.Join(missing, ", "))
+	} else {
+		fmt.Printf("  all issuers signed: molt attest submit --attestation %s\n", *attFile)
+	}
+	return nil
+}
+
+// cmdAttestSubmit submits a co-signed attestation once every issuer signed.
+func cmdAttestSubmit(args []string) error {
+	fs := flag.NewFlagSet("attest submit", flag.ExitOnError)
+	attFile := fs.String("attestation", "attestation.json", "signed attestation to submit")
+	registry := fs.String("registry", "", "registry base URL")
+	fs.Parse(args)
+
+	var a core.Attestation
+	if err := readJSONFile(*attFile, &a); err != nil {
+		return err
+	}
+	if err := a.Verify(); err != nil {
+		return fmt.Errorf("attestation fails local verification (not submitting): %w", err)
+	}
+	reg := registryURL(*registry)
+	var resp map[string]any
+	if err := httpPostJSON(reg+"/v1/attestations", &a, &resp); err != nil {
+		return err
+	}
+	hash, _ := a.Hash()
+	fmt.Printf("attestation %s issued\n  issuers: %s\n  subject: %s\n  hash:    %s\n",
+		a.Type, strings.Join(a.Issuers(), ", "), a.Subject, hash)
+	return nil
+}
+
+// parseValidity parses a validity period: a Go duration, or a whole number of
+// days such as "90d".
+func parseValidity(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid validity period %q", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid validity period %q", s)
+	}
+	return d, nil
+}
+
 func cmdRotate(args []string) error {
+	if len(args) > 0 && args[0] == "sign" {
+		return cmdRotateSign(args[1:])
+	}
+	if len(args) > 0 && args[0] == "submit" {
+		return cmdRotateSubmit(args[1:])
+	}
 	fs := flag.NewFlagSet("rotate", flag.ExitOnError)
 	ownerFile := fs.String("owner", "owner.key", "owner keyfile (authorizes the rotation)")
-	oldDID := fs.String("old", "", "current agent DID being retired (required)")
-	newDID := fs.String("new", "", "replacement agent DID (required)")
+	policyFile := fs.String("owner-policy", "", "owner policy file: write the rotation for its members to sign with `molt rotate sign`")
+	out := fs.String("out", "rotation.json", "with --owner-policy, output rotation path")
+	oldDID := fs.String("old", "", "current agent DID being retired (required for an agent key rotation)")
+	newDID := fs.String("new", "", "replacement agent DID (required for an agent key rotation)")
+	newOwnerFile := fs.String("new-owner", "", "rotate the owner key itself: keyfile of the replacement owner key")
 	registry := fs.String("registry", "", "registry base URL")
 	fs.Parse(args)
 
-	if *oldDID == "" || *newDID == "" {
-		return fmt.Errorf("--old and --new agent DIDs are required")
+	if *policyFile != "" {
+		if *oldDID == "" || *newDID == "" {
+			return fmt.Errorf("--old and --new agent DIDs are required")
+		}
+		policy, did, err := loadPolicy(*policyFile)
+		if err != nil {
+			return err
+		}
+		rot := core.NewRotation(did, *oldDID, *newDID)
+		rot.OwnerPolicy = policy
+		if err := writeJSONFile(*out, rot); err != nil {
+			return err
+		}
+		fmt.Printf("unsigned rotation written to %s\n  owner: %s (policy, %d of %d)\n", *out, did, policy.Threshold, len(policy.Members))
+		fmt.Printf("each member signs with `molt rotate sign --owner member.key --rotation %s`, then `molt rotate submit --rotation %s`\n", *out, *out)
+		return nil
 	}
 	ownerKP, err := loadKeyfile(*ownerFile)
 	if err != nil {
 		return err
 	}
+	if *newOwnerFile != "" {
+		return rotateOwner(ownerKP, *newOwnerFile, registryURL(*registry))
+	}
+	if *oldDID == "" || *newDID == "" {
+		return fmt.Errorf("--old and --new agent DIDs are required")
+	}
 	rot := core.NewRotation(ownerKP.DID, *oldDID, *newDID)
 	if err := rot.Sign(ownerKP.Private); err != nil {
 		return err
@@ -317,6 +621,67 @@ func cmdRotate(args []string) error {
 	return nil
 }
 
+// rotateOwner hands the owner identity of oldKP to the key in newOwnerFile.
+// Both keys sign; from then on only the new key may rotate, revoke or register
+// the owner's agents, and the old key's sessions end.
+func rotateOwner(oldKP *core.KeyPair, newOwnerFile, reg string) error {
+	newKP, err := loadKeyfile(newOwnerFile)
+	if err != nil {
+		return err
+	}
+	rot := core.NewOwnerRotation(oldKP.DID, newKP.DID)
+	if err := rot.Sign(oldKP.Private, newKP.Private); err != nil {
+		return err
+	}
+	var resp map[string]any
+	if err := httpPostJSON(reg+"/v1/owner-rotations", rot, &resp); err != nil {
+		return err
+	}
+	fmt.Printf("rotated owner key\n  old: %s\n  new: %s\n", oldKP.DID, newKP.DID)
+	return nil
+}
+
+func cmdRevoke(args []string) error {
+	fs := flag.NewFlagSet("revoke", flag.ExitOnError)
+	ownerFile := fs.String("owner", "owner.key", "owner keyfile (authorizes the revocation)")
+	agentDID := fs.String("agent", "", "compromised agent DID (required)")
+	effective := fs.String("effective", "", "RFC 3339 instant from which to distrust the key (default: now)")
+	reason := fs.String("reason", "", "optional note, e.g. how the key leaked")
+	registry := fs.String("registry", "", "registry base URL")
+	fs.Parse(args)
+
+	if *agentDID == "" {
+		return fmt.Errorf("--agent is required")
+	}
+	at := time.Now().UTC()
+	if *effective != "" {
+		t, err := time.Parse(time.RFC3339, *effective)
+		if err != nil {
+			return fmt.Errorf("--effective: %w", err)
+		}
+		at = t
+	}
+	ownerKP, err := loadKeyfile(*ownerFile)
+	if err != nil {
+		return err
+	}
+	rev := core.NewRevocation(ownerKP.DID, *agentDID, at)
+	rev.Reason = *reason
+	if err := rev.Sign(ownerKP.Private); err != nil {
+		return err
+	}
+	reg := registryURL(*registry)
+	var resp struct {
+		Hash string `json:"hash"`
+	}
+	if err := httpPostJSON(reg+"/v1/revocations", rev, &resp); err != nil {
+		return err
+	}
+	fmt.Printf("revoked agent key\n  agent: %s\n  effective: %s\n  owner: %s\n  hash: %s\n", *agentDID, rev.EffectiveAt, ownerKP.DID, resp.Hash)
+	fmt.Println("anything it signed from then on is disregarded; rotate to a fresh key with `molt rotate` to carry on")
+	return nil
+}
+
 func cmdSearch(args []string) error {
 	fs := flag.NewFlagSet("search", flag.ExitOnError)
 	capTag := fs.String("cap", "", "capability tag filter")
@@ -338,6 +703,9 @@ func cmdSearch(args []string) error {
 	fmt.Printf("%d result(s)\n", resp.Count)
 	for _, a := range resp.Results {
 		fmt.Printf("  %5.1f  %-24s  %s\n    %s\n", a.Score, a.Name, a.DID, strings.Join(a.Capabilities, ", "))
+		if a.CapabilityScore != nil {
+			fmt.Printf("    %s score: %.1f\n", *capTag, *a.CapabilityScore)
+		}
 	}
 	return nil
 }
@@ -357,6 +725,76 @@ func cmdBadge(args []string) error {
 	return nil
 }
 
+func cmdScore(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: molt score <simulate> [flags]")
+	}
+	switch args[0] {
+	case "simulate":
+		return cmdScoreSimulate(args[1:])
+	default:
+		return fmt.Errorf("unknown score subcommand %q", args[0])
+	}
+}
+
+// cmdScoreSimulate asks the registry what an agent's score would be with
+// hypothetical attestations added and stored ones excluded. Nothing is signed
+// or stored.
+func cmdScoreSimulate(args []string) error {
+	fs := flag.NewFlagSet("score simulate", flag.ExitOnError)
+	var add, exHash, exIssuer stringSlice
+	fs.Var(&add, "add", "hypothetical attestation TYPE,ISSUER[,CAPABILITY] (repeatable)")
+	fs.Var(&exHash, "exclude-hash", "drop the attestation with this hash (repeatable)")
+	fs.Var(&exIssuer, "exclude-issuer", "drop every attestation by this issuer (repeatable)")
+	algorithm := fs.String("algorithm", "", "scoring model (default: the registry's)")
+	registry := fs.String("registry", "", "registry base URL")
+	positional := parseInterspersed(fs, args)
+	if len(positional) < 1 {
+		return fmt.Errorf("usage: molt score simulate <did> [--add type,issuer[,capability]] [--exclude-hash h] [--exclude-issuer did]")
+	}
+	did := positional[0]
+
+	type hypothetical struct {
+		Type   string         `json:"type"`
+		Issuer string         `json:"issuer"`
+		Body   map[string]any `json:"body,omitempty"`
+	}
+	req := struct {
+		Algorithm      string         `json:"algorithm,omitempty"`
+		Add            []hypothetical `json:"add,omitempty"`
+		ExcludeHashes  []string       `json:"exclude_hashes,omitempty"`
+		ExcludeIssuers []string       `json:"exclude_issuers,omitempty"`
+	}{Algorithm: *algorithm, ExcludeHashes: exHash, ExcludeIssuers: exIssuer}
+	for _, spec := range add {
+		parts := strings.Split(spec, ",")
+		if len(parts) < 2 || len(parts) > 3 || parts[0] == "" || parts[1] == "" {
+			return fmt.Errorf("--add %q: want TYPE,ISSUER[,CAPABILITY]", spec)
+		}
+		h := hypothetical{Type: parts[0], Issuer: parts[1]}
+		if len(parts) == 3 && parts[2] != "" {
+			h.Body = map[string]any{"capability": parts[2]}
+		}
+		req.Add = append(req.Add, h)
+	}
+
+	var resp struct {
+		Algorithm string       `json:"algorithm"`
+		Before    score.Output `json:"before"`
+		After     score.Output `json:"after"`
+		Delta     float64      `json:"delta"`
+		Excluded  []string     `json:"excluded"`
+	}
+	if err := httpPostJSON(registryURL(*registry)+"/v1/score/"+did+"/simulate", req, &resp); err != nil {
+		return err
+	}
+	fmt.Printf("simulation for %s (%s) — nothing is stored\n", did, resp.Algorithm)
+	fmt.Printf("  added: %d hypothetical, excluded: %d stored\n", len(req.Add), len(resp.Excluded))
+	fmt.Printf("  before: %s\n", scoreLine(resp.Before))
+	fmt.Printf("  after:  %s\n", scoreLine(resp.After))
+	fmt.Printf("  delta:  %+.1f\n", resp.Delta)
+	return nil
+}
+
 func cmdServe(args []string) error {
 	fs := flag.NewFlagSet("serve", flag.ExitOnError)
 	addr := fs.String("addr", ":8787", "listen address")
@@ -377,8 +815,10 @@ func cmdServe(args []string) error {
 
 // scoreLine formats a one-line score summary.
 func scoreLine(out score.Output) string {
-	return fmt.Sprintf("%.1f/100  (completions=%d disputes=%d incidents=%d distinct_issuers=%d)",
+	line := fmt.Sprintf("%.1f/100  (completions=%d disputes=%d incidents=%d distinct_issuers=%d)",
 		out.Score, out.Inputs.Completions, out.Inputs.Disputes, out.Inputs.Incidents, out.Inputs.DistinctIssuers)
+	if p := out.Pending; p != nil {
+		line += fmt.Sprintf("  pending: disputes=%d incidents=%d (uncorroborated)", p.Disputes, p.Incidents)
+	}
+	return line
 }
-
-var _ = time.Now // retained for future timestamp flags
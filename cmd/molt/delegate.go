@@ -0,0 +1,71 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/moltnet/moltnet/core"
+)
+
+// cmdDelegate authorizes a sub-key to issue attestations on behalf of an
+// agent, so a high-volume issuer can keep its root key off its workers. Each
+// worker attests with `molt attest --issuer worker.key --delegation <hash>` on
+// its own chain; `molt revoke --agent <worker-did>` ends the delegation.
+func cmdDelegate(args []string) error {
+	fs := flag.NewFlagSet("delegate", flag.ExitOnError)
+	ownerFile := fs.String("owner", "owner.key", "owner keyfile of the principal agent (signs the delegation)")
+	principal := fs.String("agent", "", "principal agent DID the delegate issues for (required)")
+	delegate := fs.String("delegate", "", "sub-key DID being authorized (required; create it with `molt keygen`)")
+	ttl := fs.Duration("ttl", 30*24*time.Hour, "how long the delegation lasts")
+	registry := fs.String("registry", "", "registry base URL")
+	var types, caps stringSlice
+	fs.Var(&types, "type", "attestation type the delegate may issue (repeatable; default any)")
+	fs.Var(&caps, "capability", "capability tag the delegate may attest (repeatable; default any)")
+	fs.Parse(args)
+
+	if *principal == "" || *delegate == "" {
+		return fmt.Errorf("--agent and --delegate are required")
+	}
+	ownerKP, err := loadKeyfile(*ownerFile)
+	if err != nil {
+		return err
+	}
+	d := core.NewDelegation(ownerKP.DID, *principal, *delegate, time.Now().Add(*ttl))
+	d.Types, d.Capabilities = types, caps
+	if err := d.Sign(ownerKP.Private); err != nil {
+		return err
+	}
+	if err := d.Verify(); err != nil {
+		return err
+	}
+	var resp struct {
+		Hash string `json:"hash"`
+	}
+	if err := httpPostJSON(registryURL(*registry)+"/v1/delegations", d, &resp); err != nil {
+		return err
+	}
+	fmt.Printf("delegated issuing\n  principal: %s\n  delegate:  %s\n  expires:   %s\n  hash:      %s\n", *principal, *delegate, d.ExpiresAt, resp.Hash)
+	if len(types) > 0 || len(caps) > 0 {
+		fmt.Printf("  scope:     types %v, capabilities %v\n", d.Types, d.Capabilities)
+	}
+	fmt.Printf("the delegate attests with `molt attest --issuer <its keyfile> --delegation %s`\n", resp.Hash)
+	return nil
+}
+
+// fetchDelegation returns the delegation with the given hash that names
+// delegate, as the registry serves it.
+func fetchDelegation(reg, delegate, hash string) (*core.Delegation, error) {
+	var resp struct {
+		Delegations []*core.Delegation `json:"delegations"`
+	}
+	if err := httpGet(reg+"/v1/delegations?did="+urlEscape(delegate), &resp); err != nil {
+		return nil, err
+	}
+	for _, d := range resp.Delegations {
+		if h, _ := d.Hash(); h == hash && d.Delegate == delegate {
+			return d, nil
+		}
+	}
+	return nil, fmt.Errorf("registry holds no delegation %s to %s", hash, delegate)
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/moltnet/moltnet/core"
+)
+
+// cmdDIDDoc writes the DID document a domain publishes so that cards signed by
+// the listed owner keys may name the domain's did:web as their owner.
+func cmdDIDDoc(args []string) error {
+	fs := flag.NewFlagSet("did-doc", flag.ExitOnError)
+	did := fs.String("did", "", "the domain's did:web, e.g. did:web:acme.com (required)")
+	out := fs.String("out", "did.json", "output path")
+	var keys stringSlice
+	fs.Var(&keys, "key", "owner keyfile or did:key the domain vouches for (repeatable)")
+	fs.Parse(args)
+
+	if *did == "" || len(keys) == 0 {
+		return fmt.Errorf("usage: molt did-doc --did did:web:<domain> --key owner.key [--key did:key:…] [--out did.json]")
+	}
+	var dids []string
+	for _, k := range keys {
+		if strings.HasPrefix(k, "did:key:") {
+			dids = append(dids, k)
+			continue
+		}
+		kp, err := loadKeyfile(k)
+		if err != nil {
+			return err
+		}
+		dids = append(dids, kp.DID)
+	}
+	doc, err := core.NewDIDDocument(*did, dids...)
+	if err != nil {
+		return err
+	}
+	if err := writeJSONFile(*out, doc); err != nil {
+		return err
+	}
+	url, _ := core.DIDWebURL(*did)
+	fmt.Printf("DID document for %s written to %s\n  publish it at %s\n", *did, *out, url)
+	fmt.Printf("then sign cards with `molt card new --owner owner.key --owner-did %s`\n", *did)
+	return nil
+}
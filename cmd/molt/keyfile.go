@@ -9,20 +9,26 @@ import (
 )
 
 // Keyfile is the on-disk representation of a MoltNet identity. The private key
-// never leaves the holder; signing happens locally in the CLI.
+// never leaves the holder; signing happens locally in the CLI. Kind is the key
+// type; keyfiles written before key types existed say "owner" or "agent"
+// there, and hold Ed25519 keys.
 type Keyfile struct {
 	DID     string `json:"did"`
-	Kind    string `json:"kind"` // "owner" or "agent"
+	Kind    string `json:"kind"` // "ed25519", "p256" or "secp256k1"
 	Public  string `json:"public"`
 	Private string `json:"private"`
 }
 
-func writeKeyfile(path string, kp *core.KeyPair, kind string) error {
+func writeKeyfile(path string, kp *core.KeyPair) error {
+	priv, err := core.PrivateKeyHex(kp.Private)
+	if err != nil {
+		return err
+	}
 	kf := Keyfile{
 		DID:     kp.DID,
-		Kind:    kind,
+		Kind:    string(kp.Type),
 		Public:  core.PublicKeyHex(kp.Public),
-		Private: core.PrivateKeyHex(kp.Private),
+		Private: priv,
 	}
 	data, err := json.MarshalIndent(kf, "", "  ")
 	if err != nil {
@@ -40,9 +46,20 @@ func loadKeyfile(path string) (*core.KeyPair, error) {
 	if err := json.Unmarshal(data, &kf); err != nil {
 		return nil, fmt.Errorf("%s: %w", path, err)
 	}
-	kp, err := core.KeyPairFromHex(kf.Private)
+	kind := kf.Kind
+	if kind == "owner" || kind == "agent" {
+		kind = "" // a legacy keyfile: Ed25519
+	}
+	typ, err := core.ParseKeyType(kind)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	kp, err := core.KeyPairFromHexOf(typ, kf.Private)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", path, err)
 	}
+	if kf.DID != "" && kf.DID != kp.DID {
+		return nil, fmt.Errorf("%s: private key does not match did %s", path, kf.DID)
+	}
 	return kp, nil
 }
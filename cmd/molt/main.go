@@ -16,12 +16,18 @@ USAGE:
 
 COMMANDS:
   keygen     Create an owner or agent keypair
-  card       Build/sign an agent card (subcommands: new, update)
+  card       Build/sign an agent card (subcommands: new, update, sign, resolve)
+  policy     Create an M-of-N owner policy for team-owned agents (subcommand: new)
+  did-doc    Write the did:web DID document a domain publishes to vouch for owner keys
   register   Sign-check and submit a card to a registry
-  attest     Issue a signed attestation about an agent
-  rotate     Owner-signed key rotation (retire an agent key for a new one)
-  verify     Fetch an agent's chain, verify signatures, recompute score locally
+  attest     Issue a signed attestation about an agent (--delegation to issue as a delegate, --retracts to withdraw one, --expires-in to bound it, --co-issuer to co-sign; sign, submit)
+  delegate   Owner-signed delegation letting a sub-key issue attestations for an agent
+  rotate     Owner-signed key rotation (an agent key, or the owner key with --new-owner; sign, submit for policies)
+  revoke     Owner-signed key revocation (distrust a compromised key from a cutoff)
+  recovery   Guardian-based recovery of a lost owner key (subcommands: guardians, start, sign, submit, veto, status)
+  verify     Fetch an agent's chain, verify signatures, recompute score locally (--did-doc to verify offline)
   search     Search the registry by text, capability and min score
+  score      Score what-ifs (subcommand: simulate) — nothing is stored
   badge      Print a Markdown badge snippet for an agent
   serve      Run a local moltnetd instance (single-node quickstart)
   mcp        Run an MCP (Model Context Protocol) server over stdio for agents
@@ -47,16 +53,28 @@ func main() {
 		err = cmdKeygen(os.Args[2:])
 	case "card":
 		err = cmdCard(os.Args[2:])
+	case "policy":
+		err = cmdPolicy(os.Args[2:])
 	case "register":
 		err = cmdRegister(os.Args[2:])
 	case "attest":
 		err = cmdAttest(os.Args[2:])
+	case "delegate":
+		err = cmdDelegate(os.Args[2:])
+	case "did-doc":
+		err = cmdDIDDoc(os.Args[2:])
 	case "rotate":
 		err = cmdRotate(os.Args[2:])
+	case "revoke":
+		err = cmdRevoke(os.Args[2:])
+	case "recovery":
+		err = cmdRecovery(os.Args[2:])
 	case "verify":
 		err = cmdVerify(os.Args[2:])
 	case "search":
 		err = cmdSearch(os.Args[2:])
+	case "score":
+		err = cmdScore(os.Args[2:])
 	case "badge":
 		err = cmdBadge(os.Args[2:])
 	case "serve":
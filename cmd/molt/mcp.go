@@ -202,24 +202,81 @@ func (s *mcpServer) toolVerify(argsRaw json.RawMessage) (any, error) {
 	if err := checkSubjectBinding(a.DID, card, atts); err != nil {
 		return nil, err
 	}
+	// Owner rotations and completed recoveries: a card signed by an owner key
+	// after it was rotated away does not verify.
+	orots, _ := fetchOwnerRotations(s.registry)
+	recovered, _ := recoveredOwners(s.registry)
+	owners, _ := checkOwnerRotations(orots, recovered)
 	cardErr := card.Verify()
-	chainErr := core.VerifyAll(atts)
-	out := score.Compute(atts, nil, nil, time.Now().UTC())
+	if cardErr == nil && !owners.Authorizes(card.Owner, card.Owner, card.CreatedAt) {
+		cardErr = fmt.Errorf("card owner key %s was rotated away before signing the card", card.Owner)
+	}
+	if cardErr == nil && core.IsDIDWeb(card.Owner) {
+		cardErr = didWeb.VerifyDomain(card.Owner, card.OwnerKey)
+	}
+	// Predecessor keys' history counts, once their rotations check out. A
+	// registry that serves no lineage leaves only this key's own history.
+	lineage, _ := fetchLineage(s.registry, a.DID)
+	lineageErr := checkLineage(card, lineage, owners)
+	if lineageErr == nil && len(lineage) > 0 {
+		inherited, err := fetchInherited(s.registry, lineage)
+		if err != nil {
+			return nil, err
+		}
+		atts = append(inherited, atts...)
+	}
+	// Revocations that hold: what a compromised issuer key signed after its
+	// cutoff counts for nothing.
+	// Delegated attestations count for their principal.
+	ds, _ := fetchDelegations(s.registry)
+	delegations, _ := checkDelegations(s.registry, ds, owners)
+	revs, _ := fetchRevocations(s.registry)
+	revoked, _ := checkRevocations(s.registry, revs, owners, delegations)
+	chainErr := core.VerifyAllWith(atts, core.VerifyOptions{Revocations: revoked, Delegations: delegations})
+	cutoffs, delegated := core.RevocationCutoffs(revoked), core.NewDelegations(delegations)
+	// Proofs of a forked chain carry their own signatures; only those that
+	// verify against this agent count.
+	var equivocations []*core.Equivocation
+	eqs, _ := fetchEquivocations(s.registry, a.DID)
+	for _, e := range eqs {
+		if e.Issuer == a.DID && e.Verify() == nil {
+			equivocations = append(equivocations, e)
+		}
+	}
+	out := score.V1{}.Score(score.Input{
+		Subject: a.DID, Attestations: atts, Revoked: cutoffs,
+		Delegations: delegated, Equivocations: equivocations, Now: time.Now().UTC(),
+	})
 	verdict := map[string]any{
 		"did":                a.DID,
 		"name":               card.Name,
 		"card_signature_ok":  cardErr == nil,
 		"chain_ok":           chainErr == nil,
-		"verified":           cardErr == nil && chainErr == nil,
+		"lineage_ok":         lineageErr == nil,
+		"predecessors":       core.Predecessors(lineage),
+		"verified":           cardErr == nil && lineageErr == nil && chainErr == nil,
 		"moltscore":          out.Score,
 		"algorithm":          out.Algorithm,
 		"inputs":             out.Inputs,
 		"attestation_count":  len(atts),
 		"recomputed_locally": true,
 	}
+	if core.IsDIDWeb(card.Owner) {
+		verdict["owner_domain"] = core.DIDWebDomain(card.Owner)
+	}
+	// Attestations their issuers withdrew are in the chain but not the score.
+	if rs := core.NewRetractions(delegated.Filter(cutoffs.Filter(atts))); len(rs) > 0 {
+		verdict["retracted"] = len(rs)
+	}
+	if len(equivocations) > 0 {
+		verdict["equivocations"] = len(equivocations)
+	}
 	if cardErr != nil {
 		verdict["card_error"] = cardErr.Error()
 	}
+	if lineageErr != nil {
+		verdict["lineage_error"] = lineageErr.Error()
+	}
 	if chainErr != nil {
 		verdict["chain_error"] = chainErr.Error()
 	}
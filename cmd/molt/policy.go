@@ -0,0 +1,186 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/moltnet/moltnet/core"
+)
+
+// cmdPolicy creates an M-of-N owner policy: a team of owner keys, any
+// threshold of which stand in for a single owner signature on cards and
+// rotations.
+func cmdPolicy(args []string) error {
+	if len(args) == 0 || args[0] != "new" {
+		return fmt.Errorf("usage: molt policy new --threshold 2 --member <did> --member <did> [--out policy.json]")
+	}
+	fs := flag.NewFlagSet("policy new", flag.ExitOnError)
+	threshold := fs.Int("threshold", 0, "member signatures required (required)")
+	out := fs.String("out", "policy.json", "output policy path")
+	var members stringSlice
+	fs.Var(&members, "member", "member owner DID (repeatable)")
+	fs.Parse(args[1:])
+
+	p, err := core.NewOwnerPolicy(*threshold, members...)
+	if err != nil {
+		return err
+	}
+	if err := writeJSONFile(*out, p); err != nil {
+		return err
+	}
+	did, _ := p.DID()
+	fmt.Printf("owner policy written to %s\n  DID:       %s\n  threshold: %d of %d\n", *out, did, p.Threshold, len(p.Members))
+	return nil
+}
+
+// loadPolicy reads an owner policy file and returns it with its DID.
+func loadPolicy(path string) (*core.OwnerPolicy, string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, "", err
+	}
+	var p core.OwnerPolicy
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, "", fmt.Errorf("%s: %w", path, err)
+	}
+	if err := p.Validate(); err != nil {
+		return nil, "", fmt.Errorf("%s: %w", path, err)
+	}
+	did, err := p.DID()
+	return &p, did, err
+}
+
+// cmdCardSign adds one policy member's signature to a policy-owned card file.
+// Each member runs it in turn on the same file; once the threshold is met the
+// card can be submitted with `molt register`.
+func cmdCardSign(args []string) error {
+	fs := flag.NewFlagSet("card sign", flag.ExitOnError)
+	ownerFile := fs.String("owner", "owner.key", "your member owner keyfile")
+	cardFile := fs.String("card", "card.json", "policy-owned card to sign")
+	fs.Parse(args)
+
+	var c core.Card
+	if err := readJSONFile(*cardFile, &c); err != nil {
+		return err
+	}
+	if c.OwnerPolicy == nil {
+		return fmt.Errorf("%s is not owned by an owner policy; sign it with `molt card new`", *cardFile)
+	}
+	kp, err := loadKeyfile(*ownerFile)
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(c.OwnerPolicy.Members, kp.DID) {
+		return fmt.Errorf("%s is not a member of the card's owner policy", kp.DID)
+	}
+	if err := c.AddOwnerSig(kp); err != nil {
+		return err
+	}
+	if err := writeJSONFile(*cardFile, &c); err != nil {
+		return err
+	}
+	payload, err := c.SigningPayload()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("signed %s as %s\n", *cardFile, kp.DID)
+	printPolicyProgress(c.OwnerPolicy, payload, c.OwnerSigs, "molt register --card "+*cardFile)
+	return nil
+}
+
+// cmdRotateSign adds one policy member's signature to a policy-owned rotation
+// file written by `molt rotate --owner-policy`.
+func cmdRotateSign(args []string) error {
+	fs := flag.NewFlagSet("rotate sign", flag.ExitOnError)
+	ownerFile := fs.String("owner", "owner.key", "your member owner keyfile")
+	rotFile := fs.String("rotation", "rotation.json", "policy-owned rotation to sign")
+	fs.Parse(args)
+
+	var rot core.Rotation
+	if err := readJSONFile(*rotFile, &rot); err != nil {
+		return err
+	}
+	if rot.OwnerPolicy == nil {
+		return fmt.Errorf("%s is not authorized by an owner policy", *rotFile)
+	}
+	kp, err := loadKeyfile(*ownerFile)
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(rot.OwnerPolicy.Members, kp.DID) {
+		return fmt.Errorf("%s is not a member of the rotation's owner policy", kp.DID)
+	}
+	if err := rot.AddOwnerSig(kp); err != nil {
+		return err
+	}
+	if err := writeJSONFile(*rotFile, &rot); err != nil {
+		return err
+	}
+	payload, err := rot.SigningPayload()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("signed %s as %s\n", *rotFile, kp.DID)
+	printPolicyProgress(rot.OwnerPolicy, payload, rot.OwnerSigs, "molt rotate submit --rotation "+*rotFile)
+	return nil
+}
+
+// cmdRotateSubmit submits a rotation file once its signatures are complete.
+func cmdRotateSubmit(args []string) error {
+	fs := flag.NewFlagSet("rotate submit", flag.ExitOnError)
+	rotFile := fs.String("rotation", "rotation.json", "signed rotation to submit")
+	registry := fs.String("registry", "", "registry base URL")
+	fs.Parse(args)
+
+	var rot core.Rotation
+	if err := readJSONFile(*rotFile, &rot); err != nil {
+		return err
+	}
+	if err := rot.Verify(); err != nil {
+		return fmt.Errorf("rotation fails local verification (not submitting): %w", err)
+	}
+	reg := registryURL(*registry)
+	var resp map[string]any
+	if err := httpPostJSON(reg+"/v1/rotations", &rot, &resp); err != nil {
+		return err
+	}
+	fmt.Printf("rotated agent key\n  old: %s\n  new: %s\n  owner: %s\n", rot.OldAgent, rot.NewAgent, rot.Owner)
+	return nil
+}
+
+// printPolicyProgress reports how many of the policy's required member
+// signatures sigs hold, and what to run once they are enough.
+func printPolicyProgress(p *core.OwnerPolicy, payload []byte, sigs []core.OwnerSig, next string) {
+	valid := 0
+	for _, s := range sigs {
+		if slices.Contains(p.Members, s.Signer) && core.Verify(s.Signer, payload, s.Sig) == nil {
+			valid++
+		}
+	}
+	fmt.Printf("  %d of %d required owner signatures\n", valid, p.Threshold)
+	if valid >= p.Threshold {
+		fmt.Printf("  threshold met: %s\n", next)
+	}
+}
+
+func readJSONFile(path string, v any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+func writeJSONFile(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
@@ -0,0 +1,284 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/moltnet/moltnet/core"
+)
+
+// cmdRecovery drives social recovery of an owner identity: the owner names
+// guardians while it still holds its key; if the key is lost, a threshold of
+// them sign a recovery to a new key, which takes effect after a waiting period
+// unless the old key vetoes it.
+func cmdRecovery(args []string) error {
+	const usage = "usage: molt recovery <guardians|start|sign|submit|veto|status> [flags]"
+	if len(args) == 0 {
+		return fmt.Errorf(usage)
+	}
+	switch args[0] {
+	case "guardians":
+		return cmdRecoveryGuardians(args[1:])
+	case "start":
+		return cmdRecoveryStart(args[1:])
+	case "sign":
+		return cmdRecoverySign(args[1:])
+	case "submit":
+		return cmdRecoverySubmit(args[1:])
+	case "veto":
+		return cmdRecoveryVeto(args[1:])
+	case "status":
+		return cmdRecoveryStatus(args[1:])
+	}
+	return fmt.Errorf(usage)
+}
+
+// cmdRecoveryGuardians publishes the owner's guardian set, replacing any
+// earlier one.
+func cmdRecoveryGuardians(args []string) error {
+	fs := flag.NewFlagSet("recovery guardians", flag.ExitOnError)
+	ownerFile := fs.String("owner", "owner.key", "owner keyfile")
+	threshold := fs.Int("threshold", 0, "guardian signatures required to recover (required)")
+	delay := fs.Int("delay-days", core.DefaultRecoveryDelay, "days a recovery waits before it takes effect, for the owner to veto")
+	registry := fs.String("registry", "", "registry base URL")
+	var guardians stringSlice
+	fs.Var(&guardians, "guardian", "guardian DID (repeatable)")
+	fs.Parse(args)
+
+	kp, err := loadKeyfile(*ownerFile)
+	if err != nil {
+		return err
+	}
+	g := core.NewGuardianSet(kp.DID, *threshold, guardians...)
+	g.DelayDays = *delay
+	if err := g.Sign(kp.Private); err != nil {
+		return err
+	}
+	if err := g.Verify(); err != nil {
+		return err
+	}
+	var resp map[string]any
+	if err := httpPostJSON(registryURL(*registry)+"/v1/guardian-sets", g, &resp); err != nil {
+		return err
+	}
+	fmt.Printf("guardian set published\n  owner:     %s\n  threshold: %d of %d\n  delay:     %d days\n  hash:      %v\n",
+		kp.DID, g.Threshold, len(g.Guardians), g.DelayDays, resp["hash"])
+	return nil
+}
+
+// cmdRecoveryStart writes an unsigned-by-guardians recovery of a lost owner key
+// to a new one, against the owner's guardian set in force. The new key signs
+// it here; guardians then sign with `molt recovery sign`.
+func cmdRecoveryStart(args []string) error {
+	fs := flag.NewFlagSet("recovery start", flag.ExitOnError)
+	lost := fs.String("lost-owner", "", "owner DID whose key was lost (required)")
+	newOwnerFile := fs.String("new-owner", "", "keyfile of the replacement owner key (required)")
+	out := fs.String("out", "recovery.json", "output recovery path")
+	registry := fs.String("registry", "", "registry base URL")
+	fs.Parse(args)
+
+	if *lost == "" || *newOwnerFile == "" {
+		return fmt.Errorf("--lost-owner and --new-owner are required")
+	}
+	newKP, err := loadKeyfile(*newOwnerFile)
+	if err != nil {
+		return err
+	}
+	sets, err := fetchGuardianSets(registryURL(*registry), *lost)
+	if err != nil {
+		return err
+	}
+	if len(sets) == 0 {
+		return fmt.Errorf("%s has published no guardian set; it cannot be recovered", *lost)
+	}
+	set := sets[len(sets)-1]
+	if err := set.Verify(); err != nil {
+		return fmt.Errorf("registry served a bad guardian set: %w", err)
+	}
+	setHash, _ := set.Hash()
+	rec := core.NewRecovery(*lost, newKP.DID, setHash)
+	if err := rec.SignNew(newKP.Private); err != nil {
+		return err
+	}
+	if err := writeJSONFile(*out, rec); err != nil {
+		return err
+	}
+	fmt.Printf("recovery written to %s\n  owner:     %s\n  new owner: %s\n  needs %d of %d guardians: %v\n",
+		*out, *lost, newKP.DID, set.Threshold, len(set.Guardians), set.Guardians)
+	fmt.Printf("each guardian signs with `molt recovery sign --guardian g.key --recovery %s`, then `molt recovery submit --recovery %s`\n", *out, *out)
+	return nil
+}
+
+// cmdRecoverySign adds one guardian's signature to a recovery file.
+func cmdRecoverySign(args []string) error {
+	fs := flag.NewFlagSet("recovery sign", flag.ExitOnError)
+	guardianFile := fs.String("guardian", "guardian.key", "your guardian keyfile")
+	recFile := fs.String("recovery", "recovery.json", "recovery to sign")
+	registry := fs.String("registry", "", "registry base URL")
+	fs.Parse(args)
+
+	var rec core.Recovery
+	if err := readJSONFile(*recFile, &rec); err != nil {
+		return err
+	}
+	if err := rec.Verify(); err != nil {
+		return err
+	}
+	kp, err := loadKeyfile(*guardianFile)
+	if err != nil {
+		return err
+	}
+	set, err := guardianSetFor(registryURL(*registry), &rec)
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(set.Guardians, kp.DID) {
+		return fmt.Errorf("%s is not a guardian of %s", kp.DID, rec.Owner)
+	}
+	if err := rec.AddGuardianSig(kp); err != nil {
+		return err
+	}
+	if err := writeJSONFile(*recFile, &rec); err != nil {
+		return err
+	}
+	fmt.Printf("signed %s as %s\n  hands %s to %s\n", *recFile, kp.DID, rec.Owner, rec.NewOwner)
+	fmt.Printf("  %d of %d required guardian signatures\n", len(rec.GuardianSigs), set.Threshold)
+	if rec.VerifyWith(set) == nil {
+		fmt.Printf("  threshold met: molt recovery submit --recovery %s\n", *recFile)
+	}
+	return nil
+}
+
+// cmdRecoverySubmit submits a recovery once its guardian signatures are
+// complete, starting its waiting period.
+func cmdRecoverySubmit(args []string) error {
+	fs := flag.NewFlagSet("recovery submit", flag.ExitOnError)
+	recFile := fs.String("recovery", "recovery.json", "signed recovery to submit")
+	registry := fs.String("registry", "", "registry base URL")
+	fs.Parse(args)
+
+	var rec core.Recovery
+	if err := readJSONFile(*recFile, &rec); err != nil {
+		return err
+	}
+	reg := registryURL(*registry)
+	set, err := guardianSetFor(reg, &rec)
+	if err != nil {
+		return err
+	}
+	if err := rec.VerifyWith(set); err != nil {
+		return fmt.Errorf("recovery fails local verification (not submitting): %w", err)
+	}
+	var st core.RecoveryState
+	if err := httpPostJSON(reg+"/v1/recoveries", &rec, &st); err != nil {
+		return err
+	}
+	fmt.Printf("recovery submitted\n  hash:      %s\n  owner:     %s\n  new owner: %s\n  status:    %s, effective at %s unless the owner vetoes it\n",
+		st.Hash, rec.Owner, rec.NewOwner, st.Status, st.EffectiveAt)
+	return nil
+}
+
+// cmdRecoveryVeto stops a pending recovery of the owner's identity: the key
+// it would retire signs that it is not lost.
+func cmdRecoveryVeto(args []string) error {
+	fs := flag.NewFlagSet("recovery veto", flag.ExitOnError)
+	ownerFile := fs.String("owner", "owner.key", "owner keyfile the recovery would retire")
+	hash := fs.String("recovery", "", "hash of the recovery to veto (required)")
+	registry := fs.String("registry", "", "registry base URL")
+	fs.Parse(args)
+
+	if *hash == "" {
+		return fmt.Errorf("--recovery is required (see `molt recovery status`)")
+	}
+	kp, err := loadKeyfile(*ownerFile)
+	if err != nil {
+		return err
+	}
+	v := core.NewRecoveryVeto(kp.DID, *hash)
+	if err := v.Sign(kp.Private); err != nil {
+		return err
+	}
+	var st core.RecoveryState
+	if err := httpPostJSON(registryURL(*registry)+"/v1/recoveries/vetoes", v, &st); err != nil {
+		return err
+	}
+	fmt.Printf("vetoed recovery %s\n  status: %s\n", *hash, st.Status)
+	return nil
+}
+
+// cmdRecoveryStatus lists the recoveries of an owner DID, re-judged locally
+// from the signed records.
+func cmdRecoveryStatus(args []string) error {
+	fs := flag.NewFlagSet("recovery status", flag.ExitOnError)
+	registry := fs.String("registry", "", "registry base URL")
+	positional := parseInterspersed(fs, args)
+	if len(positional) != 1 {
+		return fmt.Errorf("usage: molt recovery status <owner-did>")
+	}
+	owner := positional[0]
+	reg := registryURL(*registry)
+	sets, err := fetchGuardianSets(reg, owner)
+	if err != nil {
+		return err
+	}
+	recs, vetoes, err := fetchRecoveries(reg, owner)
+	if err != nil {
+		return err
+	}
+	if len(sets) == 0 {
+		fmt.Printf("%s has published no guardian set\n", owner)
+	} else {
+		g := sets[len(sets)-1]
+		fmt.Printf("guardians of %s: %d of %v, %d-day waiting period\n", owner, g.Threshold, g.Guardians, g.DelayDays)
+	}
+	if len(recs) == 0 {
+		fmt.Println("no recoveries")
+		return nil
+	}
+	for _, st := range core.Recoveries(sets, recs, vetoes, nil, time.Now().UTC()) {
+		fmt.Printf("  %s  %-8s -> %s", st.Hash, st.Status, st.Recovery.NewOwner)
+		switch st.Status {
+		case core.RecoveryInvalid:
+			fmt.Printf("  (%s)\n", st.Reason)
+		case core.RecoveryPending:
+			fmt.Printf("  effective %s; veto with `molt recovery veto --recovery %s`\n", st.EffectiveAt, st.Hash)
+		default:
+			fmt.Printf("  effective %s\n", st.EffectiveAt)
+		}
+	}
+	return nil
+}
+
+// guardianSetFor fetches the guardian set rec invokes, which must be its
+// owner's latest.
+func guardianSetFor(reg string, rec *core.Recovery) (*core.GuardianSet, error) {
+	sets, err := fetchGuardianSets(reg, rec.Owner)
+	if err != nil {
+		return nil, err
+	}
+	if len(sets) == 0 {
+		return nil, fmt.Errorf("%s has published no guardian set", rec.Owner)
+	}
+	set := sets[len(sets)-1]
+	if h, _ := set.Hash(); h != rec.GuardianSet {
+		return nil, fmt.Errorf("recovery invokes guardian set %s, but %s's latest is %s; start again", rec.GuardianSet, rec.Owner, h)
+	}
+	return set, nil
+}
+
+// recoveredOwners judges the registry's recoveries locally and returns the
+// owner hand-overs of the complete ones. Waiting periods run from each
+// recovery's own issued_at, since only the registry saw when it arrived.
+func recoveredOwners(reg string) ([]*core.OwnerRotation, error) {
+	sets, err := fetchGuardianSets(reg, "")
+	if err != nil {
+		return nil, err
+	}
+	recs, vetoes, err := fetchRecoveries(reg, "")
+	if err != nil || len(recs) == 0 {
+		return nil, err
+	}
+	return core.RecoveredOwners(core.Recoveries(sets, recs, vetoes, nil, time.Now().UTC())), nil
+}
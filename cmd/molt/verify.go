@@ -1,8 +1,14 @@
 package main
 
 import (
+	"cmp"
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"net/url"
+	"os"
+	"slices"
 	"time"
 
 	"github.com/moltnet/moltnet/core"
@@ -25,6 +31,11 @@ func checkSubjectBinding(did string, card *core.Card, atts []*core.Attestation)
 	if card.ID != did {
 		return fmt.Errorf("registry returned a card for %s, but %s was requested — do not trust this registry", card.ID, did)
 	}
+	return checkAttestationSubjects(did, atts)
+}
+
+// checkAttestationSubjects checks that every attestation is about did.
+func checkAttestationSubjects(did string, atts []*core.Attestation) error {
 	for _, a := range atts {
 		if a.Subject != did {
 			h, _ := a.Hash()
@@ -34,6 +45,155 @@ func checkSubjectBinding(did string, card *core.Card, atts []*core.Attestation)
 	return nil
 }
 
+// checkOwnerRotations builds the owner succession from the rotations that
+// verify, plus the hand-overs of recoveries already judged complete; the rest
+// are returned as errors. Both keys sign each rotation, so a registry cannot
+// invent a hand-over — at most withhold one.
+func checkOwnerRotations(rots, recovered []*core.OwnerRotation) (core.OwnerSuccession, []error) {
+	var held []*core.OwnerRotation
+	var rejected []error
+	for _, r := range rots {
+		if err := r.Verify(); err != nil {
+			rejected = append(rejected, err)
+			continue
+		}
+		held = append(held, r)
+	}
+	return core.NewOwnerSuccession(append(held, recovered...)), rejected
+}
+
+// checkLineage verifies the rotation lineage of card's agent: every rotation
+// is signed by a key that spoke for the card's owner at the time, and they link
+// up to the card's DID. Otherwise a registry could graft a stranger's history
+// onto the agent.
+func checkLineage(card *core.Card, lineage []*core.Rotation, owners core.OwnerSuccession) error {
+	return core.VerifyLineage(lineage, card.ID, card.Owner, owners)
+}
+
+// fetchInherited fetches the attestations about each key a verified lineage
+// retired, oldest key first, checking that each is about that key.
+func fetchInherited(reg string, lineage []*core.Rotation) ([]*core.Attestation, error) {
+	var out []*core.Attestation
+	for _, did := range core.Predecessors(lineage) {
+		atts, err := fetchAttestations(reg, did)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkAttestationSubjects(did, atts); err != nil {
+			return nil, err
+		}
+		out = append(out, atts...)
+	}
+	return out, nil
+}
+
+// checkDelegations keeps the issuing-key delegations that hold: validly
+// signed, by a key that spoke for the owner on the principal's card when it
+// was issued. Served unchecked, a delegation would let a registry lend any
+// issuer's weight to a key of its own. The rest are returned as errors.
+func checkDelegations(reg string, ds []*core.Delegation, owners core.OwnerSuccession) ([]*core.Delegation, []error) {
+	var held []*core.Delegation
+	var rejected []error
+	cards := cardOwners{reg: reg}
+	for _, d := range ds {
+		if err := d.Verify(); err != nil {
+			rejected = append(rejected, err)
+			continue
+		}
+		if owner := cards.of(d.Principal); owner == "" || !owners.Authorizes(d.Owner, owner, d.IssuedAt) {
+			rejected = append(rejected, fmt.Errorf("delegation from %s is not signed by its card owner", d.Principal))
+			continue
+		}
+		held = append(held, d)
+	}
+	return held, rejected
+}
+
+// checkRevocations keeps the revocations that hold: validly signed, by the
+// owner on the revoked agent's card or the key that succeeded it — for a
+// delegate key, the owner of a principal that held delegations name. Served
+// unchecked, a revocation would let a registry silence any issuer it liked.
+// The rest are returned as errors.
+func checkRevocations(reg string, revs []*core.Revocation, owners core.OwnerSuccession, delegations []*core.Delegation) ([]*core.Revocation, []error) {
+	var held []*core.Revocation
+	var rejected []error
+	cards := cardOwners{reg: reg}
+	for _, r := range revs {
+		if err := r.Verify(); err != nil {
+			rejected = append(rejected, err)
+			continue
+		}
+		ok := false
+		if owner := cards.of(r.Agent); owner != "" {
+			ok = owners.Authorizes(r.Owner, owner, r.IssuedAt)
+		} else {
+			for _, d := range delegations {
+				if d.Delegate == r.Agent && owners.Authorizes(r.Owner, cards.of(d.Principal), r.IssuedAt) {
+					ok = true
+					break
+				}
+			}
+		}
+		if !ok {
+			rejected = append(rejected, fmt.Errorf("revocation of %s is not signed by its card owner", r.Agent))
+			continue
+		}
+		held = append(held, r)
+	}
+	return held, rejected
+}
+
+// checkForkResolution reports whether a fork resolution holds for card: validly
+// signed by the card's agent key and by its owner, with a key that spoke for
+// the owner when it was issued (for a did:web owner, one its domain lists).
+func checkForkResolution(fr *core.ForkResolution, card *core.Card, owners core.OwnerSuccession, didWeb *core.DIDWebResolver) error {
+	if err := fr.Verify(); err != nil {
+		return err
+	}
+	if fr.Agent != card.ID {
+		return fmt.Errorf("fork resolution is for %s, not this card", fr.Agent)
+	}
+	// The owner key may have been rotated since the card was signed; its
+	// successor speaks for the card.
+	if !owners.Authorizes(fr.Owner, card.Owner, fr.IssuedAt) {
+		return fmt.Errorf("fork resolution owner key %s did not speak for the card's owner %s when it was issued", fr.Owner, card.Owner)
+	}
+	if core.IsDIDWeb(fr.Owner) {
+		return didWeb.VerifyDomain(fr.Owner, fr.OwnerKey)
+	}
+	return nil
+}
+
+// cardOwners looks up the owner on agents' verified cards, once each; "" for
+// an agent with no such card.
+type cardOwners struct {
+	reg  string
+	seen map[string]string
+}
+
+func (c *cardOwners) of(did string) string {
+	if owner, ok := c.seen[did]; ok {
+		return owner
+	}
+	if c.seen == nil {
+		c.seen = map[string]string{}
+	}
+	var owner string
+	if card, err := fetchCard(c.reg, did); err == nil && card != nil && card.Verify() == nil {
+		owner = card.Owner
+	}
+	c.seen[did] = owner
+	return owner
+}
+
+// ownerProblem words why a card owner no longer authorizes anything.
+func ownerProblem(err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	return "the owner rotated to a new key"
+}
+
 // cmdVerify is the flagship command. It pulls an agent's entire history from a
 // registry and proves it locally: every card and attestation signature is
 // checked, every issuer chain is verified, and the MoltScore is recomputed from
@@ -41,13 +201,48 @@ func checkSubjectBinding(did string, card *core.Card, atts []*core.Attestation)
 func cmdVerify(args []string) error {
 	fs := flag.NewFlagSet("verify", flag.ExitOnError)
 	registry := fs.String("registry", "", "registry base URL")
+	explain := fs.Bool("explain", false, "show each attestation's issuer weight, decay and effect on the score")
+	basisPath := fs.String("basis", "", "moltscore/v2 basis JSON to recompute under (your own trust roots)")
+	atFlag := fs.String("at", "", "score as of this RFC 3339 instant (only attestations issued by then, at that clock)")
+	algName := fs.String("algorithm", score.AlgorithmV1, "scoring model: moltscore/v1, moltscore/v2, or a v2-family model the registry publishes a basis for")
+	var didDocs stringSlice
+	fs.Var(&didDocs, "did-doc", "resolved did:web DID document, or a JSON array of them, to use instead of fetching (repeatable; verifies offline)")
 	positional := parseInterspersed(fs, args)
 	if len(positional) < 1 {
-		return fmt.Errorf("usage: molt verify <did>")
+		return fmt.Errorf("usage: molt verify <did> [--algorithm moltscore/v2] [--basis my-basis.json] [--at 2026-08-01T00:00:00Z]")
+	}
+	if *explain && *algName != score.AlgorithmV1 {
+		return fmt.Errorf("--explain is only available for %s", score.AlgorithmV1)
 	}
 	did := positional[0]
 	reg := registryURL(*registry)
 
+	now, historical := time.Now().UTC(), false
+	if *atFlag != "" {
+		t, err := time.Parse(time.RFC3339, *atFlag)
+		if err != nil {
+			return fmt.Errorf("--at: %w", err)
+		}
+		now, historical = t.UTC(), true
+	}
+
+	var basis score.Basis
+	if *basisPath != "" {
+		data, err := os.ReadFile(*basisPath)
+		if err != nil {
+			return err
+		}
+		if basis, err = score.ParseBasis(data); err != nil {
+			return fmt.Errorf("%s: %w", *basisPath, err)
+		}
+	}
+
+	for _, path := range didDocs {
+		if err := loadDIDDocs(didWeb, path); err != nil {
+			return err
+		}
+	}
+
 	card, atts, err := fetchAgent(reg, did)
 	if err != nil {
 		return err
@@ -59,48 +254,345 @@ func cmdVerify(args []string) error {
 	fmt.Printf("VERIFY  %s\n", did)
 	fmt.Printf("registry %s  (trusted for transport only)\n\n", reg)
 
-	// 1. Card signatures.
+	// Owner rotations and completed recoveries: which key speaks for each
+	// card owner now.
+	var owners core.OwnerSuccession
+	recovered, err := recoveredOwners(reg)
+	if err != nil {
+		fmt.Printf("  [warn] registry serves no recoveries: %v\n", err)
+	}
+	if rots, err := fetchOwnerRotations(reg); err != nil {
+		fmt.Printf("  [warn] registry serves no owner rotations: %v\n", err)
+		owners = core.NewOwnerSuccession(recovered)
+	} else {
+		var rejected []error
+		owners, rejected = checkOwnerRotations(rots, recovered)
+		for _, err := range rejected {
+			fmt.Printf("  [warn] ignoring owner rotation: %v\n", err)
+		}
+	}
+
+	// 1. Card signatures, by an owner key that had not been rotated away.
 	cardOK := true
 	if err := card.Verify(); err != nil {
 		cardOK = false
 		fmt.Printf("  [FAIL] card signatures: %v\n", err)
+	} else if !owners.Authorizes(card.Owner, card.Owner, card.CreatedAt) {
+		cardOK = false
+		_, err := owners.Current(card.Owner)
+		fmt.Printf("  [FAIL] card owner key %s… was rotated away before signing the card: %s\n", short(card.Owner), ownerProblem(err))
 	} else {
 		hash, _ := card.Hash()
 		fmt.Printf("  [ ok ] card signatures (agent + owner)\n")
 		fmt.Printf("         name=%q version=%s hash=%s\n", card.Name, card.Version, hash)
+		if cur, err := owners.Current(card.Owner); err != nil {
+			fmt.Printf("  [warn] card owner is contested: %v\n", err)
+		} else if cur != card.Owner {
+			fmt.Printf("         owner key rotated: %s… now speaks for owner %s…\n", short(cur), short(card.Owner))
+		}
+	}
+	// A did:web owner's domain must list the key that signed for it.
+	if cardOK && core.IsDIDWeb(card.Owner) {
+		domain := core.DIDWebDomain(card.Owner)
+		if err := didWeb.VerifyDomain(card.Owner, card.OwnerKey); err != nil {
+			cardOK = false
+			fmt.Printf("  [FAIL] owner domain %s: %v\n", domain, err)
+		} else {
+			fmt.Printf("  [ ok ] owner domain %s (its DID document lists owner key %s…)\n", domain, short(card.OwnerKey))
+		}
+	}
+
+	// Card forks: the registry must not serve a version that a resolution the
+	// agent and owner signed set aside.
+	if rs, err := fetchForkResolutions(reg, did); err != nil {
+		fmt.Printf("  [warn] registry serves no fork resolutions: %v\n", err)
+	} else {
+		hash, _ := card.Hash()
+		for _, fr := range rs {
+			if err := checkForkResolution(fr, card, owners, didWeb); err != nil {
+				fmt.Printf("  [warn] ignoring fork resolution: %v\n", err)
+				continue
+			}
+			if slices.Contains(fr.Rejected, hash) {
+				cardOK = false
+				fmt.Printf("  [FAIL] card fork: the registry serves version %s…, which the owner set aside for %s…\n", short(hash), short(fr.Head))
+				continue
+			}
+			fmt.Printf("  [ ok ] card fork resolved by agent + owner: %s… chosen over %d version(s)\n", short(fr.Head), len(fr.Rejected))
+		}
+	}
+
+	// 2. Key rotations. The identity's earlier keys' history is part of its
+	// record, so each rotation back to its first key must be owner-signed by
+	// the owner of this card, and each predecessor's attestations about it.
+	lineageOK := true
+	lineage, err := fetchLineage(reg, did)
+	switch {
+	case err != nil:
+		fmt.Printf("  [warn] registry serves no rotation lineage, scoring this key's own history only: %v\n", err)
+	case len(lineage) > 0:
+		if err := checkLineage(card, lineage, owners); err != nil {
+			lineageOK = false
+			fmt.Printf("  [FAIL] key rotations: %v\n", err)
+			break
+		}
+		fmt.Printf("  [ ok ] %d key rotation(s), all signed by the owner; predecessors' history counts\n", len(lineage))
+		for _, r := range lineage {
+			fmt.Printf("         %s… → %s…  rotated %s\n", short(r.OldAgent), short(r.NewAgent), r.IssuedAt)
+		}
+		inherited, err := fetchInherited(reg, lineage)
+		if err != nil {
+			return err
+		}
+		atts = append(inherited, atts...)
 	}
 
-	// 2. Attestation signatures + per-issuer chains.
-	chainErr := core.VerifyAll(atts)
+	// 3. Delegations and key revocations: a delegated attestation counts for
+	// its principal under a delegation from the principal's owner, and what a
+	// compromised issuer key signed from its cutoff on is disregarded, in the
+	// chains and in the score.
+	var delegations []*core.Delegation
+	if ds, err := fetchDelegations(reg); err != nil {
+		fmt.Printf("  [warn] registry serves no delegations: %v\n", err)
+	} else {
+		var rejected []error
+		delegations, rejected = checkDelegations(reg, ds, owners)
+		for _, err := range rejected {
+			fmt.Printf("  [warn] ignoring delegation: %v\n", err)
+		}
+	}
+	delegated := core.NewDelegations(delegations)
+	var revoked []*core.Revocation
+	if revs, err := fetchRevocations(reg); err != nil {
+		fmt.Printf("  [warn] registry serves no key revocations: %v\n", err)
+	} else {
+		var rejected []error
+		revoked, rejected = checkRevocations(reg, revs, owners, delegations)
+		for _, err := range rejected {
+			fmt.Printf("  [warn] ignoring revocation: %v\n", err)
+		}
+	}
+	cutoffs := core.RevocationCutoffs(revoked)
+	if cut, ok := cutoffs[did]; ok {
+		fmt.Printf("  [warn] this agent's key was revoked by its owner as of %s\n", cut.Format(time.RFC3339))
+	}
+	if n := len(atts) - len(cutoffs.Filter(atts)); n > 0 {
+		fmt.Printf("  [ ok ] %d attestation(s) signed by revoked issuer keys after their cutoff are disregarded\n", n)
+	}
+
+	// 4. Attestation signatures + per-issuer chains.
+	chainErr := core.VerifyAllWith(atts, core.VerifyOptions{Revocations: revoked, Delegations: delegations})
 	if chainErr != nil {
 		fmt.Printf("  [FAIL] attestation chains: %v\n", chainErr)
 	} else {
 		fmt.Printf("  [ ok ] %d attestation(s), all signatures valid, all issuer chains intact\n", len(atts))
 	}
+	// A proof that this agent forked its own chain as an issuer needs no trust
+	// in the registry: it carries both signed branches. One that does not
+	// verify is ignored.
+	var equivocations []*core.Equivocation
+	if eqs, err := fetchEquivocations(reg, did); err != nil {
+		fmt.Printf("  [warn] registry serves no equivocation proofs: %v\n", err)
+	} else {
+		for _, e := range eqs {
+			if err := e.Verify(); err != nil || e.Issuer != did {
+				fmt.Printf("  [warn] ignoring equivocation proof: %v\n", cmp.Or(err, fmt.Errorf("it is against %s", e.Issuer)))
+				continue
+			}
+			equivocations = append(equivocations, e)
+			ha, hb := e.Hashes()
+			fmt.Printf("  [warn] this agent forked its own attestation chain: %s… and %s… both follow %q\n", short(ha), short(hb), e.Prev)
+		}
+	}
+	// Retractions count only from trusted issuers, whose signatures were
+	// checked above with the rest of their chains.
+	retractions := core.NewRetractions(delegated.Filter(cutoffs.Filter(atts)))
+	if n := len(retractions); n > 0 {
+		effect := "are disregarded"
+		if *algName == score.AlgorithmV1 {
+			effect = "still count under " + score.AlgorithmV1 + ", which applies no retractions"
+		}
+		fmt.Printf("  [ ok ] %d attestation(s) withdrawn by their issuers' signed retractions %s\n", n, effect)
+	}
 
 	// Per-attestation summary.
 	for _, a := range atts {
-		status := "ok"
-		if a.Verify() != nil {
+		status, note := "ok", ""
+		switch {
+		case a.Verify() != nil:
 			status = "BAD"
+		case cutoffs.Revoked(a):
+			status = "revoked"
+		case delegated.Check(a) != nil:
+			status = "BAD"
+		case retractions.Retracted(a) != nil:
+			rh, _ := retractions.Retracted(a).Hash()
+			status, note = "retracted", " (by "+short(rh)+"…)"
+		case a.Window(now) == core.WindowExpired:
+			status, note = "expired", " (at "+a.ExpiresAt+")"
+		case a.Window(now) == core.WindowNotYetValid:
+			status, note = "not yet valid", " (from "+a.NotBefore+")"
+		}
+		if a.OnBehalfOf != "" {
+			fmt.Printf("         [%s] %-15s from %s… (delegate of %s…)%s\n", status, a.Type, short(a.Issuer), short(a.OnBehalfOf), note)
+		} else if len(a.CoIssuers) > 0 {
+			fmt.Printf("         [%s] %-15s from %s… (+%d co-issuers)%s\n", status, a.Type, short(a.Issuer), len(a.CoIssuers), note)
+		} else {
+			fmt.Printf("         [%s] %-15s from %s…%s\n", status, a.Type, short(a.Issuer), note)
+		}
+		// A body off its type's schema is signed and counts all the same, but
+		// the convention it breaks is worth seeing.
+		var be *core.BodyError
+		if errors.As(core.ValidateBody(a.Type, a.Body), &be) {
+			for _, v := range be.Violations {
+				fmt.Printf("           [warn] %s\n", v)
+			}
 		}
-		fmt.Printf("         [%s] %-15s from %s…\n", status, a.Type, short(a.Issuer))
 	}
 
-	// 3. Recompute MoltScore locally with default (trustless) issuer weights.
-	out := score.Compute(atts, nil, nil, time.Now().UTC())
-	fmt.Printf("\n  MoltScore (recomputed locally, %s): %s\n", score.Algorithm, scoreLine(out))
+	// 5. Recompute the score locally. Every signature and chain is verified
+	// above regardless of --at; only the score is restricted to the chain as it
+	// stood then.
+	in := score.Input{
+		Subject: did, Attestations: atts, Revoked: cutoffs, Delegations: delegated,
+		Equivocations: equivocations, Now: now,
+	}
+	when := ""
+	if historical {
+		in = in.AsOf(now)
+		when = fmt.Sprintf(", as of %s over %d attestation(s)", now.Format(time.RFC3339), len(in.Attestations))
+	}
+	if *algName == score.AlgorithmV1 {
+		// Default (trustless) issuer weights.
+		out := score.V1{}.Score(in)
+		if *explain {
+			out = score.V1{}.Explain(in)
+		}
+		fmt.Printf("\n  MoltScore (recomputed locally, %s%s): %s\n", score.AlgorithmV1, when, scoreLine(out))
+		if out.Explain != nil {
+			printExplanation(out.Explain)
+		}
+		// Optionally, moltscore/v2 under the caller's own basis as well.
+		if *basisPath != "" {
+			verifyBasis(reg, score.V2{Basis: basis}, true, in, historical)
+		}
+	} else {
+		// A v2-family model: under the caller's basis if given, otherwise
+		// under the one the registry publishes for it.
+		alg := score.V2{Basis: basis}
+		if *algName != score.AlgorithmV2 {
+			alg.Label = *algName
+		}
+		if *basisPath == "" {
+			b, err := registryBasis(reg, *algName)
+			if err != nil {
+				return fmt.Errorf("registry publishes no basis for %s, so it cannot be recomputed here: %w", *algName, err)
+			}
+			alg.Basis = b
+		}
+		fmt.Println()
+		verifyBasis(reg, alg, *basisPath != "", in, historical)
+	}
 
-	if !cardOK || chainErr != nil {
+	if !cardOK || !lineageOK || chainErr != nil {
 		return fmt.Errorf("verification failed")
 	}
 	fmt.Printf("\n  RESULT: verified ✓  (no trust placed in the registry)\n")
 	return nil
 }
 
+// registryBasis fetches the basis document the registry runs model name under.
+func registryBasis(reg, name string) (score.Basis, error) {
+	var raw json.RawMessage
+	if err := httpGet(reg+"/v1/score/basis?algorithm="+url.QueryEscape(name), &raw); err != nil {
+		return score.Basis{}, err
+	}
+	return score.ParseBasis(raw)
+}
+
+// verifyBasis recomputes a moltscore/v2-family model. With own set the basis is
+// the caller's, and is compared with the one the registry publishes for the
+// model. A mismatch is not a failure: different trust roots give different
+// scores, and saying so is the point.
+//
+// in is the subject's input without a graph. With historical set, the graph is
+// cut to what was issued by in.Now, so the issuer weights are historical too.
+// Revocations and delegations apply to the graph as to the attestations.
+func verifyBasis(reg string, alg score.V2, own bool, in score.Input, historical bool) {
+	mine, _ := alg.Basis.Hash()
+	whose := "registry's basis"
+	if own {
+		whose = "your basis"
+		switch theirs, err := registryBasis(reg, alg.Name()); {
+		case err != nil:
+			fmt.Printf("  [warn] registry publishes no usable score basis: %v\n", err)
+		default:
+			if h, _ := theirs.Hash(); h == mine {
+				fmt.Printf("  [ ok ] basis %s matches the registry's\n", mine)
+			} else {
+				fmt.Printf("  [diff] basis %s differs from the registry's %s — its %s scores answer a different question\n", mine, h, alg.Name())
+			}
+		}
+	} else {
+		fmt.Printf("  [ ok ] basis %s as published by the registry\n", mine)
+	}
+
+	// Issuer weights are a property of the whole graph, so they are computed
+	// over the registry's full attestation set, cached locally and refreshed
+	// incrementally. A registry that cannot serve it leaves only this agent's
+	// chain: an anchor's direct attestations count, longer trust paths do not.
+	graph, scope := in.Attestations, "subject chain only"
+	if c, added, err := syncAttestations(reg, attCachePath(reg)); err != nil {
+		fmt.Printf("  [warn] full attestation set unavailable, weighting from this chain only: %v\n", err)
+	} else {
+		graph, scope = c.Attestations, fmt.Sprintf("full graph, %d attestation(s)", len(c.Attestations))
+		fmt.Printf("  [ ok ] attestation cache synced: %d new, %d total\n", added, len(c.Attestations))
+	}
+	if historical {
+		graph = score.AsOf(graph, in.Now)
+		scope += ", as of " + in.Now.Format(time.RFC3339)
+	}
+	in.Graph = graph
+	out := alg.Score(in)
+	fmt.Printf("  MoltScore (recomputed locally, %s, %s, %s): %s\n", alg.Name(), whose, scope, scoreLine(out))
+}
+
+// printExplanation lists each attestation's part in the score: the issuer weight
+// and decay it was scored with, and Δx, its marginal effect on the sigmoid input.
+func printExplanation(ex *score.Explanation) {
+	fmt.Printf("         x = %+.3f  (score = 100·sigmoid(x))\n", ex.X)
+	for _, c := range ex.Attestations {
+		if c.SelfDealing {
+			fmt.Printf("         %-15s from %s…  dropped: issuer shares the subject's owner\n", c.Type, short(c.Issuer))
+			continue
+		}
+		fmt.Printf("         %-15s from %s…  weight=%.2f decay=%.3f Δx=%+.3f\n",
+			c.Type, short(c.Issuer), c.IssuerWeight, c.Decay, c.Marginal)
+	}
+}
+
 func short(did string) string {
 	if len(did) <= 16 {
 		return did
 	}
 	return did[:16]
 }
+
+// loadDIDDocs adds the DID documents in path — one, or a JSON array of them —
+// to r, so it need not fetch them.
+func loadDIDDocs(r *core.DIDWebResolver, path string) error {
+	var docs []*core.DIDDocument
+	if err := readJSONFile(path, &docs); err != nil {
+		var doc core.DIDDocument
+		if readJSONFile(path, &doc) != nil {
+			return err
+		}
+		docs = []*core.DIDDocument{&doc}
+	}
+	for _, doc := range docs {
+		if err := r.Add(doc); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return nil
+}
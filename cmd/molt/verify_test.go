@@ -1,10 +1,19 @@
 package main
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/moltnet/moltnet/core"
+	"github.com/moltnet/moltnet/internal/server"
+	"github.com/moltnet/moltnet/internal/store"
 )
 
 // checkSubjectBinding is what stands between "verified ✓" and a registry that
@@ -75,3 +84,204 @@ func TestCheckSubjectBinding(t *testing.T) {
 		})
 	}
 }
+
+// A registry chooses which rotations to hand back, so a lineage is believed
+// only if the agent's own owner signed every link down to the requested key.
+func TestCheckLineage(t *testing.T) {
+	owner, _ := core.GenerateKeyPair()
+	mallory, _ := core.GenerateKeyPair()
+	a0, _ := core.GenerateKeyPair()
+	a1, _ := core.GenerateKeyPair()
+	rotate := func(by *core.KeyPair, from, to string) *core.Rotation {
+		r := core.NewRotation(by.DID, from, to)
+		if err := r.Sign(by.Private); err != nil {
+			t.Fatal(err)
+		}
+		return r
+	}
+	card := &core.Card{ID: a1.DID, Owner: owner.DID}
+
+	if err := checkLineage(card, []*core.Rotation{rotate(owner, a0.DID, a1.DID)}, core.OwnerSuccession{}); err != nil {
+		t.Fatalf("owner-signed lineage rejected: %v", err)
+	}
+	if err := checkLineage(card, nil, core.OwnerSuccession{}); err != nil {
+		t.Fatalf("an unrotated agent has an empty lineage: %v", err)
+	}
+	// Genuinely signed, but by someone else: a stranger's history grafted on.
+	if err := checkLineage(card, []*core.Rotation{rotate(mallory, a0.DID, a1.DID)}, core.OwnerSuccession{}); err == nil {
+		t.Fatal("lineage signed by a foreign owner should fail")
+	}
+	if err := checkLineage(card, []*core.Rotation{rotate(owner, a1.DID, a0.DID)}, core.OwnerSuccession{}); err == nil {
+		t.Fatal("lineage that does not end at the card's DID should fail")
+	}
+}
+
+// A registry that could serve any revocation would be able to silence any
+// issuer; only one signed by the owner on the revoked agent's card holds.
+func TestCheckRevocations(t *testing.T) {
+	st, err := store.Open(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+	ts := httptest.NewServer((&server.Server{Store: st}).Handler())
+	defer ts.Close()
+
+	owner, _ := core.GenerateKeyPair()
+	agent, _ := core.GenerateKeyPair()
+	mallory, _ := core.GenerateKeyPair()
+	card := core.NewCard(agent.DID, owner.DID, "issuer")
+	if err := card.Sign(agent.Private, owner.Private); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := st.PutCard(card); err != nil {
+		t.Fatal(err)
+	}
+	revoke := func(by *core.KeyPair) *core.Revocation {
+		r := core.NewRevocation(by.DID, agent.DID, time.Now())
+		if err := r.Sign(by.Private); err != nil {
+			t.Fatal(err)
+		}
+		return r
+	}
+	genuine, forged := revoke(owner), revoke(mallory)
+	held, rejected := checkRevocations(ts.URL, []*core.Revocation{genuine, forged}, core.OwnerSuccession{}, nil)
+	if len(held) != 1 || held[0] != genuine || len(rejected) != 1 {
+		t.Fatalf("want only the owner's revocation held: held=%d rejected=%v", len(held), rejected)
+	}
+}
+
+// Likewise a delegation lends its principal's weight, so only one signed by
+// the principal's owner holds — and only that owner can revoke the delegate.
+func TestCheckDelegations(t *testing.T) {
+	st, err := store.Open(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+	ts := httptest.NewServer((&server.Server{Store: st}).Handler())
+	defer ts.Close()
+
+	owner, _ := core.GenerateKeyPair()
+	principal, _ := core.GenerateKeyPair()
+	worker, _ := core.GenerateKeyPair()
+	mallory, _ := core.GenerateKeyPair()
+	card := core.NewCard(principal.DID, owner.DID, "busy-evaluator")
+	if err := card.Sign(principal.Private, owner.Private); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := st.PutCard(card); err != nil {
+		t.Fatal(err)
+	}
+	delegate := func(by *core.KeyPair) *core.Delegation {
+		d := core.NewDelegation(by.DID, principal.DID, worker.DID, time.Now().Add(24*time.Hour))
+		if err := d.Sign(by.Private); err != nil {
+			t.Fatal(err)
+		}
+		return d
+	}
+	genuine, forged := delegate(owner), delegate(mallory)
+	held, rejected := checkDelegations(ts.URL, []*core.Delegation{genuine, forged}, core.OwnerSuccession{})
+	if len(held) != 1 || held[0] != genuine || len(rejected) != 1 {
+		t.Fatalf("want only the owner's delegation held: held=%d rejected=%v", len(held), rejected)
+	}
+	revoke := func(by *core.KeyPair) *core.Revocation {
+		r := core.NewRevocation(by.DID, worker.DID, time.Now())
+		if err := r.Sign(by.Private); err != nil {
+			t.Fatal(err)
+		}
+		return r
+	}
+	revs, rejected := checkRevocations(ts.URL, []*core.Revocation{revoke(owner), revoke(mallory)}, core.OwnerSuccession{}, held)
+	if len(revs) != 1 || revs[0].Owner != owner.DID || len(rejected) != 1 {
+		t.Fatalf("want only the owner's revocation of its delegate held: held=%d rejected=%v", len(revs), rejected)
+	}
+}
+
+// The attestation cache is filled incrementally: a second sync downloads only
+// what was written since the first, and the cache ends up holding every record.
+func TestSyncAttestationsIncremental(t *testing.T) {
+	st, err := store.Open(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+	ts := httptest.NewServer((&server.Server{Store: st}).Handler())
+	defer ts.Close()
+
+	owner, _ := core.GenerateKeyPair()
+	agent, _ := core.GenerateKeyPair()
+	issuer, _ := core.GenerateKeyPair()
+	card := core.NewCard(agent.DID, owner.DID, "subject")
+	if err := card.Sign(agent.Private, owner.Private); err != nil {
+		t.Fatal(err)
+	}
+	post := func(path string, v any) {
+		data, _ := json.Marshal(v)
+		resp, err := http.Post(ts.URL+path, "application/json", bytes.NewReader(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != 201 {
+			t.Fatalf("POST %s: %d", path, resp.StatusCode)
+		}
+	}
+	post("/v1/agents", card)
+	prev := ""
+	attest := func(n int) {
+		for i := 0; i < n; i++ {
+			a := core.NewAttestation(core.TypeTaskCompleted, issuer.DID, agent.DID)
+			a.Prev = prev
+			if err := a.Sign(issuer.Private); err != nil {
+				t.Fatal(err)
+			}
+			post("/v1/attestations", a)
+			prev, _ = a.Hash()
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "cache.json")
+	attest(3)
+	if c, added, err := syncAttestations(ts.URL, path); err != nil || added != 3 || len(c.Attestations) != 3 {
+		t.Fatalf("first sync: added=%d err=%v", added, err)
+	}
+	attest(2)
+	c, added, err := syncAttestations(ts.URL, path)
+	if err != nil || added != 2 || len(c.Attestations) != 5 {
+		t.Fatalf("second sync should fetch only the 2 new records: added=%d total=%d err=%v", added, len(c.Attestations), err)
+	}
+	if _, added, _ := syncAttestations(ts.URL, path); added != 0 {
+		t.Fatalf("caught-up sync added %d", added)
+	}
+}
+
+// A DID document bundle is used in place of fetching, so verify can link a
+// did:web owner to its domain offline.
+func TestLoadDIDDocs(t *testing.T) {
+	owner, _ := core.GenerateKeyPair()
+	doc, err := core.NewDIDDocument("did:web:acme.example", owner.DID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "bundle.json")
+	if err := writeJSONFile(path, []*core.DIDDocument{doc}); err != nil {
+		t.Fatal(err)
+	}
+	r := core.NewDIDWebResolver(&http.Client{Transport: failingTransport{}})
+	if err := loadDIDDocs(r, path); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.VerifyDomain("did:web:acme.example", owner.DID); err != nil {
+		t.Fatalf("bundled document should link the key offline: %v", err)
+	}
+	if r.VerifyDomain("did:web:other.example", owner.DID) == nil {
+		t.Fatal("a document missing from the bundle cannot be resolved offline")
+	}
+}
+
+type failingTransport struct{}
+
+func (failingTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, errors.New("offline")
+}
@@ -16,17 +16,19 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/moltnet/moltnet/core"
 	"github.com/moltnet/moltnet/internal/server"
 	"github.com/moltnet/moltnet/internal/store"
+	"github.com/moltnet/moltnet/score"
 )
 
 const version = "0.1.0"
 
-// peerList collects repeated --peer flags.
-type peerList []string
+// listFlag collects a repeatable flag (--peer, --score-model).
+type listFlag []string
 
-func (p *peerList) String() string { return fmt.Sprintf("%v", []string(*p)) }
-func (p *peerList) Set(v string) error {
+func (p *listFlag) String() string { return fmt.Sprintf("%v", []string(*p)) }
+func (p *listFlag) Set(v string) error {
 	*p = append(*p, v)
 	return nil
 }
@@ -53,6 +55,32 @@ func splitList(s string) []string {
 	return out
 }
 
+// scoreModels builds the registry of scoring models: the built-in moltscore/v1
+// and v2 (rooted at anchors), plus one custom v2-family model per
+// --score-model name=basis.json, so an operator can run an experimental basis
+// next to the reference one without forking the server.
+func scoreModels(anchors, models []string) (*score.Registry, error) {
+	reg := score.Builtin(anchors)
+	for _, m := range models {
+		name, path, ok := strings.Cut(m, "=")
+		if !ok || name == "" || path == "" {
+			return nil, fmt.Errorf("--score-model %q: want name=basis.json", m)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		basis, err := score.ParseBasis(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		if err := reg.Register(score.V2{Label: name, Basis: basis}); err != nil {
+			return nil, err
+		}
+	}
+	return reg, nil
+}
+
 func main() {
 	var (
 		addr   = flag.String("addr", ":8787", "listen address")
@@ -72,10 +100,20 @@ func main() {
 		// bucket and the whole internet rate-limits as one IP.
 		trustedProxies = flag.String("trusted-proxy", envOr("MOLTNET_TRUSTED_PROXIES", ""),
 			"comma-separated CIDRs whose X-Forwarded-For is trusted, e.g. 172.16.0.0/12 ($MOLTNET_TRUSTED_PROXIES)")
-		logReq = flag.Bool("log-requests", false, "write one structured JSON log line per request to stderr")
+		// The moltscore/v2 trust roots. With none, every v2 score is the
+		// no-history baseline; an operator anchors at least their own owner DID.
+		anchors = flag.String("anchor", envOr("MOLTNET_ANCHORS", ""),
+			"comma-separated moltscore/v2 anchor DIDs published in the score basis ($MOLTNET_ANCHORS)")
+		scoreAlg = flag.String("score-algorithm", envOr("MOLTNET_SCORE_ALGORITHM", score.AlgorithmV1),
+			"default scoring model for profiles, badges and search ($MOLTNET_SCORE_ALGORITHM)")
+		recDepth = flag.Int("recompute-depth", 8, "issuer→subject hops a score change is propagated to dependent agents")
+		logReq   = flag.Bool("log-requests", false, "write one structured JSON log line per request to stderr")
+		didTTL   = flag.Duration("did-web-ttl", core.DefaultDIDWebTTL, "how long a fetched did:web owner document is cached")
+		strict   = flag.Bool("strict-bodies", false, "refuse attestations whose body fails its type's JSON Schema (by default they are stored, with warnings)")
 	)
-	var peers peerList
+	var peers, models listFlag
 	flag.Var(&peers, "peer", "federation peer base URL to follow (repeatable)")
+	flag.Var(&models, "score-model", "custom moltscore/v2-family model as name=basis.json (repeatable)")
 	flag.Parse()
 
 	st, err := store.Open(*dbPath)
@@ -83,20 +121,50 @@ func main() {
 		log.Fatalf("open store: %v", err)
 	}
 	defer st.Close()
+	// Records signed before canonicalization followed RFC 8785 may hash
+	// differently now; name them at startup rather than let them fail one by
+	// one.
+	legacy, err := st.LegacyRecords()
+	if err != nil {
+		log.Fatalf("check stored records: %v", err)
+	}
+
+	algs, err := scoreModels(splitList(*anchors), models)
+	if err != nil {
+		log.Fatalf("score models: %v", err)
+	}
+	if _, ok := algs.Lookup(*scoreAlg); !ok {
+		log.Fatalf("--score-algorithm %q is not a registered model (have %s)", *scoreAlg, strings.Join(algs.Names(), ", "))
+	}
 
 	srv := &server.Server{Store: st, AppDir: *appDir, Name: *name, Version: version, Peers: peers,
-		RateLimitPerMin: *rlimit, TrustedProxies: splitList(*trustedProxies)}
+		RateLimitPerMin: *rlimit, TrustedProxies: splitList(*trustedProxies), Anchors: splitList(*anchors),
+		Algorithms: algs, DefaultAlgorithm: *scoreAlg, RecomputeDepth: *recDepth, StrictBodies: *strict,
+		DIDWeb: &core.DIDWebResolver{TTL: *didTTL}}
 	if *logReq {
 		srv.LogWriter = os.Stderr
 	}
 	srv.StartLivenessProber(*probe)
 	srv.StartFederation(*fedInt)
+	// Keep cached scores current when an issuer's score moves: v1 weighs each
+	// attestation by its issuer's cached score.
+	srv.StartRecompute()
 	// Reap spent SIWK challenges and expired sessions. /v1/auth/challenge is
 	// unauthenticated, so without this the auth tables grow without bound.
 	srv.StartAuthGC(time.Hour)
 
 	fmt.Fprintf(os.Stderr, "moltnetd %s\n", version)
 	fmt.Fprintf(os.Stderr, "  db:   %s\n", *dbPath)
+	fmt.Fprintf(os.Stderr, "  score: %s (available: %s)\n", *scoreAlg, strings.Join(algs.Names(), ", "))
+	if len(srv.Anchors) == 0 {
+		fmt.Fprintf(os.Stderr, "  warning: no --anchor set; every moltscore/v2 score is the baseline\n")
+	}
+	if len(legacy) > 0 {
+		fmt.Fprintf(os.Stderr, "  warning: %d stored record(s) predate RFC 8785 canonicalization; their signatures and hashes no longer verify:\n", len(legacy))
+		for _, r := range legacy {
+			fmt.Fprintf(os.Stderr, "    %s %s: %s\n", r.Table, r.Key, strings.Join(r.Diffs, "; "))
+		}
+	}
 	if *appDir != "" {
 		fmt.Fprintf(os.Stderr, "  app:  %s\n", *appDir)
 	}
@@ -1,8 +1,9 @@
 package core
 
 import (
-	"crypto/ed25519"
+	"crypto"
 	"fmt"
+	"strings"
 	"time"
 )
 
@@ -11,20 +12,21 @@ const AttestationSpec = "moltnet/attestation/v0.1"
 
 // Attestation types recognised in v0.1.
 const (
-	TypeTaskCompleted   = "task.completed"
-	TypeTaskDisputed    = "task.disputed"
-	TypeEndorsement     = "endorsement"
-	TypeIncident        = "incident"
-	TypePaymentReceipt  = "payment.receipt"
-	TypeKeyRotation     = "key.rotation"
-	TypeSelfClaim       = "self.claim"
+	TypeTaskCompleted  = "task.completed"
+	TypeTaskDisputed   = "task.disputed"
+	TypeEndorsement    = "endorsement"
+	TypeIncident       = "incident"
+	TypePaymentReceipt = "payment.receipt"
+	TypeKeyRotation    = "key.rotation"
+	TypeSelfClaim      = "self.claim"
+	TypeRetraction     = "retraction"
 )
 
 // ValidType reports whether t is a known v0.1 attestation type.
 func ValidType(t string) bool {
 	switch t {
 	case TypeTaskCompleted, TypeTaskDisputed, TypeEndorsement, TypeIncident,
-		TypePaymentReceipt, TypeKeyRotation, TypeSelfClaim:
+		TypePaymentReceipt, TypeKeyRotation, TypeSelfClaim, TypeRetraction:
 		return true
 	default:
 		return false
@@ -42,17 +44,34 @@ type Anchor struct {
 // (subject). Attestations are the raw material of reputation. Each one chains
 // to the issuer's previous attestation (any subject) via a per-issuer hash
 // chain, so history cannot be silently reordered or retracted.
+//
+// A delegated attestation is signed by a sub-key (Issuer) on behalf of an
+// agent (OnBehalfOf) under a Delegation; it chains on the sub-key's own chain
+// and counts as the principal's.
+//
+// An attestation may be time-bounded ("passed an audit, valid for 90 days"):
+// it is in effect from NotBefore until ExpiresAt, and outside that window it
+// stays in the chain but counts for nothing (see Window).
+//
+// A co-signed attestation is one statement by several issuers: Issuer leads,
+// CoIssuers follow in order, and each signs the same payload (see Issuers).
 type Attestation struct {
 	Spec        string         `json:"spec"`
 	Type        string         `json:"type"`
-	Subject     string         `json:"subject"`      // did:key of the subject agent
-	SubjectCard string         `json:"subject_card"` // card hash at time of attestation
-	Issuer      string         `json:"issuer"`       // did:key of the issuer
-	Prev        string         `json:"prev,omitempty"` // hash of issuer's previous attestation
+	Subject     string         `json:"subject"`                // did:key of the subject agent
+	SubjectCard string         `json:"subject_card"`           // card hash at time of attestation
+	Issuer      string         `json:"issuer"`                 // did:key of the issuer
+	OnBehalfOf  string         `json:"on_behalf_of,omitempty"` // principal DID, if Issuer is a delegate
+	Delegation  string         `json:"delegation,omitempty"`   // hash of the delegation, if delegated
+	Prev        string         `json:"prev,omitempty"`         // hash of issuer's previous attestation
+	CoIssuers   []CoIssuer     `json:"co_issuers,omitempty"`   // further issuers of a co-signed attestation
 	Body        map[string]any `json:"body,omitempty"`
 	IssuedAt    string         `json:"issued_at"`
+	NotBefore   string         `json:"not_before,omitempty"` // RFC 3339; in effect from then on
+	ExpiresAt   string         `json:"expires_at,omitempty"` // RFC 3339; no longer in effect from then on
 	Anchor      *Anchor        `json:"anchor,omitempty"`
 	Sig         string         `json:"sig,omitempty"`
+	CoSigs      []CoSig        `json:"co_sigs,omitempty"` // co-issuer signatures
 }
 
 // NewAttestation builds an unsigned attestation with spec tag and timestamp set.
@@ -66,9 +85,19 @@ func NewAttestation(typ, issuerDID, subjectDID string) *Attestation {
 	}
 }
 
-// SigningPayload is the canonical attestation without its signature.
+// Principal is the identity the attestation counts for: OnBehalfOf if it was
+// issued by a delegate, else Issuer. Only meaningful once the delegation has
+// been checked (Delegations.Check).
+func (a *Attestation) Principal() string {
+	if a.OnBehalfOf != "" {
+		return a.OnBehalfOf
+	}
+	return a.Issuer
+}
+
+// SigningPayload is the canonical attestation without its signatures.
 func (a *Attestation) SigningPayload() ([]byte, error) {
-	return CanonicalizeWithout(a, "sig")
+	return CanonicalizeWithout(a, "sig", "co_sigs")
 }
 
 // Hash returns the content address (BLAKE3 of the signing payload). This is the
@@ -82,16 +111,17 @@ func (a *Attestation) Hash() (string, error) {
 }
 
 // Sign fills in the issuer signature.
-func (a *Attestation) Sign(issuerKey ed25519.PrivateKey) error {
+func (a *Attestation) Sign(issuerKey crypto.Signer) error {
 	payload, err := a.SigningPayload()
 	if err != nil {
 		return err
 	}
-	a.Sig = Sign(issuerKey, payload)
-	return nil
+	a.Sig, err = Sign(issuerKey, payload)
+	return err
 }
 
-// Verify checks structural invariants and the issuer signature.
+// Verify checks structural invariants and the issuer signature, and for a
+// co-signed attestation every co-issuer's.
 func (a *Attestation) Verify() error {
 	if a.Spec != AttestationSpec {
 		return fmt.Errorf("attestation: unexpected spec %q", a.Spec)
@@ -102,6 +132,18 @@ func (a *Attestation) Verify() error {
 	if a.Issuer == "" || a.Subject == "" {
 		return fmt.Errorf("attestation: issuer and subject are required")
 	}
+	if (a.OnBehalfOf == "") != (a.Delegation == "") {
+		return fmt.Errorf("attestation: on_behalf_of and delegation go together")
+	}
+	if a.OnBehalfOf != "" && a.OnBehalfOf == a.Issuer {
+		return fmt.Errorf("attestation: an issuer cannot act on its own behalf")
+	}
+	if a.Type == TypeRetraction && !strings.HasPrefix(a.Retracts(), "blake3:") {
+		return fmt.Errorf("attestation: a retraction needs body.retracts, the hash of the attestation it withdraws")
+	}
+	if err := a.checkWindow(); err != nil {
+		return fmt.Errorf("attestation: %w", err)
+	}
 	if a.Sig == "" {
 		return fmt.Errorf("attestation: missing issuer signature")
 	}
@@ -112,5 +154,87 @@ func (a *Attestation) Verify() error {
 	if err := Verify(a.Issuer, payload, a.Sig); err != nil {
 		return fmt.Errorf("attestation: issuer signature invalid: %w", err)
 	}
+	if err := a.verifyCoSigs(payload); err != nil {
+		return fmt.Errorf("attestation: %w", err)
+	}
 	return nil
 }
+
+// Validity window states, as Window reports them.
+const (
+	WindowInEffect    = "in_effect"
+	WindowNotYetValid = "not_yet_valid"
+	WindowExpired     = "expired"
+)
+
+// checkWindow checks that the validity window is well formed: each bound, if
+// set, is an RFC 3339 time, and the window closes after it opens and after
+// the attestation was issued. A retraction takes no window: a withdrawal that
+// lapsed would revive what it withdrew.
+func (a *Attestation) checkWindow() error {
+	if a.NotBefore == "" && a.ExpiresAt == "" {
+		return nil
+	}
+	if a.Type == TypeRetraction {
+		return fmt.Errorf("a retraction cannot carry not_before or expires_at")
+	}
+	var nb, exp time.Time
+	var err error
+	if a.NotBefore != "" {
+		if nb, err = time.Parse(time.RFC3339, a.NotBefore); err != nil {
+			return fmt.Errorf("not_before is not an RFC 3339 time: %w", err)
+		}
+	}
+	if a.ExpiresAt == "" {
+		return nil
+	}
+	if exp, err = time.Parse(time.RFC3339, a.ExpiresAt); err != nil {
+		return fmt.Errorf("expires_at is not an RFC 3339 time: %w", err)
+	}
+	if !nb.IsZero() && !exp.After(nb) {
+		return fmt.Errorf("expires_at must be after not_before")
+	}
+	if issued, err := time.Parse(time.RFC3339, a.IssuedAt); err == nil && !exp.After(issued) {
+		return fmt.Errorf("expires_at must be after issued_at")
+	}
+	return nil
+}
+
+// Window reports where t falls in the attestation's validity window:
+// WindowNotYetValid before NotBefore, WindowExpired from ExpiresAt on, and
+// WindowInEffect otherwise (always, for an attestation without one). A bound
+// that does not parse leaves the attestation never in effect — Verify rejects
+// it, but scores also see records no one verified.
+func (a *Attestation) Window(t time.Time) string {
+	if a.NotBefore != "" {
+		nb, err := time.Parse(time.RFC3339, a.NotBefore)
+		if err != nil || t.Before(nb) {
+			return WindowNotYetValid
+		}
+	}
+	if a.ExpiresAt != "" {
+		exp, err := time.Parse(time.RFC3339, a.ExpiresAt)
+		if err != nil || !t.Before(exp) {
+			return WindowExpired
+		}
+	}
+	return WindowInEffect
+}
+
+// InEffect returns the attestations in atts whose validity window holds t, in
+// order. With every one in effect it returns atts itself.
+func InEffect(atts []*Attestation, t time.Time) []*Attestation {
+	for i, a := range atts {
+		if a.Window(t) == WindowInEffect {
+			continue
+		}
+		out := append(make([]*Attestation, 0, len(atts)), atts[:i]...)
+		for _, a := range atts[i+1:] {
+			if a.Window(t) == WindowInEffect {
+				out = append(out, a)
+			}
+		}
+		return out
+	}
+	return atts
+}
@@ -4,26 +4,35 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"math"
+	"slices"
 	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
 )
 
-// Canonicalize renders v into deterministic JSON: object keys sorted
-// lexicographically, minimal separators, no insignificant whitespace. This is
-// a JCS-compatible (RFC 8785) serializer for the subset of JSON that MoltNet
-// cards and attestations use (objects, arrays, strings, bools, null, integers).
-//
-// Full RFC 8785 floating-point canonicalization is not implemented; MoltNet
-// signed content avoids non-integer numbers. See spec/card-v0.1.md.
+// Canonicalize renders v into its RFC 8785 (JCS) canonical JSON: object keys
+// sorted by UTF-16 code units, minimal separators, no insignificant whitespace,
+// and numbers serialized as ECMAScript does (so 1, 1.0 and 1e0 agree). Values
+// JSON cannot carry exactly — NaN, ±Inf, numbers beyond float64 range, lone
+// surrogates — are rejected rather than approximated.
 func Canonicalize(v any) ([]byte, error) {
 	// Round-trip through generic JSON so struct field order can't affect output.
 	raw, err := json.Marshal(v)
 	if err != nil {
 		return nil, err
 	}
-	var generic any
-	dec := json.NewDecoder(bytes.NewReader(raw))
-	dec.UseNumber()
-	if err := dec.Decode(&generic); err != nil {
+	return CanonicalizeJSON(raw)
+}
+
+// CanonicalizeJSON canonicalizes JSON text. Unlike decoding into a Go value,
+// it sees the text as written, so duplicate object keys are rejected instead of
+// silently keeping the last one. See ParseJSON.
+func CanonicalizeJSON(data []byte) ([]byte, error) {
+	generic, err := ParseJSON(data)
+	if err != nil {
 		return nil, err
 	}
 	var buf bytes.Buffer
@@ -40,23 +49,225 @@ func CanonicalizeWithout(v any, dropKeys ...string) ([]byte, error) {
 	if err != nil {
 		return nil, err
 	}
-	var m map[string]any
-	dec := json.NewDecoder(bytes.NewReader(raw))
-	dec.UseNumber()
-	if err := dec.Decode(&m); err != nil {
-		// Not an object; nothing to drop.
-		return Canonicalize(v)
+	generic, err := ParseJSON(raw)
+	if err != nil {
+		return nil, err
 	}
-	for _, k := range dropKeys {
-		delete(m, k)
+	if m, ok := generic.(map[string]any); ok {
+		for _, k := range dropKeys {
+			delete(m, k)
+		}
 	}
 	var buf bytes.Buffer
-	if err := writeCanonical(&buf, m); err != nil {
+	if err := writeCanonical(&buf, generic); err != nil {
 		return nil, err
 	}
 	return buf.Bytes(), nil
 }
 
+// LegacyCanonicalDiffs lists where the canonical form of the JSON text data
+// differs from the one MoltNet wrote before it adopted RFC 8785, which copied
+// numbers as written and sorted object keys by UTF-8 bytes: each number not
+// already in ECMAScript form, and each object whose keys sort differently by
+// UTF-16. A record with any was signed and hashed over the old form, so its
+// signature no longer verifies and its hash, and every prev that links to it,
+// has changed. Records in the old form with none are unaffected.
+func LegacyCanonicalDiffs(data []byte) ([]string, error) {
+	generic, err := ParseJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	var out []string
+	var walk func(v any)
+	walk = func(v any) {
+		switch val := v.(type) {
+		case map[string]any:
+			keys := make([]string, 0, len(val))
+			for k := range val {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			if !sort.SliceIsSorted(keys, func(i, j int) bool { return lessUTF16(keys[i], keys[j]) }) {
+				out = append(out, "object keys "+strings.Join(keys, ",")+" now sort by UTF-16")
+			}
+			for _, k := range keys {
+				walk(val[k])
+			}
+		case []any:
+			for _, item := range val {
+				walk(item)
+			}
+		case json.Number:
+			f, _ := numberFloat(val)
+			if c, _ := formatNumber(f); c != val.String() {
+				out = append(out, "number "+val.String()+" is now "+c)
+			}
+		}
+	}
+	walk(generic)
+	return out, nil
+}
+
+// ParseJSON decodes JSON text strictly, as RFC 8785 requires of its input
+// (I-JSON, RFC 7493): the text must be valid UTF-8, no object may repeat a
+// key, no string may hold an unpaired UTF-16 surrogate escape, and every
+// number must fit a float64. Numbers are returned as json.Number. Records are
+// checked with it on ingest, so a signer and a verifier can never read
+// different values out of the same bytes.
+func ParseJSON(data []byte) (any, error) {
+	if !utf8.Valid(data) {
+		return nil, fmt.Errorf("canonicalize: invalid UTF-8")
+	}
+	if !json.Valid(data) {
+		return nil, fmt.Errorf("canonicalize: invalid JSON")
+	}
+	if err := checkSurrogates(data); err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return parseValue(dec)
+}
+
+func parseValue(dec *json.Decoder) (any, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch t := tok.(type) {
+	case json.Delim:
+		if t == '[' {
+			arr := []any{}
+			for dec.More() {
+				item, err := parseValue(dec)
+				if err != nil {
+					return nil, err
+				}
+				arr = append(arr, item)
+			}
+			_, err := dec.Token() // ']'
+			return arr, err
+		}
+		m := map[string]any{}
+		for dec.More() {
+			kt, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			k := kt.(string)
+			if _, dup := m[k]; dup {
+				return nil, fmt.Errorf("canonicalize: duplicate key %q", k)
+			}
+			if m[k], err = parseValue(dec); err != nil {
+				return nil, err
+			}
+		}
+		_, err := dec.Token() // '}'
+		return m, err
+	case json.Number:
+		if _, err := numberFloat(t); err != nil {
+			return nil, err
+		}
+		return t, nil
+	default:
+		return t, nil
+	}
+}
+
+// checkSurrogates rejects \uXXXX escapes that encode half of a UTF-16
+// surrogate pair on its own; decoding would quietly turn them into U+FFFD.
+// data must already be valid JSON.
+func checkSurrogates(data []byte) error {
+	inString := false
+	for i := 0; i < len(data); i++ {
+		switch c := data[i]; {
+		case !inString:
+			inString = c == '"'
+		case c == '"':
+			inString = false
+		case c == '\\':
+			if data[i+1] != 'u' {
+				i++
+				continue
+			}
+			r := hexRune(data[i+2 : i+6])
+			i += 5
+			switch {
+			case utf16.IsSurrogate(r) && r < 0xdc00:
+				if i+6 < len(data) && data[i+1] == '\\' && data[i+2] == 'u' {
+					if lo := hexRune(data[i+3 : i+7]); lo >= 0xdc00 && lo <= 0xdfff {
+						i += 6
+						continue
+					}
+				}
+				return fmt.Errorf("canonicalize: lone surrogate \\u%04x", r)
+			case utf16.IsSurrogate(r):
+				return fmt.Errorf("canonicalize: lone surrogate \\u%04x", r)
+			}
+		}
+	}
+	return nil
+}
+
+func hexRune(h []byte) rune {
+	n, _ := strconv.ParseUint(string(h), 16, 16)
+	return rune(n)
+}
+
+// numberFloat reads a JSON number as the float64 every RFC 8785 implementation
+// sees, rejecting numbers outside its range.
+func numberFloat(n json.Number) (float64, error) {
+	f, err := strconv.ParseFloat(n.String(), 64)
+	if err != nil || math.IsInf(f, 0) {
+		return 0, fmt.Errorf("canonicalize: number %s out of range", n)
+	}
+	return f, nil
+}
+
+// formatNumber serializes f as ECMAScript's Number.prototype.toString does,
+// which RFC 8785 adopts: the shortest digits that round-trip, in plain notation
+// from 1e-6 up to 1e21 and exponent notation (1e+21, 1.5e-7) outside it.
+func formatNumber(f float64) (string, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return "", fmt.Errorf("canonicalize: %v is not a JSON number", f)
+	}
+	if f == 0 {
+		return "0", nil // also -0
+	}
+	sign := ""
+	if f < 0 {
+		sign, f = "-", -f
+	}
+	// Shortest round-trip digits d1.d2d3…e±x; value = 0.digits × 10^n.
+	mant, exp, _ := strings.Cut(strconv.FormatFloat(f, 'e', -1, 64), "e")
+	digits := strings.Replace(mant, ".", "", 1)
+	x, _ := strconv.Atoi(exp)
+	n, k := x+1, len(digits)
+	switch {
+	case k <= n && n <= 21:
+		return sign + digits + strings.Repeat("0", n-k), nil
+	case 0 < n && n <= 21:
+		return sign + digits[:n] + "." + digits[n:], nil
+	case -6 < n && n <= 0:
+		return sign + "0." + strings.Repeat("0", -n) + digits, nil
+	}
+	out := digits[:1]
+	if k > 1 {
+		out += "." + digits[1:]
+	}
+	if n-1 >= 0 {
+		return sign + out + "e+" + strconv.Itoa(n-1), nil
+	}
+	return sign + out + "e" + strconv.Itoa(n-1), nil
+}
+
+// lessUTF16 orders object keys as RFC 8785 does: by their UTF-16 code units,
+// which differs from byte order once keys mix U+E000–U+FFFF with characters
+// outside the BMP.
+func lessUTF16(a, b string) bool {
+	return slices.Compare(utf16.Encode([]rune(a)), utf16.Encode([]rune(b))) < 0
+}
+
 func writeCanonical(buf *bytes.Buffer, v any) error {
 	switch val := v.(type) {
 	case map[string]any:
@@ -64,7 +275,7 @@ func writeCanonical(buf *bytes.Buffer, v any) error {
 		for k := range val {
 			keys = append(keys, k)
 		}
-		sort.Strings(keys)
+		sort.Slice(keys, func(i, j int) bool { return lessUTF16(keys[i], keys[j]) })
 		buf.WriteByte('{')
 		for i, k := range keys {
 			if i > 0 {
@@ -91,7 +302,11 @@ func writeCanonical(buf *bytes.Buffer, v any) error {
 	case string:
 		writeJSONString(buf, val)
 	case json.Number:
-		buf.WriteString(val.String())
+		f, err := numberFloat(val)
+		if err != nil {
+			return err
+		}
+		return writeCanonical(buf, f)
 	case bool:
 		if val {
 			buf.WriteString("true")
@@ -101,8 +316,11 @@ func writeCanonical(buf *bytes.Buffer, v any) error {
 	case nil:
 		buf.WriteString("null")
 	case float64:
-		// Only reached if UseNumber was bypassed; keep it deterministic.
-		buf.WriteString(fmt.Sprintf("%v", val))
+		num, err := formatNumber(val)
+		if err != nil {
+			return err
+		}
+		buf.WriteString(num)
 	default:
 		return fmt.Errorf("canonicalize: unsupported type %T", v)
 	}
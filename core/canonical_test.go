@@ -0,0 +1,87 @@
+package core
+
+import (
+	"math"
+	"testing"
+)
+
+// The IEEE 754 samples of RFC 8785 Appendix B, which pin the ECMAScript number
+// serialization independently of the generated conformance vectors.
+func TestFormatNumber(t *testing.T) {
+	for bits, want := range map[uint64]string{
+		0x0000000000000000: "0",
+		0x8000000000000000: "0",
+		0x0000000000000001: "5e-324",
+		0x8000000000000001: "-5e-324",
+		0x7fefffffffffffff: "1.7976931348623157e+308",
+		0xffefffffffffffff: "-1.7976931348623157e+308",
+		0x4340000000000000: "9007199254740992",
+		0xc340000000000000: "-9007199254740992",
+		0x4430000000000000: "295147905179352830000",
+		0x44b52d02c7e14af5: "9.999999999999997e+22",
+		0x44b52d02c7e14af6: "1e+23",
+		0x44b52d02c7e14af7: "1.0000000000000001e+23",
+		0x444b1ae4d6e2ef4e: "999999999999999700000",
+		0x444b1ae4d6e2ef4f: "999999999999999900000",
+		0x444b1ae4d6e2ef50: "1e+21",
+		0x3eb0c6f7a0b5ed8c: "9.999999999999997e-7",
+		0x3eb0c6f7a0b5ed8d: "0.000001",
+		0x41b3de4355555553: "333333333.3333332",
+		0x41b3de4355555554: "333333333.33333325",
+		0x41b3de4355555555: "333333333.3333333",
+		0x41b3de4355555556: "333333333.3333334",
+		0x41b3de4355555557: "333333333.33333343",
+		0xbecbf647612f3696: "-0.0000033333333333333333",
+		0x43143ff3c1cb0959: "1424953923781206.2",
+	} {
+		got, err := formatNumber(math.Float64frombits(bits))
+		if err != nil || got != want {
+			t.Errorf("%016x: got %q (%v), want %q", bits, got, err, want)
+		}
+	}
+	for _, f := range []float64{math.NaN(), math.Inf(1), math.Inf(-1)} {
+		if _, err := formatNumber(f); err == nil {
+			t.Errorf("%v should be rejected", f)
+		}
+	}
+}
+
+func TestCanonicalizeJSON(t *testing.T) {
+	for in, want := range map[string]string{
+		`[1, 1.0, 1e0, 10E-1, -0, 2.50]`:                                  `[1,1,1,1,0,2.5]`,
+		"{\"b\":{\"\ufb33\":1,\"\U0001f600\":2},\"a\":\"\u20ac\\u000f\"}": "{\"a\":\"\u20ac\\u000f\",\"b\":{\"\U0001f600\":2,\"\ufb33\":1}}",
+	} {
+		got, err := CanonicalizeJSON([]byte(in))
+		if err != nil || string(got) != want {
+			t.Errorf("%s: got %s (%v), want %s", in, got, err, want)
+		}
+	}
+	for name, in := range map[string]string{
+		"duplicate key":       `{"a":1,"b":{"c":1,"c":2}}`,
+		"lone high surrogate": `["\ud83d"]`,
+		"lone low surrogate":  `{"k\ude00":1}`,
+		"reversed pair":       `"\ude00\ud83d"`,
+		"number out of range": `[1e400]`,
+		"invalid UTF-8":       "\"\xff\"",
+		"trailing data":       `{} {}`,
+	} {
+		if _, err := CanonicalizeJSON([]byte(in)); err == nil {
+			t.Errorf("%s: %s should be rejected", name, in)
+		}
+	}
+}
+
+// Records in the pre-RFC 8785 form are flagged exactly where their canonical
+// form changed: numbers not in ECMAScript form and keys UTF-16 sorts apart.
+func TestLegacyCanonicalDiffs(t *testing.T) {
+	diffs, err := LegacyCanonicalDiffs([]byte(`{"a":1,"b":[1E3,2.50,9007199254740993],"c":{"ﬁ":0,"😀":0},"d":"x"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diffs) != 4 {
+		t.Fatalf("want the three numbers and the key order flagged, got %q", diffs)
+	}
+	if diffs, _ := LegacyCanonicalDiffs([]byte(`{"spec":"moltnet/card/v0.1","n":10,"f":0.5,"e":1e+21}`)); diffs != nil {
+		t.Fatalf("a record already in canonical form was flagged: %q", diffs)
+	}
+}
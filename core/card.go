@@ -1,7 +1,7 @@
 package core
 
 import (
-	"crypto/ed25519"
+	"crypto"
 	"fmt"
 	"time"
 )
@@ -27,24 +27,33 @@ type Liveness struct {
 // Card is the canonical Agent Card: one JSON document describing an agent's
 // identity, capabilities, endpoints and protocol bindings. It is content
 // addressed (BLAKE3 of the canonical signing payload) and doubly signed by the
-// agent key and the owner key.
+// agent key and the owner key — or, for a team-owned agent, by the agent key and
+// a threshold of the owner policy's members.
+//
+// An owner may also be a did:web, naming the domain that stands behind the
+// agent. The card then names in owner_key the did:key that made owner_sig;
+// that the domain's DID document lists the key is checked apart from Verify,
+// with a DIDWebResolver, so a card still verifies offline.
 type Card struct {
-	Spec         string                 `json:"spec"`
-	ID           string                 `json:"id"`    // did:key of the agent
-	Name         string                 `json:"name"`
-	Owner        string                 `json:"owner"` // did:key of the owner
-	Description  string                 `json:"description,omitempty"`
-	Version      string                 `json:"version,omitempty"`
-	Prev         string                 `json:"prev,omitempty"` // hash of the previous card version ("" for genesis)
-	Capabilities []Capability           `json:"capabilities,omitempty"`
-	Protocols    map[string]any         `json:"protocols,omitempty"`
-	Anchors      map[string]any         `json:"anchors,omitempty"`
-	Links        map[string]string      `json:"links,omitempty"`
-	PricingHint  map[string]any         `json:"pricing_hint,omitempty"`
-	Liveness     *Liveness              `json:"liveness,omitempty"`
-	CreatedAt    string                 `json:"created_at"`
-	Sig          string                 `json:"sig,omitempty"`       // agent key signature
-	OwnerSig     string                 `json:"owner_sig,omitempty"` // owner key signature
+	Spec         string            `json:"spec"`
+	ID           string            `json:"id"` // did:key of the agent
+	Name         string            `json:"name"`
+	Owner        string            `json:"owner"`                  // did:key of the owner, an owner policy DID, or a did:web
+	OwnerPolicy  *OwnerPolicy      `json:"owner_policy,omitempty"` // required iff Owner is a policy DID
+	OwnerKey     string            `json:"owner_key,omitempty"`    // required iff Owner is a did:web: the listed key that signs
+	Description  string            `json:"description,omitempty"`
+	Version      string            `json:"version,omitempty"`
+	Prev         string            `json:"prev,omitempty"` // hash of the previous card version ("" for genesis)
+	Capabilities []Capability      `json:"capabilities,omitempty"`
+	Protocols    map[string]any    `json:"protocols,omitempty"`
+	Anchors      map[string]any    `json:"anchors,omitempty"`
+	Links        map[string]string `json:"links,omitempty"`
+	PricingHint  map[string]any    `json:"pricing_hint,omitempty"`
+	Liveness     *Liveness         `json:"liveness,omitempty"`
+	CreatedAt    string            `json:"created_at"`
+	Sig          string            `json:"sig,omitempty"`        // agent key signature
+	OwnerSig     string            `json:"owner_sig,omitempty"`  // owner key signature
+	OwnerSigs    []OwnerSig        `json:"owner_sigs,omitempty"` // policy member signatures
 }
 
 // NewCard builds an unsigned card with the spec tag and creation timestamp set.
@@ -58,10 +67,10 @@ func NewCard(agentDID, ownerDID, name string) *Card {
 	}
 }
 
-// SigningPayload is the canonical card with signature fields removed. Both the
-// agent and owner signatures are computed over this payload.
+// SigningPayload is the canonical card with signature fields removed. The agent,
+// owner and policy member signatures are all computed over this payload.
 func (c *Card) SigningPayload() ([]byte, error) {
-	return CanonicalizeWithout(c, "sig", "owner_sig")
+	return CanonicalizeWithout(c, "sig", "owner_sig", "owner_sigs")
 }
 
 // Hash returns the content address of the card: BLAKE3 of the signing payload.
@@ -75,17 +84,43 @@ func (c *Card) Hash() (string, error) {
 }
 
 // Sign fills in the agent and owner signatures.
-func (c *Card) Sign(agentKey, ownerKey ed25519.PrivateKey) error {
+func (c *Card) Sign(agentKey, ownerKey crypto.Signer) error {
 	payload, err := c.SigningPayload()
 	if err != nil {
 		return err
 	}
-	c.Sig = Sign(agentKey, payload)
-	c.OwnerSig = Sign(ownerKey, payload)
-	return nil
+	if c.Sig, err = Sign(agentKey, payload); err != nil {
+		return err
+	}
+	c.OwnerSig, err = Sign(ownerKey, payload)
+	return err
+}
+
+// SignAgent fills in the agent signature alone, for a policy-owned card whose
+// members then sign one by one with AddOwnerSig.
+func (c *Card) SignAgent(agentKey crypto.Signer) error {
+	payload, err := c.SigningPayload()
+	if err != nil {
+		return err
+	}
+	c.Sig, err = Sign(agentKey, payload)
+	return err
 }
 
-// Verify checks structural invariants and both signatures.
+// AddOwnerSig adds member's signature toward the card's owner policy,
+// replacing any it made before. It does not check membership; Verify does.
+func (c *Card) AddOwnerSig(member *KeyPair) error {
+	payload, err := c.SigningPayload()
+	if err != nil {
+		return err
+	}
+	c.OwnerSigs, err = addOwnerSig(c.OwnerSigs, member, payload)
+	return err
+}
+
+// Verify checks structural invariants, the agent signature and the owner's
+// authorization: the owner key's signature, or a threshold of the owner
+// policy's members.
 func (c *Card) Verify() error {
 	if c.Spec != CardSpec {
 		return fmt.Errorf("card: unexpected spec %q", c.Spec)
@@ -96,9 +131,6 @@ func (c *Card) Verify() error {
 	if c.Sig == "" {
 		return fmt.Errorf("card: missing agent signature")
 	}
-	if c.OwnerSig == "" {
-		return fmt.Errorf("card: missing owner signature")
-	}
 	payload, err := c.SigningPayload()
 	if err != nil {
 		return err
@@ -106,8 +138,20 @@ func (c *Card) Verify() error {
 	if err := Verify(c.ID, payload, c.Sig); err != nil {
 		return fmt.Errorf("card: agent signature invalid: %w", err)
 	}
-	if err := Verify(c.Owner, payload, c.OwnerSig); err != nil {
-		return fmt.Errorf("card: owner signature invalid: %w", err)
+	owner := c.Owner
+	if IsDIDWeb(c.Owner) {
+		if _, err := PublicKeyFromDID(c.OwnerKey); err != nil {
+			return fmt.Errorf("card: a did:web owner signs with an owner_key listed in its DID document: %w", err)
+		}
+		if _, err := DIDWebURL(c.Owner); err != nil {
+			return fmt.Errorf("card: %w", err)
+		}
+		owner = c.OwnerKey
+	} else if c.OwnerKey != "" {
+		return fmt.Errorf("card: owner_key is only for a did:web owner")
+	}
+	if err := verifyOwner(owner, c.OwnerPolicy, c.OwnerSig, c.OwnerSigs, payload); err != nil {
+		return fmt.Errorf("card: %w", err)
 	}
 	return nil
 }
@@ -6,25 +6,39 @@ import (
 )
 
 // VerifyIssuerChain verifies a single issuer's per-issuer hash chain. The input
-// is that issuer's attestations in chain order (oldest first). It checks that:
-//   - every attestation is signed by the stated issuer,
-//   - every attestation is issued by the same issuer,
+// is that issuer's attestations in chain order (oldest first), and the issuer
+// is the one that issued the first of them. It checks that:
+//   - every attestation is validly signed, by each of its issuers,
+//   - every attestation is issued or co-signed by the same issuer,
 //   - the first attestation has an empty Prev,
-//   - each subsequent Prev equals the hash of the preceding attestation.
+//   - each subsequent prev for the issuer equals the hash of the preceding
+//     attestation.
 //
 // This makes it impossible for an issuer to silently retract or reorder its own
-// history: any tampering breaks the chain.
+// history: any tampering breaks the chain. A chain that starts with a record
+// the issuer co-signed needs VerifyChainOf.
 func VerifyIssuerChain(chain []*Attestation) error {
+	if len(chain) == 0 {
+		return nil
+	}
+	return VerifyChainOf(chain[0].Issuer, chain)
+}
+
+// VerifyChainOf is VerifyIssuerChain for the named issuer, whose chain may
+// hold attestations it co-signed as well as those it issued: its prev in
+// each is Prev or its co-issuer entry's (see Attestation.PrevOf).
+func VerifyChainOf(issuer string, chain []*Attestation) error {
 	var prevHash string
 	for i, att := range chain {
-		if att.Issuer != chain[0].Issuer {
-			return fmt.Errorf("chain: attestation %d has issuer %s, expected %s", i, att.Issuer, chain[0].Issuer)
+		prev, ok := att.PrevOf(issuer)
+		if !ok {
+			return fmt.Errorf("chain: attestation %d is not issued by %s", i, issuer)
 		}
 		if err := att.Verify(); err != nil {
 			return fmt.Errorf("chain: attestation %d: %w", i, err)
 		}
-		if att.Prev != prevHash {
-			return fmt.Errorf("chain: attestation %d prev=%q, expected %q", i, att.Prev, prevHash)
+		if prev != prevHash {
+			return fmt.Errorf("chain: attestation %d prev=%q, expected %q", i, prev, prevHash)
 		}
 		h, err := att.Hash()
 		if err != nil {
@@ -36,11 +50,14 @@ func VerifyIssuerChain(chain []*Attestation) error {
 }
 
 // GroupByIssuer partitions attestations by issuer DID, preserving input order
-// within each group.
+// within each group. A co-signed attestation is in the group of each of its
+// issuers, since it is on each of their chains.
 func GroupByIssuer(atts []*Attestation) map[string][]*Attestation {
 	out := make(map[string][]*Attestation)
 	for _, a := range atts {
-		out[a.Issuer] = append(out[a.Issuer], a)
+		for _, issuer := range a.Issuers() {
+			out[issuer] = append(out[issuer], a)
+		}
 	}
 	return out
 }
@@ -48,14 +65,65 @@ func GroupByIssuer(atts []*Attestation) map[string][]*Attestation {
 // VerifyAll verifies the chains of every issuer present in atts. Attestations
 // are grouped by issuer and, within each group, sorted by issued_at before the
 // chain link check so callers can pass an unordered set.
+//
+// An issuer that signed two attestations following the same prev fails with
+// an error naming them (see Equivocation). A co-signed attestation is checked
+// on the chain of each of its issuers. A delegated attestation fails: there
+// is no delegation to cover it (see VerifyAllWith).
 func VerifyAll(atts []*Attestation) error {
+	return VerifyAllWith(atts, VerifyOptions{})
+}
+
+// VerifyOptions are the records VerifyAllWith checks attestations against
+// besides their own signatures and chains. The zero value holds none.
+type VerifyOptions struct {
+	// Revocations distrust what a compromised key signed at or after its
+	// cutoff. Each one's signature is checked; that its owner owns the
+	// revoked agent is the caller's to establish.
+	Revocations []*Revocation
+	// Delegations cover delegated attestations (see Delegation.Authorizes).
+	// As with revocations, that each one's owner owns its principal is the
+	// caller's to establish.
+	Delegations []*Delegation
+}
+
+// VerifyAllWith is VerifyAll under opts. Attestations that opts.Revocations
+// distrust are dropped first: they are the tail of that issuer's chain, so
+// what the key signed before the compromise still verifies. A delegated
+// attestation must be covered by one of opts.Delegations; it is verified on
+// its delegate's chain.
+func VerifyAllWith(atts []*Attestation, opts VerifyOptions) error {
+	for _, r := range opts.Revocations {
+		if err := r.Verify(); err != nil {
+			return err
+		}
+	}
+	for _, d := range opts.Delegations {
+		if err := d.Verify(); err != nil {
+			return err
+		}
+	}
+	atts = RevocationCutoffs(opts.Revocations).Filter(atts)
+	ds := NewDelegations(opts.Delegations)
+	for _, a := range atts {
+		if err := ds.Check(a); err != nil {
+			h, _ := a.Hash()
+			return fmt.Errorf("attestation %s: %w", h, err)
+		}
+	}
 	for issuer, group := range GroupByIssuer(atts) {
+		// A forked chain cannot verify; name the fork rather than whichever
+		// link happens to break first.
+		if eqs := findEquivocations(issuer, group); len(eqs) > 0 {
+			ha, hb := eqs[0].Hashes()
+			return fmt.Errorf("issuer %s equivocated: %s and %s both follow %q", issuer, ha, hb, eqs[0].Prev)
+		}
 		sorted := make([]*Attestation, len(group))
 		copy(sorted, group)
 		sort.SliceStable(sorted, func(i, j int) bool {
 			return sorted[i].IssuedAt < sorted[j].IssuedAt
 		})
-		if err := VerifyIssuerChain(sorted); err != nil {
+		if err := VerifyChainOf(issuer, sorted); err != nil {
 			return fmt.Errorf("issuer %s: %w", issuer, err)
 		}
 	}
@@ -15,8 +15,11 @@ func TestCanonicalConformance(t *testing.T) {
 		t.Fatal(err)
 	}
 	var vectors []struct {
-		Input    any    `json:"input"`
-		Expected string `json:"expected"`
+		Name      string          `json:"name"`
+		Input     json.RawMessage `json:"input"`
+		InputText string          `json:"input_text"`
+		Expected  string          `json:"expected"`
+		Error     bool            `json:"error"`
 	}
 	if err := json.Unmarshal(data, &vectors); err != nil {
 		t.Fatal(err)
@@ -25,12 +28,104 @@ func TestCanonicalConformance(t *testing.T) {
 		t.Fatal("no canonical vectors loaded")
 	}
 	for i, v := range vectors {
-		got, err := Canonicalize(v.Input)
+		if v.Error {
+			if _, err := CanonicalizeJSON([]byte(v.InputText)); err == nil {
+				t.Errorf("vector %d (%s): should be rejected", i, v.Name)
+			}
+			continue
+		}
+		got, err := CanonicalizeJSON(v.Input)
 		if err != nil {
-			t.Fatalf("vector %d: %v", i, err)
+			t.Fatalf("vector %d (%s): %v", i, v.Name, err)
 		}
 		if string(got) != v.Expected {
 			t.Errorf("vector %d:\n got  %s\n want %s", i, got, v.Expected)
 		}
 	}
 }
+
+// TestOwnerPolicyConformance pins the M-of-N owner shape: the policy DID, the
+// bytes members sign, and which records meet the threshold.
+func TestOwnerPolicyConformance(t *testing.T) {
+	data, err := os.ReadFile("../spec/conformance/owner_policy_vectors.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var vectors []struct {
+		Name           string          `json:"name"`
+		Policy         OwnerPolicy     `json:"policy"`
+		PolicyDID      string          `json:"policy_did"`
+		Kind           string          `json:"kind"`
+		Record         json.RawMessage `json:"record"`
+		SigningPayload string          `json:"signing_payload"`
+		Valid          bool            `json:"valid"`
+	}
+	if err := json.Unmarshal(data, &vectors); err != nil {
+		t.Fatal(err)
+	}
+	if len(vectors) == 0 {
+		t.Fatal("no owner policy vectors loaded")
+	}
+	for _, v := range vectors {
+		if did, err := v.Policy.DID(); err != nil || did != v.PolicyDID {
+			t.Errorf("%s: policy DID %s, want %s", v.Name, did, v.PolicyDID)
+		}
+		var rec interface {
+			SigningPayload() ([]byte, error)
+			Verify() error
+		}
+		switch v.Kind {
+		case "card":
+			rec = &Card{}
+		case "rotation":
+			rec = &Rotation{}
+		default:
+			t.Fatalf("%s: unknown kind %q", v.Name, v.Kind)
+		}
+		if err := json.Unmarshal(v.Record, rec); err != nil {
+			t.Fatal(err)
+		}
+		if got, _ := rec.SigningPayload(); string(got) != v.SigningPayload {
+			t.Errorf("%s: signing payload\n got  %s\n want %s", v.Name, got, v.SigningPayload)
+		}
+		if err := rec.Verify(); (err == nil) != v.Valid {
+			t.Errorf("%s: verify = %v, want valid=%v", v.Name, err, v.Valid)
+		}
+	}
+}
+
+// TestKeyConformance pins the did:key types: how each DID decodes, and which
+// signatures verify under it.
+func TestKeyConformance(t *testing.T) {
+	data, err := os.ReadFile("../spec/conformance/key_vectors.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var vectors []struct {
+		Name      string  `json:"name"`
+		KeyType   KeyType `json:"key_type"`
+		DID       string  `json:"did"`
+		PublicKey string  `json:"public_key"`
+		Message   string  `json:"message"`
+		Sig       string  `json:"sig"`
+		Valid     bool    `json:"valid"`
+	}
+	if err := json.Unmarshal(data, &vectors); err != nil {
+		t.Fatal(err)
+	}
+	if len(vectors) == 0 {
+		t.Fatal("no key vectors loaded")
+	}
+	for _, v := range vectors {
+		typ, pub, err := ParseDIDKey(v.DID)
+		if err != nil {
+			t.Fatalf("%s: %v", v.Name, err)
+		}
+		if typ != v.KeyType || PublicKeyHex(pub) != v.PublicKey {
+			t.Errorf("%s: decoded %s key %s, want %s %s", v.Name, typ, PublicKeyHex(pub), v.KeyType, v.PublicKey)
+		}
+		if err := Verify(v.DID, []byte(v.Message), v.Sig); (err == nil) != v.Valid {
+			t.Errorf("%s: verify = %v, want valid=%v", v.Name, err, v.Valid)
+		}
+	}
+}
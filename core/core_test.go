@@ -1,18 +1,91 @@
 package core
 
-import "testing"
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+)
 
 func TestDIDRoundTrip(t *testing.T) {
-	kp, err := GenerateKeyPair()
-	if err != nil {
+	for _, typ := range []KeyType{KeyTypeEd25519, KeyTypeP256, KeyTypeSecp256k1} {
+		kp, err := GenerateKeyPairOf(typ)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, pub, err := ParseDIDKey(kp.DID)
+		if err != nil {
+			t.Fatalf("%s: decode did: %v", typ, err)
+		}
+		if got != typ || PublicKeyHex(pub) != PublicKeyHex(kp.Public) {
+			t.Fatalf("%s: recovered %s key does not match", typ, got)
+		}
+		privHex, err := PrivateKeyHex(kp.Private)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if back, err := KeyPairFromHexOf(typ, privHex); err != nil || back.DID != kp.DID {
+			t.Fatalf("%s: keyfile round trip: %v", typ, err)
+		}
+	}
+}
+
+// ECDSA signatures are made and accepted with s in the lower half of the group
+// order only, so a signed record has one encoding.
+func TestLowS(t *testing.T) {
+	for _, typ := range []KeyType{KeyTypeP256, KeyTypeSecp256k1} {
+		kp, _ := GenerateKeyPairOf(typ)
+		n := curveOrder(typ)
+		for i := range 16 {
+			msg := fmt.Appendf(nil, "message %d", i)
+			sig, err := Sign(kp.Private, msg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			raw, _ := hex.DecodeString(sig)
+			s := new(big.Int).SetBytes(raw[32:])
+			if s.Cmp(new(big.Int).Rsh(n, 1)) > 0 {
+				t.Fatalf("%s: Sign produced a high s", typ)
+			}
+			if err := Verify(kp.DID, msg, sig); err != nil {
+				t.Fatalf("%s: %v", typ, err)
+			}
+			s.Sub(n, s).FillBytes(raw[32:])
+			if Verify(kp.DID, msg, hex.EncodeToString(raw)) == nil {
+				t.Fatalf("%s: high-s signature verified", typ)
+			}
+		}
+	}
+}
+
+// Cards and attestations verify whatever key types their signers use.
+func TestMixedKeyTypes(t *testing.T) {
+	owner, _ := GenerateKeyPairOf(KeyTypeP256)
+	agent, _ := GenerateKeyPairOf(KeyTypeSecp256k1)
+	subject, _ := GenerateKeyPair()
+	c := NewCard(agent.DID, owner.DID, "kms-agent")
+	if err := c.Sign(agent.Private, owner.Private); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Verify(); err != nil {
+		t.Fatalf("card signed with EC keys: %v", err)
+	}
+	a := NewAttestation(TypeTaskCompleted, agent.DID, subject.DID)
+	if err := a.Sign(agent.Private); err != nil {
 		t.Fatal(err)
 	}
-	pub, err := PublicKeyFromDID(kp.DID)
-	if err != nil {
-		t.Fatalf("decode did: %v", err)
+	if err := a.Verify(); err != nil {
+		t.Fatalf("secp256k1 attestation: %v", err)
+	}
+	a.Body = map[string]any{"outcome": "forged"}
+	if a.Verify() == nil {
+		t.Fatal("tampered secp256k1 attestation should fail")
 	}
-	if string(pub) != string(kp.Public) {
-		t.Fatal("recovered public key does not match")
+	// A signature by one key type does not verify under another DID.
+	payload, _ := c.SigningPayload()
+	if Verify(owner.DID, payload, c.Sig) == nil {
+		t.Fatal("agent's signature verified under the owner's DID")
 	}
 }
 
@@ -65,3 +138,58 @@ func TestAttestationChain(t *testing.T) {
 		t.Fatal("expected broken chain to be rejected")
 	}
 }
+
+// A validity window is signed with the record, must close after it opens, and
+// places any instant before, in or after it.
+func TestValidityWindow(t *testing.T) {
+	issuer, _ := GenerateKeyPair()
+	subject, _ := GenerateKeyPair()
+	a := NewAttestation(TypeEndorsement, issuer.DID, subject.DID)
+	a.IssuedAt = "2026-07-01T00:00:00Z"
+	a.NotBefore = "2026-07-02T00:00:00Z"
+	a.ExpiresAt = "2026-09-30T00:00:00Z"
+	if err := a.Sign(issuer.Private); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Verify(); err != nil {
+		t.Fatalf("valid window rejected: %v", err)
+	}
+	for at, want := range map[string]string{
+		"2026-07-01T12:00:00Z": WindowNotYetValid,
+		"2026-07-02T00:00:00Z": WindowInEffect,
+		"2026-09-29T23:59:59Z": WindowInEffect,
+		"2026-09-30T00:00:00Z": WindowExpired,
+	} {
+		tm, _ := time.Parse(time.RFC3339, at)
+		if got := a.Window(tm); got != want {
+			t.Errorf("%s: got %s, want %s", at, got, want)
+		}
+	}
+	plain := NewAttestation(TypeTaskCompleted, issuer.DID, subject.DID)
+	late, _ := time.Parse(time.RFC3339, "2026-10-01T00:00:00Z")
+	if got := InEffect([]*Attestation{a, plain}, late); len(got) != 1 || got[0] != plain {
+		t.Fatalf("only the unbounded record should be in effect, got %d", len(got))
+	}
+
+	tampered := *a
+	tampered.ExpiresAt = "2027-09-30T00:00:00Z"
+	if tampered.Verify() == nil {
+		t.Fatal("the window is signed: extending it must break the signature")
+	}
+	for name, mutate := range map[string]func(*Attestation){
+		"closes before it opens":  func(b *Attestation) { b.ExpiresAt = "2026-07-01T12:00:00Z" },
+		"expires before issuance": func(b *Attestation) { b.NotBefore = ""; b.ExpiresAt = "2026-06-30T00:00:00Z" },
+		"unparseable bound":       func(b *Attestation) { b.NotBefore = "next tuesday" },
+		"windowed retraction": func(b *Attestation) {
+			b.Type = TypeRetraction
+			b.Body = map[string]any{"retracts": "blake3:00"}
+		},
+	} {
+		b := *a
+		mutate(&b)
+		_ = b.Sign(issuer.Private)
+		if b.Verify() == nil {
+			t.Errorf("%s: should be rejected", name)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package core
+
+import (
+	"fmt"
+	"slices"
+)
+
+// A co-signed attestation is one statement made jointly by several issuers —
+// a swarm that delivered a task, a panel that reviewed one. Issuer leads and
+// CoIssuers follow, in order; every issuer signs the same payload, the lead
+// in Sig and the others in CoSigs. The record chains into each issuer's own
+// chain: Prev is the lead's previous attestation, and each CoIssuer names its
+// own. Scores count it once, with the issuers' weight combined.
+//
+// A co-signed attestation cannot be delegated: each co-issuer signs as
+// itself.
+
+// CoIssuer is one further issuer of a co-signed attestation, with the hash of
+// its own previous attestation.
+type CoIssuer struct {
+	Issuer string `json:"issuer"`
+	Prev   string `json:"prev,omitempty"`
+}
+
+// CoSig is a co-issuer's signature over the attestation's signing payload.
+type CoSig struct {
+	Signer string `json:"signer"` // co-issuer DID
+	Sig    string `json:"sig"`
+}
+
+// Issuers returns every issuer of a, in order: Issuer, then its co-issuers.
+func (a *Attestation) Issuers() []string {
+	out := make([]string, 0, 1+len(a.CoIssuers))
+	out = append(out, a.Issuer)
+	for _, c := range a.CoIssuers {
+		out = append(out, c.Issuer)
+	}
+	return out
+}
+
+// Principals returns the identities a counts for: its principal, or for a
+// co-signed attestation every issuer, in order.
+func (a *Attestation) Principals() []string {
+	if len(a.CoIssuers) == 0 {
+		return []string{a.Principal()}
+	}
+	return a.Issuers()
+}
+
+// PrevOf returns the link a follows on issuer's chain, and whether issuer is
+// one of a's issuers.
+func (a *Attestation) PrevOf(issuer string) (string, bool) {
+	if issuer == a.Issuer {
+		return a.Prev, true
+	}
+	for _, c := range a.CoIssuers {
+		if c.Issuer == issuer {
+			return c.Prev, true
+		}
+	}
+	return "", false
+}
+
+// AddCoSig adds co's signature as one of a's co-issuers, replacing any it made
+// before. Sign the lead's signature too: both cover the same payload.
+func (a *Attestation) AddCoSig(co *KeyPair) error {
+	if _, ok := a.PrevOf(co.DID); !ok || co.DID == a.Issuer {
+		return fmt.Errorf("attestation: %s is not a co-issuer", co.DID)
+	}
+	payload, err := a.SigningPayload()
+	if err != nil {
+		return err
+	}
+	sig, err := Sign(co.Private, payload)
+	if err != nil {
+		return err
+	}
+	for i, s := range a.CoSigs {
+		if s.Signer == co.DID {
+			a.CoSigs[i].Sig = sig
+			return nil
+		}
+	}
+	a.CoSigs = append(a.CoSigs, CoSig{Signer: co.DID, Sig: sig})
+	return nil
+}
+
+// MissingCoSigs returns the co-issuers that have not yet validly signed a, in
+// order.
+func (a *Attestation) MissingCoSigs() []string {
+	payload, err := a.SigningPayload()
+	if err != nil {
+		return nil
+	}
+	return a.missingCoSigs(payload)
+}
+
+func (a *Attestation) missingCoSigs(payload []byte) []string {
+	var out []string
+	for _, c := range a.CoIssuers {
+		i := slices.IndexFunc(a.CoSigs, func(s CoSig) bool { return s.Signer == c.Issuer })
+		if i < 0 || Verify(c.Issuer, payload, a.CoSigs[i].Sig) != nil {
+			out = append(out, c.Issuer)
+		}
+	}
+	return out
+}
+
+// verifyCoSigs checks the co-issuer list and that each co-issuer, and only
+// they, signed payload.
+func (a *Attestation) verifyCoSigs(payload []byte) error {
+	if len(a.CoIssuers) == 0 {
+		if len(a.CoSigs) > 0 {
+			return fmt.Errorf("co_sigs without co_issuers")
+		}
+		return nil
+	}
+	if a.OnBehalfOf != "" {
+		return fmt.Errorf("a co-signed attestation cannot be delegated")
+	}
+	seen := map[string]bool{a.Issuer: true}
+	for _, c := range a.CoIssuers {
+		if c.Issuer == "" || seen[c.Issuer] {
+			return fmt.Errorf("co-issuers must be distinct from each other and from the issuer")
+		}
+		seen[c.Issuer] = true
+	}
+	if len(a.CoSigs) != len(a.CoIssuers) {
+		return fmt.Errorf("%d co-issuer signature(s) for %d co-issuer(s)", len(a.CoSigs), len(a.CoIssuers))
+	}
+	if missing := a.missingCoSigs(payload); len(missing) > 0 {
+		return fmt.Errorf("co-issuer %s has not validly signed", missing[0])
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package core
+
+import "testing"
+
+// A co-signed attestation needs every issuer's signature over one payload, and
+// chains into each issuer's chain.
+func TestCoSignedAttestation(t *testing.T) {
+	lead, _ := GenerateKeyPair()
+	co, _ := GenerateKeyPair()
+	subject, _ := GenerateKeyPair()
+
+	// co already has a chain of its own.
+	prior := NewAttestation(TypeEndorsement, co.DID, subject.DID)
+	prior.IssuedAt = "2026-07-01T00:00:00Z"
+	_ = prior.Sign(co.Private)
+	ph, _ := prior.Hash()
+
+	a := NewAttestation(TypeTaskCompleted, lead.DID, subject.DID)
+	a.IssuedAt = "2026-07-02T00:00:00Z"
+	a.CoIssuers = []CoIssuer{{Issuer: co.DID, Prev: ph}}
+	if err := a.Sign(lead.Private); err != nil {
+		t.Fatal(err)
+	}
+	if a.Verify() == nil {
+		t.Fatal("a co-signed attestation without its co-signature must not verify")
+	}
+	if m := a.MissingCoSigs(); len(m) != 1 || m[0] != co.DID {
+		t.Fatalf("missing co-signatures: %v", m)
+	}
+	if a.AddCoSig(subject) == nil {
+		t.Fatal("only a listed co-issuer may co-sign")
+	}
+	if err := a.AddCoSig(co); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Verify(); err != nil {
+		t.Fatalf("fully signed: %v", err)
+	}
+	ah, _ := a.Hash()
+
+	next := NewAttestation(TypeEndorsement, co.DID, subject.DID)
+	next.IssuedAt = "2026-07-03T00:00:00Z"
+	next.Prev = ah
+	_ = next.Sign(co.Private)
+	all := []*Attestation{prior, a, next}
+	if err := VerifyAll(all); err != nil {
+		t.Fatalf("the record should sit on both chains: %v", err)
+	}
+	if err := VerifyChainOf(co.DID, all); err != nil {
+		t.Fatalf("co-issuer chain: %v", err)
+	}
+	if g := GroupByIssuer(all); len(g[lead.DID]) != 1 || len(g[co.DID]) != 3 {
+		t.Fatalf("groups: %d lead, %d co", len(g[lead.DID]), len(g[co.DID]))
+	}
+
+	// A second successor to prior on co's chain is an equivocation, whether co
+	// leads it or co-signs it.
+	fork := NewAttestation(TypeIncident, co.DID, subject.DID)
+	fork.IssuedAt = "2026-07-02T12:00:00Z"
+	fork.Prev = ph
+	_ = fork.Sign(co.Private)
+	eqs := FindEquivocations([]*Attestation{prior, a, fork})
+	if len(eqs) != 1 || eqs[0].Issuer != co.DID || eqs[0].Prev != ph || eqs[0].Verify() != nil {
+		t.Fatalf("want one proof against the co-issuer's link: %+v", eqs)
+	}
+
+	// Withdrawing it takes every issuer.
+	r := NewAttestation(TypeRetraction, lead.DID, subject.DID)
+	r.Prev = ah
+	r.Body = map[string]any{"retracts": ah}
+	_ = r.Sign(lead.Private)
+	if CheckRetraction(r, a) == nil {
+		t.Fatal("the lead alone must not retract a joint statement")
+	}
+	r.CoIssuers = []CoIssuer{{Issuer: co.DID, Prev: ah}}
+	_ = r.Sign(lead.Private)
+	_ = r.AddCoSig(co)
+	if err := CheckRetraction(r, a); err != nil {
+		t.Fatalf("a retraction by both issuers: %v", err)
+	}
+
+	for name, mutate := range map[string]func(*Attestation){
+		"tampered co-issuer prev": func(b *Attestation) { b.CoIssuers = []CoIssuer{{Issuer: co.DID, Prev: "blake3:00"}} },
+		"lead as co-issuer":       func(b *Attestation) { b.CoIssuers = append(b.CoIssuers, CoIssuer{Issuer: lead.DID}) },
+		"stray co-signature":      func(b *Attestation) { b.CoIssuers = nil },
+		"delegated":               func(b *Attestation) { b.OnBehalfOf, b.Delegation = subject.DID, "blake3:00" },
+	} {
+		b := *a
+		mutate(&b)
+		_ = b.Sign(lead.Private)
+		if b.Verify() == nil {
+			t.Errorf("%s: should be rejected", name)
+		}
+	}
+}
@@ -1,73 +1,260 @@
 package core
 
 import (
+	"crypto"
+	"crypto/ecdsa"
 	"crypto/ed25519"
+	"crypto/elliptic"
 	"crypto/rand"
+	"crypto/sha256"
+	"encoding/asn1"
 	"encoding/hex"
 	"fmt"
+	"io"
+	"math/big"
 	"strings"
 
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	secpecdsa "github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
 	"github.com/mr-tron/base58"
 )
 
-// Multicodec prefix for an Ed25519 public key (0xed) as an unsigned varint,
-// per the did:key specification: [0xed, 0x01].
-var ed25519MulticodecPrefix = []byte{0xed, 0x01}
+// KeyType is the signature algorithm of an identity key. It is written to
+// keyfiles and recovered from a did:key DID's multicodec prefix.
+type KeyType string
 
-// KeyPair is an Ed25519 identity. The public key, encoded as a did:key DID, is
-// the permanent identifier for an agent or owner.
+const (
+	KeyTypeEd25519   KeyType = "ed25519"   // the default; signs the message directly
+	KeyTypeP256      KeyType = "p256"      // ECDSA over NIST P-256 with SHA-256
+	KeyTypeSecp256k1 KeyType = "secp256k1" // ECDSA over secp256k1 with SHA-256
+)
+
+// Multicodec prefixes of the public key types, as unsigned varints, per the
+// did:key specification. EC keys follow the prefix in 33-byte compressed form.
+var multicodecPrefixes = map[KeyType][]byte{
+	KeyTypeEd25519:   {0xed, 0x01}, // ed25519-pub 0xed
+	KeyTypeP256:      {0x80, 0x24}, // p256-pub 0x1200
+	KeyTypeSecp256k1: {0xe7, 0x01}, // secp256k1-pub 0xe7
+}
+
+// ParseKeyType accepts a key type name as written in keyfiles and flags; ""
+// means Ed25519.
+func ParseKeyType(s string) (KeyType, error) {
+	if s == "" {
+		return KeyTypeEd25519, nil
+	}
+	if _, ok := multicodecPrefixes[KeyType(s)]; !ok {
+		return "", fmt.Errorf("unknown key type %q (want ed25519, p256 or secp256k1)", s)
+	}
+	return KeyType(s), nil
+}
+
+// KeyPair is an identity of any supported key type. The public key, encoded as
+// a did:key DID, is the permanent identifier for an agent or owner. Private is
+// any crypto.Signer for the key — a local key, or one held in a KMS.
 type KeyPair struct {
 	DID     string
-	Public  ed25519.PublicKey
-	Private ed25519.PrivateKey
+	Type    KeyType
+	Public  crypto.PublicKey
+	Private crypto.Signer
 }
 
 // GenerateKeyPair creates a fresh Ed25519 identity.
 func GenerateKeyPair() (*KeyPair, error) {
-	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	return GenerateKeyPairOf(KeyTypeEd25519)
+}
+
+// GenerateKeyPairOf creates a fresh identity of key type t.
+func GenerateKeyPairOf(t KeyType) (*KeyPair, error) {
+	var priv crypto.Signer
+	switch t {
+	case KeyTypeEd25519:
+		_, k, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		priv = k
+	case KeyTypeP256:
+		k, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		priv = k
+	case KeyTypeSecp256k1:
+		k, err := secp256k1.GeneratePrivateKey()
+		if err != nil {
+			return nil, err
+		}
+		priv = secp256k1Signer{k}
+	default:
+		return nil, fmt.Errorf("unknown key type %q", t)
+	}
+	return KeyPairFromSigner(priv)
+}
+
+// KeyPairFromSigner wraps a signer whose public key is of a supported type,
+// such as a KMS-backed crypto.Signer.
+func KeyPairFromSigner(priv crypto.Signer) (*KeyPair, error) {
+	pub := priv.Public()
+	t, err := keyTypeOf(pub)
+	if err != nil {
+		return nil, err
+	}
+	did, err := DIDFromPublicKey(pub)
 	if err != nil {
 		return nil, err
 	}
-	return &KeyPair{DID: DIDFromPublicKey(pub), Public: pub, Private: priv}, nil
+	return &KeyPair{DID: did, Type: t, Public: pub, Private: priv}, nil
+}
+
+// keyTypeOf names the key type of a public key.
+func keyTypeOf(pub crypto.PublicKey) (KeyType, error) {
+	switch k := pub.(type) {
+	case ed25519.PublicKey:
+		return KeyTypeEd25519, nil
+	case *ecdsa.PublicKey:
+		if k.Curve == elliptic.P256() {
+			return KeyTypeP256, nil
+		}
+	case *secp256k1.PublicKey:
+		return KeyTypeSecp256k1, nil
+	}
+	return "", fmt.Errorf("unsupported public key type %T", pub)
 }
 
-// DIDFromPublicKey encodes an Ed25519 public key as a did:key DID.
-func DIDFromPublicKey(pub ed25519.PublicKey) string {
-	buf := make([]byte, 0, len(ed25519MulticodecPrefix)+len(pub))
-	buf = append(buf, ed25519MulticodecPrefix...)
-	buf = append(buf, pub...)
+// publicKeyBytes is the did:key encoding of a public key, without the
+// multicodec prefix: raw for Ed25519, SEC 1 compressed for EC keys.
+func publicKeyBytes(pub crypto.PublicKey) ([]byte, error) {
+	switch k := pub.(type) {
+	case ed25519.PublicKey:
+		return k, nil
+	case *ecdsa.PublicKey:
+		raw, err := k.Bytes() // 0x04 || X || Y
+		if err != nil {
+			return nil, err
+		}
+		x, y := raw[1:33], raw[33:]
+		return append([]byte{0x02 | y[31]&1}, x...), nil
+	case *secp256k1.PublicKey:
+		return k.SerializeCompressed(), nil
+	}
+	return nil, fmt.Errorf("unsupported public key type %T", pub)
+}
+
+// DIDFromPublicKey encodes a public key as a did:key DID.
+func DIDFromPublicKey(pub crypto.PublicKey) (string, error) {
+	t, err := keyTypeOf(pub)
+	if err != nil {
+		return "", err
+	}
+	raw, err := publicKeyBytes(pub)
+	if err != nil {
+		return "", err
+	}
+	buf := append(append([]byte{}, multicodecPrefixes[t]...), raw...)
 	// Multibase base58btc is prefixed with 'z'.
-	return "did:key:z" + base58.Encode(buf)
+	return "did:key:z" + base58.Encode(buf), nil
 }
 
-// PublicKeyFromDID recovers the Ed25519 public key from a did:key DID.
-func PublicKeyFromDID(did string) (ed25519.PublicKey, error) {
+// ParseDIDKey recovers the key type and public key from a did:key DID.
+func ParseDIDKey(did string) (KeyType, crypto.PublicKey, error) {
 	const prefix = "did:key:z"
 	if !strings.HasPrefix(did, prefix) {
-		return nil, fmt.Errorf("did %q is not a did:key with base58btc encoding", did)
+		return "", nil, fmt.Errorf("did %q is not a did:key with base58btc encoding", did)
 	}
 	decoded, err := base58.Decode(strings.TrimPrefix(did, prefix))
 	if err != nil {
-		return nil, fmt.Errorf("did %q: base58 decode: %w", did, err)
-	}
-	if len(decoded) != len(ed25519MulticodecPrefix)+ed25519.PublicKeySize {
-		return nil, fmt.Errorf("did %q: unexpected key length %d", did, len(decoded))
+		return "", nil, fmt.Errorf("did %q: base58 decode: %w", did, err)
 	}
-	if decoded[0] != ed25519MulticodecPrefix[0] || decoded[1] != ed25519MulticodecPrefix[1] {
-		return nil, fmt.Errorf("did %q: not an ed25519 multicodec key", did)
+	for t, p := range multicodecPrefixes {
+		if len(decoded) < len(p) || string(decoded[:len(p)]) != string(p) {
+			continue
+		}
+		raw := decoded[len(p):]
+		switch t {
+		case KeyTypeEd25519:
+			if len(raw) != ed25519.PublicKeySize {
+				return "", nil, fmt.Errorf("did %q: unexpected key length %d", did, len(decoded))
+			}
+			return t, ed25519.PublicKey(raw), nil
+		case KeyTypeP256:
+			x, y := elliptic.UnmarshalCompressed(elliptic.P256(), raw)
+			if x == nil {
+				return "", nil, fmt.Errorf("did %q: not a compressed P-256 point", did)
+			}
+			point := make([]byte, 65)
+			point[0] = 0x04
+			x.FillBytes(point[1:33])
+			y.FillBytes(point[33:])
+			pub, err := ecdsa.ParseUncompressedPublicKey(elliptic.P256(), point)
+			if err != nil {
+				return "", nil, fmt.Errorf("did %q: %w", did, err)
+			}
+			return t, pub, nil
+		case KeyTypeSecp256k1:
+			if len(raw) != 33 {
+				return "", nil, fmt.Errorf("did %q: secp256k1 keys must be compressed", did)
+			}
+			pub, err := secp256k1.ParsePubKey(raw)
+			if err != nil {
+				return "", nil, fmt.Errorf("did %q: %w", did, err)
+			}
+			return t, pub, nil
+		}
 	}
-	return ed25519.PublicKey(decoded[len(ed25519MulticodecPrefix):]), nil
+	return "", nil, fmt.Errorf("did %q: unsupported multicodec key type", did)
 }
 
-// Sign produces a hex-encoded Ed25519 signature over msg.
-func Sign(priv ed25519.PrivateKey, msg []byte) string {
-	return hex.EncodeToString(ed25519.Sign(priv, msg))
+// PublicKeyFromDID recovers the public key from a did:key DID.
+func PublicKeyFromDID(did string) (crypto.PublicKey, error) {
+	_, pub, err := ParseDIDKey(did)
+	return pub, err
+}
+
+// Sign produces a hex-encoded signature over msg. Ed25519 signs msg itself;
+// ECDSA keys sign its SHA-256 digest, encoded as the 64-byte r || s with s
+// in the lower half of the group order (see Verify).
+func Sign(priv crypto.Signer, msg []byte) (string, error) {
+	t, err := keyTypeOf(priv.Public())
+	if err != nil {
+		return "", err
+	}
+	if t == KeyTypeEd25519 {
+		sig, err := priv.Sign(nil, msg, crypto.Hash(0))
+		if err != nil {
+			return "", err
+		}
+		return hex.EncodeToString(sig), nil
+	}
+	digest := sha256.Sum256(msg)
+	var rnd io.Reader = rand.Reader
+	if _, ok := priv.(*ecdsa.PrivateKey); ok {
+		rnd = nil // deterministic (RFC 6979), like Ed25519
+	}
+	der, err := priv.Sign(rnd, digest[:], crypto.SHA256)
+	if err != nil {
+		return "", err
+	}
+	var rs struct{ R, S *big.Int }
+	if _, err := asn1.Unmarshal(der, &rs); err != nil {
+		return "", fmt.Errorf("signer returned a malformed ECDSA signature: %w", err)
+	}
+	if n := curveOrder(t); rs.S.Cmp(new(big.Int).Rsh(n, 1)) > 0 {
+		rs.S.Sub(n, rs.S)
+	}
+	sig := make([]byte, 64)
+	rs.R.FillBytes(sig[:32])
+	rs.S.FillBytes(sig[32:])
+	return hex.EncodeToString(sig), nil
 }
 
 // Verify checks a hex-encoded signature against a message and the public key
-// recovered from the signer's DID.
+// recovered from the signer's DID, by the DID's key type. An ECDSA signature
+// whose s is in the upper half of the group order is rejected: (r, n − s)
+// verifies whenever (r, s) does, and accepting both would give one signed
+// record two encodings, and so two hashes.
 func Verify(signerDID string, msg []byte, sigHex string) error {
-	pub, err := PublicKeyFromDID(signerDID)
+	t, pub, err := ParseDIDKey(signerDID)
 	if err != nil {
 		return err
 	}
@@ -75,32 +262,108 @@ func Verify(signerDID string, msg []byte, sigHex string) error {
 	if err != nil {
 		return fmt.Errorf("signature is not valid hex: %w", err)
 	}
-	if !ed25519.Verify(pub, msg, sig) {
+	ok := false
+	switch t {
+	case KeyTypeEd25519:
+		ok = ed25519.Verify(pub.(ed25519.PublicKey), msg, sig)
+	case KeyTypeP256:
+		digest := sha256.Sum256(msg)
+		s := new(big.Int).SetBytes(sig[min(len(sig), 32):])
+		ok = len(sig) == 64 && s.Cmp(new(big.Int).Rsh(curveOrder(t), 1)) <= 0 &&
+			ecdsa.Verify(pub.(*ecdsa.PublicKey), digest[:], new(big.Int).SetBytes(sig[:32]), s)
+	case KeyTypeSecp256k1:
+		digest := sha256.Sum256(msg)
+		var r, s secp256k1.ModNScalar
+		ok = len(sig) == 64 && !r.SetByteSlice(sig[:32]) && !s.SetByteSlice(sig[32:]) &&
+			!s.IsOverHalfOrder() && secpecdsa.NewSignature(&r, &s).Verify(digest[:], pub.(*secp256k1.PublicKey))
+	}
+	if !ok {
 		return fmt.Errorf("signature does not verify for %s", signerDID)
 	}
 	return nil
 }
 
-// PrivateKeyHex / PublicKeyHex encode raw key bytes for keyfile storage.
-func PrivateKeyHex(priv ed25519.PrivateKey) string { return hex.EncodeToString(priv) }
-func PublicKeyHex(pub ed25519.PublicKey) string    { return hex.EncodeToString(pub) }
+// curveOrder is the group order n of an ECDSA key type.
+func curveOrder(t KeyType) *big.Int {
+	if t == KeyTypeSecp256k1 {
+		return secp256k1.S256().Params().N
+	}
+	return elliptic.P256().Params().N
+}
+
+// secp256k1Signer adapts a secp256k1 key to crypto.Signer. Like
+// *ecdsa.PrivateKey it signs a digest and returns an ASN.1 DER signature;
+// nonces are deterministic (RFC 6979) and S is canonical (low).
+type secp256k1Signer struct{ key *secp256k1.PrivateKey }
+
+func (s secp256k1Signer) Public() crypto.PublicKey { return s.key.PubKey() }
 
-// KeyPairFromHex reconstructs a KeyPair from a hex-encoded private key seed or
-// full private key.
+func (s secp256k1Signer) Sign(_ io.Reader, digest []byte, _ crypto.SignerOpts) ([]byte, error) {
+	return secpecdsa.Sign(s.key, digest).Serialize(), nil
+}
+
+// PrivateKeyHex / PublicKeyHex encode key bytes for keyfile storage: the full
+// Ed25519 private key or the 32-byte EC scalar, and the did:key public key
+// bytes. Keys held outside the process cannot be exported.
+func PrivateKeyHex(priv crypto.Signer) (string, error) {
+	switch k := priv.(type) {
+	case ed25519.PrivateKey:
+		return hex.EncodeToString(k), nil
+	case *ecdsa.PrivateKey:
+		raw, err := k.Bytes()
+		if err != nil {
+			return "", err
+		}
+		return hex.EncodeToString(raw), nil
+	case secp256k1Signer:
+		return hex.EncodeToString(k.key.Serialize()), nil
+	}
+	return "", fmt.Errorf("cannot export private key of type %T", priv)
+}
+
+func PublicKeyHex(pub crypto.PublicKey) string {
+	raw, _ := publicKeyBytes(pub)
+	return hex.EncodeToString(raw)
+}
+
+// KeyPairFromHex reconstructs an Ed25519 KeyPair from a hex-encoded private
+// key seed or full private key.
 func KeyPairFromHex(privHex string) (*KeyPair, error) {
+	return KeyPairFromHexOf(KeyTypeEd25519, privHex)
+}
+
+// KeyPairFromHexOf reconstructs a KeyPair of key type t from the hex encoding
+// PrivateKeyHex produced (or, for Ed25519, a 32-byte seed).
+func KeyPairFromHexOf(t KeyType, privHex string) (*KeyPair, error) {
 	raw, err := hex.DecodeString(privHex)
 	if err != nil {
 		return nil, fmt.Errorf("private key is not valid hex: %w", err)
 	}
-	var priv ed25519.PrivateKey
-	switch len(raw) {
-	case ed25519.PrivateKeySize:
-		priv = ed25519.PrivateKey(raw)
-	case ed25519.SeedSize:
-		priv = ed25519.NewKeyFromSeed(raw)
+	var priv crypto.Signer
+	switch t {
+	case KeyTypeEd25519:
+		switch len(raw) {
+		case ed25519.PrivateKeySize:
+			priv = ed25519.PrivateKey(raw)
+		case ed25519.SeedSize:
+			priv = ed25519.NewKeyFromSeed(raw)
+		default:
+			return nil, fmt.Errorf("unexpected private key length %d", len(raw))
+		}
+	case KeyTypeP256:
+		k, err := ecdsa.ParseRawPrivateKey(elliptic.P256(), raw)
+		if err != nil {
+			return nil, fmt.Errorf("p256 private key: %w", err)
+		}
+		priv = k
+	case KeyTypeSecp256k1:
+		var d secp256k1.ModNScalar
+		if len(raw) != 32 || d.SetByteSlice(raw) || d.IsZero() {
+			return nil, fmt.Errorf("secp256k1 private key must be a 32-byte scalar below the group order")
+		}
+		priv = secp256k1Signer{secp256k1.NewPrivateKey(&d)}
 	default:
-		return nil, fmt.Errorf("unexpected private key length %d", len(raw))
+		return nil, fmt.Errorf("unknown key type %q", t)
 	}
-	pub := priv.Public().(ed25519.PublicKey)
-	return &KeyPair{DID: DIDFromPublicKey(pub), Public: pub, Private: priv}, nil
+	return KeyPairFromSigner(priv)
 }
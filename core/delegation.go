@@ -0,0 +1,200 @@
+package core
+
+import (
+	"crypto"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// DelegationSpec is the spec tag for a v0.1 issuing-key delegation record.
+const DelegationSpec = "moltnet/delegation/v0.1"
+
+// Delegation authorizes a sub-key to issue attestations on behalf of an agent
+// (the principal) until ExpiresAt, so a busy issuer need not keep its root key
+// hot on every worker. It is signed by the principal's owner, like a rotation.
+//
+// A delegated attestation is signed by the delegate, names the principal in
+// on_behalf_of and this record's hash in delegation, and chains on the
+// delegate's own per-issuer chain, so workers do not serialize on one head.
+// Reputation treats it as the principal's. Revoking the delegate key (a
+// Revocation naming it as the agent, signed by the delegation's owner) ends
+// the delegation from its cutoff.
+type Delegation struct {
+	Spec         string   `json:"spec"`
+	Owner        string   `json:"owner"`                  // owner DID of the principal; signs
+	Principal    string   `json:"principal"`              // agent DID the delegate issues for
+	Delegate     string   `json:"delegate"`               // sub-key DID
+	Types        []string `json:"types,omitempty"`        // attestation types allowed; empty allows any
+	Capabilities []string `json:"capabilities,omitempty"` // body.capability values allowed; empty allows any
+	ExpiresAt    string   `json:"expires_at"`             // RFC 3339; nothing issued from here on is covered
+	IssuedAt     string   `json:"issued_at"`
+	Sig          string   `json:"sig,omitempty"` // owner signature
+}
+
+// NewDelegation builds an unsigned delegation with the spec tag and timestamp
+// set, allowing any type and capability until expiresAt.
+func NewDelegation(ownerDID, principalDID, delegateDID string, expiresAt time.Time) *Delegation {
+	return &Delegation{
+		Spec:      DelegationSpec,
+		Owner:     ownerDID,
+		Principal: principalDID,
+		Delegate:  delegateDID,
+		ExpiresAt: expiresAt.UTC().Format(time.RFC3339),
+		IssuedAt:  time.Now().UTC().Format(time.RFC3339),
+	}
+}
+
+// SigningPayload is the canonical delegation without its signature.
+func (d *Delegation) SigningPayload() ([]byte, error) {
+	return CanonicalizeWithout(d, "sig")
+}
+
+// Hash returns the content address of the delegation, which delegated
+// attestations name.
+func (d *Delegation) Hash() (string, error) {
+	payload, err := d.SigningPayload()
+	if err != nil {
+		return "", err
+	}
+	return HashBytes(payload), nil
+}
+
+// Sign fills in the owner signature.
+func (d *Delegation) Sign(ownerKey crypto.Signer) error {
+	payload, err := d.SigningPayload()
+	if err != nil {
+		return err
+	}
+	d.Sig, err = Sign(ownerKey, payload)
+	return err
+}
+
+// Verify checks structural invariants and the owner signature. That Owner
+// owns Principal is checked against the principal's card by the caller.
+func (d *Delegation) Verify() error {
+	if d.Spec != DelegationSpec {
+		return fmt.Errorf("delegation: unexpected spec %q", d.Spec)
+	}
+	if d.Owner == "" || d.Principal == "" || d.Delegate == "" {
+		return fmt.Errorf("delegation: owner, principal and delegate are required")
+	}
+	if d.Delegate == d.Principal {
+		return fmt.Errorf("delegation: principal cannot delegate to itself")
+	}
+	if _, err := PublicKeyFromDID(d.Delegate); err != nil {
+		return fmt.Errorf("delegation: delegate: %w", err)
+	}
+	for _, t := range d.Types {
+		if !ValidType(t) || t == TypeKeyRotation {
+			return fmt.Errorf("delegation: type %q cannot be delegated", t)
+		}
+	}
+	issued, err := time.Parse(time.RFC3339, d.IssuedAt)
+	if err != nil {
+		return fmt.Errorf("delegation: issued_at must be an RFC 3339 timestamp")
+	}
+	expires, err := time.Parse(time.RFC3339, d.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("delegation: expires_at must be an RFC 3339 timestamp")
+	}
+	if !expires.After(issued) {
+		return fmt.Errorf("delegation: expires_at must be after issued_at")
+	}
+	if d.Sig == "" {
+		return fmt.Errorf("delegation: missing owner signature")
+	}
+	payload, err := d.SigningPayload()
+	if err != nil {
+		return err
+	}
+	if err := Verify(d.Owner, payload, d.Sig); err != nil {
+		return fmt.Errorf("delegation: owner signature invalid: %w", err)
+	}
+	return nil
+}
+
+// Authorizes checks that d covers attestation a: a names d and its principal,
+// is signed by d's delegate, falls within d's scope, and was issued between
+// d's issued_at and its expiry. a's own signature is checked by a.Verify.
+func (d *Delegation) Authorizes(a *Attestation) error {
+	hash, err := d.Hash()
+	if err != nil {
+		return err
+	}
+	switch {
+	case a.Delegation != hash:
+		return fmt.Errorf("delegation: attestation names delegation %s, not %s", a.Delegation, hash)
+	case a.Issuer != d.Delegate:
+		return fmt.Errorf("delegation: attestation is signed by %s, not the delegate %s", a.Issuer, d.Delegate)
+	case a.OnBehalfOf != d.Principal:
+		return fmt.Errorf("delegation: attestation is on behalf of %s, not the principal %s", a.OnBehalfOf, d.Principal)
+	case a.Type == TypeKeyRotation:
+		return fmt.Errorf("delegation: %s cannot be delegated", a.Type)
+	case len(d.Types) > 0 && !slices.Contains(d.Types, a.Type):
+		return fmt.Errorf("delegation: type %s is outside the delegation's scope", a.Type)
+	}
+	if len(d.Capabilities) > 0 {
+		if c, _ := a.Body["capability"].(string); !slices.Contains(d.Capabilities, c) {
+			return fmt.Errorf("delegation: capability %q is outside the delegation's scope", c)
+		}
+	}
+	at, err := time.Parse(time.RFC3339, a.IssuedAt)
+	if err != nil {
+		return fmt.Errorf("delegation: attestation issued_at must be an RFC 3339 timestamp")
+	}
+	issued, _ := time.Parse(time.RFC3339, d.IssuedAt)
+	expires, _ := time.Parse(time.RFC3339, d.ExpiresAt)
+	if at.Before(issued) || !at.Before(expires) {
+		return fmt.Errorf("delegation: attestation issued %s, outside the delegation's %s to %s", a.IssuedAt, d.IssuedAt, d.ExpiresAt)
+	}
+	return nil
+}
+
+// Delegations indexes verified delegations by hash. A nil Delegations
+// authorizes no delegated attestation.
+type Delegations map[string]*Delegation
+
+// NewDelegations indexes the delegations in ds that verify; the rest are
+// ignored.
+func NewDelegations(ds []*Delegation) Delegations {
+	var out Delegations
+	for _, d := range ds {
+		if d.Verify() != nil {
+			continue
+		}
+		h, err := d.Hash()
+		if err != nil {
+			continue
+		}
+		if out == nil {
+			out = Delegations{}
+		}
+		out[h] = d
+	}
+	return out
+}
+
+// Check reports why a delegated attestation is not covered by a delegation
+// in ds, or nil if it is — or if a is not delegated at all.
+func (ds Delegations) Check(a *Attestation) error {
+	if a.Delegation == "" && a.OnBehalfOf == "" {
+		return nil
+	}
+	d := ds[a.Delegation]
+	if d == nil {
+		return fmt.Errorf("delegation: %s is unknown", a.Delegation)
+	}
+	return d.Authorizes(a)
+}
+
+// Filter returns atts without the delegated attestations ds does not cover.
+func (ds Delegations) Filter(atts []*Attestation) []*Attestation {
+	out := make([]*Attestation, 0, len(atts))
+	for _, a := range atts {
+		if ds.Check(a) == nil {
+			out = append(out, a)
+		}
+	}
+	return out
+}
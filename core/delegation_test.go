@@ -0,0 +1,87 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+// Two workers issue for one principal on their own chains; their attestations
+// verify only under the delegation that covers them.
+func TestDelegation(t *testing.T) {
+	owner, _ := GenerateKeyPair()
+	principal, _ := GenerateKeyPair()
+	w1, _ := GenerateKeyPair()
+	w2, _ := GenerateKeyPair()
+	subject, _ := GenerateKeyPair()
+	at := time.Now().UTC().Add(-time.Hour).Truncate(time.Second)
+
+	delegate := func(worker *KeyPair) (*Delegation, string) {
+		d := NewDelegation(owner.DID, principal.DID, worker.DID, at.AddDate(0, 1, 0))
+		d.IssuedAt = at.Format(time.RFC3339)
+		d.Types = []string{TypeTaskCompleted, TypeTaskDisputed}
+		d.Capabilities = []string{"code.review"}
+		if err := d.Sign(owner.Private); err != nil {
+			t.Fatal(err)
+		}
+		if err := d.Verify(); err != nil {
+			t.Fatalf("valid delegation rejected: %v", err)
+		}
+		h, _ := d.Hash()
+		return d, h
+	}
+	d1, h1 := delegate(w1)
+	d2, h2 := delegate(w2)
+	issue := func(worker *KeyPair, delegation, typ, capability string, issued time.Time) *Attestation {
+		a := NewAttestation(typ, worker.DID, subject.DID)
+		a.OnBehalfOf, a.Delegation = principal.DID, delegation
+		a.Body = map[string]any{"capability": capability}
+		a.IssuedAt = issued.Format(time.RFC3339)
+		if err := a.Sign(worker.Private); err != nil {
+			t.Fatal(err)
+		}
+		return a
+	}
+	a1 := issue(w1, h1, TypeTaskCompleted, "code.review", at.Add(time.Minute))
+	a2 := issue(w2, h2, TypeTaskCompleted, "code.review", at.Add(time.Minute))
+	if a1.Principal() != principal.DID {
+		t.Fatalf("delegated attestation should count for the principal, got %s", a1.Principal())
+	}
+	if err := VerifyAllWith([]*Attestation{a1, a2}, VerifyOptions{Delegations: []*Delegation{d1, d2}}); err != nil {
+		t.Fatalf("delegated attestations on two sub-chains: %v", err)
+	}
+	if err := VerifyAllWith([]*Attestation{a1, a2}, VerifyOptions{Delegations: []*Delegation{d1}}); err == nil {
+		t.Fatal("an attestation under an unknown delegation should fail")
+	}
+	if err := VerifyAllWith([]*Attestation{issue(w2, h1, TypeTaskCompleted, "code.review", at.Add(time.Minute))}, VerifyOptions{Delegations: []*Delegation{d1}}); err == nil {
+		t.Fatal("a worker should not issue under another worker's delegation")
+	}
+
+	ds := NewDelegations([]*Delegation{d1, d2})
+	for name, a := range map[string]*Attestation{
+		"type out of scope":       issue(w1, h1, TypeEndorsement, "code.review", at.Add(time.Minute)),
+		"capability out of scope": issue(w1, h1, TypeTaskCompleted, "translation", at.Add(time.Minute)),
+		"issued after expiry":     issue(w1, h1, TypeTaskCompleted, "code.review", at.AddDate(0, 2, 0)),
+		"issued before delegated": issue(w1, h1, TypeTaskCompleted, "code.review", at.Add(-time.Minute)),
+	} {
+		if err := ds.Check(a); err == nil {
+			t.Errorf("%s: should not be covered", name)
+		}
+	}
+	if got := ds.Filter([]*Attestation{a1, issue(w1, h1, TypeEndorsement, "code.review", at)}); len(got) != 1 {
+		t.Fatalf("Filter should keep only covered attestations, kept %d", len(got))
+	}
+
+	// Revoking the worker key ends the delegation from the cutoff.
+	rev := NewRevocation(owner.DID, w1.DID, at.Add(30*time.Minute))
+	_ = rev.Sign(owner.Private)
+	late := issue(w1, h1, TypeTaskCompleted, "code.review", at.Add(time.Hour))
+	if !RevocationCutoffs([]*Revocation{rev}).Revoked(late) {
+		t.Fatal("a revoked delegate's later attestation should be distrusted")
+	}
+
+	self := NewDelegation(owner.DID, principal.DID, principal.DID, at.AddDate(0, 1, 0))
+	_ = self.Sign(owner.Private)
+	if self.Verify() == nil {
+		t.Fatal("a principal delegating to itself should be rejected")
+	}
+}
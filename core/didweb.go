@@ -0,0 +1,248 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DIDWebPrefix starts a did:web DID, which names a domain (and optionally a
+// path on it) whose DID document lists the keys that speak for it.
+const DIDWebPrefix = "did:web:"
+
+// DefaultDIDWebTTL is how long a fetched DID document is trusted before it is
+// fetched again.
+const DefaultDIDWebTTL = time.Hour
+
+// IsDIDWeb reports whether did is a did:web DID.
+func IsDIDWeb(did string) bool {
+	return strings.HasPrefix(did, DIDWebPrefix)
+}
+
+// DIDWebURL maps a did:web DID to the HTTPS URL of its DID document:
+// did:web:acme.com is served at https://acme.com/.well-known/did.json, and
+// did:web:acme.com:agents at https://acme.com/agents/did.json. A port is
+// percent-encoded in the DID (did:web:localhost%3A8443).
+func DIDWebURL(did string) (string, error) {
+	if !IsDIDWeb(did) {
+		return "", fmt.Errorf("did %q is not a did:web", did)
+	}
+	parts := strings.Split(strings.TrimPrefix(did, DIDWebPrefix), ":")
+	host, err := url.PathUnescape(parts[0])
+	if err != nil || host == "" || strings.ContainsAny(host, "/?#@") {
+		return "", fmt.Errorf("did %q: invalid domain", did)
+	}
+	path := "/.well-known"
+	if len(parts) > 1 {
+		path = ""
+		for _, p := range parts[1:] {
+			seg, err := url.PathUnescape(p)
+			if err != nil || seg == "" || seg == "." || seg == ".." || strings.Contains(seg, "/") {
+				return "", fmt.Errorf("did %q: invalid path segment %q", did, p)
+			}
+			path += "/" + url.PathEscape(seg)
+		}
+	}
+	return "https://" + host + path + "/did.json", nil
+}
+
+// DIDWebDomain returns the domain (with any port) a did:web DID names.
+func DIDWebDomain(did string) string {
+	host, _, _ := strings.Cut(strings.TrimPrefix(did, DIDWebPrefix), ":")
+	if h, err := url.PathUnescape(host); err == nil {
+		return h
+	}
+	return host
+}
+
+// DIDDocument is the part of a W3C DID document MoltNet reads: the
+// verification methods and which of them may make assertions.
+type DIDDocument struct {
+	ID                 string               `json:"id"`
+	VerificationMethod []VerificationMethod `json:"verificationMethod,omitempty"`
+	// AssertionMethod lists the methods that may sign claims, by reference
+	// ("#key-1" or the full id) or embedded.
+	AssertionMethod []json.RawMessage `json:"assertionMethod,omitempty"`
+}
+
+// VerificationMethod is one key in a DID document. Only publicKeyMultibase
+// keys of a supported did:key type are understood (Multikey,
+// Ed25519VerificationKey2020 and the like); others are ignored.
+type VerificationMethod struct {
+	ID                 string `json:"id"`
+	Type               string `json:"type"`
+	Controller         string `json:"controller,omitempty"`
+	PublicKeyMultibase string `json:"publicKeyMultibase,omitempty"`
+}
+
+// NewDIDDocument builds the DID document a domain publishes for did, listing
+// keys (did:key DIDs) as Multikey assertion methods #key-1, #key-2, ….
+func NewDIDDocument(did string, keys ...string) (*DIDDocument, error) {
+	if _, err := DIDWebURL(did); err != nil {
+		return nil, err
+	}
+	doc := &DIDDocument{ID: did}
+	for i, key := range keys {
+		if _, err := PublicKeyFromDID(key); err != nil {
+			return nil, err
+		}
+		id := fmt.Sprintf("#key-%d", i+1)
+		doc.VerificationMethod = append(doc.VerificationMethod, VerificationMethod{
+			ID: id, Type: "Multikey", Controller: did, PublicKeyMultibase: strings.TrimPrefix(key, "did:key:"),
+		})
+		ref, _ := json.Marshal(id)
+		doc.AssertionMethod = append(doc.AssertionMethod, ref)
+	}
+	return doc, nil
+}
+
+// AssertionKeys returns the did:key DIDs of the document's assertion methods.
+func (d *DIDDocument) AssertionKeys() []string {
+	methods := map[string]VerificationMethod{}
+	for _, vm := range d.VerificationMethod {
+		methods[vm.ID] = vm
+		if strings.HasPrefix(vm.ID, "#") {
+			methods[d.ID+vm.ID] = vm
+		}
+	}
+	var keys []string
+	for _, raw := range d.AssertionMethod {
+		var vm VerificationMethod
+		var ref string
+		if json.Unmarshal(raw, &ref) == nil {
+			if strings.HasPrefix(ref, "#") {
+				ref = d.ID + ref
+			}
+			vm = methods[ref]
+		} else if json.Unmarshal(raw, &vm) != nil {
+			continue
+		}
+		if !strings.HasPrefix(vm.PublicKeyMultibase, "z") {
+			continue
+		}
+		key := "did:key:" + vm.PublicKeyMultibase
+		if _, err := PublicKeyFromDID(key); err == nil && !slices.Contains(keys, key) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// Lists reports whether key (a did:key) is one of the document's assertion
+// keys.
+func (d *DIDDocument) Lists(key string) bool {
+	return slices.Contains(d.AssertionKeys(), key)
+}
+
+// DIDWebResolver fetches did:web DID documents and caches them until TTL
+// passes. Documents added with Add (from a bundle the verifier trusts) never
+// expire and are never fetched, so verification can run offline.
+type DIDWebResolver struct {
+	// Client fetches documents; nil means http.DefaultClient. Set its Transport
+	// to serve documents from somewhere other than the domain, as tests do.
+	Client *http.Client
+	// TTL bounds how long a fetched document is reused; 0 means
+	// DefaultDIDWebTTL.
+	TTL time.Duration
+
+	mu    sync.Mutex
+	cache map[string]didWebEntry
+}
+
+type didWebEntry struct {
+	doc     *DIDDocument
+	expires time.Time // zero for a bundled document
+}
+
+// NewDIDWebResolver returns a resolver fetching with client (nil for the
+// default client).
+func NewDIDWebResolver(client *http.Client) *DIDWebResolver {
+	return &DIDWebResolver{Client: client}
+}
+
+// Add supplies a resolved DID document, which is then used in place of
+// fetching it.
+func (r *DIDWebResolver) Add(doc *DIDDocument) error {
+	if !IsDIDWeb(doc.ID) {
+		return fmt.Errorf("did document %q is not for a did:web", doc.ID)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.cache == nil {
+		r.cache = map[string]didWebEntry{}
+	}
+	r.cache[doc.ID] = didWebEntry{doc: doc}
+	return nil
+}
+
+// Resolve returns the DID document for a did:web DID, from the cache while it
+// is fresh.
+func (r *DIDWebResolver) Resolve(did string) (*DIDDocument, error) {
+	r.mu.Lock()
+	e, ok := r.cache[did]
+	r.mu.Unlock()
+	if ok && (e.expires.IsZero() || time.Now().Before(e.expires)) {
+		return e.doc, nil
+	}
+	doc, err := r.fetch(did)
+	if err != nil {
+		return nil, err
+	}
+	ttl := r.TTL
+	if ttl <= 0 {
+		ttl = DefaultDIDWebTTL
+	}
+	r.mu.Lock()
+	if r.cache == nil {
+		r.cache = map[string]didWebEntry{}
+	}
+	r.cache[did] = didWebEntry{doc: doc, expires: time.Now().Add(ttl)}
+	r.mu.Unlock()
+	return doc, nil
+}
+
+func (r *DIDWebResolver) fetch(did string) (*DIDDocument, error) {
+	u, err := DIDWebURL(did)
+	if err != nil {
+		return nil, err
+	}
+	client := r.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Get(u)
+	if err != nil {
+		return nil, fmt.Errorf("resolve %s: %w", did, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("resolve %s: %s returned %s", did, u, resp.Status)
+	}
+	var doc DIDDocument
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("resolve %s: invalid did document: %w", did, err)
+	}
+	if doc.ID != did {
+		return nil, fmt.Errorf("resolve %s: document is for %q", did, doc.ID)
+	}
+	return &doc, nil
+}
+
+// VerifyDomain checks that a did:web owner's DID document lists key as an
+// assertion method, which links the key to the owner's domain.
+func (r *DIDWebResolver) VerifyDomain(owner, key string) error {
+	doc, err := r.Resolve(owner)
+	if err != nil {
+		return err
+	}
+	if !doc.Lists(key) {
+		return fmt.Errorf("%s does not list %s as an assertion method", owner, key)
+	}
+	return nil
+}
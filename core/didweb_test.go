@@ -0,0 +1,107 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// roundTripFunc serves HTTP requests in-process.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+func TestDIDWebURL(t *testing.T) {
+	for did, want := range map[string]string{
+		"did:web:acme.com":              "https://acme.com/.well-known/did.json",
+		"did:web:acme.com:agents:fleet": "https://acme.com/agents/fleet/did.json",
+		"did:web:localhost%3A8443":      "https://localhost:8443/.well-known/did.json",
+		"did:web:acme.com:users:a%20b":  "https://acme.com/users/a%20b/did.json",
+	} {
+		if got, err := DIDWebURL(did); err != nil || got != want {
+			t.Errorf("%s: got %q (%v), want %q", did, got, err, want)
+		}
+	}
+	for _, bad := range []string{"did:key:z6Mk", "did:web:", "did:web:acme.com:..", "did:web:evil.com%2Fx"} {
+		if _, err := DIDWebURL(bad); err == nil {
+			t.Errorf("%s: should be rejected", bad)
+		}
+	}
+}
+
+// A card owned by acme.com verifies offline; the domain link is checked
+// through the resolver, which caches the document until its TTL passes.
+func TestDIDWebOwner(t *testing.T) {
+	owner, _ := GenerateKeyPairOf(KeyTypeP256)
+	stranger, _ := GenerateKeyPair()
+	agent, _ := GenerateKeyPair()
+	const did = "did:web:acme.com"
+	doc, err := NewDIDDocument(did, owner.DID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := json.Marshal(doc)
+	fetches := 0
+	client := &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		if r.URL.String() != "https://acme.com/.well-known/did.json" {
+			return nil, errors.New("unexpected fetch " + r.URL.String())
+		}
+		fetches++
+		return &http.Response{StatusCode: 200, Status: "200 OK", Body: io.NopCloser(strings.NewReader(string(body)))}, nil
+	})}
+	res := NewDIDWebResolver(client)
+
+	card := func(key *KeyPair) *Card {
+		c := NewCard(agent.DID, did, "acme-agent")
+		c.OwnerKey = key.DID
+		if err := c.Sign(agent.Private, key.Private); err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+	c := card(owner)
+	if err := c.Verify(); err != nil {
+		t.Fatalf("did:web-owned card: %v", err)
+	}
+	if err := res.VerifyDomain(c.Owner, c.OwnerKey); err != nil {
+		t.Fatalf("listed key: %v", err)
+	}
+	// A stranger's card verifies on its own, but the domain does not list it.
+	if forged := card(stranger); forged.Verify() != nil || res.VerifyDomain(forged.Owner, forged.OwnerKey) == nil {
+		t.Fatal("an unlisted key should fail domain verification only")
+	}
+	if fetches != 1 {
+		t.Fatalf("document fetched %d times, want once while cached", fetches)
+	}
+	short := NewDIDWebResolver(client)
+	short.TTL = time.Nanosecond
+	_, _ = short.Resolve(did)
+	time.Sleep(time.Millisecond)
+	_, _ = short.Resolve(did)
+	if fetches != 3 {
+		t.Fatalf("an expired document should be fetched again (%d fetches)", fetches)
+	}
+
+	// Offline: a bundled document is used without fetching.
+	offline := NewDIDWebResolver(&http.Client{Transport: roundTripFunc(func(*http.Request) (*http.Response, error) {
+		return nil, errors.New("offline")
+	})})
+	if err := offline.VerifyDomain(did, owner.DID); err == nil {
+		t.Fatal("resolution should fail offline without a bundle")
+	}
+	if err := offline.Add(doc); err != nil {
+		t.Fatal(err)
+	}
+	if err := offline.VerifyDomain(did, owner.DID); err != nil {
+		t.Fatalf("bundled document: %v", err)
+	}
+
+	c.OwnerKey = ""
+	if c.Verify() == nil {
+		t.Fatal("a did:web owner without owner_key should be rejected")
+	}
+}
@@ -0,0 +1,163 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+)
+
+// EquivocationSpec is the spec tag for a v0.1 equivocation proof.
+const EquivocationSpec = "moltnet/equivocation/v0.1"
+
+// Equivocation proves that an issuer forked its own chain: two different
+// attestations, both signed by Issuer, both naming Prev as their predecessor
+// on its chain (as lead or co-issuer).
+// An honest issuer never does this, since a chain has one successor per link,
+// but a registry only enforces it for writes it receives itself — an issuer can
+// show each branch to a different instance. The proof needs no signature of
+// its own: it is self-contained, and anyone can check it with Verify.
+//
+// A and B are ordered by hash, so the two records yield one proof whichever
+// was seen first.
+type Equivocation struct {
+	Spec   string       `json:"spec"`
+	Issuer string       `json:"issuer"`
+	Prev   string       `json:"prev"`
+	A      *Attestation `json:"a"`
+	B      *Attestation `json:"b"`
+}
+
+// NewEquivocation builds the proof that a and b equivocate, or returns an
+// error if they do not. Should they share more than one link, the proof is
+// against the first of a's issuers that both follow from the same prev.
+func NewEquivocation(a, b *Attestation) (*Equivocation, error) {
+	for _, issuer := range a.Issuers() {
+		pa, _ := a.PrevOf(issuer)
+		if pb, ok := b.PrevOf(issuer); ok && pa == pb {
+			return newEquivocation(issuer, a, b)
+		}
+	}
+	return nil, fmt.Errorf("equivocation: the attestations share no issuer and prev")
+}
+
+func newEquivocation(issuer string, a, b *Attestation) (*Equivocation, error) {
+	ha, err := a.Hash()
+	if err != nil {
+		return nil, err
+	}
+	hb, err := b.Hash()
+	if err != nil {
+		return nil, err
+	}
+	if hb < ha {
+		a, b = b, a
+	}
+	prev, _ := a.PrevOf(issuer)
+	e := &Equivocation{Spec: EquivocationSpec, Issuer: issuer, Prev: prev, A: a, B: b}
+	if err := e.Verify(); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Hash returns the content address of the proof.
+func (e *Equivocation) Hash() (string, error) {
+	c, err := Canonicalize(e)
+	if err != nil {
+		return "", err
+	}
+	return HashBytes(c), nil
+}
+
+// Hashes returns the hashes of the two conflicting attestations, in proof
+// order.
+func (e *Equivocation) Hashes() (string, string) {
+	ha, _ := e.A.Hash()
+	hb, _ := e.B.Hash()
+	return ha, hb
+}
+
+// Verify checks that both attestations are validly signed by Issuer, name
+// Prev on its chain, and differ.
+func (e *Equivocation) Verify() error {
+	if e.Spec != EquivocationSpec {
+		return fmt.Errorf("equivocation: unexpected spec %q", e.Spec)
+	}
+	if e.A == nil || e.B == nil {
+		return fmt.Errorf("equivocation: two attestations are required")
+	}
+	for _, a := range []*Attestation{e.A, e.B} {
+		if prev, ok := a.PrevOf(e.Issuer); !ok || prev != e.Prev {
+			return fmt.Errorf("equivocation: both attestations must come from %s and follow %q", e.Issuer, e.Prev)
+		}
+		if err := a.Verify(); err != nil {
+			return fmt.Errorf("equivocation: %w", err)
+		}
+	}
+	ha, hb := e.Hashes()
+	if ha == hb {
+		return fmt.Errorf("equivocation: the two attestations are the same")
+	}
+	if hb < ha {
+		return fmt.Errorf("equivocation: attestations must be ordered by hash")
+	}
+	return nil
+}
+
+// FindEquivocations returns a proof for every pair of validly signed
+// attestations in atts that share an issuer and a prev, ordered by issuer and
+// prev. Unsigned or mis-signed attestations prove nothing and are skipped.
+func FindEquivocations(atts []*Attestation) []*Equivocation {
+	return findEquivocations("", atts)
+}
+
+// findEquivocations is FindEquivocations for one issuer's links, or for every
+// issuer's if only is "".
+func findEquivocations(only string, atts []*Attestation) []*Equivocation {
+	type link struct{ issuer, prev string }
+	seen := map[link][]*Attestation{}
+	hashes := map[string]bool{}
+	var links []link
+	for _, a := range atts {
+		h, err := a.Hash()
+		if err != nil || hashes[h] {
+			continue
+		}
+		hashes[h] = true
+		for _, issuer := range a.Issuers() {
+			if only != "" && issuer != only {
+				continue
+			}
+			prev, _ := a.PrevOf(issuer)
+			l := link{issuer, prev}
+			if len(seen[l]) == 0 {
+				links = append(links, l)
+			}
+			seen[l] = append(seen[l], a)
+		}
+	}
+	sort.Slice(links, func(i, j int) bool {
+		if links[i].issuer != links[j].issuer {
+			return links[i].issuer < links[j].issuer
+		}
+		return links[i].prev < links[j].prev
+	})
+	var out []*Equivocation
+	for _, l := range links {
+		if len(seen[l]) < 2 {
+			continue
+		}
+		// Only now check signatures: most links have a single successor.
+		var branch []*Attestation
+		for _, a := range seen[l] {
+			if a.Verify() == nil {
+				branch = append(branch, a)
+			}
+		}
+		for i := 1; i < len(branch); i++ {
+			if e, err := newEquivocation(l.issuer, branch[0], branch[i]); err == nil {
+				out = append(out, e)
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,54 @@
+package core
+
+import (
+	"strings"
+	"testing"
+)
+
+// Two signed successors of one link prove a fork, whichever is found first.
+func TestEquivocation(t *testing.T) {
+	issuer, _ := GenerateKeyPair()
+	subject, _ := GenerateKeyPair()
+	branch := func(id string) *Attestation {
+		a := NewAttestation(TypeTaskCompleted, issuer.DID, subject.DID)
+		a.Body = map[string]any{"task_id": id}
+		_ = a.Sign(issuer.Private)
+		return a
+	}
+	a, b := branch("one"), branch("two")
+	if err := VerifyAll([]*Attestation{a, b}); err == nil || !strings.Contains(err.Error(), "equivocated") {
+		t.Fatalf("VerifyAll should name the equivocation, got %v", err)
+	}
+	e1, err := NewEquivocation(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e2, _ := NewEquivocation(b, a)
+	h1, _ := e1.Hash()
+	h2, _ := e2.Hash()
+	if h1 != h2 {
+		t.Fatal("the proof should not depend on which branch came first")
+	}
+	if found := FindEquivocations([]*Attestation{a, b, a}); len(found) != 1 {
+		t.Fatalf("want one proof, got %d", len(found))
+	}
+
+	if _, err := NewEquivocation(a, a); err == nil {
+		t.Fatal("an attestation does not equivocate with itself")
+	}
+	next := NewAttestation(TypeTaskCompleted, issuer.DID, subject.DID)
+	next.Prev, _ = a.Hash()
+	_ = next.Sign(issuer.Private)
+	if _, err := NewEquivocation(a, next); err == nil {
+		t.Fatal("successive links are a chain, not a fork")
+	}
+	tampered := *e1
+	tampered.B = branch("three")
+	tampered.B.Body["task_id"] = "four"
+	if tampered.Verify() == nil {
+		t.Fatal("a proof with a mis-signed branch should be rejected")
+	}
+	if FindEquivocations([]*Attestation{a, tampered.B}) != nil {
+		t.Fatal("an unsigned branch proves nothing")
+	}
+}
@@ -0,0 +1,145 @@
+package core
+
+import (
+	"crypto"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// ForkResolutionSpec is the spec tag for a v0.1 card fork resolution.
+const ForkResolutionSpec = "moltnet/fork-resolution/v0.1"
+
+// ForkResolution settles a card-version fork: competing card versions of one
+// agent, each validly signed, neither chaining onto the other. It names the
+// version chosen as the agent's head and the ones set aside, and is signed as
+// a card is — by the agent key and by the owner (its key, a threshold of its
+// policy's members, or a did:web's listed owner_key) — so it carries the same
+// authority as the versions it chooses between.
+//
+// A resolution settles only forks between its branches: a later fork, even
+// one off the chosen head, needs a resolution of its own.
+type ForkResolution struct {
+	Spec        string       `json:"spec"`
+	Agent       string       `json:"agent"` // the agent whose card forked
+	Owner       string       `json:"owner"`
+	OwnerPolicy *OwnerPolicy `json:"owner_policy,omitempty"`
+	OwnerKey    string       `json:"owner_key,omitempty"` // for a did:web owner, as on a card
+	Head        string       `json:"head"`                // hash of the card version chosen
+	Rejected    []string     `json:"rejected"`            // hashes of the competing versions set aside
+	IssuedAt    string       `json:"issued_at"`
+	Sig         string       `json:"sig,omitempty"`        // agent signature
+	OwnerSig    string       `json:"owner_sig,omitempty"`  // owner key signature
+	OwnerSigs   []OwnerSig   `json:"owner_sigs,omitempty"` // policy member signatures
+}
+
+// NewForkResolution builds an unsigned resolution choosing head over rejected
+// for card's agent and owner, with the spec tag and timestamp set.
+func NewForkResolution(card *Card, head string, rejected ...string) *ForkResolution {
+	return &ForkResolution{
+		Spec:        ForkResolutionSpec,
+		Agent:       card.ID,
+		Owner:       card.Owner,
+		OwnerPolicy: card.OwnerPolicy,
+		Head:        head,
+		Rejected:    rejected,
+		IssuedAt:    time.Now().UTC().Format(time.RFC3339),
+	}
+}
+
+// SigningPayload is the canonical resolution without its signatures.
+func (r *ForkResolution) SigningPayload() ([]byte, error) {
+	return CanonicalizeWithout(r, "sig", "owner_sig", "owner_sigs")
+}
+
+// Hash returns the content address of the resolution.
+func (r *ForkResolution) Hash() (string, error) {
+	payload, err := r.SigningPayload()
+	if err != nil {
+		return "", err
+	}
+	return HashBytes(payload), nil
+}
+
+// Sign fills in the agent and owner signatures.
+func (r *ForkResolution) Sign(agentKey, ownerKey crypto.Signer) error {
+	payload, err := r.SigningPayload()
+	if err != nil {
+		return err
+	}
+	if r.Sig, err = Sign(agentKey, payload); err != nil {
+		return err
+	}
+	r.OwnerSig, err = Sign(ownerKey, payload)
+	return err
+}
+
+// SignAgent fills in the agent signature alone, for a policy-owned agent whose
+// members then sign with AddOwnerSig.
+func (r *ForkResolution) SignAgent(agentKey crypto.Signer) error {
+	payload, err := r.SigningPayload()
+	if err != nil {
+		return err
+	}
+	r.Sig, err = Sign(agentKey, payload)
+	return err
+}
+
+// AddOwnerSig adds member's signature toward the owner policy, replacing any
+// it made before.
+func (r *ForkResolution) AddOwnerSig(member *KeyPair) error {
+	payload, err := r.SigningPayload()
+	if err != nil {
+		return err
+	}
+	r.OwnerSigs, err = addOwnerSig(r.OwnerSigs, member, payload)
+	return err
+}
+
+// Verify checks structural invariants, the agent signature and the owner's
+// authorization. As for a card, a did:web owner's domain linkage is checked
+// apart, with a DIDWebResolver.
+func (r *ForkResolution) Verify() error {
+	if r.Spec != ForkResolutionSpec {
+		return fmt.Errorf("fork resolution: unexpected spec %q", r.Spec)
+	}
+	if r.Agent == "" || r.Owner == "" || r.Head == "" {
+		return fmt.Errorf("fork resolution: agent, owner and head are required")
+	}
+	if len(r.Rejected) == 0 {
+		return fmt.Errorf("fork resolution: at least one rejected version is required")
+	}
+	if slices.Contains(r.Rejected, r.Head) {
+		return fmt.Errorf("fork resolution: head %s is also rejected", r.Head)
+	}
+	if r.Sig == "" {
+		return fmt.Errorf("fork resolution: missing agent signature")
+	}
+	payload, err := r.SigningPayload()
+	if err != nil {
+		return err
+	}
+	if err := Verify(r.Agent, payload, r.Sig); err != nil {
+		return fmt.Errorf("fork resolution: agent signature invalid: %w", err)
+	}
+	owner := r.Owner
+	if IsDIDWeb(r.Owner) {
+		if _, err := PublicKeyFromDID(r.OwnerKey); err != nil {
+			return fmt.Errorf("fork resolution: a did:web owner signs with an owner_key listed in its DID document: %w", err)
+		}
+		owner = r.OwnerKey
+	} else if r.OwnerKey != "" {
+		return fmt.Errorf("fork resolution: owner_key is only for a did:web owner")
+	}
+	if err := verifyOwner(owner, r.OwnerPolicy, r.OwnerSig, r.OwnerSigs, payload); err != nil {
+		return fmt.Errorf("fork resolution: %w", err)
+	}
+	return nil
+}
+
+// Settles reports whether the resolution decides between card versions a and
+// b: both are among its branches.
+func (r *ForkResolution) Settles(a, b string) bool {
+	branch := func(h string) bool { return h == r.Head || slices.Contains(r.Rejected, h) }
+	return a != b && branch(a) && branch(b)
+}
@@ -0,0 +1,39 @@
+package core
+
+import "testing"
+
+// A fork resolution carries the card's two signatures and settles only forks
+// between its own branches.
+func TestForkResolution(t *testing.T) {
+	owner, _ := GenerateKeyPair()
+	agent, _ := GenerateKeyPair()
+	other, _ := GenerateKeyPair()
+	card := NewCard(agent.DID, owner.DID, "agent")
+
+	r := NewForkResolution(card, "blake3:aa", "blake3:bb")
+	if err := r.Sign(agent.Private, owner.Private); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Verify(); err != nil {
+		t.Fatalf("valid resolution rejected: %v", err)
+	}
+	if !r.Settles("blake3:bb", "blake3:aa") || r.Settles("blake3:aa", "blake3:cc") {
+		t.Fatal("a resolution settles the forks between its branches, and only those")
+	}
+
+	agentOnly := NewForkResolution(card, "blake3:aa", "blake3:bb")
+	_ = agentOnly.Sign(agent.Private, other.Private)
+	if agentOnly.Verify() == nil {
+		t.Fatal("a resolution the owner did not sign should be rejected")
+	}
+	ownerOnly := NewForkResolution(card, "blake3:aa", "blake3:bb")
+	_ = ownerOnly.Sign(other.Private, owner.Private)
+	if ownerOnly.Verify() == nil {
+		t.Fatal("a resolution the agent did not sign should be rejected")
+	}
+	both := NewForkResolution(card, "blake3:aa", "blake3:aa")
+	_ = both.Sign(agent.Private, owner.Private)
+	if both.Verify() == nil {
+		t.Fatal("the head cannot also be rejected")
+	}
+}
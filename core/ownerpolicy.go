@@ -0,0 +1,169 @@
+package core
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// OwnerPolicySpec is the spec tag for a v0.1 M-of-N owner policy.
+const OwnerPolicySpec = "moltnet/owner-policy/v0.1"
+
+// PolicyDIDPrefix starts every owner policy identifier. The rest is the hex
+// BLAKE3 of the canonical policy, so the identifier commits to the members and
+// the threshold.
+const PolicyDIDPrefix = "did:moltpolicy:"
+
+// OwnerPolicy is an owner controlled by a team: Threshold of the Members'
+// did:key signatures stand in for a single owner signature. Records owned by a
+// policy name its DID as their owner and carry the policy itself, which a
+// verifier hashes to check it is the one named.
+type OwnerPolicy struct {
+	Spec      string   `json:"spec"`
+	Threshold int      `json:"threshold"`
+	Members   []string `json:"members"` // member owner DIDs, sorted, no repeats
+}
+
+// OwnerSig is one member's signature toward an owner policy's threshold.
+type OwnerSig struct {
+	Signer string `json:"signer"` // member DID
+	Sig    string `json:"sig"`
+}
+
+// NewOwnerPolicy builds a policy requiring threshold of members, in canonical
+// (sorted, de-duplicated) member order.
+func NewOwnerPolicy(threshold int, members ...string) (*OwnerPolicy, error) {
+	p := &OwnerPolicy{Spec: OwnerPolicySpec, Threshold: threshold, Members: sortedKeys(members)}
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Validate checks the policy's structural invariants. Members must already be
+// in canonical order, so one team has one policy DID.
+func (p *OwnerPolicy) Validate() error {
+	if p.Spec != OwnerPolicySpec {
+		return fmt.Errorf("owner policy: unexpected spec %q", p.Spec)
+	}
+	return validateKeySet("owner policy", "member", p.Members, p.Threshold)
+}
+
+// DID returns the policy's content-addressed identifier.
+func (p *OwnerPolicy) DID() (string, error) {
+	h, err := HashCanonical(p)
+	if err != nil {
+		return "", err
+	}
+	return PolicyDIDPrefix + strings.TrimPrefix(h, "blake3:"), nil
+}
+
+// IsPolicyDID reports whether did names an owner policy rather than a key.
+func IsPolicyDID(did string) bool {
+	return strings.HasPrefix(did, PolicyDIDPrefix)
+}
+
+// VerifySigs checks that sigs over payload meet the threshold. Every listed
+// signature must be a valid one by a distinct member: a stray or broken
+// signature fails the whole set rather than being skipped.
+func (p *OwnerPolicy) VerifySigs(payload []byte, sigs []OwnerSig) error {
+	return verifyThreshold("owner policy", "member", p.Members, p.Threshold, payload, sigs)
+}
+
+// verifyThreshold checks that sigs over payload are valid signatures by at
+// least threshold distinct keys of set. what and role word the errors.
+func verifyThreshold(what, role string, set []string, threshold int, payload []byte, sigs []OwnerSig) error {
+	seen := map[string]bool{}
+	for _, s := range sigs {
+		if !slices.Contains(set, s.Signer) {
+			return fmt.Errorf("%s: %s is not a %s", what, s.Signer, role)
+		}
+		if seen[s.Signer] {
+			return fmt.Errorf("%s: %s signed twice", what, s.Signer)
+		}
+		seen[s.Signer] = true
+		if err := Verify(s.Signer, payload, s.Sig); err != nil {
+			return fmt.Errorf("%s: %s %s: %w", what, role, s.Signer, err)
+		}
+	}
+	if len(seen) < threshold {
+		return fmt.Errorf("%s: %d of %d required %s signatures", what, len(seen), threshold, role)
+	}
+	return nil
+}
+
+// validateKeySet checks a threshold key set: did:key members in sorted order
+// with no repeats, and a threshold they can meet.
+func validateKeySet(what, role string, set []string, threshold int) error {
+	if len(set) == 0 {
+		return fmt.Errorf("%s: %ss are required", what, role)
+	}
+	if threshold < 1 || threshold > len(set) {
+		return fmt.Errorf("%s: threshold %d is not between 1 and %d %ss", what, threshold, len(set), role)
+	}
+	for i, m := range set {
+		if _, err := PublicKeyFromDID(m); err != nil {
+			return fmt.Errorf("%s: %s %d: %w", what, role, i, err)
+		}
+		if i > 0 && m <= set[i-1] {
+			return fmt.Errorf("%s: %ss must be sorted with no repeats", what, role)
+		}
+	}
+	return nil
+}
+
+// sortedKeys returns keys sorted with repeats removed.
+func sortedKeys(keys []string) []string {
+	k := slices.Clone(keys)
+	slices.Sort(k)
+	return slices.Compact(k)
+}
+
+// addOwnerSig signs payload as kp and records it in sigs, replacing kp's
+// earlier signature if it had one.
+func addOwnerSig(sigs []OwnerSig, kp *KeyPair, payload []byte) ([]OwnerSig, error) {
+	hexSig, err := Sign(kp.Private, payload)
+	if err != nil {
+		return sigs, err
+	}
+	sig := OwnerSig{Signer: kp.DID, Sig: hexSig}
+	for i, s := range sigs {
+		if s.Signer == kp.DID {
+			sigs[i] = sig
+			return sigs, nil
+		}
+	}
+	return append(sigs, sig), nil
+}
+
+// verifyOwner checks the owner authorization of a record whose signing payload
+// is payload. A did:key owner signs alone in sig; a policy owner is
+// authorized by policy, which must hash to owner, and threshold member
+// signatures in sigs.
+func verifyOwner(owner string, policy *OwnerPolicy, sig string, sigs []OwnerSig, payload []byte) error {
+	if !IsPolicyDID(owner) {
+		if policy != nil || len(sigs) > 0 {
+			return fmt.Errorf("owner %s is a key, not a policy: use a single owner signature", owner)
+		}
+		if sig == "" {
+			return fmt.Errorf("missing owner signature")
+		}
+		if err := Verify(owner, payload, sig); err != nil {
+			return fmt.Errorf("owner signature invalid: %w", err)
+		}
+		return nil
+	}
+	if policy == nil {
+		return fmt.Errorf("owner %s is a policy, but the record carries none", owner)
+	}
+	if sig != "" {
+		return fmt.Errorf("a policy owner signs with member signatures, not a single owner signature")
+	}
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+	if did, err := policy.DID(); err != nil || did != owner {
+		return fmt.Errorf("owner policy does not hash to the owner %s", owner)
+	}
+	return policy.VerifySigs(payload, sigs)
+}
@@ -0,0 +1,140 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func TestOwnerPolicyCard(t *testing.T) {
+	m1, _ := GenerateKeyPair()
+	m2, _ := GenerateKeyPair()
+	m3, _ := GenerateKeyPair()
+	agent, _ := GenerateKeyPair()
+	p, err := NewOwnerPolicy(2, m3.DID, m1.DID, m2.DID, m1.DID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p.Members) != 3 {
+		t.Fatalf("members should be de-duplicated: %v", p.Members)
+	}
+	if _, err := NewOwnerPolicy(4, m1.DID, m2.DID, m3.DID); err == nil {
+		t.Fatal("a threshold above the member count should be rejected")
+	}
+	did, _ := p.DID()
+	if !IsPolicyDID(did) {
+		t.Fatalf("policy DID %s lacks the policy prefix", did)
+	}
+
+	c := NewCard(agent.DID, did, "team-agent")
+	c.OwnerPolicy = p
+	if err := c.SignAgent(agent.Private); err != nil {
+		t.Fatal(err)
+	}
+	_ = c.AddOwnerSig(m1)
+	if err := c.Verify(); err == nil {
+		t.Fatal("1 of 2 required member signatures should not verify")
+	}
+	_ = c.AddOwnerSig(m1) // re-signing replaces, it does not count twice
+	if len(c.OwnerSigs) != 1 {
+		t.Fatalf("re-signing should replace the member's signature: %d", len(c.OwnerSigs))
+	}
+	_ = c.AddOwnerSig(m3)
+	if err := c.Verify(); err != nil {
+		t.Fatalf("2 of 3 members should verify: %v", err)
+	}
+
+	// The policy must be the one the owner DID names: swapping in a 1-of-1
+	// policy of one member is caught by the hash.
+	solo, _ := NewOwnerPolicy(1, m1.DID)
+	forged := *c
+	forged.OwnerPolicy = solo
+	if err := forged.Verify(); err == nil {
+		t.Fatal("a policy that does not hash to the owner DID should fail")
+	}
+	// A key owner cannot smuggle in member signatures instead of its own.
+	keyOwned := *c
+	keyOwned.Owner, keyOwned.OwnerPolicy = m1.DID, nil
+	if err := keyOwned.Verify(); err == nil {
+		t.Fatal("a did:key owner must sign with owner_sig")
+	}
+}
+
+func TestOwnerPolicyRotation(t *testing.T) {
+	m1, _ := GenerateKeyPair()
+	m2, _ := GenerateKeyPair()
+	outsider, _ := GenerateKeyPair()
+	a0, _ := GenerateKeyPair()
+	a1, _ := GenerateKeyPair()
+	p, _ := NewOwnerPolicy(2, m1.DID, m2.DID)
+	did, _ := p.DID()
+
+	r := NewRotation(did, a0.DID, a1.DID)
+	r.OwnerPolicy = p
+	_ = r.AddOwnerSig(m1)
+	_ = r.AddOwnerSig(outsider)
+	if err := r.Verify(); err == nil {
+		t.Fatal("a non-member signature should fail the rotation")
+	}
+	r.OwnerSigs = nil
+	_ = r.AddOwnerSig(m2)
+	_ = r.AddOwnerSig(m1)
+	if err := r.Verify(); err != nil {
+		t.Fatalf("2 of 2 members should verify: %v", err)
+	}
+	if err := VerifyLineage([]*Rotation{r}, a1.DID, did, OwnerSuccession{}); err != nil {
+		t.Fatalf("a policy-signed lineage should verify: %v", err)
+	}
+}
+
+// Revocations, owner rotations, guardian sets and vetoes owned by a policy are
+// authorized by its members, as cards and rotations are.
+func TestOwnerPolicyOwnerRecords(t *testing.T) {
+	m1, _ := GenerateKeyPair()
+	m2, _ := GenerateKeyPair()
+	m3, _ := GenerateKeyPair()
+	g, _ := GenerateKeyPair()
+	a0, _ := GenerateKeyPair()
+	p, _ := NewOwnerPolicy(2, m1.DID, m2.DID)
+	did, _ := p.DID()
+	next, _ := NewOwnerPolicy(2, m2.DID, m3.DID)
+	nextDID, _ := next.DID()
+
+	rev := NewRevocation(did, a0.DID, time.Now())
+	rev.OwnerPolicy = p
+	gs := NewGuardianSet(did, 1, g.DID)
+	gs.OwnerPolicy = p
+	veto := NewRecoveryVeto(did, "blake3:00")
+	veto.OwnerPolicy = p
+	rot := NewOwnerRotation(did, nextDID)
+	rot.OwnerPolicy, rot.NewOwnerPolicy = p, next
+	records := []struct {
+		name   string
+		add    func(*KeyPair) error
+		verify func() error
+	}{
+		{"revocation", rev.AddOwnerSig, rev.Verify},
+		{"guardian set", gs.AddOwnerSig, gs.Verify},
+		{"recovery veto", veto.AddOwnerSig, veto.Verify},
+		{"owner rotation", rot.AddOwnerSig, func() error {
+			_ = rot.AddNewOwnerSig(m2)
+			_ = rot.AddNewOwnerSig(m3)
+			return rot.Verify()
+		}},
+	}
+	for _, r := range records {
+		_ = r.add(m1)
+		if err := r.verify(); err == nil {
+			t.Fatalf("%s: 1 of 2 members should not verify", r.name)
+		}
+		_ = r.add(m2)
+		if err := r.verify(); err != nil {
+			t.Fatalf("%s: 2 of 2 members should verify: %v", r.name, err)
+		}
+	}
+
+	// The new side of an owner rotation needs its own threshold too.
+	rot.NewOwnerSigs = rot.NewOwnerSigs[:1]
+	if err := rot.Verify(); err == nil {
+		t.Fatal("an owner rotation short of the new policy's threshold should fail")
+	}
+}
@@ -0,0 +1,229 @@
+package core
+
+import (
+	"crypto"
+	"fmt"
+	"time"
+)
+
+// OwnerRotationSpec is the spec tag for a v0.1 owner key rotation record.
+const OwnerRotationSpec = "moltnet/owner-rotation/v0.1"
+
+// OwnerRotation hands an owner identity from one owner key to another. It is
+// signed by the old owner key, which authorizes the hand-over, and by the new
+// one, which proves it is held. From IssuedAt on the old key speaks for
+// nothing: every card it co-signed stays valid, but only the new key may
+// rotate, revoke or re-sign its agents.
+//
+// Either side may be an owner policy: its member signatures stand in for that
+// side's signature, so a team can hand its identity to a new membership.
+//
+// An owner key can be retired once: a registry keeps the first rotation of
+// it that it receives and refuses any other. A verifier that nonetheless holds
+// two different rotations of the same key — merged from registries that
+// received them in different orders — has no receive order to go by, so the
+// key is contested: it resolves to no current owner and authorizes nothing,
+// rather than to whichever record claims the earlier time.
+type OwnerRotation struct {
+	Spec           string       `json:"spec"`
+	OldOwner       string       `json:"old_owner"` // owner DID being retired
+	OwnerPolicy    *OwnerPolicy `json:"owner_policy,omitempty"`
+	NewOwner       string       `json:"new_owner"` // replacement owner DID
+	NewOwnerPolicy *OwnerPolicy `json:"new_owner_policy,omitempty"`
+	IssuedAt       string       `json:"issued_at"`
+	Sig            string       `json:"sig,omitempty"`            // old owner signature
+	NewSig         string       `json:"new_sig,omitempty"`        // new owner signature
+	OwnerSigs      []OwnerSig   `json:"owner_sigs,omitempty"`     // old owner policy member signatures
+	NewOwnerSigs   []OwnerSig   `json:"new_owner_sigs,omitempty"` // new owner policy member signatures
+}
+
+// NewOwnerRotation builds an unsigned owner rotation with the spec tag and
+// timestamp set.
+func NewOwnerRotation(oldOwnerDID, newOwnerDID string) *OwnerRotation {
+	return &OwnerRotation{
+		Spec:     OwnerRotationSpec,
+		OldOwner: oldOwnerDID,
+		NewOwner: newOwnerDID,
+		IssuedAt: time.Now().UTC().Format(time.RFC3339),
+	}
+}
+
+// SigningPayload is the canonical owner rotation without its signatures. All
+// signatures are over this same payload.
+func (r *OwnerRotation) SigningPayload() ([]byte, error) {
+	return CanonicalizeWithout(r, "sig", "new_sig", "owner_sigs", "new_owner_sigs")
+}
+
+// Hash returns the content address of the owner rotation record.
+func (r *OwnerRotation) Hash() (string, error) {
+	payload, err := r.SigningPayload()
+	if err != nil {
+		return "", err
+	}
+	return HashBytes(payload), nil
+}
+
+// Sign fills in the old and new owner signatures.
+func (r *OwnerRotation) Sign(oldOwnerKey, newOwnerKey crypto.Signer) error {
+	payload, err := r.SigningPayload()
+	if err != nil {
+		return err
+	}
+	if r.Sig, err = Sign(oldOwnerKey, payload); err != nil {
+		return err
+	}
+	r.NewSig, err = Sign(newOwnerKey, payload)
+	return err
+}
+
+// AddOwnerSig adds member's signature toward the old owner policy, replacing
+// any it made before.
+func (r *OwnerRotation) AddOwnerSig(member *KeyPair) error {
+	payload, err := r.SigningPayload()
+	if err != nil {
+		return err
+	}
+	r.OwnerSigs, err = addOwnerSig(r.OwnerSigs, member, payload)
+	return err
+}
+
+// AddNewOwnerSig adds member's signature toward the new owner policy,
+// replacing any it made before.
+func (r *OwnerRotation) AddNewOwnerSig(member *KeyPair) error {
+	payload, err := r.SigningPayload()
+	if err != nil {
+		return err
+	}
+	r.NewOwnerSigs, err = addOwnerSig(r.NewOwnerSigs, member, payload)
+	return err
+}
+
+// Verify checks structural invariants and both sides' authorization.
+func (r *OwnerRotation) Verify() error {
+	if r.Spec != OwnerRotationSpec {
+		return fmt.Errorf("owner rotation: unexpected spec %q", r.Spec)
+	}
+	if r.OldOwner == "" || r.NewOwner == "" {
+		return fmt.Errorf("owner rotation: old_owner and new_owner are required")
+	}
+	if r.OldOwner == r.NewOwner {
+		return fmt.Errorf("owner rotation: old_owner and new_owner must differ")
+	}
+	if _, err := time.Parse(time.RFC3339, r.IssuedAt); err != nil {
+		return fmt.Errorf("owner rotation: issued_at must be an RFC 3339 timestamp")
+	}
+	payload, err := r.SigningPayload()
+	if err != nil {
+		return err
+	}
+	if err := verifyOwner(r.OldOwner, r.OwnerPolicy, r.Sig, r.OwnerSigs, payload); err != nil {
+		return fmt.Errorf("owner rotation: old_owner: %w", err)
+	}
+	if err := verifyOwner(r.NewOwner, r.NewOwnerPolicy, r.NewSig, r.NewOwnerSigs, payload); err != nil {
+		return fmt.Errorf("owner rotation: new_owner: %w", err)
+	}
+	return nil
+}
+
+// OwnerSuccession resolves a set of verified owner rotations: which key
+// currently speaks for an owner identity, and when each retired key stopped.
+// The zero value holds no rotations: every owner key is current.
+type OwnerSuccession struct {
+	next map[string][]*OwnerRotation // old owner → the rotations retiring it
+
+	// Received, when set, maps rotation hashes to when the registry recorded
+	// them. A rotation found there retires its key from that time rather
+	// than from its signed issued_at, which the retired key's holder could
+	// otherwise race by backdating what it signs.
+	Received map[string]time.Time
+}
+
+// NewOwnerSuccession indexes rots. Repeats of one record count once.
+func NewOwnerSuccession(rots []*OwnerRotation) OwnerSuccession {
+	s := OwnerSuccession{next: map[string][]*OwnerRotation{}}
+	seen := map[string]bool{}
+	for _, r := range rots {
+		h, err := r.Hash()
+		if err != nil || seen[h] {
+			continue
+		}
+		seen[h] = true
+		s.next[r.OldOwner] = append(s.next[r.OldOwner], r)
+	}
+	return s
+}
+
+// Current follows rotations from owner to the key that speaks for it now. An
+// owner never rotated resolves to itself. It is an error if a key on the way
+// is contested or the rotations form a cycle.
+func (s OwnerSuccession) Current(owner string) (string, error) {
+	seen := map[string]bool{owner: true}
+	current := owner
+	for {
+		rs := s.next[current]
+		switch {
+		case len(rs) == 0:
+			return current, nil
+		case len(rs) > 1:
+			return "", fmt.Errorf("owner rotation: %s is contested: rotated to both %s and %s", current, rs[0].NewOwner, rs[1].NewOwner)
+		}
+		n := rs[0].NewOwner
+		if seen[n] {
+			return "", fmt.Errorf("owner rotation cycle detected at %s", n)
+		}
+		seen[n] = true
+		current = n
+	}
+}
+
+// Resolve is Current, falling back to owner itself when the succession cannot
+// be resolved — for grouping agents by controller, where a contested owner is
+// still one party.
+func (s OwnerSuccession) Resolve(owner string) string {
+	if c, err := s.Current(owner); err == nil {
+		return c
+	}
+	return owner
+}
+
+// RetiredAt reports when owner was rotated away, if it was: the earliest
+// rotation retiring it, contested or not, as of when it was received if that
+// is known.
+func (s OwnerSuccession) RetiredAt(owner string) (time.Time, bool) {
+	var out time.Time
+	for _, r := range s.next[owner] {
+		t, err := time.Parse(time.RFC3339, r.IssuedAt)
+		if h, herr := r.Hash(); herr == nil && !s.Received[h].IsZero() {
+			t, err = s.Received[h], nil
+		}
+		if err != nil {
+			continue
+		}
+		if out.IsZero() || t.Before(out) {
+			out = t
+		}
+	}
+	return out, !out.IsZero()
+}
+
+// Authorizes reports whether signer spoke for owner's identity at at (RFC
+// 3339): both resolve to the same current key and signer had not been retired
+// by then. A registry judging a record as it arrives passes the time of
+// arrival, not the time the record claims. A record whose time does not parse
+// is not authorized by a retired key.
+func (s OwnerSuccession) Authorizes(signer, owner, at string) bool {
+	cs, err := s.Current(signer)
+	if err != nil {
+		return false
+	}
+	if co, err := s.Current(owner); err != nil || co != cs {
+		return false
+	}
+	if retired, ok := s.RetiredAt(signer); ok {
+		t, err := time.Parse(time.RFC3339, at)
+		if err != nil || !t.Before(retired) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,93 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func TestOwnerRotationSignVerify(t *testing.T) {
+	o1, _ := GenerateKeyPair()
+	o2, _ := GenerateKeyPair()
+	r := NewOwnerRotation(o1.DID, o2.DID)
+	if err := r.Sign(o1.Private, o2.Private); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Verify(); err != nil {
+		t.Fatalf("valid owner rotation rejected: %v", err)
+	}
+
+	// The new key must prove it is held: naming someone else's key fails.
+	mallory, _ := GenerateKeyPair()
+	bad := NewOwnerRotation(o1.DID, o2.DID)
+	if err := bad.Sign(o1.Private, mallory.Private); err != nil {
+		t.Fatal(err)
+	}
+	if err := bad.Verify(); err == nil {
+		t.Fatal("expected a rotation not signed by the new owner to fail")
+	}
+	bad.NewSig = ""
+	if err := bad.Verify(); err == nil {
+		t.Fatal("expected a rotation missing the new owner signature to fail")
+	}
+}
+
+func TestOwnerSuccession(t *testing.T) {
+	o1, _ := GenerateKeyPair()
+	o2, _ := GenerateKeyPair()
+	o3, _ := GenerateKeyPair()
+	at := time.Date(2026, 8, 1, 12, 0, 0, 0, time.UTC)
+	rotate := func(from, to *KeyPair, when time.Time) *OwnerRotation {
+		r := NewOwnerRotation(from.DID, to.DID)
+		r.IssuedAt = when.Format(time.RFC3339)
+		_ = r.Sign(from.Private, to.Private)
+		return r
+	}
+	r12, r23 := rotate(o1, o2, at), rotate(o2, o3, at.AddDate(0, 1, 0))
+	s := NewOwnerSuccession([]*OwnerRotation{r23, r12, r12})
+
+	if got, err := s.Current(o1.DID); err != nil || got != o3.DID {
+		t.Fatalf("current: got %s, %v; want %s", got, err, o3.DID)
+	}
+	before, after := at.Add(-time.Hour).Format(time.RFC3339), at.Add(time.Hour).Format(time.RFC3339)
+	if !s.Authorizes(o1.DID, o3.DID, before) {
+		t.Fatal("o1 spoke for the identity before it was retired")
+	}
+	if s.Authorizes(o1.DID, o3.DID, after) {
+		t.Fatal("a retired owner key must not authorize anything afterwards")
+	}
+	if !s.Authorizes(o3.DID, o1.DID, after) {
+		t.Fatal("the current key speaks for cards its predecessors signed")
+	}
+	stranger, _ := GenerateKeyPair()
+	if s.Authorizes(stranger.DID, o1.DID, before) {
+		t.Fatal("an unrelated key must not be authorized")
+	}
+	if !(OwnerSuccession{}).Authorizes(o1.DID, o1.DID, after) {
+		t.Fatal("with no rotations an owner speaks for itself")
+	}
+
+	// Recorded an hour late, r12 retires o1 from then: what o1 signed in the
+	// meantime stands, and claiming an earlier time buys nothing after it.
+	s.Received = map[string]time.Time{}
+	h12, _ := r12.Hash()
+	s.Received[h12] = at.Add(time.Hour)
+	if !s.Authorizes(o1.DID, o3.DID, at.Add(time.Minute).Format(time.RFC3339)) {
+		t.Fatal("o1 spoke for the identity until its retirement was recorded")
+	}
+	if s.Authorizes(o1.DID, o3.DID, at.Add(2*time.Hour).Format(time.RFC3339)) {
+		t.Fatal("a retired owner key must not authorize anything after it was recorded")
+	}
+
+	// A thief holding o1 rotates it too: o1's identity is contested, not won.
+	thief, _ := GenerateKeyPair()
+	s = NewOwnerSuccession([]*OwnerRotation{r12, rotate(o1, thief, at.Add(-time.Minute))})
+	if _, err := s.Current(o1.DID); err == nil {
+		t.Fatal("expected a contested owner to resolve to no one")
+	}
+	if s.Authorizes(thief.DID, o1.DID, after) || s.Authorizes(o2.DID, o1.DID, after) {
+		t.Fatal("a contested owner authorizes nothing")
+	}
+	if s.Resolve(o1.DID) != o1.DID {
+		t.Fatal("Resolve should fall back to the owner itself when contested")
+	}
+}
@@ -0,0 +1,422 @@
+package core
+
+import (
+	"crypto"
+	"fmt"
+	"time"
+)
+
+// Spec tags for the v0.1 social recovery records.
+const (
+	GuardianSetSpec      = "moltnet/guardian-set/v0.1"
+	RecoverySpec         = "moltnet/recovery/v0.1"
+	RecoveryVetoSpec     = "moltnet/recovery-veto/v0.1"
+	DefaultRecoveryDelay = 7 // days
+)
+
+// GuardianSet is an owner's standing instruction for losing its key: Threshold
+// of Guardians may hand the owner identity to a new key, DelayDays after they
+// publish the hand-over. It is signed by the owner key while it is still held.
+// An owner's latest guardian set replaces its earlier ones. A policy owner signs
+// it with a threshold of its members.
+type GuardianSet struct {
+	Spec        string       `json:"spec"`
+	Owner       string       `json:"owner"` // owner DID the guardians may recover
+	OwnerPolicy *OwnerPolicy `json:"owner_policy,omitempty"`
+	Guardians   []string     `json:"guardians"` // guardian DIDs, sorted, no repeats
+	Threshold   int          `json:"threshold"`
+	DelayDays   int          `json:"delay_days"` // waiting period during which the owner may veto
+	IssuedAt    string       `json:"issued_at"`
+	Sig         string       `json:"sig,omitempty"`        // owner signature
+	OwnerSigs   []OwnerSig   `json:"owner_sigs,omitempty"` // policy member signatures
+}
+
+// NewGuardianSet builds an unsigned guardian set with the spec tag, timestamp
+// and the default waiting period set, guardians in canonical order.
+func NewGuardianSet(ownerDID string, threshold int, guardians ...string) *GuardianSet {
+	return &GuardianSet{
+		Spec:      GuardianSetSpec,
+		Owner:     ownerDID,
+		Guardians: sortedKeys(guardians),
+		Threshold: threshold,
+		DelayDays: DefaultRecoveryDelay,
+		IssuedAt:  time.Now().UTC().Format(time.RFC3339),
+	}
+}
+
+// SigningPayload is the canonical guardian set without its signatures.
+func (g *GuardianSet) SigningPayload() ([]byte, error) {
+	return CanonicalizeWithout(g, "sig", "owner_sigs")
+}
+
+// Hash returns the content address of the guardian set, which recoveries name.
+func (g *GuardianSet) Hash() (string, error) {
+	payload, err := g.SigningPayload()
+	if err != nil {
+		return "", err
+	}
+	return HashBytes(payload), nil
+}
+
+// Sign fills in the owner signature.
+func (g *GuardianSet) Sign(ownerKey crypto.Signer) error {
+	payload, err := g.SigningPayload()
+	if err != nil {
+		return err
+	}
+	g.Sig, err = Sign(ownerKey, payload)
+	return err
+}
+
+// AddOwnerSig adds member's signature toward the owner policy, replacing any
+// it made before.
+func (g *GuardianSet) AddOwnerSig(member *KeyPair) error {
+	payload, err := g.SigningPayload()
+	if err != nil {
+		return err
+	}
+	g.OwnerSigs, err = addOwnerSig(g.OwnerSigs, member, payload)
+	return err
+}
+
+// Verify checks structural invariants and the owner's authorization.
+func (g *GuardianSet) Verify() error {
+	if g.Spec != GuardianSetSpec {
+		return fmt.Errorf("guardian set: unexpected spec %q", g.Spec)
+	}
+	if g.Owner == "" {
+		return fmt.Errorf("guardian set: owner is required")
+	}
+	if err := validateKeySet("guardian set", "guardian", g.Guardians, g.Threshold); err != nil {
+		return err
+	}
+	for _, k := range g.Guardians {
+		if k == g.Owner {
+			return fmt.Errorf("guardian set: the owner cannot be its own guardian")
+		}
+	}
+	if g.DelayDays < 1 {
+		return fmt.Errorf("guardian set: delay_days must be at least 1")
+	}
+	if _, err := time.Parse(time.RFC3339, g.IssuedAt); err != nil {
+		return fmt.Errorf("guardian set: issued_at must be an RFC 3339 timestamp")
+	}
+	payload, err := g.SigningPayload()
+	if err != nil {
+		return err
+	}
+	if err := verifyOwner(g.Owner, g.OwnerPolicy, g.Sig, g.OwnerSigs, payload); err != nil {
+		return fmt.Errorf("guardian set: %w", err)
+	}
+	return nil
+}
+
+// Recovery hands a lost owner identity to a new key on the authority of the
+// owner's guardians. Unlike an owner rotation it is not signed by the old key,
+// so it only takes effect after the guardian set's waiting period, and the old
+// key — if it turns out not to be lost after all — can veto it until then.
+type Recovery struct {
+	Spec         string     `json:"spec"`
+	Owner        string     `json:"owner"`        // owner DID being recovered
+	NewOwner     string     `json:"new_owner"`    // replacement owner DID
+	GuardianSet  string     `json:"guardian_set"` // hash of the guardian set invoked
+	IssuedAt     string     `json:"issued_at"`
+	NewSig       string     `json:"new_sig,omitempty"`       // new owner signature
+	GuardianSigs []OwnerSig `json:"guardian_sigs,omitempty"` // guardian signatures
+}
+
+// NewRecovery builds an unsigned recovery with the spec tag and timestamp set.
+func NewRecovery(ownerDID, newOwnerDID, guardianSetHash string) *Recovery {
+	return &Recovery{
+		Spec:        RecoverySpec,
+		Owner:       ownerDID,
+		NewOwner:    newOwnerDID,
+		GuardianSet: guardianSetHash,
+		IssuedAt:    time.Now().UTC().Format(time.RFC3339),
+	}
+}
+
+// SigningPayload is the canonical recovery without its signatures. The new
+// owner and every guardian sign this same payload.
+func (r *Recovery) SigningPayload() ([]byte, error) {
+	return CanonicalizeWithout(r, "new_sig", "guardian_sigs")
+}
+
+// Hash returns the content address of the recovery; vetoes name it.
+func (r *Recovery) Hash() (string, error) {
+	payload, err := r.SigningPayload()
+	if err != nil {
+		return "", err
+	}
+	return HashBytes(payload), nil
+}
+
+// SignNew fills in the new owner's signature, proving the key is held.
+func (r *Recovery) SignNew(newOwnerKey crypto.Signer) error {
+	payload, err := r.SigningPayload()
+	if err != nil {
+		return err
+	}
+	r.NewSig, err = Sign(newOwnerKey, payload)
+	return err
+}
+
+// AddGuardianSig adds guardian's signature, replacing any it made before.
+func (r *Recovery) AddGuardianSig(guardian *KeyPair) error {
+	payload, err := r.SigningPayload()
+	if err != nil {
+		return err
+	}
+	r.GuardianSigs, err = addOwnerSig(r.GuardianSigs, guardian, payload)
+	return err
+}
+
+// Verify checks structural invariants and the new owner signature. The
+// guardian signatures are checked against the guardian set by VerifyWith.
+func (r *Recovery) Verify() error {
+	if r.Spec != RecoverySpec {
+		return fmt.Errorf("recovery: unexpected spec %q", r.Spec)
+	}
+	if r.Owner == "" || r.NewOwner == "" || r.GuardianSet == "" {
+		return fmt.Errorf("recovery: owner, new_owner and guardian_set are required")
+	}
+	if r.Owner == r.NewOwner {
+		return fmt.Errorf("recovery: owner and new_owner must differ")
+	}
+	if _, err := time.Parse(time.RFC3339, r.IssuedAt); err != nil {
+		return fmt.Errorf("recovery: issued_at must be an RFC 3339 timestamp")
+	}
+	if r.NewSig == "" {
+		return fmt.Errorf("recovery: missing new owner signature")
+	}
+	payload, err := r.SigningPayload()
+	if err != nil {
+		return err
+	}
+	if err := Verify(r.NewOwner, payload, r.NewSig); err != nil {
+		return fmt.Errorf("recovery: new owner signature invalid: %w", err)
+	}
+	return nil
+}
+
+// VerifyWith checks r against the guardian set it invokes: set is the owner's,
+// hashes to r.GuardianSet, and a threshold of its guardians signed r.
+func (r *Recovery) VerifyWith(set *GuardianSet) error {
+	if err := r.Verify(); err != nil {
+		return err
+	}
+	if h, err := set.Hash(); err != nil || h != r.GuardianSet {
+		return fmt.Errorf("recovery: guardian set does not match %s", r.GuardianSet)
+	}
+	if set.Owner != r.Owner {
+		return fmt.Errorf("recovery: guardian set is for %s, not %s", set.Owner, r.Owner)
+	}
+	payload, err := r.SigningPayload()
+	if err != nil {
+		return err
+	}
+	return verifyThreshold("recovery", "guardian", set.Guardians, set.Threshold, payload, r.GuardianSigs)
+}
+
+// RecoveryVeto is the owner's objection to a recovery of its identity: the key
+// was not lost. Signed by the owner key the recovery would retire, or a
+// threshold of the owner policy's members.
+type RecoveryVeto struct {
+	Spec        string       `json:"spec"`
+	Owner       string       `json:"owner"`
+	OwnerPolicy *OwnerPolicy `json:"owner_policy,omitempty"`
+	Recovery    string       `json:"recovery"` // hash of the vetoed recovery
+	IssuedAt    string       `json:"issued_at"`
+	Sig         string       `json:"sig,omitempty"`        // owner signature
+	OwnerSigs   []OwnerSig   `json:"owner_sigs,omitempty"` // policy member signatures
+}
+
+// NewRecoveryVeto builds an unsigned veto with the spec tag and timestamp set.
+func NewRecoveryVeto(ownerDID, recoveryHash string) *RecoveryVeto {
+	return &RecoveryVeto{
+		Spec:     RecoveryVetoSpec,
+		Owner:    ownerDID,
+		Recovery: recoveryHash,
+		IssuedAt: time.Now().UTC().Format(time.RFC3339),
+	}
+}
+
+// SigningPayload is the canonical veto without its signatures.
+func (v *RecoveryVeto) SigningPayload() ([]byte, error) {
+	return CanonicalizeWithout(v, "sig", "owner_sigs")
+}
+
+// Hash returns the content address of the veto.
+func (v *RecoveryVeto) Hash() (string, error) {
+	payload, err := v.SigningPayload()
+	if err != nil {
+		return "", err
+	}
+	return HashBytes(payload), nil
+}
+
+// Sign fills in the owner signature.
+func (v *RecoveryVeto) Sign(ownerKey crypto.Signer) error {
+	payload, err := v.SigningPayload()
+	if err != nil {
+		return err
+	}
+	v.Sig, err = Sign(ownerKey, payload)
+	return err
+}
+
+// AddOwnerSig adds member's signature toward the owner policy, replacing any
+// it made before.
+func (v *RecoveryVeto) AddOwnerSig(member *KeyPair) error {
+	payload, err := v.SigningPayload()
+	if err != nil {
+		return err
+	}
+	v.OwnerSigs, err = addOwnerSig(v.OwnerSigs, member, payload)
+	return err
+}
+
+// Verify checks structural invariants and the owner's authorization.
+func (v *RecoveryVeto) Verify() error {
+	if v.Spec != RecoveryVetoSpec {
+		return fmt.Errorf("recovery veto: unexpected spec %q", v.Spec)
+	}
+	if v.Owner == "" || v.Recovery == "" {
+		return fmt.Errorf("recovery veto: owner and recovery are required")
+	}
+	if _, err := time.Parse(time.RFC3339, v.IssuedAt); err != nil {
+		return fmt.Errorf("recovery veto: issued_at must be an RFC 3339 timestamp")
+	}
+	payload, err := v.SigningPayload()
+	if err != nil {
+		return err
+	}
+	if err := verifyOwner(v.Owner, v.OwnerPolicy, v.Sig, v.OwnerSigs, payload); err != nil {
+		return fmt.Errorf("recovery veto: %w", err)
+	}
+	return nil
+}
+
+// Recovery statuses.
+const (
+	RecoveryPending  = "pending"
+	RecoveryComplete = "complete"
+	RecoveryVetoed   = "vetoed"
+	RecoveryInvalid  = "invalid"
+)
+
+// RecoveryState is a recovery as judged at some instant.
+type RecoveryState struct {
+	Hash        string    `json:"hash"`
+	Recovery    *Recovery `json:"recovery"`
+	Status      string    `json:"status"`
+	EffectiveAt string    `json:"effective_at,omitempty"` // RFC 3339; unset if invalid
+	Reason      string    `json:"reason,omitempty"`       // why an invalid recovery does not hold
+}
+
+// Recoveries judges recs at now. A recovery holds only if it invokes the
+// guardian set its owner had in force when the recovery was published — the
+// latest one issued by then — and meets that set's threshold. A set issued
+// later does not undo it. It takes effect the set's waiting period after it was
+// published; a veto its owner issued before then stops it, and otherwise it is
+// complete from then on.
+//
+// received gives the time each recovery and veto (by hash) was first seen,
+// where the caller knows it better than the record's own issued_at, which its
+// signers chose; it may be nil. Each counts from the later of the two, so a
+// veto backdated into the waiting period after it closed stops nothing.
+func Recoveries(sets []*GuardianSet, recs []*Recovery, vetoes []*RecoveryVeto, received map[string]time.Time, now time.Time) []RecoveryState {
+	owned := map[string][]*GuardianSet{}
+	for _, g := range sets {
+		if g.Verify() == nil {
+			owned[g.Owner] = append(owned[g.Owner], g)
+		}
+	}
+	vetoedAt := map[string]time.Time{}
+	for _, v := range vetoes {
+		if v.Verify() != nil {
+			continue
+		}
+		t, _ := time.Parse(time.RFC3339, v.IssuedAt)
+		if h, err := v.Hash(); err == nil && received[h].After(t) {
+			t = received[h]
+		}
+		key := v.Owner + " " + v.Recovery
+		if prev, ok := vetoedAt[key]; !ok || t.Before(prev) {
+			vetoedAt[key] = t
+		}
+	}
+	var out []RecoveryState
+	for _, r := range recs {
+		h, err := r.Hash()
+		if err != nil {
+			continue
+		}
+		st := RecoveryState{Hash: h, Recovery: r, Status: RecoveryInvalid}
+		from, _ := time.Parse(time.RFC3339, r.IssuedAt)
+		if p, ok := received[h]; ok && p.After(from) {
+			from = p
+		}
+		set := guardianSetAt(owned[r.Owner], from)
+		if set == nil {
+			st.Reason = "the owner had published no guardian set"
+			out = append(out, st)
+			continue
+		}
+		if err := r.VerifyWith(set); err != nil {
+			st.Reason = err.Error()
+			out = append(out, st)
+			continue
+		}
+		effective := from.AddDate(0, 0, set.DelayDays)
+		st.EffectiveAt = effective.UTC().Format(time.RFC3339)
+		switch vt, vetoed := vetoedAt[r.Owner+" "+h]; {
+		case vetoed && vt.Before(effective):
+			st.Status = RecoveryVetoed
+		case now.Before(effective):
+			st.Status = RecoveryPending
+		default:
+			st.Status = RecoveryComplete
+		}
+		out = append(out, st)
+	}
+	return out
+}
+
+// guardianSetAt returns the latest of sets issued at or before t, or nil.
+func guardianSetAt(sets []*GuardianSet, t time.Time) *GuardianSet {
+	var in *GuardianSet
+	var inAt time.Time
+	for _, g := range sets {
+		at, _ := time.Parse(time.RFC3339, g.IssuedAt)
+		if !at.After(t) && (in == nil || at.After(inAt)) {
+			in, inAt = g, at
+		}
+	}
+	return in
+}
+
+// AsOwnerRotation is the owner succession a complete recovery amounts to: the
+// owner key retired in favour of the new one as of the effective time. The
+// record is unsigned — its authority is the guardians' — and only ever joins
+// an OwnerSuccession, never the wire.
+func (st RecoveryState) AsOwnerRotation() *OwnerRotation {
+	return &OwnerRotation{
+		Spec:     OwnerRotationSpec,
+		OldOwner: st.Recovery.Owner,
+		NewOwner: st.Recovery.NewOwner,
+		IssuedAt: st.EffectiveAt,
+	}
+}
+
+// RecoveredOwners returns the owner rotations of the complete recoveries in
+// states, for NewOwnerSuccession.
+func RecoveredOwners(states []RecoveryState) []*OwnerRotation {
+	var out []*OwnerRotation
+	for _, st := range states {
+		if st.Status == RecoveryComplete {
+			out = append(out, st.AsOwnerRotation())
+		}
+	}
+	return out
+}
@@ -0,0 +1,91 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRecovery(t *testing.T) {
+	owner, _ := GenerateKeyPair()
+	fresh, _ := GenerateKeyPair()
+	g1, _ := GenerateKeyPair()
+	g2, _ := GenerateKeyPair()
+	g3, _ := GenerateKeyPair()
+	at := time.Date(2026, 8, 1, 12, 0, 0, 0, time.UTC)
+
+	set := NewGuardianSet(owner.DID, 2, g3.DID, g1.DID, g2.DID)
+	set.IssuedAt = at.AddDate(0, -1, 0).Format(time.RFC3339)
+	if err := set.Sign(owner.Private); err != nil {
+		t.Fatal(err)
+	}
+	if err := set.Verify(); err != nil {
+		t.Fatalf("valid guardian set rejected: %v", err)
+	}
+	setHash, _ := set.Hash()
+
+	rec := NewRecovery(owner.DID, fresh.DID, setHash)
+	rec.IssuedAt = at.Format(time.RFC3339)
+	_ = rec.SignNew(fresh.Private)
+	_ = rec.AddGuardianSig(g1)
+	judge := func(now time.Time, vetoes ...*RecoveryVeto) RecoveryState {
+		return Recoveries([]*GuardianSet{set}, []*Recovery{rec}, vetoes, nil, now)[0]
+	}
+	if st := judge(at); st.Status != RecoveryInvalid {
+		t.Fatalf("1 of 2 guardians: status %s, want invalid", st.Status)
+	}
+	_ = rec.AddGuardianSig(g2)
+	if st := judge(at.Add(time.Hour)); st.Status != RecoveryPending {
+		t.Fatalf("inside the waiting period: status %s (%s), want pending", st.Status, st.Reason)
+	}
+	done := judge(at.AddDate(0, 0, DefaultRecoveryDelay))
+	if done.Status != RecoveryComplete {
+		t.Fatalf("after the waiting period: status %s, want complete", done.Status)
+	}
+	succ := NewOwnerSuccession(RecoveredOwners([]RecoveryState{done}))
+	if cur, err := succ.Current(owner.DID); err != nil || cur != fresh.DID {
+		t.Fatalf("a complete recovery should hand the owner to the new key: %s, %v", cur, err)
+	}
+
+	// The owner still holds its key and objects in time.
+	recHash, _ := rec.Hash()
+	veto := NewRecoveryVeto(owner.DID, recHash)
+	veto.IssuedAt = at.AddDate(0, 0, 3).Format(time.RFC3339)
+	_ = veto.Sign(owner.Private)
+	if st := judge(at.AddDate(0, 1, 0), veto); st.Status != RecoveryVetoed {
+		t.Fatalf("vetoed in time: status %s, want vetoed", st.Status)
+	}
+	late := NewRecoveryVeto(owner.DID, recHash)
+	late.IssuedAt = at.AddDate(0, 0, 30).Format(time.RFC3339)
+	_ = late.Sign(owner.Private)
+	if st := judge(at.AddDate(0, 1, 0), late); st.Status != RecoveryComplete {
+		t.Fatalf("a veto after the recovery took effect is too late: status %s", st.Status)
+	}
+
+	// A veto that arrives after the recovery took effect is too late however
+	// it is dated.
+	vetoHash, _ := veto.Hash()
+	received := map[string]time.Time{vetoHash: at.AddDate(0, 0, 30)}
+	if st := Recoveries([]*GuardianSet{set}, []*Recovery{rec}, []*RecoveryVeto{veto}, received, at.AddDate(0, 1, 0))[0]; st.Status != RecoveryComplete {
+		t.Fatalf("a backdated veto received late: status %s, want complete", st.Status)
+	}
+
+	// A backdated recovery waits from when it was published, not issued.
+	published := map[string]time.Time{recHash: at.AddDate(0, 0, 5)}
+	if st := Recoveries([]*GuardianSet{set}, []*Recovery{rec}, nil, published, at.AddDate(0, 0, 8))[0]; st.Status != RecoveryPending {
+		t.Fatalf("waiting period should run from publication: status %s", st.Status)
+	}
+
+	// A newer guardian set replaces the one the recovery invoked.
+	newer := NewGuardianSet(owner.DID, 1, g3.DID)
+	newer.IssuedAt = at.Add(-time.Hour).Format(time.RFC3339)
+	_ = newer.Sign(owner.Private)
+	if st := Recoveries([]*GuardianSet{set, newer}, []*Recovery{rec}, nil, nil, at.AddDate(0, 1, 0))[0]; st.Status != RecoveryInvalid {
+		t.Fatalf("recovery under a replaced guardian set: status %s, want invalid", st.Status)
+	}
+	// One issued after the recovery was published does not undo it.
+	newer.IssuedAt = at.AddDate(0, 0, 10).Format(time.RFC3339)
+	_ = newer.Sign(owner.Private)
+	if st := Recoveries([]*GuardianSet{set, newer}, []*Recovery{rec}, nil, nil, at.AddDate(0, 1, 0))[0]; st.Status != RecoveryComplete {
+		t.Fatalf("guardian set issued after the recovery: status %s, want complete", st.Status)
+	}
+}
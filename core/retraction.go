@@ -0,0 +1,117 @@
+package core
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// A retraction is an attestation (type "retraction") by which an issuer
+// withdraws one of its own earlier attestations: body.retracts holds the
+// withdrawn attestation's hash, and body.reason may say why. It chains like
+// any other attestation, so nothing is deleted — the withdrawn record stays
+// in the chain, marked retracted, and scores treat it as if it had never been
+// issued.
+
+// Retracts returns the hash a retraction withdraws, or "" if a is not one.
+func (a *Attestation) Retracts() string {
+	if a.Type != TypeRetraction {
+		return ""
+	}
+	h, _ := a.Body["retracts"].(string)
+	return h
+}
+
+// CheckRetraction checks that r may withdraw target: r names target's hash,
+// comes from the same issuer (the same principal, for delegated attestations;
+// the same issuers, in any order, for co-signed ones) and is about the same
+// subject. A retraction cannot itself be retracted; an
+// issuer that withdrew in error attests again.
+func CheckRetraction(r, target *Attestation) error {
+	h, err := target.Hash()
+	if err != nil {
+		return err
+	}
+	switch {
+	case r.Retracts() != h:
+		return fmt.Errorf("retraction: does not name %s", h)
+	case target.Type == TypeRetraction:
+		return fmt.Errorf("retraction: a retraction cannot be retracted")
+	case !sameSet(r.Principals(), target.Principals()):
+		return fmt.Errorf("retraction: %s can only retract its own attestations", strings.Join(r.Principals(), ", "))
+	case r.Subject != target.Subject:
+		return fmt.Errorf("retraction: subject %s differs from the retracted attestation's %s", r.Subject, target.Subject)
+	}
+	return nil
+}
+
+// Retractions maps the hash of each retracted attestation to the retraction
+// that withdrew it. A nil Retractions retracts nothing.
+type Retractions map[string]*Attestation
+
+// NewRetractions collects the retractions in atts that take effect: those
+// whose target is also in atts and passes CheckRetraction. A retraction of an
+// attestation atts does not hold is ignored, since it cannot be checked.
+func NewRetractions(atts []*Attestation) Retractions {
+	var out Retractions
+	byHash := map[string]*Attestation{}
+	for _, a := range atts {
+		if a.Type == TypeRetraction {
+			continue
+		}
+		if h, err := a.Hash(); err == nil {
+			byHash[h] = a
+		}
+	}
+	for _, r := range atts {
+		target := byHash[r.Retracts()]
+		if target == nil || CheckRetraction(r, target) != nil {
+			continue
+		}
+		if out == nil {
+			out = Retractions{}
+		}
+		if _, seen := out[r.Retracts()]; !seen {
+			out[r.Retracts()] = r
+		}
+	}
+	return out
+}
+
+// Retracted returns the retraction that withdrew a, or nil.
+func (rs Retractions) Retracted(a *Attestation) *Attestation {
+	if len(rs) == 0 {
+		return nil
+	}
+	h, err := a.Hash()
+	if err != nil {
+		return nil
+	}
+	return rs[h]
+}
+
+// DropRetracted returns atts without the attestations a retraction in atts
+// withdraws, in order. The retractions themselves are kept: they carry no
+// weight, but they are part of their issuers' chains. With nothing retracted
+// it returns atts itself.
+func DropRetracted(atts []*Attestation) []*Attestation {
+	rs := NewRetractions(atts)
+	if len(rs) == 0 {
+		return atts
+	}
+	out := make([]*Attestation, 0, len(atts))
+	for _, a := range atts {
+		if rs.Retracted(a) == nil {
+			out = append(out, a)
+		}
+	}
+	return out
+}
+
+// sameSet reports whether a and b hold the same DIDs, in any order.
+func sameSet(a, b []string) bool {
+	a, b = slices.Clone(a), slices.Clone(b)
+	slices.Sort(a)
+	slices.Sort(b)
+	return slices.Equal(a, b)
+}
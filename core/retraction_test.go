@@ -0,0 +1,52 @@
+package core
+
+import "testing"
+
+// A retraction chains after the record it withdraws and takes effect only for
+// the same issuer and subject.
+func TestRetraction(t *testing.T) {
+	issuer, _ := GenerateKeyPair()
+	other, _ := GenerateKeyPair()
+	subject, _ := GenerateKeyPair()
+
+	d := NewAttestation(TypeTaskDisputed, issuer.DID, subject.DID)
+	_ = d.Sign(issuer.Private)
+	dh, _ := d.Hash()
+	r := NewAttestation(TypeRetraction, issuer.DID, subject.DID)
+	r.Prev = dh
+	r.Body = map[string]any{"retracts": dh, "reason": "filed in error"}
+	if err := r.Sign(issuer.Private); err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyAll([]*Attestation{d, r}); err != nil {
+		t.Fatalf("a retraction should chain like any attestation: %v", err)
+	}
+	if err := CheckRetraction(r, d); err != nil {
+		t.Fatalf("valid retraction rejected: %v", err)
+	}
+	if got := NewRetractions([]*Attestation{d, r}).Retracted(d); got != r {
+		t.Fatal("the dispute should be marked retracted")
+	}
+	if live := DropRetracted([]*Attestation{d, r}); len(live) != 1 || live[0] != r {
+		t.Fatalf("DropRetracted should keep only the retraction, got %d", len(live))
+	}
+
+	bare := NewAttestation(TypeRetraction, issuer.DID, subject.DID)
+	_ = bare.Sign(issuer.Private)
+	if bare.Verify() == nil {
+		t.Fatal("a retraction without body.retracts should be rejected")
+	}
+	forged := NewAttestation(TypeRetraction, other.DID, subject.DID)
+	forged.Body = map[string]any{"retracts": dh}
+	_ = forged.Sign(other.Private)
+	if CheckRetraction(forged, d) == nil || NewRetractions([]*Attestation{d, forged}).Retracted(d) != nil {
+		t.Fatal("another issuer must not retract the dispute")
+	}
+	rh, _ := r.Hash()
+	again := NewAttestation(TypeRetraction, issuer.DID, subject.DID)
+	again.Body = map[string]any{"retracts": rh}
+	_ = again.Sign(issuer.Private)
+	if CheckRetraction(again, r) == nil {
+		t.Fatal("a retraction must not be retracted")
+	}
+}
@@ -0,0 +1,161 @@
+package core
+
+import (
+	"crypto"
+	"fmt"
+	"time"
+)
+
+// RevocationSpec is the spec tag for a v0.1 key revocation record.
+const RevocationSpec = "moltnet/revocation/v0.1"
+
+// Revocation is an owner-signed record declaring an agent key compromised as of
+// EffectiveAt: anything it signed from then on is to be distrusted. Unlike a
+// rotation it does not name a successor, and it is retroactive — the owner may
+// only learn of a compromise after the thief has used the key, so EffectiveAt
+// may precede IssuedAt.
+//
+// The cutoff is compared with the issued_at the key itself signed, which a
+// thief can backdate. Registries close that gap for new writes by refusing
+// every attestation from a revoked key once the revocation is stored; a
+// backdated record that was already federated before it cannot be told apart.
+//
+// As with a rotation, a team-owned agent is revoked by a threshold of its
+// owner policy's members.
+type Revocation struct {
+	Spec        string       `json:"spec"`
+	Owner       string       `json:"owner"` // owner DID that authorizes the revocation
+	OwnerPolicy *OwnerPolicy `json:"owner_policy,omitempty"`
+	Agent       string       `json:"agent"`        // compromised agent DID
+	EffectiveAt string       `json:"effective_at"` // RFC 3339; distrust what it signed from here on
+	Reason      string       `json:"reason,omitempty"`
+	IssuedAt    string       `json:"issued_at"`
+	Sig         string       `json:"sig,omitempty"`        // owner signature
+	OwnerSigs   []OwnerSig   `json:"owner_sigs,omitempty"` // policy member signatures
+}
+
+// NewRevocation builds an unsigned revocation with the spec tag and timestamp
+// set.
+func NewRevocation(ownerDID, agentDID string, effectiveAt time.Time) *Revocation {
+	return &Revocation{
+		Spec:        RevocationSpec,
+		Owner:       ownerDID,
+		Agent:       agentDID,
+		EffectiveAt: effectiveAt.UTC().Format(time.RFC3339),
+		IssuedAt:    time.Now().UTC().Format(time.RFC3339),
+	}
+}
+
+// SigningPayload is the canonical revocation without its signatures.
+func (r *Revocation) SigningPayload() ([]byte, error) {
+	return CanonicalizeWithout(r, "sig", "owner_sigs")
+}
+
+// Hash returns the content address of the revocation record.
+func (r *Revocation) Hash() (string, error) {
+	payload, err := r.SigningPayload()
+	if err != nil {
+		return "", err
+	}
+	return HashBytes(payload), nil
+}
+
+// Sign fills in the owner signature.
+func (r *Revocation) Sign(ownerKey crypto.Signer) error {
+	payload, err := r.SigningPayload()
+	if err != nil {
+		return err
+	}
+	r.Sig, err = Sign(ownerKey, payload)
+	return err
+}
+
+// AddOwnerSig adds member's signature toward the revocation's owner policy,
+// replacing any it made before.
+func (r *Revocation) AddOwnerSig(member *KeyPair) error {
+	payload, err := r.SigningPayload()
+	if err != nil {
+		return err
+	}
+	r.OwnerSigs, err = addOwnerSig(r.OwnerSigs, member, payload)
+	return err
+}
+
+// Verify checks structural invariants and the owner's authorization. That
+// Owner actually owns Agent is checked against the agent's card by the caller.
+func (r *Revocation) Verify() error {
+	if r.Spec != RevocationSpec {
+		return fmt.Errorf("revocation: unexpected spec %q", r.Spec)
+	}
+	if r.Owner == "" || r.Agent == "" {
+		return fmt.Errorf("revocation: owner and agent are required")
+	}
+	if _, err := time.Parse(time.RFC3339, r.EffectiveAt); err != nil {
+		return fmt.Errorf("revocation: effective_at must be an RFC 3339 timestamp")
+	}
+	payload, err := r.SigningPayload()
+	if err != nil {
+		return err
+	}
+	if err := verifyOwner(r.Owner, r.OwnerPolicy, r.Sig, r.OwnerSigs, payload); err != nil {
+		return fmt.Errorf("revocation: %w", err)
+	}
+	return nil
+}
+
+// Cutoffs maps each revoked agent DID to the time from which its signatures
+// are distrusted. A nil Cutoffs revokes nothing.
+type Cutoffs map[string]time.Time
+
+// RevocationCutoffs collects the cutoffs of revs; a key revoked more than once
+// is distrusted from the earliest effective time.
+func RevocationCutoffs(revs []*Revocation) Cutoffs {
+	var out Cutoffs
+	for _, r := range revs {
+		t, err := time.Parse(time.RFC3339, r.EffectiveAt)
+		if err != nil {
+			continue
+		}
+		if out == nil {
+			out = Cutoffs{}
+		}
+		if prev, ok := out[r.Agent]; !ok || t.Before(prev) {
+			out[r.Agent] = t
+		}
+	}
+	return out
+}
+
+// Revoked reports whether a was signed by a revoked key at or after its
+// cutoff. An attestation from a revoked key whose issued_at does not parse
+// cannot be placed before the cutoff, so it is revoked too. A co-signed
+// attestation is revoked if any of its issuers' keys is: a thief could have
+// made that signature, and the statement is one whole.
+func (c Cutoffs) Revoked(a *Attestation) bool {
+	for _, issuer := range a.Issuers() {
+		cut, ok := c[issuer]
+		if !ok {
+			continue
+		}
+		at, err := time.Parse(time.RFC3339, a.IssuedAt)
+		if err != nil || !at.Before(cut) {
+			return true
+		}
+	}
+	return false
+}
+
+// Filter returns atts without the revoked ones, in order. With no cutoffs it
+// returns atts itself.
+func (c Cutoffs) Filter(atts []*Attestation) []*Attestation {
+	if len(c) == 0 {
+		return atts
+	}
+	out := make([]*Attestation, 0, len(atts))
+	for _, a := range atts {
+		if !c.Revoked(a) {
+			out = append(out, a)
+		}
+	}
+	return out
+}
@@ -0,0 +1,80 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRevocationSignVerify(t *testing.T) {
+	owner, _ := GenerateKeyPair()
+	agent, _ := GenerateKeyPair()
+	r := NewRevocation(owner.DID, agent.DID, time.Now().Add(-time.Hour))
+	r.Reason = "key leaked in CI logs"
+	if err := r.Sign(owner.Private); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Verify(); err != nil {
+		t.Fatalf("valid revocation rejected: %v", err)
+	}
+	r.EffectiveAt = time.Now().UTC().Format(time.RFC3339)
+	if err := r.Verify(); err == nil {
+		t.Fatal("expected tampered effective_at to fail verification")
+	}
+	r.EffectiveAt = "last tuesday"
+	if err := r.Verify(); err == nil {
+		t.Fatal("expected an unparseable effective_at to be rejected")
+	}
+}
+
+// A thief holding the key extends its chain past the cutoff — here forking it,
+// so the chain no longer verifies. With the revocation, what the key signed
+// before the compromise verifies and the rest is disregarded.
+func TestVerifyAllDropsRevoked(t *testing.T) {
+	owner, _ := GenerateKeyPair()
+	issuer, _ := GenerateKeyPair()
+	subject, _ := GenerateKeyPair()
+	cut := time.Now().UTC().Add(-24 * time.Hour).Truncate(time.Second)
+
+	var atts []*Attestation
+	prev := ""
+	add := func(at time.Time, prevHash string) *Attestation {
+		a := NewAttestation(TypeTaskCompleted, issuer.DID, subject.DID)
+		a.IssuedAt = at.Format(time.RFC3339)
+		a.Prev = prevHash
+		if err := a.Sign(issuer.Private); err != nil {
+			t.Fatal(err)
+		}
+		atts = append(atts, a)
+		return a
+	}
+	first := add(cut.Add(-2*time.Hour), prev)
+	prev, _ = first.Hash()
+	second := add(cut.Add(-time.Hour), prev)
+	h2, _ := second.Hash()
+	add(cut.Add(time.Hour), h2)
+	add(cut.Add(2*time.Hour), h2) // fork: same prev
+
+	if err := VerifyAll(atts); err == nil {
+		t.Fatal("the forked chain should fail without the revocation")
+	}
+	rev := NewRevocation(owner.DID, issuer.DID, cut)
+	if err := rev.Sign(owner.Private); err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyAllWith(atts, VerifyOptions{Revocations: []*Revocation{rev}}); err != nil {
+		t.Fatalf("pre-cutoff chain should verify once the revocation applies: %v", err)
+	}
+	if got := RevocationCutoffs([]*Revocation{rev}).Filter(atts); len(got) != 2 {
+		t.Fatalf("filter kept %d attestations, want 2", len(got))
+	}
+
+	// A later revocation of the same key does not move the cutoff forward.
+	later := NewRevocation(owner.DID, issuer.DID, cut.Add(48*time.Hour))
+	if c := RevocationCutoffs([]*Revocation{later, rev}); !c[issuer.DID].Equal(cut) {
+		t.Fatalf("cutoff = %v, want the earliest %v", c[issuer.DID], cut)
+	}
+	// An unsigned revocation is not applied.
+	if err := VerifyAllWith(atts, VerifyOptions{Revocations: []*Revocation{later}}); err == nil {
+		t.Fatal("an unsigned revocation should be rejected")
+	}
+}
@@ -1,7 +1,7 @@
 package core
 
 import (
-	"crypto/ed25519"
+	"crypto"
 	"fmt"
 	"time"
 )
@@ -14,13 +14,18 @@ const RotationSpec = "moltnet/rotation/v0.1"
 // separate from the agent key), an agent-key compromise does not destroy the
 // identity — the owner rotates to a fresh agent key and the attestation history
 // continues across the rotation.
+//
+// A team-owned agent is rotated by its owner policy: Owner is the policy DID,
+// OwnerPolicy the policy, and OwnerSigs a threshold of member signatures.
 type Rotation struct {
-	Spec     string `json:"spec"`
-	Owner    string `json:"owner"`     // owner DID that authorizes the rotation
-	OldAgent string `json:"old_agent"` // agent DID being retired
-	NewAgent string `json:"new_agent"` // replacement agent DID
-	IssuedAt string `json:"issued_at"`
-	Sig      string `json:"sig,omitempty"` // owner signature
+	Spec        string       `json:"spec"`
+	Owner       string       `json:"owner"` // owner DID that authorizes the rotation
+	OwnerPolicy *OwnerPolicy `json:"owner_policy,omitempty"`
+	OldAgent    string       `json:"old_agent"` // agent DID being retired
+	NewAgent    string       `json:"new_agent"` // replacement agent DID
+	IssuedAt    string       `json:"issued_at"`
+	Sig         string       `json:"sig,omitempty"`        // owner signature
+	OwnerSigs   []OwnerSig   `json:"owner_sigs,omitempty"` // policy member signatures
 }
 
 // NewRotation builds an unsigned rotation with the spec tag and timestamp set.
@@ -34,9 +39,9 @@ func NewRotation(ownerDID, oldAgentDID, newAgentDID string) *Rotation {
 	}
 }
 
-// SigningPayload is the canonical rotation without its signature.
+// SigningPayload is the canonical rotation without its signatures.
 func (r *Rotation) SigningPayload() ([]byte, error) {
-	return CanonicalizeWithout(r, "sig")
+	return CanonicalizeWithout(r, "sig", "owner_sigs")
 }
 
 // Hash returns the content address of the rotation record.
@@ -49,16 +54,28 @@ func (r *Rotation) Hash() (string, error) {
 }
 
 // Sign fills in the owner signature.
-func (r *Rotation) Sign(ownerKey ed25519.PrivateKey) error {
+func (r *Rotation) Sign(ownerKey crypto.Signer) error {
 	payload, err := r.SigningPayload()
 	if err != nil {
 		return err
 	}
-	r.Sig = Sign(ownerKey, payload)
-	return nil
+	r.Sig, err = Sign(ownerKey, payload)
+	return err
+}
+
+// AddOwnerSig adds member's signature toward the rotation's owner policy,
+// replacing any it made before.
+func (r *Rotation) AddOwnerSig(member *KeyPair) error {
+	payload, err := r.SigningPayload()
+	if err != nil {
+		return err
+	}
+	r.OwnerSigs, err = addOwnerSig(r.OwnerSigs, member, payload)
+	return err
 }
 
-// Verify checks structural invariants and the owner signature.
+// Verify checks structural invariants and the owner's authorization: the owner
+// key's signature, or a threshold of the owner policy's members.
 func (r *Rotation) Verify() error {
 	if r.Spec != RotationSpec {
 		return fmt.Errorf("rotation: unexpected spec %q", r.Spec)
@@ -69,15 +86,12 @@ func (r *Rotation) Verify() error {
 	if r.OldAgent == r.NewAgent {
 		return fmt.Errorf("rotation: old_agent and new_agent must differ")
 	}
-	if r.Sig == "" {
-		return fmt.Errorf("rotation: missing owner signature")
-	}
 	payload, err := r.SigningPayload()
 	if err != nil {
 		return err
 	}
-	if err := Verify(r.Owner, payload, r.Sig); err != nil {
-		return fmt.Errorf("rotation: owner signature invalid: %w", err)
+	if err := verifyOwner(r.Owner, r.OwnerPolicy, r.Sig, r.OwnerSigs, payload); err != nil {
+		return fmt.Errorf("rotation: %w", err)
 	}
 	return nil
 }
@@ -104,3 +118,76 @@ func ResolveCurrentAgent(rotations []*Rotation, start string) (string, error) {
 		current = n
 	}
 }
+
+// Lineage returns the rotations that retired did's predecessor keys, oldest
+// first: the first record's OldAgent is the identity's earliest key and the last
+// record's NewAgent is did. A DID no rotation leads to has an empty lineage.
+// As in ResolveCurrentAgent, only the last rotation listed for a key retires
+// it, so a key's history passes to one successor. It is an error for two keys
+// to have been rotated into the same DID on the path, or for the rotations to
+// form a cycle.
+func Lineage(rotations []*Rotation, did string) ([]*Rotation, error) {
+	retiredBy := map[string]*Rotation{}
+	for _, r := range rotations {
+		retiredBy[r.OldAgent] = r
+	}
+	into := map[string][]*Rotation{}
+	for _, r := range rotations {
+		if retiredBy[r.OldAgent] == r {
+			into[r.NewAgent] = append(into[r.NewAgent], r)
+		}
+	}
+	var out []*Rotation
+	seen := map[string]bool{did: true}
+	for current := did; ; {
+		rs := into[current]
+		if len(rs) == 0 {
+			break
+		}
+		if len(rs) > 1 {
+			return nil, fmt.Errorf("rotation: %s has %d predecessors (%s, %s, …)", current, len(rs), rs[0].OldAgent, rs[1].OldAgent)
+		}
+		prev := rs[0].OldAgent
+		if seen[prev] {
+			return nil, fmt.Errorf("rotation cycle detected at %s", prev)
+		}
+		seen[prev] = true
+		out = append(out, rs[0])
+		current = prev
+	}
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return out, nil
+}
+
+// VerifyLineage checks a lineage as returned by Lineage: every rotation is
+// validly signed, each retires the key the previous one introduced, the last
+// introduces did, and each was signed by a key that spoke for owner — did's
+// card owner — when it was issued, under owners.
+func VerifyLineage(lineage []*Rotation, did, owner string, owners OwnerSuccession) error {
+	for i, r := range lineage {
+		if err := r.Verify(); err != nil {
+			return fmt.Errorf("lineage: rotation %d: %w", i, err)
+		}
+		if !owners.Authorizes(r.Owner, owner, r.IssuedAt) {
+			return fmt.Errorf("lineage: rotation %d is signed by %s, which did not speak for owner %s at %s", i, r.Owner, owner, r.IssuedAt)
+		}
+		if i > 0 && r.OldAgent != lineage[i-1].NewAgent {
+			return fmt.Errorf("lineage: rotation %d retires %s, expected %s", i, r.OldAgent, lineage[i-1].NewAgent)
+		}
+	}
+	if n := len(lineage); n > 0 && lineage[n-1].NewAgent != did {
+		return fmt.Errorf("lineage: ends at %s, expected %s", lineage[n-1].NewAgent, did)
+	}
+	return nil
+}
+
+// Predecessors lists the agent DIDs a lineage retired, oldest first.
+func Predecessors(lineage []*Rotation) []string {
+	out := make([]string, len(lineage))
+	for i, r := range lineage {
+		out[i] = r.OldAgent
+	}
+	return out
+}
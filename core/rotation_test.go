@@ -78,3 +78,49 @@ func TestResolveDetectsCycle(t *testing.T) {
 		t.Fatal("expected cycle detection error")
 	}
 }
+
+func TestLineage(t *testing.T) {
+	owner, _ := GenerateKeyPair()
+	a0, _ := GenerateKeyPair()
+	a1, _ := GenerateKeyPair()
+	a2, _ := GenerateKeyPair()
+
+	r1 := NewRotation(owner.DID, a0.DID, a1.DID)
+	_ = r1.Sign(owner.Private)
+	r2 := NewRotation(owner.DID, a1.DID, a2.DID)
+	_ = r2.Sign(owner.Private)
+
+	// Listed newest first, the lineage still comes back oldest first.
+	lin, err := Lineage([]*Rotation{r2, r1}, a2.DID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lin) != 2 || lin[0] != r1 || lin[1] != r2 {
+		t.Fatalf("lineage: got %v", Predecessors(lin))
+	}
+	if err := VerifyLineage(lin, a2.DID, owner.DID, OwnerSuccession{}); err != nil {
+		t.Fatalf("valid lineage rejected: %v", err)
+	}
+	if err := VerifyLineage(lin, a1.DID, owner.DID, OwnerSuccession{}); err == nil {
+		t.Fatal("a lineage that ends elsewhere should fail")
+	}
+	if lin, _ := Lineage([]*Rotation{r1, r2}, a0.DID); len(lin) != 0 {
+		t.Fatalf("the first key has no predecessors, got %d", len(lin))
+	}
+
+	// A foreign owner's rotation cannot splice into the lineage.
+	mallory, _ := GenerateKeyPair()
+	forged := NewRotation(mallory.DID, a1.DID, a2.DID)
+	_ = forged.Sign(mallory.Private)
+	if err := VerifyLineage([]*Rotation{r1, forged}, a2.DID, owner.DID, OwnerSuccession{}); err == nil {
+		t.Fatal("a lineage signed by a foreign owner should fail")
+	}
+
+	// Two keys rotated into one DID would merge two histories.
+	b0, _ := GenerateKeyPair()
+	merge := NewRotation(owner.DID, b0.DID, a1.DID)
+	_ = merge.Sign(owner.Private)
+	if _, err := Lineage([]*Rotation{r1, merge}, a1.DID); err == nil {
+		t.Fatal("expected an error for a DID with two predecessors")
+	}
+}
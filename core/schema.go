@@ -0,0 +1,259 @@
+package core
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"math"
+	"path"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// BodySchemaVersion is the version of the attestation body schemas. It tracks
+// the attestation spec; a body convention that changes incompatibly gets a
+// new version rather than an edit.
+const BodySchemaVersion = "v0.1"
+
+// The body schemas, one JSON Schema per attestation type. spec/schemas/ ships
+// the same files for other implementations.
+//
+//go:embed schemas
+var schemaFiles embed.FS
+
+// BodySchema returns the JSON Schema for the body of an attestation of type
+// typ, or nil for an unknown type.
+func BodySchema(typ string) []byte {
+	data, err := schemaFiles.ReadFile(path.Join("schemas", BodySchemaVersion, typ+".json"))
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+// BodyError lists the ways an attestation body departs from its type's
+// schema, one violation per entry, each naming the offending field.
+type BodyError struct {
+	Type       string
+	Violations []string
+}
+
+func (e *BodyError) Error() string {
+	return fmt.Sprintf("%s body: %s", e.Type, strings.Join(e.Violations, "; "))
+}
+
+// ValidateBody checks body against the schema for attestation type typ. It
+// returns a *BodyError listing every violation, or nil if the body conforms.
+// Schemas constrain the conventional fields and leave others open, so a body
+// may carry more than its schema names.
+func ValidateBody(typ string, body map[string]any) error {
+	s := bodySchemas()[typ]
+	if s == nil {
+		return fmt.Errorf("attestation: no body schema for type %q", typ)
+	}
+	// Validate the body as JSON carries it: whatever Go types built it, the
+	// signed bytes hold only JSON values.
+	var v any = map[string]any{}
+	if body != nil {
+		raw, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(raw, &v); err != nil {
+			return err
+		}
+	}
+	var violations []string
+	s.validate(v, "body", &violations)
+	if len(violations) > 0 {
+		return &BodyError{Type: typ, Violations: violations}
+	}
+	return nil
+}
+
+var bodySchemas = sync.OnceValue(func() map[string]*schema {
+	out := map[string]*schema{}
+	for _, typ := range []string{TypeTaskCompleted, TypeTaskDisputed, TypeEndorsement, TypeIncident,
+		TypePaymentReceipt, TypeKeyRotation, TypeSelfClaim, TypeRetraction} {
+		s, err := parseSchema(BodySchema(typ))
+		if err != nil {
+			panic(fmt.Sprintf("core: body schema for %s: %v", typ, err))
+		}
+		out[typ] = s
+	}
+	return out
+})
+
+// schema is the subset of JSON Schema (2020-12) the body schemas use. A
+// schema using any other keyword fails to parse rather than validating less
+// than it says.
+type schema struct {
+	types                []string
+	properties           map[string]*schema
+	required             []string
+	additionalProperties *bool
+	enum                 []any
+	pattern              *regexp.Regexp
+	minLength, maxLength *int
+	minimum              *float64
+	items                *schema
+}
+
+// annotations are the keywords that describe a schema without constraining
+// anything.
+var annotations = map[string]bool{"$schema": true, "$id": true, "title": true, "description": true}
+
+func parseSchema(data []byte) (*schema, error) {
+	if data == nil {
+		return nil, fmt.Errorf("missing")
+	}
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	s := &schema{}
+	for k, raw := range m {
+		var err error
+		switch k {
+		case "type":
+			if err = json.Unmarshal(raw, &s.types); err != nil {
+				var t string
+				if err = json.Unmarshal(raw, &t); err == nil {
+					s.types = []string{t}
+				}
+			}
+		case "properties":
+			var props map[string]json.RawMessage
+			if err = json.Unmarshal(raw, &props); err == nil {
+				s.properties = map[string]*schema{}
+				for name, p := range props {
+					if s.properties[name], err = parseSchema(p); err != nil {
+						return nil, fmt.Errorf("%s: %w", name, err)
+					}
+				}
+			}
+		case "items":
+			s.items, err = parseSchema(raw)
+		case "required":
+			err = json.Unmarshal(raw, &s.required)
+		case "additionalProperties":
+			err = json.Unmarshal(raw, &s.additionalProperties)
+		case "enum":
+			err = json.Unmarshal(raw, &s.enum)
+		case "pattern":
+			var p string
+			if err = json.Unmarshal(raw, &p); err == nil {
+				s.pattern, err = regexp.Compile(p)
+			}
+		case "minLength":
+			err = json.Unmarshal(raw, &s.minLength)
+		case "maxLength":
+			err = json.Unmarshal(raw, &s.maxLength)
+		case "minimum":
+			err = json.Unmarshal(raw, &s.minimum)
+		default:
+			if !annotations[k] {
+				return nil, fmt.Errorf("unsupported keyword %q", k)
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", k, err)
+		}
+	}
+	return s, nil
+}
+
+// jsonType names the JSON type of a decoded value.
+func jsonType(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// validate appends to out a violation for each way v, found at path p, fails
+// the schema. As in JSON Schema, a keyword constrains only values of the type
+// it applies to: pattern only strings, minimum only numbers.
+func (s *schema) validate(v any, p string, out *[]string) {
+	if len(s.types) > 0 {
+		t := jsonType(v)
+		ok := false
+		for _, want := range s.types {
+			if want == t || want == "integer" && t == "number" && v.(float64) == math.Trunc(v.(float64)) {
+				ok = true
+			}
+		}
+		if !ok {
+			*out = append(*out, fmt.Sprintf("%s: want %s, got %s", p, strings.Join(s.types, " or "), t))
+			return
+		}
+	}
+	if len(s.enum) > 0 {
+		ok := false
+		for _, e := range s.enum {
+			if reflect.DeepEqual(e, v) {
+				ok = true
+			}
+		}
+		if !ok {
+			want, _ := json.Marshal(s.enum)
+			*out = append(*out, fmt.Sprintf("%s: %v is not one of %s", p, v, want))
+		}
+	}
+	switch v := v.(type) {
+	case string:
+		n := utf8.RuneCountInString(v)
+		if s.minLength != nil && n < *s.minLength {
+			*out = append(*out, fmt.Sprintf("%s: shorter than %d characters", p, *s.minLength))
+		}
+		if s.maxLength != nil && n > *s.maxLength {
+			*out = append(*out, fmt.Sprintf("%s: longer than %d characters", p, *s.maxLength))
+		}
+		if s.pattern != nil && !s.pattern.MatchString(v) {
+			*out = append(*out, fmt.Sprintf("%s: %q does not match %s", p, v, s.pattern))
+		}
+	case float64:
+		if s.minimum != nil && v < *s.minimum {
+			*out = append(*out, fmt.Sprintf("%s: %v is below the minimum %v", p, v, *s.minimum))
+		}
+	case []any:
+		if s.items != nil {
+			for i, e := range v {
+				s.items.validate(e, fmt.Sprintf("%s[%d]", p, i), out)
+			}
+		}
+	case map[string]any:
+		for _, k := range s.required {
+			if _, ok := v[k]; !ok {
+				*out = append(*out, fmt.Sprintf("%s.%s: required", p, k))
+			}
+		}
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if ps := s.properties[k]; ps != nil {
+				ps.validate(v[k], p+"."+k, out)
+			} else if s.additionalProperties != nil && !*s.additionalProperties {
+				*out = append(*out, fmt.Sprintf("%s.%s: not allowed", p, k))
+			}
+		}
+	}
+}
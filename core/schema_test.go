@@ -0,0 +1,90 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// The embedded schemas are the ones spec/schemas/ ships, one per type, and
+// each parses.
+func TestBodySchemasShipped(t *testing.T) {
+	for _, typ := range []string{TypeTaskCompleted, TypeTaskDisputed, TypeEndorsement, TypeIncident,
+		TypePaymentReceipt, TypeKeyRotation, TypeSelfClaim, TypeRetraction} {
+		spec, err := os.ReadFile(filepath.Join("..", "spec", "schemas", BodySchemaVersion, typ+".json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(spec, BodySchema(typ)) {
+			t.Errorf("%s: spec/schemas/ and core/schemas/ differ", typ)
+		}
+		if _, err := parseSchema(spec); err != nil {
+			t.Errorf("%s: %v", typ, err)
+		}
+	}
+	if BodySchema("task.invented") != nil {
+		t.Fatal("an unknown type has no schema")
+	}
+	if _, err := parseSchema([]byte(`{"type":"object","oneOf":[]}`)); err == nil {
+		t.Fatal("an unsupported keyword must not be ignored")
+	}
+}
+
+func TestValidateBody(t *testing.T) {
+	for name, c := range map[string]struct {
+		typ  string
+		body map[string]any
+	}{
+		"completion":         {TypeTaskCompleted, map[string]any{"outcome": "success", "capability": "code.review", "task_id": "extra fields are open"}},
+		"numeric receipt":    {TypePaymentReceipt, map[string]any{"amount": 19.9, "currency": "USD"}},
+		"decimal receipt":    {TypePaymentReceipt, map[string]any{"amount": "300", "currency": "USDC", "rail": "x402", "tx": "0xabc"}},
+		"bare endorsement":   {TypeEndorsement, nil},
+		"retraction":         {TypeRetraction, map[string]any{"retracts": "blake3:00ff", "reason": "filed in error"}},
+		"decision stream":    {TypeTaskCompleted, map[string]any{"stream": map[string]any{"root": "blake3:ab"}}},
+		"integer go value":   {TypePaymentReceipt, map[string]any{"amount": 5, "currency": "EUR"}},
+		"task offer as self": {TypeSelfClaim, map[string]any{"kind": "task.offer", "title": "redact a PDF", "budget": "40"}},
+	} {
+		if err := ValidateBody(c.typ, c.body); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+
+	err := ValidateBody(TypePaymentReceipt, map[string]any{"amount": "-3", "rail": "paypal", "fee": -1.5})
+	var be *BodyError
+	if !errors.As(err, &be) {
+		t.Fatalf("want a BodyError, got %v", err)
+	}
+	want := []string{
+		"body.currency: required",
+		`body.amount: "-3" does not match ^[0-9]+(\.[0-9]+)?$`,
+		"body.fee: -1.5 is below the minimum 0",
+		`body.rail: paypal is not one of ["x402","stripe"]`,
+	}
+	if len(be.Violations) != len(want) {
+		t.Fatalf("violations: got %q, want %q", be.Violations, want)
+	}
+	for i := range want {
+		if be.Violations[i] != want[i] {
+			t.Errorf("violation %d: got %q, want %q", i, be.Violations[i], want[i])
+		}
+	}
+	for name, c := range map[string]struct {
+		typ  string
+		body map[string]any
+	}{
+		"bad outcome":      {TypeTaskCompleted, map[string]any{"outcome": "great"}},
+		"typed field":      {TypeTaskCompleted, map[string]any{"task": 42}},
+		"untagged":         {TypeEndorsement, map[string]any{"capability": "Code Review"}},
+		"no target":        {TypeRetraction, map[string]any{"reason": "oops"}},
+		"stream sans root": {TypeTaskCompleted, map[string]any{"stream": map[string]any{}}},
+	} {
+		if ValidateBody(c.typ, c.body) == nil {
+			t.Errorf("%s: should be rejected", name)
+		}
+	}
+	if ValidateBody("task.invented", nil) == nil {
+		t.Fatal("an unknown type should be rejected")
+	}
+}
@@ -21,6 +21,8 @@ export interface ScoreOutput {
     incidents: number;
     distinct_issuers: number;
   };
+  /** Disputes/incidents not yet corroborated by an independent reporter. */
+  pending?: { disputes: number; incidents: number };
 }
 
 export interface Card {
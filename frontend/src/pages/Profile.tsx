@@ -161,6 +161,11 @@ export function Profile() {
               {bar('disputes', inp?.disputes || 0, true)}
               {bar('incidents', inp?.incidents || 0, true)}
             </div>
+            {score.pending && (
+              <div className="meta" style={{ marginTop: 10, fontSize: 11 }}>
+                pending (uncorroborated): {score.pending.disputes} disputes · {score.pending.incidents} incidents
+              </div>
+            )}
           </div>
           <div className="box">
             <span className="box__l"><span className="s">◈</span> IDENTITY &amp; BINDINGS</span>
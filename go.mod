@@ -3,6 +3,7 @@ module github.com/moltnet/moltnet
 go 1.25.0
 
 require (
+	github.com/decred/dcrd/dcrec/secp256k1/v4 v4.4.1
 	github.com/mr-tron/base58 v1.3.0
 	lukechampine.com/blake3 v1.4.1
 	modernc.org/sqlite v1.53.0
@@ -259,6 +259,14 @@ func (s *Server) requireOwner(h http.HandlerFunc) http.HandlerFunc {
 			writeErr(w, http.StatusUnauthorized, "sign in required")
 			return
 		}
+		// A key retired by a recovery has no moment of rotation at which to
+		// end its sessions (its waiting period simply runs out), so check
+		// that the session's key still speaks for itself.
+		if cur, err := s.currentOwner(owner); err != nil || cur != owner {
+			_ = s.Store.DeleteOwnerSessions(owner)
+			writeErr(w, http.StatusUnauthorized, "owner key is not current: "+ownerProblem(cur, err))
+			return
+		}
 		r = r.WithContext(withOwner(r.Context(), owner))
 		h(w, r)
 	}
@@ -390,6 +398,11 @@ func (s *Server) handleAuthLogin(w http.ResponseWriter, r *http.Request) {
 		writeErr(w, http.StatusUnauthorized, "signature invalid: "+err.Error())
 		return
 	}
+	// A rotated-away owner key is exactly the one a thief may hold.
+	if cur, err := s.currentOwner(body.DID); err != nil || cur != body.DID {
+		writeErr(w, http.StatusForbidden, "owner key is not current: "+ownerProblem(cur, err))
+		return
+	}
 	// Single-use: consume AFTER a successful verify.
 	if ok, err := s.Store.ConsumeChallenge(body.Nonce, now); err != nil || !ok {
 		writeErr(w, http.StatusUnauthorized, "challenge could not be consumed")
@@ -451,7 +464,7 @@ func (s *Server) handleAuthMe(w http.ResponseWriter, r *http.Request) {
 		writeErr(w, http.StatusUnauthorized, "sign in required")
 		return
 	}
-	agents, _ := s.Store.AgentsByOwner(owner)
+	agents, _ := s.Store.AgentsByOwner(owner, s.defaultAlgorithm())
 	if agents == nil {
 		agents = []store.Agent{}
 	}
@@ -465,7 +478,7 @@ func (s *Server) handleAuthMe(w http.ResponseWriter, r *http.Request) {
 // resource the dashboard's "my agents" view fetches directly.
 func (s *Server) handleMyAgents(w http.ResponseWriter, r *http.Request) {
 	owner := ownerFromContext(r)
-	agents, _ := s.Store.AgentsByOwner(owner)
+	agents, _ := s.Store.AgentsByOwner(owner, s.defaultAlgorithm())
 	if agents == nil {
 		agents = []store.Agent{}
 	}
@@ -510,7 +523,7 @@ func (s *Server) handleCreateAPIKey(w http.ResponseWriter, r *http.Request) {
 		writeErr(w, http.StatusNotFound, "agent not found")
 		return
 	}
-	if card.Owner != owner {
+	if cur, err := s.currentOwner(card.Owner); err != nil || cur != owner {
 		writeErr(w, http.StatusForbidden, "you do not own this agent")
 		return
 	}
@@ -50,7 +50,7 @@ func TestSIWKLoginAndDashboard(t *testing.T) {
 	}
 
 	// 2. sign + login
-	sig := core.Sign(owner.Private, []byte(chall.Message))
+	sig, _ := core.Sign(owner.Private, []byte(chall.Message))
 	var login struct {
 		OK       bool   `json:"ok"`
 		OwnerDID string `json:"owner_did"`
@@ -68,7 +68,7 @@ func TestSIWKLoginAndDashboard(t *testing.T) {
 	}
 
 	// 3. nonce must be single-use: a second login with the same nonce fails.
-	sig2 := core.Sign(owner.Private, []byte(chall.Message))
+	sig2, _ := core.Sign(owner.Private, []byte(chall.Message))
 	if code, _ := postJSON(t, ts.URL+"/v1/auth/login", map[string]string{
 		"did": owner.DID, "nonce": chall.Nonce, "sig": sig2,
 	}); code == 200 {
@@ -83,7 +83,8 @@ func TestSIWKLoginAndDashboard(t *testing.T) {
 	if _, body := postJSON(t, ts.URL+"/v1/auth/challenge", map[string]string{"did": owner.DID}); true {
 		decode(t, body, &chall2)
 	}
-	badSig := "00" + core.Sign(owner.Private, []byte(chall2.Message))[2:] // flip bytes
+	goodSig, _ := core.Sign(owner.Private, []byte(chall2.Message))
+	badSig := "00" + goodSig[2:] // flip bytes
 	if code, _ := postJSON(t, ts.URL+"/v1/auth/login", map[string]string{
 		"did": owner.DID, "nonce": chall2.Nonce, "sig": badSig,
 	}); code != 401 {
@@ -262,7 +263,7 @@ func loginOwner(t *testing.T, base string, owner *core.KeyPair) string {
 	}
 	_, body := postJSON(t, base+"/v1/auth/challenge", map[string]string{"did": owner.DID})
 	decode(t, body, &chall)
-	sig := core.Sign(owner.Private, []byte(chall.Message))
+	sig, _ := core.Sign(owner.Private, []byte(chall.Message))
 	var login struct {
 		Session string `json:"session"`
 	}
@@ -0,0 +1,129 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/moltnet/moltnet/core"
+)
+
+// handleDelegation accepts an owner-signed delegation of issuing rights from
+// an agent to a sub-key. Only the principal's current owner key may delegate.
+func (s *Server) handleDelegation(w http.ResponseWriter, r *http.Request) {
+	var d core.Delegation
+	if err := decodeRecord(r, &d); err != nil {
+		writeErr(w, http.StatusBadRequest, "invalid delegation json: "+err.Error())
+		return
+	}
+	if err := d.Verify(); err != nil {
+		writeErr(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	card, err := s.Store.GetCard(d.Principal)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if card == nil {
+		writeErr(w, http.StatusNotFound, "principal agent not found")
+		return
+	}
+	if cur, err := s.currentOwner(card.Owner); err != nil || cur != d.Owner {
+		writeErr(w, http.StatusForbidden, "delegation owner is not the principal's current owner: "+ownerProblem(cur, err))
+		return
+	}
+	if revs, err := s.Store.RevocationsFor(d.Delegate); err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	} else if len(revs) > 0 {
+		writeErr(w, http.StatusForbidden, "delegate key was revoked; it can no longer be delegated to")
+		return
+	}
+	if _, err := s.Store.PutDelegation(&d); err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	hash, _ := d.Hash()
+	writeJSON(w, http.StatusCreated, map[string]any{
+		"hash": hash, "principal": d.Principal, "delegate": d.Delegate, "expires_at": d.ExpiresAt,
+	})
+}
+
+// handleDelegations lists delegations, oldest first; ?did= narrows to those
+// naming one key as principal or delegate.
+func (s *Server) handleDelegations(w http.ResponseWriter, r *http.Request) {
+	var (
+		ds  []*core.Delegation
+		err error
+	)
+	if did := r.URL.Query().Get("did"); did != "" {
+		ds, err = s.Store.DelegationsFor(did)
+	} else {
+		ds, err = s.Store.AllDelegations()
+	}
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"delegations": ds})
+}
+
+// delegationProblem reports why the stored delegations do not cover a
+// delegated attestation a, or "" if they do (or a is not delegated). The
+// delegation must also have been signed by a key that spoke for the
+// principal's owner when it was issued.
+func (s *Server) delegationProblem(a *core.Attestation) string {
+	if a.Delegation == "" {
+		return ""
+	}
+	d, err := s.Store.GetDelegation(a.Delegation)
+	if err != nil {
+		return err.Error()
+	}
+	if d == nil {
+		return "delegation " + a.Delegation + " is unknown here"
+	}
+	if err := d.Authorizes(a); err != nil {
+		return err.Error()
+	}
+	card, err := s.Store.GetCard(d.Principal)
+	if err != nil {
+		return err.Error()
+	}
+	// The delegation was admitted only while its owner key was current, so it
+	// predates any retirement recorded since; this asks that the key still
+	// answers for the principal's owner identity.
+	succ, err := s.Store.OwnerSuccession()
+	if err != nil {
+		return err.Error()
+	}
+	if card == nil || !succ.Authorizes(d.Owner, card.Owner, d.IssuedAt) {
+		return "delegation is not signed by the principal's owner"
+	}
+	return ""
+}
+
+// keyOwners returns the owners that answer for key: its card's owner, or, for
+// a delegate key with no card, the owners of the principals it issues for.
+func (s *Server) keyOwners(key string) ([]string, error) {
+	card, err := s.Store.GetCard(key)
+	if err != nil {
+		return nil, err
+	}
+	if card != nil {
+		return []string{card.Owner}, nil
+	}
+	ds, err := s.Store.DelegationsFor(key)
+	if err != nil {
+		return nil, err
+	}
+	var owners []string
+	for _, d := range ds {
+		if d.Delegate != key {
+			continue
+		}
+		if pc, err := s.Store.GetCard(d.Principal); err == nil && pc != nil {
+			owners = append(owners, pc.Owner)
+		}
+	}
+	return owners, nil
+}
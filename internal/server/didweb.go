@@ -0,0 +1,108 @@
+package server
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+
+	"github.com/moltnet/moltnet/core"
+	"github.com/moltnet/moltnet/internal/store"
+)
+
+// didWebClient fetches owner DID documents. A card names the host, so the
+// dialer refuses loopback, link-local and private addresses: checked on the
+// address actually dialed, after DNS, so no name can point the server at its
+// own network.
+var didWebClient = &http.Client{
+	Timeout: 10 * time.Second,
+	Transport: &http.Transport{
+		DialContext:         (&net.Dialer{Timeout: 5 * time.Second, Control: publicAddrOnly}).DialContext,
+		TLSHandshakeTimeout: 5 * time.Second,
+	},
+}
+
+func publicAddrOnly(_, address string, _ syscall.RawConn) error {
+	ap, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+	ip := ap.Addr().Unmap()
+	if ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsPrivate() || ip.IsUnspecified() {
+		return fmt.Errorf("refusing to fetch a did:web document from non-public address %s", ip)
+	}
+	return nil
+}
+
+// didWeb returns the resolver for did:web owners, creating a default one on
+// first use if none was configured, and giving it didWebClient if it has no
+// client of its own.
+func (s *Server) didWeb() *core.DIDWebResolver {
+	s.didWebOnce.Do(func() {
+		if s.DIDWeb == nil {
+			s.DIDWeb = core.NewDIDWebResolver(nil)
+		}
+		if s.DIDWeb.Client == nil {
+			s.DIDWeb.Client = didWebClient
+		}
+	})
+	return s.DIDWeb
+}
+
+// checkOwnerDomain checks in the background whether a did:web-owned card's
+// domain lists its owner key, and records the outcome, failures included. An
+// outcome younger than the resolver's TTL is reused and a check already
+// running is not repeated, so a domain is fetched at most once per TTL however
+// often its cards are written or viewed.
+func (s *Server) checkOwnerDomain(c *core.Card) {
+	if !core.IsDIDWeb(c.Owner) {
+		return
+	}
+	ttl := s.didWeb().TTL
+	if ttl <= 0 {
+		ttl = core.DefaultDIDWebTTL
+	}
+	if d, err := s.Store.GetOwnerDomain(c.Owner, c.OwnerKey); err != nil {
+		return
+	} else if d != nil {
+		if at, err := time.Parse(time.RFC3339, d.CheckedAt); err == nil && time.Since(at) < ttl {
+			return
+		}
+	}
+	key := c.Owner + " " + c.OwnerKey
+	if _, running := s.domainChecks.LoadOrStore(key, true); running {
+		return
+	}
+	go func() {
+		defer s.domainChecks.Delete(key)
+		d := &store.OwnerDomain{Owner: c.Owner, OwnerKey: c.OwnerKey, Verified: true}
+		if err := s.didWeb().VerifyDomain(c.Owner, c.OwnerKey); err != nil {
+			d.Verified, d.Error = false, err.Error()
+		}
+		d.CheckedAt = time.Now().UTC().Format(time.RFC3339)
+		_ = s.Store.RecordOwnerDomain(d)
+	}()
+}
+
+// ownerDomain reports, for a did:web-owned card, the domain it names and the
+// last check of whether the domain's DID document lists the card's owner key;
+// until the first check completes it is unverified and pending. A stale check
+// is refreshed in the background. It is nil for any other owner.
+func (s *Server) ownerDomain(c *core.Card) map[string]any {
+	if !core.IsDIDWeb(c.Owner) {
+		return nil
+	}
+	d := map[string]any{"did": c.Owner, "domain": core.DIDWebDomain(c.Owner), "owner_key": c.OwnerKey, "verified": false}
+	if od, err := s.Store.GetOwnerDomain(c.Owner, c.OwnerKey); err == nil && od != nil {
+		d["verified"], d["checked_at"] = od.Verified, od.CheckedAt
+		if od.Error != "" {
+			d["error"] = od.Error
+		}
+	} else {
+		d["pending"] = true
+	}
+	s.checkOwnerDomain(c)
+	return d
+}
@@ -0,0 +1,95 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/moltnet/moltnet/core"
+)
+
+// handleEquivocation accepts a proof that an issuer forked its own chain. It
+// is self-contained, so anyone may submit one, whether or not either branch
+// is held here.
+func (s *Server) handleEquivocation(w http.ResponseWriter, r *http.Request) {
+	var e core.Equivocation
+	if err := decodeRecord(r, &e); err != nil {
+		writeErr(w, http.StatusBadRequest, "invalid equivocation json: "+err.Error())
+		return
+	}
+	if err := e.Verify(); err != nil {
+		writeErr(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if _, err := s.Store.PutEquivocation(&e); err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	_, _ = s.recomputeScore(e.Issuer)
+	hash, _ := e.Hash()
+	ha, hb := e.Hashes()
+	writeJSON(w, http.StatusCreated, map[string]any{
+		"hash": hash, "issuer": e.Issuer, "prev": e.Prev, "a": ha, "b": hb,
+	})
+}
+
+// handleEquivocations lists equivocation proofs, oldest first; ?issuer=
+// narrows to those against one key.
+func (s *Server) handleEquivocations(w http.ResponseWriter, r *http.Request) {
+	var (
+		eqs []*core.Equivocation
+		err error
+	)
+	if issuer := r.URL.Query().Get("issuer"); issuer != "" {
+		eqs, err = s.Store.EquivocationsFor(issuer)
+	} else {
+		eqs, err = s.Store.AllEquivocations()
+	}
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"equivocations": eqs})
+}
+
+// recordEquivocation stores the proof that a, refused for not following
+// issuer's head, forks a link of issuer's already held here, and returns the
+// proof's hash; it returns "" if a forks nothing.
+func (s *Server) recordEquivocation(a *core.Attestation, issuer string) string {
+	prev, _ := a.PrevOf(issuer)
+	other, err := s.Store.Successor(issuer, prev)
+	if err != nil || other == nil {
+		return ""
+	}
+	var e *core.Equivocation
+	for _, x := range core.FindEquivocations([]*core.Attestation{other, a}) {
+		if x.Issuer == issuer {
+			e = x
+		}
+	}
+	if e == nil {
+		return ""
+	}
+	if _, err := s.Store.PutEquivocation(e); err != nil {
+		return ""
+	}
+	_, _ = s.recomputeScore(issuer)
+	hash, _ := e.Hash()
+	return hash
+}
+
+// equivocations summarizes the proofs against did: the link each forked and
+// the two attestations that follow it.
+func (s *Server) equivocations(did string) []map[string]any {
+	eqs, err := s.Store.EquivocationsFor(did)
+	if err != nil {
+		return nil
+	}
+	out := make([]map[string]any, 0, len(eqs))
+	for _, e := range eqs {
+		hash, _ := e.Hash()
+		ha, hb := e.Hashes()
+		out = append(out, map[string]any{
+			"hash": hash, "prev": e.Prev, "a": ha, "b": hb, "verified": e.Verify() == nil,
+		})
+	}
+	return out
+}
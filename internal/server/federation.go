@@ -101,18 +101,37 @@ func (s *Server) syncPeer(peer string) error {
 	}
 }
 
+// ownerAuthorizes reports whether signer speaks for owner now, under the stored
+// owner rotations — as a direct write is checked. A synced record is judged
+// when it arrives, not at the time it claims: once a key's retirement is
+// recorded here, nothing it signs is taken, however it is dated. The feed
+// delivers in order, so what the key signed before its rotation arrives first.
+func (s *Server) ownerAuthorizes(signer, owner string) bool {
+	cur, err := s.currentOwner(owner)
+	return err == nil && cur == signer
+}
+
 // ingestFederated re-verifies a synced record's signatures and stores it. Chain
 // head is NOT enforced here (unlike direct writes): a peer's records arrive in
 // its own order, and per-issuer chains are validated by readers over the full
-// set. A record that fails signature verification is dropped.
+// set. A record that fails signature verification, or that does not
+// canonicalize unambiguously, is dropped.
 func (s *Server) ingestFederated(kind string, record json.RawMessage) {
+	if _, err := core.ParseJSON(record); err != nil {
+		return
+	}
 	switch kind {
 	case "card":
 		var c core.Card
 		if json.Unmarshal(record, &c) != nil || c.Verify() != nil {
 			return
 		}
+		// A card its owner key signed after being rotated away is dropped.
+		if !s.ownerAuthorizes(c.Owner, c.Owner) {
+			return
+		}
 		if changed, _ := s.Store.PutCard(&c); changed {
+			s.checkOwnerDomain(&c)
 			_, _ = s.recomputeScore(c.ID)
 		}
 	case "attestation":
@@ -120,6 +139,21 @@ func (s *Server) ingestFederated(kind string, record json.RawMessage) {
 		if json.Unmarshal(record, &a) != nil || a.Verify() != nil {
 			return
 		}
+		// Unlike a direct write, a peer may relay what the key signed before
+		// its revocation; only what falls after the cutoff is dropped. As
+		// over HTTP, that goes for every issuer of a co-signed one.
+		var revs []*core.Revocation
+		for _, issuer := range a.Issuers() {
+			r, _ := s.Store.RevocationsFor(issuer)
+			revs = append(revs, r...)
+		}
+		if core.RevocationCutoffs(revs).Revoked(&a) {
+			return
+		}
+		// A delegated one needs its delegation, which the feed carries first.
+		if s.delegationProblem(&a) != "" {
+			return
+		}
 		if inserted, _ := s.Store.PutAttestation(&a); inserted {
 			_, _ = s.recomputeScore(a.Subject)
 		}
@@ -128,9 +162,99 @@ func (s *Server) ingestFederated(kind string, record json.RawMessage) {
 		if json.Unmarshal(record, &rot) != nil || rot.Verify() != nil {
 			return
 		}
-		// Only accept the rotation if the local old-agent card owner matches.
-		if oldCard, _ := s.Store.GetCard(rot.OldAgent); oldCard != nil && oldCard.Owner == rot.Owner {
-			_, _ = s.Store.PutRotation(&rot)
+		// Only accept the rotation if it was signed by a key that spoke for the
+		// local old-agent card's owner.
+		if oldCard, _ := s.Store.GetCard(rot.OldAgent); oldCard != nil && s.ownerAuthorizes(rot.Owner, oldCard.Owner) && s.rotationConflict(&rot) == nil {
+			if inserted, _ := s.Store.PutRotation(&rot); inserted {
+				_, _ = s.recomputeScore(rot.NewAgent)
+			}
+		}
+	case "revocation":
+		var rev core.Revocation
+		if json.Unmarshal(record, &rev) != nil || rev.Verify() != nil {
+			return
+		}
+		// As for rotations, the revoking owner must speak for the local card's
+		// (for a delegate key, a principal's).
+		owners, _ := s.keyOwners(rev.Agent)
+		for _, o := range owners {
+			if !s.ownerAuthorizes(rev.Owner, o) {
+				continue
+			}
+			if inserted, _ := s.Store.PutRevocation(&rev); inserted {
+				s.rescoreAttestedBy(rev.Agent)
+			}
+			break
+		}
+	case "delegation":
+		var d core.Delegation
+		if json.Unmarshal(record, &d) != nil || d.Verify() != nil {
+			return
+		}
+		if card, _ := s.Store.GetCard(d.Principal); card != nil && s.ownerAuthorizes(d.Owner, card.Owner) {
+			_, _ = s.Store.PutDelegation(&d)
+		}
+	case "fork_resolution":
+		var fr core.ForkResolution
+		if json.Unmarshal(record, &fr) != nil || fr.Verify() != nil {
+			return
+		}
+		// It must come from the card's current owner, as over HTTP. One that
+		// settles nothing held here is dropped.
+		if card, _ := s.Store.GetCard(fr.Agent); card != nil && s.ownerAuthorizes(fr.Owner, card.Owner) {
+			if settled, _ := s.Store.PutForkResolution(&fr); settled {
+				_, _ = s.recomputeScore(fr.Agent)
+			}
+		}
+	case "equivocation":
+		var e core.Equivocation
+		if json.Unmarshal(record, &e) != nil || e.Verify() != nil {
+			return
+		}
+		if inserted, _ := s.Store.PutEquivocation(&e); inserted {
+			_, _ = s.recomputeScore(e.Issuer)
+		}
+	case "owner_rotation":
+		var rot core.OwnerRotation
+		if json.Unmarshal(record, &rot) != nil || rot.Verify() != nil {
+			return
+		}
+		// Judged as a direct one is: a rotation of a key already retired
+		// here, or one closing a cycle, is dropped, so a leaked key rotated
+		// away long ago cannot contest its owner.
+		if s.ownerRotationConflict(&rot) != nil {
+			return
+		}
+		if inserted, _ := s.Store.PutOwnerRotation(&rot); inserted {
+			_ = s.Store.DeleteOwnerSessions(rot.OldOwner)
+			s.rescoreOwnedBy(rot.NewOwner)
+		}
+	case "guardian_set":
+		var g core.GuardianSet
+		if json.Unmarshal(record, &g) != nil || g.Verify() != nil {
+			return
+		}
+		if code, _ := s.guardianSetProblem(&g); code == 0 {
+			_, _ = s.Store.PutGuardianSet(&g)
+		}
+	case "recovery":
+		// Judged as a local one is; its waiting period runs from its arrival
+		// here.
+		var rec core.Recovery
+		if json.Unmarshal(record, &rec) != nil || rec.Verify() != nil {
+			return
+		}
+		if _, code, _ := s.recoveryProblem(&rec); code == 0 {
+			_, _ = s.Store.PutRecovery(&rec)
+		}
+	case "recovery_veto":
+		// Too late here is too late, whenever the owner says it signed.
+		var v core.RecoveryVeto
+		if json.Unmarshal(record, &v) != nil || v.Verify() != nil {
+			return
+		}
+		if _, code, _ := s.vetoProblem(&v); code == 0 {
+			_, _ = s.Store.PutRecoveryVeto(&v)
 		}
 	}
 }
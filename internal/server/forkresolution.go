@@ -0,0 +1,74 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/moltnet/moltnet/core"
+)
+
+// handleForkResolution accepts an agent- and owner-signed resolution of a card
+// fork. It must come from the card's current owner, as a card update would,
+// and settle an open fork held here; the agent's head then moves to the
+// version it chose.
+func (s *Server) handleForkResolution(w http.ResponseWriter, r *http.Request) {
+	var fr core.ForkResolution
+	if err := decodeRecord(r, &fr); err != nil {
+		writeErr(w, http.StatusBadRequest, "invalid fork resolution json: "+err.Error())
+		return
+	}
+	if err := fr.Verify(); err != nil {
+		writeErr(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	card, err := s.Store.GetCard(fr.Agent)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if card == nil {
+		writeErr(w, http.StatusNotFound, "agent not found")
+		return
+	}
+	// The card head may still name an owner key since rotated away; its
+	// successor speaks for the card.
+	if cur, err := s.currentOwner(card.Owner); err != nil || cur != fr.Owner {
+		writeErr(w, http.StatusForbidden, "fork resolution owner is not the card's current owner: "+ownerProblem(cur, err))
+		return
+	}
+	if core.IsDIDWeb(fr.Owner) {
+		if err := s.didWeb().VerifyDomain(fr.Owner, fr.OwnerKey); err != nil {
+			writeErr(w, http.StatusForbidden, "owner key is not linked to the domain: "+err.Error())
+			return
+		}
+	}
+	settled, err := s.Store.PutForkResolution(&fr)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if !settled {
+		writeErr(w, http.StatusConflict, "no open fork between the resolution's versions is held here")
+		return
+	}
+	hash, _ := fr.Hash()
+	writeJSON(w, http.StatusCreated, map[string]any{"hash": hash, "agent": fr.Agent, "head": fr.Head})
+}
+
+// handleForkResolutions lists applied fork resolutions, oldest first; ?did=
+// narrows to one agent's.
+func (s *Server) handleForkResolutions(w http.ResponseWriter, r *http.Request) {
+	var (
+		rs  []*core.ForkResolution
+		err error
+	)
+	if did := r.URL.Query().Get("did"); did != "" {
+		rs, err = s.Store.ForkResolutionsFor(did)
+	} else {
+		rs, err = s.Store.AllForkResolutions()
+	}
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"fork_resolutions": rs})
+}
@@ -0,0 +1,213 @@
+package server
+
+import (
+	"sync"
+	"time"
+
+	"github.com/moltnet/moltnet/score"
+)
+
+// defaultRecomputeDepth bounds how far one score change is pushed along
+// issuer→subject edges. Scores are rounded to 0.1, so propagation normally
+// reaches a fixed point well inside it; the bound guarantees termination on a
+// cycle that keeps flipping across a rounding boundary.
+const defaultRecomputeDepth = 8
+
+// maxRecomputeStale bounds the work list, which fills whether or not the
+// engine is running to drain it. A subject that goes stale while it is full is
+// dropped and counted: its cached score is refreshed the next time a record
+// about it is written, and StartRecompute re-queues every agent.
+const maxRecomputeStale = 10000
+
+// recomputeQueue is the background recompute engine's work list: subjects whose
+// cached score is stale because an issuer that attested them has moved. It
+// dedupes by DID, keeping the shallowest depth and the earliest time the subject
+// went stale, and holds at most limit DIDs. The zero value is ready to use.
+type recomputeQueue struct {
+	mu      sync.Mutex
+	jobs    map[string]recomputeJob
+	order   []string // FIFO of the DIDs in jobs
+	limit   int      // 0 means maxRecomputeStale
+	wake    chan struct{}
+	running bool
+	stats   recomputeStats
+}
+
+type recomputeJob struct {
+	depth int
+	since time.Time
+}
+
+// recomputeStats are the engine's lifetime counters, reported in /v1/stats.
+type recomputeStats struct {
+	Rescored     int64  // subjects re-scored by the engine
+	Changed      int64  // of which the score moved
+	DepthLimited int64  // dependents not queued: depth bound reached
+	Dropped      int64  // subjects not queued: the work list was full
+	LastDrained  string // when the queue last emptied
+}
+
+func (q *recomputeQueue) init() {
+	if q.jobs == nil {
+		q.jobs = map[string]recomputeJob{}
+		q.wake = make(chan struct{}, 1)
+	}
+}
+
+// push marks did stale at depth hops from the original change. A DID already
+// queued is coalesced; a new one is dropped when the list is full.
+func (q *recomputeQueue) push(did string, depth int) {
+	q.mu.Lock()
+	q.init()
+	limit := q.limit
+	if limit <= 0 {
+		limit = maxRecomputeStale
+	}
+	switch j, ok := q.jobs[did]; {
+	case ok:
+		j.depth = min(j.depth, depth)
+		q.jobs[did] = j
+	case len(q.order) >= limit:
+		q.stats.Dropped++
+	default:
+		q.jobs[did] = recomputeJob{depth: depth, since: time.Now()}
+		q.order = append(q.order, did)
+	}
+	q.mu.Unlock()
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// pop takes the oldest stale subject, if any.
+func (q *recomputeQueue) pop() (string, recomputeJob, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.order) == 0 {
+		return "", recomputeJob{}, false
+	}
+	did := q.order[0]
+	q.order = q.order[1:]
+	j := q.jobs[did]
+	delete(q.jobs, did)
+	return did, j, true
+}
+
+func (q *recomputeQueue) count(f func(*recomputeStats)) {
+	q.mu.Lock()
+	f(&q.stats)
+	q.mu.Unlock()
+}
+
+// snapshot reports progress and staleness: how many subjects are known stale
+// and for how long the oldest has been.
+func (q *recomputeQueue) snapshot(maxDepth int) map[string]any {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	oldest := 0.0
+	if len(q.order) > 0 {
+		oldest = time.Since(q.jobs[q.order[0]].since).Seconds()
+	}
+	return map[string]any{
+		"running":              q.running,
+		"stale":                len(q.order),
+		"oldest_stale_seconds": oldest,
+		"max_depth":            maxDepth,
+		"rescored":             q.stats.Rescored,
+		"changed":              q.stats.Changed,
+		"depth_limited":        q.stats.DepthLimited,
+		"dropped":              q.stats.Dropped,
+		"last_drained_at":      q.stats.LastDrained,
+	}
+}
+
+// StartRecompute starts the background recompute engine. It first queues every
+// registered agent, so a cold instance converges to the scores a warm one has
+// accumulated, then re-scores subjects as their issuers' scores move.
+func (s *Server) StartRecompute() {
+	q := &s.recompute
+	q.mu.Lock()
+	q.init()
+	q.running = true
+	q.mu.Unlock()
+	if dids, err := s.Store.AgentDIDs(); err != nil {
+		s.logf("recompute: list agents: %v", err)
+	} else {
+		for _, did := range dids {
+			q.push(did, 0)
+		}
+	}
+	go func() {
+		for range q.wake {
+			s.drainRecompute()
+		}
+	}()
+}
+
+// drainRecompute re-scores queued subjects until none is stale.
+func (s *Server) drainRecompute() {
+	for {
+		did, j, ok := s.recompute.pop()
+		if !ok {
+			break
+		}
+		_, changed, err := s.rescore(did, j.depth)
+		if err != nil {
+			s.logf("recompute: %s: %v", did, err)
+		}
+		s.recompute.count(func(st *recomputeStats) {
+			st.Rescored++
+			if changed {
+				st.Changed++
+			}
+		})
+	}
+	s.recompute.count(func(st *recomputeStats) { st.LastDrained = time.Now().UTC().Format(time.RFC3339) })
+}
+
+func (s *Server) recomputeDepth() int {
+	if s.RecomputeDepth > 0 {
+		return s.RecomputeDepth
+	}
+	return defaultRecomputeDepth
+}
+
+// rescore recomputes and caches did's scores and reports whether one of them
+// moved (or did had none cached yet). If so, the subjects did has attested are
+// marked stale — did's score is their issuer weight. depth is how many hops did
+// is from the change that started this; propagation stops at the bound.
+func (s *Server) rescore(did string, depth int) (score.Output, bool, error) {
+	before := s.cachedScores(did)
+	out, err := s.scoreAndCache(did)
+	if err != nil {
+		return out, false, err
+	}
+	if s.cachedScores(did) == before {
+		return out, false, nil
+	}
+	subjects, err := s.Store.SubjectsAttestedBy(did)
+	if err != nil {
+		return out, true, err
+	}
+	if depth+1 > s.recomputeDepth() {
+		s.recompute.count(func(st *recomputeStats) { st.DepthLimited += int64(len(subjects)) })
+		return out, true, nil
+	}
+	for _, sub := range subjects {
+		s.recompute.push(sub, depth+1)
+	}
+	return out, true, nil
+}
+
+// cachedScores is did's cached moltscore/v1 and default-model scores, -1 where
+// none is cached.
+func (s *Server) cachedScores(did string) [2]float64 {
+	out := [2]float64{-1, -1}
+	for i, alg := range []string{score.AlgorithmV1, s.defaultAlgorithm()} {
+		if v, ok, _ := s.Store.CachedScore(did, alg); ok {
+			out[i] = v
+		}
+	}
+	return out
+}
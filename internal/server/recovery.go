@@ -0,0 +1,244 @@
+package server
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/moltnet/moltnet/core"
+)
+
+// maxRecoverySkew is how far a recovery or veto's issued_at may run ahead of
+// this registry's clock. A recovery's waiting period runs from when it
+// arrived, so backdating gains nothing; post-dating is refused outright.
+const maxRecoverySkew = 5 * time.Minute
+
+// handleGuardianSet accepts an owner's guardian set. Only a current owner key
+// may name guardians, and a set replaces the owner's earlier ones only if it
+// was issued after them.
+func (s *Server) handleGuardianSet(w http.ResponseWriter, r *http.Request) {
+	var g core.GuardianSet
+	if err := decodeRecord(r, &g); err != nil {
+		writeErr(w, http.StatusBadRequest, "invalid guardian set json: "+err.Error())
+		return
+	}
+	if err := g.Verify(); err != nil {
+		writeErr(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if code, msg := s.guardianSetProblem(&g); code != 0 {
+		writeErr(w, code, msg)
+		return
+	}
+	hash, _ := g.Hash()
+	if _, err := s.Store.PutGuardianSet(&g); err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusCreated, map[string]any{
+		"hash": hash, "owner": g.Owner, "threshold": g.Threshold, "delay_days": g.DelayDays,
+	})
+}
+
+// guardianSetProblem reports why a verified guardian set may not be stored,
+// with the status a direct write answers, or 0 if it may. A synced set is held
+// to the same rules.
+func (s *Server) guardianSetProblem(g *core.GuardianSet) (int, string) {
+	if cur, err := s.currentOwner(g.Owner); err != nil || cur != g.Owner {
+		return http.StatusForbidden, "owner key is not current: " + ownerProblem(cur, err)
+	}
+	prev, err := s.Store.GuardianSets(g.Owner)
+	if err != nil {
+		return http.StatusInternalServerError, err.Error()
+	}
+	hash, _ := g.Hash()
+	issued, _ := time.Parse(time.RFC3339, g.IssuedAt)
+	for _, p := range prev {
+		t, _ := time.Parse(time.RFC3339, p.IssuedAt)
+		if h, _ := p.Hash(); h != hash && !issued.After(t) {
+			return http.StatusConflict, "a guardian set issued at or after " + g.IssuedAt + " is already in force"
+		}
+	}
+	return 0, ""
+}
+
+// handleGuardianSets lists guardian sets, oldest first; ?owner= narrows to one
+// owner, whose last set is the one in force.
+func (s *Server) handleGuardianSets(w http.ResponseWriter, r *http.Request) {
+	sets, err := s.Store.GuardianSets(r.URL.Query().Get("owner"))
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"guardian_sets": sets})
+}
+
+// handleRecovery accepts a guardian-signed recovery of an owner identity. It
+// must meet the threshold of the owner's guardian set in force, and an owner
+// has at most one recovery pending at a time. It takes effect after the set's
+// waiting period, counted from now, unless the owner vetoes it first.
+func (s *Server) handleRecovery(w http.ResponseWriter, r *http.Request) {
+	var rec core.Recovery
+	if err := decodeRecord(r, &rec); err != nil {
+		writeErr(w, http.StatusBadRequest, "invalid recovery json: "+err.Error())
+		return
+	}
+	if err := rec.Verify(); err != nil {
+		writeErr(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	st, code, msg := s.recoveryProblem(&rec)
+	if code != 0 {
+		writeErr(w, code, msg)
+		return
+	}
+	if _, err := s.Store.PutRecovery(&rec); err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusCreated, s.recoveryState(st.Hash, st))
+}
+
+// recoveryProblem judges a verified recovery as the store will once it is
+// stored now, and reports why it may not be, with the status a direct write
+// answers, or 0 if it may. A synced recovery is held to the same rules.
+func (s *Server) recoveryProblem(rec *core.Recovery) (core.RecoveryState, int, string) {
+	if t, _ := time.Parse(time.RFC3339, rec.IssuedAt); t.After(time.Now().Add(maxRecoverySkew)) {
+		return core.RecoveryState{}, http.StatusBadRequest, "recovery issued_at is in the future"
+	}
+	if cur, err := s.currentOwner(rec.Owner); err != nil || cur != rec.Owner {
+		return core.RecoveryState{}, http.StatusConflict, "owner key is not current: " + ownerProblem(cur, err)
+	}
+	sets, err := s.Store.GuardianSets(rec.Owner)
+	if err != nil {
+		return core.RecoveryState{}, http.StatusInternalServerError, err.Error()
+	}
+	if len(sets) == 0 {
+		return core.RecoveryState{}, http.StatusNotFound, "owner has published no guardian set"
+	}
+	states, err := s.Store.RecoveryStates(time.Now().UTC())
+	if err != nil {
+		return core.RecoveryState{}, http.StatusInternalServerError, err.Error()
+	}
+	hash, _ := rec.Hash()
+	for _, st := range states {
+		if st.Recovery.Owner == rec.Owner && st.Status == core.RecoveryPending && st.Hash != hash {
+			return core.RecoveryState{}, http.StatusConflict, "a recovery of this owner is already pending: " + st.Hash
+		}
+	}
+	// Judge it as the store will: against the set in force, from now.
+	st := core.Recoveries(sets, []*core.Recovery{rec}, nil, map[string]time.Time{hash: time.Now().UTC()}, time.Now().UTC())[0]
+	if st.Status == core.RecoveryInvalid {
+		return st, http.StatusForbidden, st.Reason
+	}
+	return st, 0, ""
+}
+
+// recoveryState returns the stored view of recovery hash, falling back to st.
+func (s *Server) recoveryState(hash string, st core.RecoveryState) core.RecoveryState {
+	if states, err := s.Store.RecoveryStates(time.Now().UTC()); err == nil {
+		for _, cur := range states {
+			if cur.Hash == hash {
+				return cur
+			}
+		}
+	}
+	return st
+}
+
+// handleRecoveries lists recoveries as judged now, with the vetoes that bear
+// on them, so a verifier can re-judge them itself; ?owner= narrows to one
+// owner.
+func (s *Server) handleRecoveries(w http.ResponseWriter, r *http.Request) {
+	owner := r.URL.Query().Get("owner")
+	states, err := s.Store.RecoveryStates(time.Now().UTC())
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	vetoes, _, err := s.Store.AllRecoveryVetoes()
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	outStates := []core.RecoveryState{}
+	for _, st := range states {
+		if owner == "" || st.Recovery.Owner == owner {
+			outStates = append(outStates, st)
+		}
+	}
+	outVetoes := []*core.RecoveryVeto{}
+	for _, v := range vetoes {
+		if owner == "" || v.Owner == owner {
+			outVetoes = append(outVetoes, v)
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"recoveries": outStates, "vetoes": outVetoes})
+}
+
+// handleRecoveryVeto accepts the owner's veto of a pending recovery: the key
+// the guardians meant to replace is not lost. It must arrive before the
+// recovery takes effect.
+func (s *Server) handleRecoveryVeto(w http.ResponseWriter, r *http.Request) {
+	var v core.RecoveryVeto
+	if err := decodeRecord(r, &v); err != nil {
+		writeErr(w, http.StatusBadRequest, "invalid veto json: "+err.Error())
+		return
+	}
+	if err := v.Verify(); err != nil {
+		writeErr(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	target, code, msg := s.vetoProblem(&v)
+	if code != 0 {
+		writeErr(w, code, msg)
+		return
+	}
+	if _, err := s.Store.PutRecoveryVeto(&v); err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusCreated, s.recoveryState(v.Recovery, target))
+}
+
+// vetoProblem finds the recovery a verified veto objects to and reports why
+// the veto may not be stored now, with the status a direct write answers, or 0
+// if it may. A veto that arrives after the recovery took effect is refused
+// whatever its issued_at says; a synced one is held to the same rules.
+func (s *Server) vetoProblem(v *core.RecoveryVeto) (core.RecoveryState, int, string) {
+	if t, _ := time.Parse(time.RFC3339, v.IssuedAt); t.After(time.Now().Add(maxRecoverySkew)) {
+		return core.RecoveryState{}, http.StatusBadRequest, "veto issued_at is in the future"
+	}
+	states, err := s.Store.RecoveryStates(time.Now().UTC())
+	if err != nil {
+		return core.RecoveryState{}, http.StatusInternalServerError, err.Error()
+	}
+	for _, st := range states {
+		switch {
+		case st.Hash != v.Recovery:
+			continue
+		case st.Recovery.Owner != v.Owner:
+			return st, http.StatusForbidden, "only the owner being recovered may veto"
+		case st.Status == core.RecoveryComplete:
+			return st, http.StatusConflict, "recovery already took effect at " + st.EffectiveAt
+		}
+		return st, 0, ""
+	}
+	return core.RecoveryState{}, http.StatusNotFound, "recovery not found"
+}
+
+// ownerRecoveries returns the recoveries of owner that are pending or were
+// vetoed, for the profiles of its agents: a pending one is public so that the
+// owner, or anyone watching, can raise the alarm in time.
+func (s *Server) ownerRecoveries(owner string) []core.RecoveryState {
+	states, err := s.Store.RecoveryStates(time.Now().UTC())
+	if err != nil {
+		return nil
+	}
+	var out []core.RecoveryState
+	for _, st := range states {
+		if st.Recovery.Owner == owner && (st.Status == core.RecoveryPending || st.Status == core.RecoveryVetoed) {
+			out = append(out, st)
+		}
+	}
+	return out
+}
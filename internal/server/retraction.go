@@ -0,0 +1,31 @@
+package server
+
+import (
+	"sort"
+
+	"github.com/moltnet/moltnet/core"
+)
+
+// retractions lists the attestations about did that their issuers withdrew,
+// each with the retraction that did so and whether its signature verifies.
+func (s *Server) retractions(did string) []map[string]any {
+	atts, err := s.Store.AttestationsForSubject(did)
+	if err != nil {
+		return nil
+	}
+	rs := core.NewRetractions(atts)
+	out := make([]map[string]any, 0, len(rs))
+	for retracted, r := range rs {
+		hash, _ := r.Hash()
+		view := map[string]any{
+			"retracted": retracted, "retraction": hash, "issuer": r.Principal(),
+			"issued_at": r.IssuedAt, "verified": r.Verify() == nil,
+		}
+		if reason, ok := r.Body["reason"].(string); ok {
+			view["reason"] = reason
+		}
+		out = append(out, view)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i]["issued_at"].(string) < out[j]["issued_at"].(string) })
+	return out
+}
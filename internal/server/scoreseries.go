@@ -0,0 +1,93 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// maxSeriesPoints bounds one series request: each point is a full recompute
+// (for a global model such as moltscore/v2, over the whole graph).
+const maxSeriesPoints = 366
+
+// seriesPoint is one sample of a score time series.
+type seriesPoint struct {
+	At    string  `json:"at"`
+	Score float64 `json:"score"`
+}
+
+// handleScoreSeries samples a subject's score every step from from to to, each
+// point computed exactly like GET /v1/score/{did}?at= — for charting how a
+// score evolved. from defaults to the subject's first attestation, to to now,
+// step to a day; to is always the last point.
+func (s *Server) handleScoreSeries(w http.ResponseWriter, r *http.Request) {
+	did := r.PathValue("did")
+	c, err := s.Store.GetCard(did)
+	if err != nil || c == nil {
+		writeErr(w, http.StatusNotFound, "agent not found")
+		return
+	}
+	q := r.URL.Query()
+	to := time.Now().UTC()
+	if v := q.Get("to"); v != "" {
+		if to, err = time.Parse(time.RFC3339, v); err != nil {
+			writeErr(w, http.StatusBadRequest, "to must be an RFC 3339 timestamp")
+			return
+		}
+	}
+	step := 24 * time.Hour
+	if v := q.Get("step"); v != "" {
+		if step, err = time.ParseDuration(v); err != nil || step <= 0 {
+			writeErr(w, http.StatusBadRequest, "step must be a positive duration, e.g. 24h")
+			return
+		}
+	}
+
+	alg, ok := s.algorithm(q.Get("algorithm"))
+	if !ok {
+		writeErr(w, http.StatusBadRequest, "unknown algorithm "+strconv.Quote(q.Get("algorithm")))
+		return
+	}
+	in, err := s.scoreInput(alg, did)
+	if err == nil {
+		in.Received, err = s.Store.Received()
+	}
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	atts := in.Attestations
+	from := to
+	if v := q.Get("from"); v != "" {
+		if from, err = time.Parse(time.RFC3339, v); err != nil {
+			writeErr(w, http.StatusBadRequest, "from must be an RFC 3339 timestamp")
+			return
+		}
+	} else if len(atts) > 0 {
+		if first, err := time.Parse(time.RFC3339, atts[0].IssuedAt); err == nil && first.Before(to) {
+			from = first // oldest first
+		}
+	}
+	if from.After(to) {
+		writeErr(w, http.StatusBadRequest, "from is after to")
+		return
+	}
+	if n := to.Sub(from)/step + 2; n > maxSeriesPoints {
+		writeErr(w, http.StatusBadRequest, "too many points ("+strconv.Itoa(int(n))+" > "+strconv.Itoa(maxSeriesPoints)+"); widen step or narrow the range")
+		return
+	}
+	var instants []time.Time
+	for t := from.UTC(); t.Before(to); t = t.Add(step) {
+		instants = append(instants, t)
+	}
+	instants = append(instants, to.UTC())
+
+	var points []seriesPoint
+	for _, t := range instants {
+		out := alg.Score(in.AsOf(t))
+		points = append(points, seriesPoint{At: t.Format(time.RFC3339), Score: out.Score})
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"did": did, "algorithm": alg.Name(), "step": step.String(), "points": points,
+	})
+}
@@ -5,9 +5,12 @@ package server
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/moltnet/moltnet/core"
@@ -34,6 +37,33 @@ type Server struct {
 	TrustedProxies []string
 	// LogWriter, if set, receives one structured JSON log line per request.
 	LogWriter io.Writer
+	// Anchors are the moltscore/v2 trust roots: the DIDs issuer weight is
+	// propagated from. Empty is honest but useless — every v2 score is the
+	// no-history baseline — so operators anchor at least their own DID.
+	Anchors []string
+	// Algorithms are the scoring models served, selectable per request with
+	// ?algorithm=. nil means score.Builtin(Anchors): moltscore/v1 and v2.
+	Algorithms *score.Registry
+	// DefaultAlgorithm names the model used when a request names none — on
+	// profiles, badges, search ranking and the dashboard. Empty means
+	// moltscore/v1.
+	DefaultAlgorithm string
+	// RecomputeDepth bounds how many issuer→subject hops one score change is
+	// propagated by the recompute engine. 0 means defaultRecomputeDepth.
+	RecomputeDepth int
+	// DIDWeb resolves the DID documents of did:web owners, to check that a
+	// card's owner key belongs to the domain it names. nil means a resolver
+	// with the default cache TTL; one without a Client is given a 10s client
+	// that refuses loopback, link-local and private addresses.
+	DIDWeb *core.DIDWebResolver
+	// StrictBodies refuses an attestation whose body fails the JSON Schema for
+	// its type (core.ValidateBody). By default such a record is stored and the
+	// violations are returned to the issuer as warnings.
+	StrictBodies bool
+
+	recompute    recomputeQueue
+	didWebOnce   sync.Once
+	domainChecks sync.Map // did:web owner checks in flight, by owner and key
 }
 
 // Handler builds the HTTP router. Go 1.22+ method+path patterns keep us on the
@@ -48,11 +78,31 @@ func (s *Server) Handler() http.Handler {
 	mux.HandleFunc("GET /v1/agents/{did}/badge.svg", s.handleBadge)
 	mux.HandleFunc("GET /v1/agents/{did}/liveness", s.handleLiveness)
 	mux.HandleFunc("GET /v1/agents/{did}/a2a", s.handleA2A)
+	mux.HandleFunc("GET /v1/agents/{did}/lineage", s.handleLineage)
+	mux.HandleFunc("GET /v1/attestations", s.handleAllAttestations)
 	mux.HandleFunc("POST /v1/attestations", s.handleAttest)
 	mux.HandleFunc("POST /v1/rotations", s.handleRotation)
+	mux.HandleFunc("POST /v1/revocations", s.handleRevocation)
+	mux.HandleFunc("GET /v1/revocations", s.handleRevocations)
+	mux.HandleFunc("POST /v1/delegations", s.handleDelegation)
+	mux.HandleFunc("GET /v1/delegations", s.handleDelegations)
+	mux.HandleFunc("POST /v1/fork-resolutions", s.handleForkResolution)
+	mux.HandleFunc("GET /v1/fork-resolutions", s.handleForkResolutions)
+	mux.HandleFunc("POST /v1/equivocations", s.handleEquivocation)
+	mux.HandleFunc("GET /v1/equivocations", s.handleEquivocations)
+	mux.HandleFunc("POST /v1/owner-rotations", s.handleOwnerRotation)
+	mux.HandleFunc("GET /v1/owner-rotations", s.handleOwnerRotations)
+	mux.HandleFunc("POST /v1/guardian-sets", s.handleGuardianSet)
+	mux.HandleFunc("GET /v1/guardian-sets", s.handleGuardianSets)
+	mux.HandleFunc("POST /v1/recoveries", s.handleRecovery)
+	mux.HandleFunc("GET /v1/recoveries", s.handleRecoveries)
+	mux.HandleFunc("POST /v1/recoveries/vetoes", s.handleRecoveryVeto)
 	mux.HandleFunc("GET /v1/issuers/{did}/head", s.handleIssuerHead)
 	mux.HandleFunc("GET /v1/search", s.handleSearch)
+	mux.HandleFunc("POST /v1/score/{did}/simulate", s.handleScoreSimulate)
+	mux.HandleFunc("GET /v1/score/basis", s.handleScoreBasis)
 	mux.HandleFunc("GET /v1/score/{did}", s.handleScore)
+	mux.HandleFunc("GET /v1/score/{did}/series", s.handleScoreSeries)
 	mux.HandleFunc("GET /v1/taxonomy", s.handleTaxonomy)
 	mux.HandleFunc("GET /v1/graph", s.handleGraph)
 	mux.HandleFunc("GET /.well-known/moltnet", s.handleWellKnown)
@@ -139,11 +189,25 @@ func writeErr(w http.ResponseWriter, code int, msg string) {
 	writeJSON(w, code, map[string]string{"error": msg})
 }
 
+// decodeRecord decodes a signed record from the request body, refusing JSON
+// that does not canonicalize unambiguously (duplicate keys, lone surrogates):
+// the signer may have read a different value out of it than we would.
+func decodeRecord(r *http.Request, v any) error {
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	if _, err := core.ParseJSON(data); err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
 // --- Handlers ---------------------------------------------------------------
 
 func (s *Server) handleRegister(w http.ResponseWriter, r *http.Request) {
 	var c core.Card
-	if err := json.NewDecoder(r.Body).Decode(&c); err != nil {
+	if err := decodeRecord(r, &c); err != nil {
 		writeErr(w, http.StatusBadRequest, "invalid card json: "+err.Error())
 		return
 	}
@@ -151,10 +215,18 @@ func (s *Server) handleRegister(w http.ResponseWriter, r *http.Request) {
 		writeErr(w, http.StatusBadRequest, err.Error())
 		return
 	}
+	// A retired (or contested) owner key can sign nothing new.
+	if cur, err := s.currentOwner(c.Owner); err != nil || cur != c.Owner {
+		writeErr(w, http.StatusForbidden, "card owner key is not current: "+ownerProblem(cur, err))
+		return
+	}
 	if _, err := s.Store.PutCard(&c); err != nil {
 		writeErr(w, http.StatusInternalServerError, err.Error())
 		return
 	}
+	// A card claiming a domain has the claim checked in the background; the
+	// profile shows the outcome.
+	s.checkOwnerDomain(&c)
 	// Opt-in liveness: record config and kick an immediate probe in the
 	// background so the profile shows a status without waiting for the sweep.
 	if c.Liveness != nil && c.Liveness.Enabled && c.Liveness.URL != "" {
@@ -184,22 +256,59 @@ func (s *Server) handleGetAgent(w http.ResponseWriter, r *http.Request) {
 	out, _ := s.recomputeScore(did)
 	live, _ := s.Store.GetLiveness(did)
 	resp := map[string]any{"card": c, "score": out, "liveness": live}
-	// Surface a card-version fork if one was detected (competing signed versions).
+	// moltscore/v2 is shown alongside whatever the default is, during migration.
+	if v2, ok := s.algorithms().Lookup(score.AlgorithmV2); ok && out.Algorithm != score.AlgorithmV2 {
+		resp["score_v2"], _ = s.computeScore(v2, did, nil, false)
+	}
+	// Surface an unresolved card-version fork (competing signed versions), and
+	// the owner's resolutions of earlier ones.
 	if fork, ferr := s.Store.GetFork(did); ferr == nil && fork != nil {
 		resp["fork"] = fork
 	}
-	// Surface whether this identity has been rotated to a newer agent key.
+	if rs, err := s.Store.ForkResolutionsFor(did); err == nil && len(rs) > 0 {
+		resp["fork_resolutions"] = rs
+	}
+	// Surface whether this identity has been rotated to a newer agent key, and
+	// the keys it was rotated from: their history counts toward its score.
 	if rots, err := s.Store.AllRotations(); err == nil {
 		if current, rerr := core.ResolveCurrentAgent(rots, did); rerr == nil && current != did {
 			resp["rotated_to"] = current
 		}
+		if lin, lerr := core.Lineage(rots, did); lerr == nil && len(lin) > 0 {
+			resp["lineage"] = lin
+		}
+	}
+	// Surface the domain behind a did:web owner, and whether it vouched for
+	// the card's owner key when last checked.
+	if d := s.ownerDomain(c); d != nil {
+		resp["owner_domain"] = d
+	}
+	// Surface an owner key rotation: the card's owner key has been succeeded.
+	if cur, err := s.currentOwner(c.Owner); err == nil && cur != c.Owner {
+		resp["current_owner"] = cur
+	}
+	// Surface a recovery of the owner still in its waiting period, or vetoed.
+	if recs := s.ownerRecoveries(c.Owner); len(recs) > 0 {
+		resp["recoveries"] = recs
+	}
+	// Surface attestations about this agent that their issuers retracted.
+	if rs := s.retractions(did); len(rs) > 0 {
+		resp["retractions"] = rs
+	}
+	// Flag an issuer proven to have forked its own attestation chain.
+	if eqs := s.equivocations(did); len(eqs) > 0 {
+		resp["equivocations"] = eqs
+	}
+	// Surface a key compromise: the owner has revoked this key.
+	if revs, err := s.Store.RevocationsFor(did); err == nil && len(revs) > 0 {
+		resp["revocations"] = revs
 	}
 	writeJSON(w, http.StatusOK, resp)
 }
 
 func (s *Server) handleRotation(w http.ResponseWriter, r *http.Request) {
 	var rot core.Rotation
-	if err := json.NewDecoder(r.Body).Decode(&rot); err != nil {
+	if err := decodeRecord(r, &rot); err != nil {
 		writeErr(w, http.StatusBadRequest, "invalid rotation json: "+err.Error())
 		return
 	}
@@ -217,20 +326,234 @@ func (s *Server) handleRotation(w http.ResponseWriter, r *http.Request) {
 		writeErr(w, http.StatusNotFound, "old_agent not found")
 		return
 	}
-	if oldCard.Owner != rot.Owner {
-		writeErr(w, http.StatusForbidden, "rotation owner does not match the agent card owner")
+	if cur, err := s.currentOwner(oldCard.Owner); err != nil || cur != rot.Owner {
+		writeErr(w, http.StatusForbidden, "rotation owner is not the agent's current owner: "+ownerProblem(cur, err))
+		return
+	}
+	if err := s.rotationConflict(&rot); err != nil {
+		writeErr(w, http.StatusConflict, err.Error())
 		return
 	}
 	if _, err := s.Store.PutRotation(&rot); err != nil {
 		writeErr(w, http.StatusInternalServerError, err.Error())
 		return
 	}
+	// The retired key's history now counts toward its successor.
+	_, _ = s.recomputeScore(rot.NewAgent)
 	hash, _ := rot.Hash()
 	writeJSON(w, http.StatusCreated, map[string]any{
 		"hash": hash, "old_agent": rot.OldAgent, "new_agent": rot.NewAgent,
 	})
 }
 
+// rotationConflict reports why rot cannot join the stored rotations: a key
+// that already has a predecessor cannot take on a second history, and a
+// rotation may not close a cycle.
+func (s *Server) rotationConflict(rot *core.Rotation) error {
+	rots, err := s.Store.AllRotations()
+	if err != nil {
+		return err
+	}
+	if _, err := core.Lineage(append(rots, rot), rot.NewAgent); err != nil {
+		return err
+	}
+	_, err = core.ResolveCurrentAgent(append(rots, rot), rot.OldAgent)
+	return err
+}
+
+// handleLineage serves the rotations that retired did's predecessor keys,
+// oldest first, for a verifier to check and fetch the predecessors' history.
+func (s *Server) handleLineage(w http.ResponseWriter, r *http.Request) {
+	did := r.PathValue("did")
+	lin, err := s.lineage(did)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if lin == nil {
+		lin = []*core.Rotation{}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"did": did, "predecessors": core.Predecessors(lin), "lineage": lin,
+	})
+}
+
+// handleRevocation accepts an owner-signed key revocation. Only the owner on
+// the agent's card may revoke it — or, for a delegate key, the owner of a
+// principal it issues for, which ends the delegation. Once stored, the key can write nothing more,
+// and what it signed from the cutoff on stops counting — so the subjects it
+// attested are re-scored.
+func (s *Server) handleRevocation(w http.ResponseWriter, r *http.Request) {
+	var rev core.Revocation
+	if err := decodeRecord(r, &rev); err != nil {
+		writeErr(w, http.StatusBadRequest, "invalid revocation json: "+err.Error())
+		return
+	}
+	if err := rev.Verify(); err != nil {
+		writeErr(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	owners, err := s.keyOwners(rev.Agent)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if len(owners) == 0 {
+		writeErr(w, http.StatusNotFound, "agent not found")
+		return
+	}
+	var problem string
+	for _, o := range owners {
+		cur, err := s.currentOwner(o)
+		if err == nil && cur == rev.Owner {
+			problem = ""
+			break
+		}
+		problem = ownerProblem(cur, err)
+	}
+	if problem != "" {
+		writeErr(w, http.StatusForbidden, "revocation owner is not the agent's current owner: "+problem)
+		return
+	}
+	if _, err := s.Store.PutRevocation(&rev); err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	s.rescoreAttestedBy(rev.Agent)
+	hash, _ := rev.Hash()
+	writeJSON(w, http.StatusCreated, map[string]any{
+		"hash": hash, "agent": rev.Agent, "effective_at": rev.EffectiveAt,
+	})
+}
+
+// currentOwner is the owner key that speaks for owner now, following owner
+// rotations.
+func (s *Server) currentOwner(owner string) (string, error) {
+	succ, err := s.Store.OwnerSuccession()
+	if err != nil {
+		return "", err
+	}
+	return succ.Current(owner)
+}
+
+// ownerProblem words a failed current-owner check for an error response.
+func ownerProblem(current string, err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	return "the current owner is " + current
+}
+
+// handleOwnerRotation accepts an owner rotation signed by the old and the new
+// owner key, unless it conflicts with those stored (see
+// ownerRotationConflict). The retired key's sessions end.
+func (s *Server) handleOwnerRotation(w http.ResponseWriter, r *http.Request) {
+	var rot core.OwnerRotation
+	if err := decodeRecord(r, &rot); err != nil {
+		writeErr(w, http.StatusBadRequest, "invalid owner rotation json: "+err.Error())
+		return
+	}
+	if err := rot.Verify(); err != nil {
+		writeErr(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := s.ownerRotationConflict(&rot); err != nil {
+		writeErr(w, http.StatusConflict, err.Error())
+		return
+	}
+	hash, _ := rot.Hash()
+	if _, err := s.Store.PutOwnerRotation(&rot); err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	_ = s.Store.DeleteOwnerSessions(rot.OldOwner)
+	s.rescoreOwnedBy(rot.NewOwner)
+	writeJSON(w, http.StatusCreated, map[string]any{
+		"hash": hash, "old_owner": rot.OldOwner, "new_owner": rot.NewOwner,
+	})
+}
+
+// ownerRotationConflict reports why rot cannot join the stored owner
+// rotations. An owner key is retired once, by the first rotation of it
+// received here: a second, different one is refused however it arrives, since
+// the retired key signed it and may be a thief's. A rotation may not close a
+// cycle either.
+func (s *Server) ownerRotationConflict(rot *core.OwnerRotation) error {
+	rots, err := s.Store.AllOwnerRotations()
+	if err != nil {
+		return err
+	}
+	hash, _ := rot.Hash()
+	for _, o := range rots {
+		if h, _ := o.Hash(); o.OldOwner == rot.OldOwner && h != hash {
+			return fmt.Errorf("owner key %s was already rotated to %s", rot.OldOwner, o.NewOwner)
+		}
+	}
+	_, err = core.NewOwnerSuccession(append(rots, rot)).Current(rot.OldOwner)
+	return err
+}
+
+// handleOwnerRotations lists stored owner rotations, oldest first, for
+// verifiers to resolve card owners to the keys that speak for them now.
+func (s *Server) handleOwnerRotations(w http.ResponseWriter, r *http.Request) {
+	rots, err := s.Store.AllOwnerRotations()
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if rots == nil {
+		rots = []*core.OwnerRotation{}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"owner_rotations": rots})
+}
+
+// rescoreOwnedBy re-scores owner's agents, whose independence from other
+// agents may have changed with the owner's key.
+func (s *Server) rescoreOwnedBy(owner string) {
+	agents, err := s.Store.AgentsByOwner(owner, score.AlgorithmV1)
+	if err != nil {
+		s.logf("rescore agents of %s: %v", owner, err)
+		return
+	}
+	for _, a := range agents {
+		_, _ = s.recomputeScore(a.DID)
+	}
+}
+
+// handleRevocations lists stored key revocations, oldest first; ?agent=
+// narrows to one key. Verifiers fetch them to disregard what revoked issuers
+// signed after their cutoff.
+func (s *Server) handleRevocations(w http.ResponseWriter, r *http.Request) {
+	var revs []*core.Revocation
+	var err error
+	if agent := r.URL.Query().Get("agent"); agent != "" {
+		revs, err = s.Store.RevocationsFor(agent)
+	} else {
+		revs, err = s.Store.AllRevocations()
+	}
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if revs == nil {
+		revs = []*core.Revocation{}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"revocations": revs})
+}
+
+// rescoreAttestedBy re-scores every subject issuer has attested, after a
+// change to how its attestations count.
+func (s *Server) rescoreAttestedBy(issuer string) {
+	subjects, err := s.Store.SubjectsAttestedBy(issuer)
+	if err != nil {
+		s.logf("rescore subjects of %s: %v", issuer, err)
+		return
+	}
+	for _, sub := range subjects {
+		_, _ = s.recomputeScore(sub)
+	}
+}
+
 func (s *Server) handleHistory(w http.ResponseWriter, r *http.Request) {
 	hist, err := s.Store.CardHistory(r.PathValue("did"))
 	if err != nil {
@@ -258,9 +581,50 @@ func (s *Server) handleAttestations(w http.ResponseWriter, r *http.Request) {
 	if next := offset + len(atts); next < total {
 		resp["next_offset"] = next
 	}
+	// Mark the records outside their validity window: they stay listed, as
+	// part of their issuer's chain, but no longer (or do not yet) count.
+	if ws := windows(atts, time.Now()); len(ws) > 0 {
+		resp["windows"] = ws
+	}
 	writeJSON(w, http.StatusOK, resp)
 }
 
+// windows maps the hash of each attestation in atts that is not in effect at
+// t to its window state, core.WindowExpired or core.WindowNotYetValid.
+func windows(atts []*core.Attestation, t time.Time) map[string]string {
+	out := map[string]string{}
+	for _, a := range atts {
+		if w := a.Window(t); w != core.WindowInEffect {
+			h, _ := a.Hash()
+			out[h] = w
+		}
+	}
+	return out
+}
+
+// handleAllAttestations pages through every attestation in the registry in
+// insertion order. moltscore/v2 issuer weights are a property of the whole
+// graph, so this is what a verifier downloads (and caches, resuming from its
+// last cursor) to reproduce them without running a federated instance.
+func (s *Server) handleAllAttestations(w http.ResponseWriter, r *http.Request) {
+	since, _ := strconv.ParseInt(r.URL.Query().Get("since"), 10, 64)
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	atts, cursor, err := s.Store.AttestationsSince(since, limit)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if atts == nil {
+		atts = []*core.Attestation{}
+	}
+	latest, _ := s.Store.LatestAttestationSeq()
+	writeJSON(w, http.StatusOK, map[string]any{
+		"attestations": atts,
+		"cursor":       cursor, // pass back as ?since= on the next page
+		"latest":       latest, // caller has the full set when cursor == latest
+	})
+}
+
 // pageParams reads limit/offset query params, defaulting limit to 100.
 func pageParams(r *http.Request) (limit, offset int) {
 	limit, _ = strconv.Atoi(r.URL.Query().Get("limit"))
@@ -289,7 +653,7 @@ func (s *Server) handleBadge(w http.ResponseWriter, r *http.Request) {
 
 func (s *Server) handleAttest(w http.ResponseWriter, r *http.Request) {
 	var a core.Attestation
-	if err := json.NewDecoder(r.Body).Decode(&a); err != nil {
+	if err := decodeRecord(r, &a); err != nil {
 		writeErr(w, http.StatusBadRequest, "invalid attestation json: "+err.Error())
 		return
 	}
@@ -297,24 +661,94 @@ func (s *Server) handleAttest(w http.ResponseWriter, r *http.Request) {
 		writeErr(w, http.StatusBadRequest, err.Error())
 		return
 	}
-	// Enforce the per-issuer hash chain: prev must match the issuer's current head.
-	head, err := s.Store.IssuerHead(a.Issuer)
-	if err != nil {
-		writeErr(w, http.StatusInternalServerError, err.Error())
-		return
+	var warnings []string
+	if err := core.ValidateBody(a.Type, a.Body); err != nil {
+		if s.StrictBodies {
+			writeErr(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		warnings = bodyViolations(err)
+	}
+	// A revoked key is refused outright, whatever issued_at it signs: a thief
+	// holding it can backdate past the cutoff. That holds for every issuer of a
+	// co-signed attestation.
+	for _, issuer := range a.Issuers() {
+		if revs, err := s.Store.RevocationsFor(issuer); err != nil {
+			writeErr(w, http.StatusInternalServerError, err.Error())
+			return
+		} else if len(revs) > 0 {
+			who := "issuer"
+			if issuer != a.Issuer {
+				who = "co-issuer " + issuer
+			}
+			writeErr(w, http.StatusForbidden, who+" key was revoked (effective "+revs[0].EffectiveAt+"); it can no longer attest")
+			return
+		}
 	}
-	if a.Prev != head {
-		writeErr(w, http.StatusConflict,
-			"attestation prev does not match issuer chain head; expected \""+head+"\"")
+	// A delegate issues only within a delegation held here.
+	if problem := s.delegationProblem(&a); problem != "" {
+		writeErr(w, http.StatusForbidden, problem)
 		return
 	}
+	// A retraction withdraws one of the issuer's own attestations held here.
+	if h := a.Retracts(); h != "" {
+		target, err := s.Store.GetAttestationByHash(h)
+		if err != nil {
+			writeErr(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if target == nil {
+			writeErr(w, http.StatusNotFound, "retracted attestation "+h+" not found")
+			return
+		}
+		if err := core.CheckRetraction(&a, target); err != nil {
+			writeErr(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	// Enforce the per-issuer hash chain: prev must match the issuer's current
+	// head, and each co-issuer's prev its own.
+	for _, issuer := range a.Issuers() {
+		head, err := s.Store.IssuerHead(issuer)
+		if err != nil {
+			writeErr(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if prev, _ := a.PrevOf(issuer); prev != head {
+			msg := "attestation prev does not match issuer chain head; expected \"" + head + "\""
+			if issuer != a.Issuer {
+				msg = "co-issuer " + issuer + " prev does not match its chain head; expected \"" + head + "\""
+			}
+			// A second successor to a link already held is an equivocation,
+			// which is kept as proof even though the attestation itself is
+			// refused.
+			if h := s.recordEquivocation(&a, issuer); h != "" {
+				msg += "; it forks the issuer's chain, recorded as equivocation " + h
+			}
+			writeErr(w, http.StatusConflict, msg)
+			return
+		}
+	}
 	if _, err := s.Store.PutAttestation(&a); err != nil {
 		writeErr(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 	out, _ := s.recomputeScore(a.Subject)
 	hash, _ := a.Hash()
-	writeJSON(w, http.StatusCreated, map[string]any{"hash": hash, "subject_score": out})
+	resp := map[string]any{"hash": hash, "subject_score": out}
+	if len(warnings) > 0 {
+		resp["warnings"] = warnings
+	}
+	writeJSON(w, http.StatusCreated, resp)
+}
+
+// bodyViolations lists what a core.ValidateBody error found.
+func bodyViolations(err error) []string {
+	var be *core.BodyError
+	if errors.As(err, &be) {
+		return be.Violations
+	}
+	return []string{err.Error()}
 }
 
 func (s *Server) handleLiveness(w http.ResponseWriter, r *http.Request) {
@@ -343,7 +777,16 @@ func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
 	q := r.URL.Query()
 	minScore, _ := strconv.ParseFloat(q.Get("min_score"), 64)
 	limit, offset := pageParams(r)
-	results, total, err := s.Store.Search(q.Get("q"), q.Get("cap"), minScore, limit, offset)
+	if c := q.Get("cap"); c != "" && !store.ValidCapability(c) {
+		writeErr(w, http.StatusBadRequest, "invalid capability tag "+strconv.Quote(c))
+		return
+	}
+	alg, ok := s.algorithm("")
+	if !ok {
+		writeErr(w, http.StatusInternalServerError, "unknown default algorithm "+strconv.Quote(s.defaultAlgorithm()))
+		return
+	}
+	results, total, err := s.Store.Search(q.Get("q"), q.Get("cap"), alg, minScore, limit, offset)
 	if err != nil {
 		writeErr(w, http.StatusInternalServerError, err.Error())
 		return
@@ -365,7 +808,29 @@ func (s *Server) handleScore(w http.ResponseWriter, r *http.Request) {
 		writeErr(w, http.StatusNotFound, "agent not found")
 		return
 	}
-	out, err := s.recomputeScore(did)
+	alg, ok := s.algorithm(r.URL.Query().Get("algorithm"))
+	if !ok {
+		writeErr(w, http.StatusBadRequest, "unknown algorithm "+strconv.Quote(r.URL.Query().Get("algorithm")))
+		return
+	}
+	wantExplain, _ := strconv.ParseBool(r.URL.Query().Get("explain"))
+	if _, can := alg.(score.Explainer); wantExplain && !can {
+		writeErr(w, http.StatusBadRequest, "explain is not available for "+alg.Name())
+		return
+	}
+	// ?at= asks for the score as it stood at a past instant: only attestations
+	// issued by then, decayed to that clock. Historical scores are not cached.
+	var at *time.Time
+	if v := r.URL.Query().Get("at"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			writeErr(w, http.StatusBadRequest, "at must be an RFC 3339 timestamp")
+			return
+		}
+		t = t.UTC()
+		at = &t
+	}
+	out, err := s.computeScore(alg, did, at, wantExplain)
 	if err != nil {
 		writeErr(w, http.StatusInternalServerError, err.Error())
 		return
@@ -373,12 +838,33 @@ func (s *Server) handleScore(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, out)
 }
 
+// handleScoreBasis serves the basis document of a moltscore/v2-family model
+// (?algorithm=, default moltscore/v2). Its hash is what every score object
+// from that model carries in `basis`.
+func (s *Server) handleScoreBasis(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("algorithm")
+	if name == "" {
+		name = score.AlgorithmV2
+	}
+	alg, ok := s.algorithms().Lookup(name)
+	if !ok {
+		writeErr(w, http.StatusNotFound, "unknown algorithm "+strconv.Quote(name))
+		return
+	}
+	v2, ok := alg.(score.V2)
+	if !ok {
+		writeErr(w, http.StatusNotFound, name+" has no basis document")
+		return
+	}
+	writeJSON(w, http.StatusOK, v2.Basis)
+}
+
 func (s *Server) handleTaxonomy(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, map[string]any{"tags": Taxonomy})
 }
 
 func (s *Server) handleGraph(w http.ResponseWriter, r *http.Request) {
-	nodes, edges, err := s.Store.Graph(r.URL.Query().Get("did"))
+	nodes, edges, err := s.Store.Graph(r.URL.Query().Get("did"), s.defaultAlgorithm())
 	if err != nil {
 		writeErr(w, http.StatusInternalServerError, err.Error())
 		return
@@ -397,54 +883,248 @@ func (s *Server) handleHealthz(w http.ResponseWriter, r *http.Request) {
 
 func (s *Server) handleStats(w http.ResponseWriter, r *http.Request) {
 	n, _ := s.Store.AgentCount()
-	writeJSON(w, http.StatusOK, map[string]any{"agents": n, "instance": s.Name})
+	writeJSON(w, http.StatusOK, map[string]any{
+		"agents": n, "instance": s.Name,
+		"recompute": s.recompute.snapshot(s.recomputeDepth()),
+	})
 }
 
 func (s *Server) handleWellKnown(w http.ResponseWriter, r *http.Request) {
+	basisHash, _ := s.basis().Hash()
 	writeJSON(w, http.StatusOK, map[string]any{
 		"name":       s.Name,
 		"software":   "moltnetd",
 		"version":    s.Version,
-		"spec":       []string{core.CardSpec, core.AttestationSpec, score.Algorithm},
+		"spec":       []string{core.CardSpec, core.AttestationSpec, score.AlgorithmV1, score.AlgorithmV2},
 		"protocols":  []string{"rest"},
 		"openapi":    "/openapi.json",
 		"federation": map[string]any{"pull_based": true, "since_cursor": true},
+		"score_algorithms": map[string]any{
+			"default":   s.defaultAlgorithm(),
+			"available": s.algorithms().Names(),
+		},
+		"score_basis": map[string]any{
+			"algorithm": score.AlgorithmV2,
+			"hash":      basisHash,
+			"url":       "/v1/score/basis",
+		},
+		"body_schemas": map[string]any{"version": core.BodySchemaVersion, "strict": s.StrictBodies},
 	})
 }
 
-// recomputeScore recomputes a subject's MoltScore using cached issuer scores as
-// issuer weights (unknown issuers weighted low as a sybil defense), caches the
-// result, and returns it. Clients can always recompute trustlessly from the raw
-// chain with default weights.
+// algorithms is the registry of scoring models this instance serves.
+func (s *Server) algorithms() *score.Registry {
+	if s.Algorithms != nil {
+		return s.Algorithms
+	}
+	return score.Builtin(s.Anchors)
+}
+
+// algorithm resolves a requested model by name; "" is the instance default.
+func (s *Server) algorithm(name string) (score.Algorithm, bool) {
+	if name == "" {
+		name = s.defaultAlgorithm()
+	}
+	return s.algorithms().Lookup(name)
+}
+
+func (s *Server) defaultAlgorithm() string {
+	if s.DefaultAlgorithm == "" {
+		return score.AlgorithmV1
+	}
+	return s.DefaultAlgorithm
+}
+
+// recomputeScore recomputes a subject's score under the instance default,
+// caches it and returns it. If it moved, the subjects it has attested are
+// queued for the recompute engine, since it is their issuer weight. Clients can
+// always recompute trustlessly from the raw chain with default weights.
 func (s *Server) recomputeScore(did string) (score.Output, error) {
-	atts, err := s.Store.AttestationsForSubject(did)
+	out, _, err := s.rescore(did, 0)
+	// A retired key's history is part of its current successor's score.
+	if rots, rerr := s.Store.AllRotations(); rerr == nil {
+		if current, cerr := core.ResolveCurrentAgent(rots, did); cerr == nil && current != did {
+			_, _, _ = s.rescore(current, 0)
+		}
+	}
+	return out, err
+}
+
+// scoreAndCache computes and caches a subject's score under the instance
+// default. The moltscore/v1 row is always refreshed too: v1 weighs issuers by
+// their cached v1 scores, so that cache must stay current whichever model is
+// shown.
+func (s *Server) scoreAndCache(did string) (score.Output, error) {
+	if s.defaultAlgorithm() != score.AlgorithmV1 {
+		if _, err := s.computeScore(score.V1{}, did, nil, false); err != nil {
+			return score.Output{}, err
+		}
+	}
+	alg, ok := s.algorithm("")
+	if !ok {
+		return score.Output{}, fmt.Errorf("default algorithm %q is not registered", s.defaultAlgorithm())
+	}
+	return s.computeScore(alg, did, nil, false)
+}
+
+// computeScore runs alg for did. A live score (at nil, no explanation) is
+// cached in alg's row of the scores table. A non-nil at asks for the score as
+// of that instant — the attestations this instance had received by then,
+// decayed to that clock — and, like an explanation, is not cached.
+func (s *Server) computeScore(alg score.Algorithm, did string, at *time.Time, explain bool) (score.Output, error) {
+	in, err := s.scoreInput(alg, did)
 	if err != nil {
 		return score.Output{}, err
 	}
-	// Per-issuer trust weight + owner resolution for the independence rule.
-	// ownerOf maps the subject and each issuer to its controlling owner so
-	// score.Compute can drop self-dealing (same-owner) attestations. Owners come
-	// from the signed cards, so the discount is reproducible by anyone who
-	// fetches those cards — it is a property of the function, not this server.
-	weights := map[string]float64{}
-	ownerOf := map[string]string{}
-	if c, _ := s.Store.GetCard(did); c != nil {
-		ownerOf[did] = c.Owner
+	if at != nil {
+		if in.Received, err = s.Store.Received(); err != nil {
+			return score.Output{}, err
+		}
+		in = in.AsOf(*at)
 	}
-	for _, a := range atts {
-		if _, seen := weights[a.Issuer]; seen {
-			continue
+	if ex, ok := alg.(score.Explainer); ok && explain {
+		return ex.Explain(in), nil
+	}
+	out := alg.Score(in)
+	if at == nil && !explain {
+		_ = s.Store.SetScore(did, out)
+	}
+	return out, nil
+}
+
+// scoreInput gathers what alg reads for did, live.
+//
+// A Global model (moltscore/v2) weighs issuers from the full attestation graph
+// and the signed cards' owners, reading no cached scores, so any instance (or
+// verifier) holding the same records and basis gets the same number on the
+// same UTC day.
+//
+// Otherwise issuer weights are the issuers' cached moltscore/v1 scores — the
+// registry keeps no history of those, so a past v1 figure is reproduced
+// exactly only on the trustless uniform basis (`molt verify --at`) — and
+// unknown issuers weigh 0.25, the primary sybil defense. ownerOf maps the
+// subject and each issuer to its controlling owner — the card owner, followed
+// through owner rotations — so self-dealing (same-owner) attestations are
+// dropped. Owners come from the signed cards, so the discount
+// is reproducible by anyone who fetches those cards — it is a property of the
+// function, not this server.
+//
+// A key rotation does not reset reputation: the attestations are those about
+// did and about every key its verified rotation lineage retired, oldest key
+// first, as `molt verify` assembles them. What a revoked key signed from its
+// cutoff on is disregarded (Input.Revoked), and a delegated attestation counts
+// for its principal (Input.Delegations).
+func (s *Server) scoreInput(alg score.Algorithm, did string) (score.Input, error) {
+	lin, err := s.lineage(did)
+	if err != nil {
+		return score.Input{}, err
+	}
+	keys := append(core.Predecessors(lin), did)
+	var atts []*core.Attestation
+	for _, k := range keys {
+		as, err := s.Store.AttestationsForSubject(k)
+		if err != nil {
+			return score.Input{}, err
 		}
-		if v, ok, _ := s.Store.CachedScore(a.Issuer); ok {
-			weights[a.Issuer] = v / 100.0
-		} else {
-			weights[a.Issuer] = 0.25 // unregistered / fresh issuer
+		atts = append(atts, as...)
+	}
+	revs, err := s.Store.AllRevocations()
+	if err != nil {
+		return score.Input{}, err
+	}
+	ds, err := s.Store.AllDelegations()
+	if err != nil {
+		return score.Input{}, err
+	}
+	eqs, err := s.Store.EquivocationsFor(did)
+	if err != nil {
+		return score.Input{}, err
+	}
+	in := score.Input{
+		Subject: did, Attestations: atts, Revoked: core.RevocationCutoffs(revs),
+		Delegations: core.NewDelegations(ds), Equivocations: eqs, Now: time.Now().UTC(),
+	}
+	if alg.Global() {
+		if in.Graph, err = s.Store.AllAttestations(); err != nil {
+			return score.Input{}, err
 		}
-		if c, _ := s.Store.GetCard(a.Issuer); c != nil {
-			ownerOf[a.Issuer] = c.Owner
+		if in.OwnerOf, err = s.Store.Owners(); err != nil {
+			return score.Input{}, err
 		}
+		return in, nil
 	}
-	out := score.Compute(atts, weights, ownerOf, time.Now().UTC())
-	_ = s.Store.SetScore(did, out)
-	return out, nil
+	owners, err := s.Store.OwnerSuccession()
+	if err != nil {
+		return score.Input{}, err
+	}
+	in.IssuerWeights = map[string]float64{}
+	in.OwnerOf = map[string]string{}
+	for _, k := range keys {
+		if c, _ := s.Store.GetCard(k); c != nil {
+			in.OwnerOf[k] = owners.Resolve(c.Owner)
+		}
+	}
+	s.weighIssuers(&in, atts, owners)
+	return in, nil
+}
+
+// weighIssuers fills in the v1 weight and current owner of every issuer of atts
+// that in does not already weigh — for a delegated attestation, its principal;
+// for a co-signed one, each issuer.
+func (s *Server) weighIssuers(in *score.Input, atts []*core.Attestation, owners core.OwnerSuccession) {
+	for _, a := range atts {
+		for _, issuer := range a.Principals() {
+			if _, seen := in.IssuerWeights[issuer]; seen {
+				continue
+			}
+			if v, ok, _ := s.Store.CachedScore(issuer, score.AlgorithmV1); ok {
+				in.IssuerWeights[issuer] = v / 100.0
+			} else {
+				in.IssuerWeights[issuer] = 0.25 // unregistered / fresh issuer
+			}
+			if c, _ := s.Store.GetCard(issuer); c != nil {
+				in.OwnerOf[issuer] = owners.Resolve(c.Owner)
+			}
+		}
+	}
+}
+
+// lineage is did's verified rotation lineage, oldest first (see
+// core.Lineage); empty if did was never rotated to.
+func (s *Server) lineage(did string) ([]*core.Rotation, error) {
+	rots, err := s.Store.AllRotations()
+	if err != nil {
+		return nil, err
+	}
+	lin, err := core.Lineage(rots, did)
+	if err != nil || len(lin) == 0 {
+		return nil, err
+	}
+	card, err := s.Store.GetCard(did)
+	if err != nil {
+		return nil, err
+	}
+	if card == nil {
+		return nil, fmt.Errorf("lineage: %s has no card to check its rotations' owner against", did)
+	}
+	succ, err := s.Store.OwnerSuccession()
+	if err != nil {
+		return nil, err
+	}
+	if err := core.VerifyLineage(lin, did, card.Owner, succ); err != nil {
+		return nil, err
+	}
+	return lin, nil
+}
+
+// basis is the instance's published moltscore/v2 basis: the one the
+// registered moltscore/v2 model runs under, by default the reference
+// parameters rooted at the operator-configured anchors.
+func (s *Server) basis() score.Basis {
+	if alg, ok := s.algorithms().Lookup(score.AlgorithmV2); ok {
+		if v2, ok := alg.(score.V2); ok {
+			return v2.Basis
+		}
+	}
+	return score.DefaultBasis(s.Anchors)
 }
@@ -6,12 +6,16 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strconv"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/moltnet/moltnet/core"
 	"github.com/moltnet/moltnet/internal/store"
+	"github.com/moltnet/moltnet/score"
 )
 
 // testEnv spins up an in-memory registry behind an httptest server.
@@ -107,6 +111,24 @@ func TestScoreDropsSameOwnerAttestations(t *testing.T) {
 		t.Fatalf("same-owner attestation must not count: completions=%d issuers=%d",
 			resp.Inputs.Completions, resp.Inputs.DistinctIssuers)
 	}
+
+	// ?explain=1 names the dropped record and why, without changing the score.
+	var ex struct {
+		Explain struct {
+			Attestations []struct {
+				Hash        string  `json:"hash"`
+				SelfDealing bool    `json:"self_dealing"`
+				Marginal    float64 `json:"marginal"`
+			} `json:"attestations"`
+		} `json:"explain"`
+	}
+	if code := getJSON(t, ts.URL+"/v1/score/"+agent.DID+"?explain=1", &ex); code != 200 {
+		t.Fatalf("explain: %d", code)
+	}
+	h, _ := a.Hash()
+	if got := ex.Explain.Attestations; len(got) != 1 || got[0].Hash != h || !got[0].SelfDealing || got[0].Marginal != 0 {
+		t.Fatalf("explain should flag the same-owner attestation as self-dealing: %+v", got)
+	}
 }
 
 func TestRegisterAttestScoreFlow(t *testing.T) {
@@ -260,302 +282,2153 @@ func TestKeyRotation(t *testing.T) {
 	}
 }
 
-func TestGraphEndpoint(t *testing.T) {
+// A rotation must not reset reputation: the successor key is scored over its
+// predecessors' attestations too, and a second history cannot be spliced in.
+func TestRotationCarriesReputation(t *testing.T) {
 	ts, cleanup := testEnv(t)
 	defer cleanup()
 
 	owner, _ := core.GenerateKeyPair()
-	subject, _ := core.GenerateKeyPair()
-	issuer, _ := core.GenerateKeyPair()
-
-	postJSON(t, ts.URL+"/v1/agents", mustCard(t, owner, subject, "subject", "code.review"))
-	postJSON(t, ts.URL+"/v1/agents", mustCard(t, owner, issuer, "issuer"))
-
-	// Two completed tasks from issuer -> subject should collapse to one weighted edge.
-	head := ""
-	for i := 0; i < 2; i++ {
-		a := core.NewAttestation(core.TypeTaskCompleted, issuer.DID, subject.DID)
-		a.Prev = head
+	a0, _ := core.GenerateKeyPair()
+	a1, _ := core.GenerateKeyPair()
+	postJSON(t, ts.URL+"/v1/agents", mustCard(t, owner, a0, "rotating-agent"))
+	postJSON(t, ts.URL+"/v1/agents", mustCard(t, owner, a1, "rotating-agent"))
+	for range 3 {
+		issuer, _ := core.GenerateKeyPair()
+		a := core.NewAttestation(core.TypeTaskCompleted, issuer.DID, a0.DID)
 		if err := a.Sign(issuer.Private); err != nil {
 			t.Fatal(err)
 		}
 		if code, body := postJSON(t, ts.URL+"/v1/attestations", a); code != 201 {
-			t.Fatalf("attest %d: %d %s", i, code, body)
-		}
-		head, _ = a.Hash()
-	}
-
-	var graph struct {
-		Nodes []struct {
-			ID   string `json:"id"`
-			Name string `json:"name"`
-		} `json:"nodes"`
-		Edges []struct {
-			Source string `json:"source"`
-			Target string `json:"target"`
-			Type   string `json:"type"`
-			Count  int    `json:"count"`
-		} `json:"edges"`
-	}
-	if code := getJSON(t, ts.URL+"/v1/graph", &graph); code != 200 {
-		t.Fatalf("graph: %d", code)
-	}
-	if len(graph.Nodes) < 2 {
-		t.Fatalf("expected >=2 nodes, got %d", len(graph.Nodes))
-	}
-	var found bool
-	for _, e := range graph.Edges {
-		if e.Source == issuer.DID && e.Target == subject.DID && e.Type == core.TypeTaskCompleted {
-			found = true
-			if e.Count != 2 {
-				t.Fatalf("expected edge count 2, got %d", e.Count)
-			}
+			t.Fatalf("attest: %d %s", code, body)
 		}
 	}
-	if !found {
-		t.Fatalf("expected an issuer->subject task.completed edge; edges=%+v", graph.Edges)
+	type scoreObj struct {
+		Score  float64 `json:"score"`
+		Inputs struct {
+			Completions int `json:"completions"`
+		} `json:"inputs"`
 	}
-}
-
-func TestCardForkSurfaced(t *testing.T) {
-	ts, cleanup := testEnv(t)
-	defer cleanup()
-
-	owner, _ := core.GenerateKeyPair()
-	agent, _ := core.GenerateKeyPair()
-
-	genesis := mustCard(t, owner, agent, "genesis")
-	if code, _ := postJSON(t, ts.URL+"/v1/agents", genesis); code != 201 {
-		t.Fatal("genesis register failed")
+	var before scoreObj
+	getJSON(t, ts.URL+"/v1/score/"+a1.DID, &before)
+	if before.Inputs.Completions != 0 {
+		t.Fatalf("fresh key should have no history yet: %+v", before)
 	}
-	gh, _ := genesis.Hash()
 
-	// A competing version branching from genesis with a different name.
-	fork := core.NewCard(agent.DID, owner.DID, "fork-branch")
-	fork.Prev = gh
-	fork.Version = "9.9.9"
-	if err := fork.Sign(agent.Private, owner.Private); err != nil {
+	rot := core.NewRotation(owner.DID, a0.DID, a1.DID)
+	if err := rot.Sign(owner.Private); err != nil {
 		t.Fatal(err)
 	}
-	// It has prev=genesis, but the head is still genesis... make head advance first.
-	update := core.NewCard(agent.DID, owner.DID, "legit-update")
-	update.Prev = gh
-	if err := update.Sign(agent.Private, owner.Private); err != nil {
-		t.Fatal(err)
+	if code, body := postJSON(t, ts.URL+"/v1/rotations", rot); code != 201 {
+		t.Fatalf("rotate: %d %s", code, body)
+	}
+	var after scoreObj
+	getJSON(t, ts.URL+"/v1/score/"+a1.DID, &after)
+	if after.Inputs.Completions != 3 || after.Score <= before.Score {
+		t.Fatalf("successor should inherit its predecessor's history: before=%+v after=%+v", before, after)
 	}
-	postJSON(t, ts.URL+"/v1/agents", update) // head -> legit-update
-	postJSON(t, ts.URL+"/v1/agents", fork)   // branches from genesis, not head -> fork
 
-	var resp struct {
-		Card struct {
-			Name string `json:"name"`
-		} `json:"card"`
-		Fork *struct {
-			CompetingHash string `json:"competing_hash"`
-		} `json:"fork"`
+	var lin struct {
+		Predecessors []string        `json:"predecessors"`
+		Lineage      []core.Rotation `json:"lineage"`
 	}
-	if code := getJSON(t, ts.URL+"/v1/agents/"+agent.DID, &resp); code != 200 {
-		t.Fatalf("get: %d", code)
+	if code := getJSON(t, ts.URL+"/v1/agents/"+a1.DID+"/lineage", &lin); code != 200 {
+		t.Fatalf("lineage: %d", code)
 	}
-	if resp.Card.Name != "legit-update" {
-		t.Fatalf("head should be legit-update, got %q", resp.Card.Name)
+	if len(lin.Predecessors) != 1 || lin.Predecessors[0] != a0.DID || lin.Lineage[0].Verify() != nil {
+		t.Fatalf("lineage should list the verified rotation from a0: %+v", lin)
 	}
-	if resp.Fork == nil {
-		t.Fatal("expected fork to be surfaced on the agent response")
+
+	// A second identity rotated into a1 would merge two track records.
+	b0, _ := core.GenerateKeyPair()
+	postJSON(t, ts.URL+"/v1/agents", mustCard(t, owner, b0, "other-agent"))
+	merge := core.NewRotation(owner.DID, b0.DID, a1.DID)
+	if err := merge.Sign(owner.Private); err != nil {
+		t.Fatal(err)
 	}
-	fh, _ := fork.Hash()
-	if resp.Fork.CompetingHash != fh {
-		t.Fatalf("fork competing hash mismatch")
+	if code, _ := postJSON(t, ts.URL+"/v1/rotations", merge); code != 409 {
+		t.Fatalf("second predecessor: got %d, want 409", code)
 	}
 }
 
-func TestAttestationPagination(t *testing.T) {
+// A stolen key keeps signing valid attestations; the owner's revocation makes
+// the registry refuse new ones and disregard those signed after the cutoff.
+func TestKeyRevocation(t *testing.T) {
 	ts, cleanup := testEnv(t)
 	defer cleanup()
 
 	owner, _ := core.GenerateKeyPair()
-	subject, _ := core.GenerateKeyPair()
 	issuer, _ := core.GenerateKeyPair()
-	postJSON(t, ts.URL+"/v1/agents", mustCard(t, owner, subject, "subject"))
+	subOwner, _ := core.GenerateKeyPair()
+	subject, _ := core.GenerateKeyPair()
+	attacker, _ := core.GenerateKeyPair()
 	postJSON(t, ts.URL+"/v1/agents", mustCard(t, owner, issuer, "issuer"))
+	postJSON(t, ts.URL+"/v1/agents", mustCard(t, subOwner, subject, "subject"))
 
-	const total = 5
-	head := ""
-	for i := 0; i < total; i++ {
+	now := time.Now().UTC().Truncate(time.Second)
+	prev := ""
+	attest := func(at time.Time) int {
 		a := core.NewAttestation(core.TypeTaskCompleted, issuer.DID, subject.DID)
-		a.Prev = head
-		a.IssuedAt = time.Now().UTC().Add(time.Duration(i) * time.Second).Format(time.RFC3339)
+		a.IssuedAt = at.Format(time.RFC3339)
+		a.Prev = prev
 		if err := a.Sign(issuer.Private); err != nil {
 			t.Fatal(err)
 		}
-		if code, body := postJSON(t, ts.URL+"/v1/attestations", a); code != 201 {
-			t.Fatalf("attest %d: %d %s", i, code, body)
+		code, _ := postJSON(t, ts.URL+"/v1/attestations", a)
+		if code == 201 {
+			prev, _ = a.Hash()
 		}
-		head, _ = a.Hash()
+		return code
 	}
+	attest(now.Add(-3 * time.Hour))
+	attest(now.Add(-30 * time.Minute)) // signed by the thief, before anyone noticed
 
-	type page struct {
-		Attestations []core.Attestation `json:"attestations"`
-		Total        int                `json:"total"`
-		Limit        int                `json:"limit"`
-		Offset       int                `json:"offset"`
-		NextOffset   *int               `json:"next_offset"`
+	type scoreObj struct {
+		Inputs struct {
+			Completions int `json:"completions"`
+		} `json:"inputs"`
 	}
-	var p1 page
-	getJSON(t, ts.URL+"/v1/agents/"+subject.DID+"/attestations?limit=2&offset=0", &p1)
-	if p1.Total != total {
-		t.Fatalf("total = %d, want %d", p1.Total, total)
+	var sc scoreObj
+	getJSON(t, ts.URL+"/v1/score/"+subject.DID, &sc)
+	if sc.Inputs.Completions != 2 {
+		t.Fatalf("before revocation: %+v", sc)
 	}
-	if len(p1.Attestations) != 2 {
-		t.Fatalf("page 1 len = %d, want 2", len(p1.Attestations))
+
+	evil := core.NewRevocation(attacker.DID, issuer.DID, now.Add(-time.Hour))
+	if err := evil.Sign(attacker.Private); err != nil {
+		t.Fatal(err)
 	}
-	if p1.NextOffset == nil || *p1.NextOffset != 2 {
-		t.Fatalf("next_offset = %v, want 2", p1.NextOffset)
+	if code, _ := postJSON(t, ts.URL+"/v1/revocations", evil); code != 403 {
+		t.Fatalf("revocation by non-owner: got %d, want 403", code)
 	}
-
-	// Last page: offset 4 -> 1 item, no next.
-	var p3 page
-	getJSON(t, ts.URL+"/v1/agents/"+subject.DID+"/attestations?limit=2&offset=4", &p3)
-	if len(p3.Attestations) != 1 {
-		t.Fatalf("last page len = %d, want 1", len(p3.Attestations))
+	rev := core.NewRevocation(owner.DID, issuer.DID, now.Add(-time.Hour))
+	if err := rev.Sign(owner.Private); err != nil {
+		t.Fatal(err)
 	}
-	if p3.NextOffset != nil {
-		t.Fatalf("expected no next_offset on last page, got %v", *p3.NextOffset)
+	if code, body := postJSON(t, ts.URL+"/v1/revocations", rev); code != 201 {
+		t.Fatalf("revocation by owner: %d %s", code, body)
 	}
-}
 
-func TestSearchPagination(t *testing.T) {
-	ts, cleanup := testEnv(t)
-	defer cleanup()
-	owner, _ := core.GenerateKeyPair()
-	for i := 0; i < 5; i++ {
-		agent, _ := core.GenerateKeyPair()
-		postJSON(t, ts.URL+"/v1/agents", mustCard(t, owner, agent, "searchable-agent", "code.review"))
+	getJSON(t, ts.URL+"/v1/score/"+subject.DID, &sc)
+	if sc.Inputs.Completions != 1 {
+		t.Fatalf("the post-cutoff attestation should no longer count: %+v", sc)
 	}
-	var resp struct {
-		Count      int  `json:"count"`
-		Total      int  `json:"total"`
-		NextOffset *int `json:"next_offset"`
+	if code := attest(now.Add(-2 * time.Hour)); code != 403 {
+		t.Fatalf("a revoked key must not write, even backdated: got %d", code)
 	}
-	getJSON(t, ts.URL+"/v1/search?cap=code.review&limit=2&offset=0", &resp)
-	if resp.Total != 5 {
-		t.Fatalf("total = %d, want 5", resp.Total)
+	var list struct {
+		Revocations []core.Revocation `json:"revocations"`
 	}
-	if resp.Count != 2 {
-		t.Fatalf("count = %d, want 2 (page size)", resp.Count)
+	if getJSON(t, ts.URL+"/v1/revocations?agent="+issuer.DID, &list); len(list.Revocations) != 1 || list.Revocations[0].Verify() != nil {
+		t.Fatalf("revocation should be listed: %+v", list)
 	}
-	if resp.NextOffset == nil || *resp.NextOffset != 2 {
-		t.Fatalf("next_offset = %v, want 2", resp.NextOffset)
+	var profile struct {
+		Revocations []core.Revocation `json:"revocations"`
+	}
+	if getJSON(t, ts.URL+"/v1/agents/"+issuer.DID, &profile); len(profile.Revocations) != 1 {
+		t.Fatalf("the revoked agent's profile should show it: %+v", profile)
 	}
 }
 
-func TestA2AResolution(t *testing.T) {
+// After an owner key rotation only the new key speaks for the owner's agents;
+// the retired key can neither rotate nor revoke them, nor register new ones.
+func TestOwnerRotation(t *testing.T) {
 	ts, cleanup := testEnv(t)
 	defer cleanup()
 
-	owner, _ := core.GenerateKeyPair()
-	agent, _ := core.GenerateKeyPair()
+	o1, _ := core.GenerateKeyPair()
+	o2, _ := core.GenerateKeyPair()
+	thief, _ := core.GenerateKeyPair()
+	a0, _ := core.GenerateKeyPair()
+	a1, _ := core.GenerateKeyPair()
+	postJSON(t, ts.URL+"/v1/agents", mustCard(t, o1, a0, "worker"))
 
-	c := core.NewCard(agent.DID, owner.DID, "resolver-agent")
-	c.Description = "does resolvable things"
-	c.Version = "1.2.3"
-	c.Capabilities = []core.Capability{{Tag: "code.review", Desc: "reviews PRs"}}
-	c.Protocols = map[string]any{
-		"a2a":  map[string]any{"agent_card": "https://agent.example/.well-known/agent.json"},
-		"http": map[string]any{"endpoint": "https://agent.example/v1"},
+	orot := core.NewOwnerRotation(o1.DID, o2.DID)
+	orot.IssuedAt = time.Now().UTC().Add(-time.Minute).Format(time.RFC3339)
+	if err := orot.Sign(o1.Private, o2.Private); err != nil {
+		t.Fatal(err)
 	}
-	if err := c.Sign(agent.Private, owner.Private); err != nil {
+	if code, body := postJSON(t, ts.URL+"/v1/owner-rotations", orot); code != 201 {
+		t.Fatalf("owner rotation: %d %s", code, body)
+	}
+	race := core.NewOwnerRotation(o1.DID, thief.DID)
+	if err := race.Sign(o1.Private, thief.Private); err != nil {
 		t.Fatal(err)
 	}
-	if code, body := postJSON(t, ts.URL+"/v1/agents", c); code != 201 {
-		t.Fatalf("register: %d %s", code, body)
+	if code, _ := postJSON(t, ts.URL+"/v1/owner-rotations", race); code != 409 {
+		t.Fatalf("second rotation of a retired owner key: got %d, want 409", code)
 	}
 
-	var a2a struct {
-		Name    string `json:"name"`
-		Version string `json:"version"`
-		URL     string `json:"url"`
-		Skills  []struct {
-			ID   string   `json:"id"`
-			Tags []string `json:"tags"`
-		} `json:"skills"`
-		Moltnet struct {
-			DID       string  `json:"did"`
-			MoltScore float64 `json:"moltscore"`
-			Verify    string  `json:"verify"`
-		} `json:"x-moltnet"`
+	if code, _ := postJSON(t, ts.URL+"/v1/agents", mustCard(t, o1, a1, "worker")); code != 403 {
+		t.Fatalf("card signed by a retired owner key: got %d, want 403", code)
 	}
-	if code := getJSON(t, ts.URL+"/v1/agents/"+agent.DID+"/a2a", &a2a); code != 200 {
-		t.Fatalf("a2a: %d", code)
+	rotate := func(by *core.KeyPair) int {
+		r := core.NewRotation(by.DID, a0.DID, a1.DID)
+		if err := r.Sign(by.Private); err != nil {
+			t.Fatal(err)
+		}
+		code, _ := postJSON(t, ts.URL+"/v1/rotations", r)
+		return code
 	}
-	if a2a.Name != "resolver-agent" || a2a.Version != "1.2.3" {
-		t.Fatalf("name/version mismatch: %+v", a2a)
+	if code := rotate(o1); code != 403 {
+		t.Fatalf("agent rotation by the retired owner key: got %d, want 403", code)
 	}
-	if a2a.URL != "https://agent.example/.well-known/agent.json" {
-		t.Fatalf("url = %q", a2a.URL)
+	rev := core.NewRevocation(o1.DID, a0.DID, time.Now())
+	if err := rev.Sign(o1.Private); err != nil {
+		t.Fatal(err)
 	}
-	if len(a2a.Skills) != 1 || a2a.Skills[0].ID != "code.review" {
-		t.Fatalf("skills = %+v", a2a.Skills)
+	if code, _ := postJSON(t, ts.URL+"/v1/revocations", rev); code != 403 {
+		t.Fatalf("revocation by the retired owner key: got %d, want 403", code)
 	}
-	if a2a.Moltnet.DID != agent.DID {
-		t.Fatalf("x-moltnet.did = %q, want %q", a2a.Moltnet.DID, agent.DID)
+
+	// The new owner key carries on: it re-signs the agent under a new key and
+	// rotates the old one, which the lineage accepts.
+	if code, body := postJSON(t, ts.URL+"/v1/agents", mustCard(t, o2, a1, "worker")); code != 201 {
+		t.Fatalf("card signed by the new owner key: %d %s", code, body)
 	}
-	if a2a.Moltnet.Verify == "" {
-		t.Fatal("expected x-moltnet.verify link")
+	if code := rotate(o2); code != 201 {
+		t.Fatalf("agent rotation by the new owner key: got %d", code)
+	}
+	var lin struct {
+		Predecessors []string `json:"predecessors"`
+	}
+	if getJSON(t, ts.URL+"/v1/agents/"+a1.DID+"/lineage", &lin); len(lin.Predecessors) != 1 || lin.Predecessors[0] != a0.DID {
+		t.Fatalf("lineage across the owner rotation: %+v", lin)
+	}
+	var profile struct {
+		CurrentOwner string `json:"current_owner"`
+	}
+	if getJSON(t, ts.URL+"/v1/agents/"+a0.DID, &profile); profile.CurrentOwner != o2.DID {
+		t.Fatalf("profile should name the current owner key: %+v", profile)
+	}
+	var list struct {
+		OwnerRotations []core.OwnerRotation `json:"owner_rotations"`
+	}
+	if getJSON(t, ts.URL+"/v1/owner-rotations", &list); len(list.OwnerRotations) != 1 || list.OwnerRotations[0].Verify() != nil {
+		t.Fatalf("owner rotation should be listed: %+v", list)
 	}
 }
 
-func TestOpenAPISpec(t *testing.T) {
-	ts, cleanup := testEnv(t)
-	defer cleanup()
-
-	resp, err := http.Get(ts.URL + "/openapi.json")
+// A retired owner key cannot slip a record past its rotation over federation by
+// dating it before the rotation: the record is judged when it arrives.
+func TestFederatedOwnerRetirement(t *testing.T) {
+	st, err := store.Open(":memory:")
 	if err != nil {
 		t.Fatal(err)
 	}
-	defer resp.Body.Close()
-	if resp.StatusCode != 200 {
-		t.Fatalf("status %d", resp.StatusCode)
-	}
-	if ct := resp.Header.Get("Content-Type"); ct != "application/json" {
-		t.Fatalf("content-type = %q", ct)
+	defer st.Close()
+	srv := &Server{Store: st, Name: "test", Version: "test"}
+
+	o1, _ := core.GenerateKeyPair()
+	o2, _ := core.GenerateKeyPair()
+	a0, _ := core.GenerateKeyPair()
+	a1, _ := core.GenerateKeyPair()
+	relay := func(kind string, v any) {
+		raw, _ := json.Marshal(v)
+		srv.ingestFederated(kind, raw)
+	}
+	relay("card", mustCard(t, o1, a0, "worker"))
+	orot := core.NewOwnerRotation(o1.DID, o2.DID)
+	if err := orot.Sign(o1.Private, o2.Private); err != nil {
+		t.Fatal(err)
 	}
-	var doc struct {
-		OpenAPI string         `json:"openapi"`
-		Info    map[string]any `json:"info"`
-		Paths   map[string]any `json:"paths"`
+	relay("owner_rotation", orot)
+
+	late := core.NewCard(a1.DID, o1.DID, "worker")
+	late.CreatedAt = time.Now().UTC().Add(-time.Hour).Format(time.RFC3339)
+	if err := late.Sign(a1.Private, o1.Private); err != nil {
+		t.Fatal(err)
 	}
-	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
-		t.Fatalf("openapi.json is not valid JSON: %v", err)
+	relay("card", late)
+	// Nor can it contest the owner with a second rotation, or hand the
+	// identity back round a cycle.
+	thief, _ := core.GenerateKeyPair()
+	race := core.NewOwnerRotation(o1.DID, thief.DID)
+	race.IssuedAt = late.CreatedAt
+	if err := race.Sign(o1.Private, thief.Private); err != nil {
+		t.Fatal(err)
 	}
-	if !strings.HasPrefix(doc.OpenAPI, "3.") {
-		t.Fatalf("expected OpenAPI 3.x, got %q", doc.OpenAPI)
+	relay("owner_rotation", race)
+	back := core.NewOwnerRotation(o2.DID, o1.DID)
+	if err := back.Sign(o2.Private, o1.Private); err != nil {
+		t.Fatal(err)
 	}
-	for _, p := range []string{"/v1/agents", "/v1/agents/{did}", "/v1/search", "/v1/attestations", "/v1/score/{did}"} {
-		if _, ok := doc.Paths[p]; !ok {
-			t.Fatalf("openapi paths missing %q", p)
-		}
+	relay("owner_rotation", back)
+	if cur, err := srv.currentOwner(o1.DID); err != nil || cur != o2.DID {
+		t.Fatalf("the first rotation should keep the owner: %q %v", cur, err)
 	}
-}
-
-func TestHealthz(t *testing.T) {
-	ts, cleanup := testEnv(t)
-	defer cleanup()
-	var resp struct {
-		Status string `json:"status"`
+	if c, _ := st.GetCard(a0.DID); c == nil {
+		t.Fatal("a card relayed before the rotation should be kept")
 	}
-	if code := getJSON(t, ts.URL+"/healthz", &resp); code != 200 {
-		t.Fatalf("healthz status %d", code)
+	if c, _ := st.GetCard(a1.DID); c != nil {
+		t.Fatal("a backdated card signed by the retired owner key was accepted")
 	}
-	if resp.Status != "ok" {
-		t.Fatalf("healthz body = %q", resp.Status)
+	relay("card", mustCard(t, o2, a1, "worker"))
+	if c, _ := st.GetCard(a1.DID); c == nil {
+		t.Fatal("a card signed by the new owner key should be accepted")
 	}
 }
 
-func TestUnknownAgentIs404(t *testing.T) {
-	ts, cleanup := testEnv(t)
-	defer cleanup()
-	if code := getJSON(t, ts.URL+"/v1/agents/did:key:zNope", nil); code != 404 {
-		t.Fatalf("expected 404, got %d", code)
+// A co-signed attestation relayed over federation is dropped once any of its
+// issuers' keys is revoked, as it is refused over HTTP; what the key co-signed
+// before the cutoff is kept.
+func TestFederatedCoIssuerRevocation(t *testing.T) {
+	st, err := store.Open(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+	srv := &Server{Store: st, Name: "test", Version: "test"}
+	relay := func(kind string, v any) {
+		raw, _ := json.Marshal(v)
+		srv.ingestFederated(kind, raw)
+	}
+	owner, _ := core.GenerateKeyPair()
+	lead, _ := core.GenerateKeyPair()
+	co, _ := core.GenerateKeyPair()
+	subject, _ := core.GenerateKeyPair()
+	for _, a := range []*core.KeyPair{lead, co, subject} {
+		relay("card", mustCard(t, owner, a, "worker"))
+	}
+	cutoff := time.Now().UTC().Add(-time.Hour)
+	cosigned := func(at time.Time) {
+		a := core.NewAttestation(core.TypeTaskCompleted, lead.DID, subject.DID)
+		a.IssuedAt = at.Format(time.RFC3339)
+		a.CoIssuers = []core.CoIssuer{{Issuer: co.DID}}
+		if err := a.Sign(lead.Private); err != nil {
+			t.Fatal(err)
+		}
+		if err := a.AddCoSig(co); err != nil {
+			t.Fatal(err)
+		}
+		relay("attestation", a)
+	}
+	rev := core.NewRevocation(owner.DID, co.DID, cutoff)
+	if err := rev.Sign(owner.Private); err != nil {
+		t.Fatal(err)
+	}
+	relay("revocation", rev)
+	cosigned(cutoff.Add(-time.Hour))
+	cosigned(cutoff.Add(time.Minute))
+	atts, err := st.AttestationsForSubject(subject.DID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(atts) != 1 || atts[0].IssuedAt != cutoff.Add(-time.Hour).Format(time.RFC3339) {
+		t.Fatalf("only the record co-signed before the cutoff should be kept: %d stored", len(atts))
+	}
+}
+
+// A team-owned agent registers and rotates on a threshold of its owner
+// policy's member signatures.
+func TestOwnerPolicy(t *testing.T) {
+	ts, cleanup := testEnv(t)
+	defer cleanup()
+
+	m1, _ := core.GenerateKeyPair()
+	m2, _ := core.GenerateKeyPair()
+	m3, _ := core.GenerateKeyPair()
+	a0, _ := core.GenerateKeyPair()
+	a1, _ := core.GenerateKeyPair()
+	policy, _ := core.NewOwnerPolicy(2, m1.DID, m2.DID, m3.DID)
+	did, _ := policy.DID()
+
+	card := func(agent *core.KeyPair, signers ...*core.KeyPair) *core.Card {
+		c := core.NewCard(agent.DID, did, "team-agent")
+		c.OwnerPolicy = policy
+		if err := c.SignAgent(agent.Private); err != nil {
+			t.Fatal(err)
+		}
+		for _, m := range signers {
+			if err := c.AddOwnerSig(m); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return c
+	}
+	if code, _ := postJSON(t, ts.URL+"/v1/agents", card(a0, m1)); code != 400 {
+		t.Fatalf("card short of the threshold: got %d, want 400", code)
+	}
+	if code, body := postJSON(t, ts.URL+"/v1/agents", card(a0, m1, m2)); code != 201 {
+		t.Fatalf("card at the threshold: %d %s", code, body)
+	}
+	postJSON(t, ts.URL+"/v1/agents", card(a1, m2, m3))
+
+	rot := core.NewRotation(did, a0.DID, a1.DID)
+	rot.OwnerPolicy = policy
+	_ = rot.AddOwnerSig(m3)
+	if code, _ := postJSON(t, ts.URL+"/v1/rotations", rot); code != 400 {
+		t.Fatalf("rotation short of the threshold: got %d, want 400", code)
+	}
+	_ = rot.AddOwnerSig(m1)
+	if code, body := postJSON(t, ts.URL+"/v1/rotations", rot); code != 201 {
+		t.Fatalf("rotation at the threshold: %d %s", code, body)
+	}
+	// A single member is not the owner.
+	solo := core.NewRotation(m1.DID, a1.DID, a0.DID)
+	_ = solo.Sign(m1.Private)
+	if code, _ := postJSON(t, ts.URL+"/v1/rotations", solo); code != 403 {
+		t.Fatalf("rotation by one member alone: got %d, want 403", code)
+	}
+	var lin struct {
+		Predecessors []string `json:"predecessors"`
+	}
+	if getJSON(t, ts.URL+"/v1/agents/"+a1.DID+"/lineage", &lin); len(lin.Predecessors) != 1 {
+		t.Fatalf("policy-signed lineage: %+v", lin)
+	}
+}
+
+// Guardians start a recovery of a lost owner key; it waits out the guardian
+// set's delay, shows on the owner's agents' profiles, and the owner can veto it.
+func TestRecovery(t *testing.T) {
+	ts, cleanup := testEnv(t)
+	defer cleanup()
+
+	owner, _ := core.GenerateKeyPair()
+	fresh, _ := core.GenerateKeyPair()
+	rival, _ := core.GenerateKeyPair()
+	g1, _ := core.GenerateKeyPair()
+	g2, _ := core.GenerateKeyPair()
+	g3, _ := core.GenerateKeyPair()
+	agent, _ := core.GenerateKeyPair()
+	postJSON(t, ts.URL+"/v1/agents", mustCard(t, owner, agent, "worker"))
+
+	recover := func(to *core.KeyPair, guardians ...*core.KeyPair) *core.Recovery {
+		var sets struct {
+			GuardianSets []*core.GuardianSet `json:"guardian_sets"`
+		}
+		getJSON(t, ts.URL+"/v1/guardian-sets?owner="+owner.DID, &sets)
+		if len(sets.GuardianSets) == 0 {
+			t.Fatal("guardian set should be listed")
+		}
+		h, _ := sets.GuardianSets[len(sets.GuardianSets)-1].Hash()
+		rec := core.NewRecovery(owner.DID, to.DID, h)
+		if err := rec.SignNew(to.Private); err != nil {
+			t.Fatal(err)
+		}
+		for _, g := range guardians {
+			if err := rec.AddGuardianSig(g); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return rec
+	}
+
+	set := core.NewGuardianSet(owner.DID, 2, g1.DID, g2.DID, g3.DID)
+	set.IssuedAt = time.Now().UTC().Add(-time.Hour).Format(time.RFC3339)
+	if err := set.Sign(owner.Private); err != nil {
+		t.Fatal(err)
+	}
+	if code, body := postJSON(t, ts.URL+"/v1/guardian-sets", set); code != 201 {
+		t.Fatalf("guardian set: %d %s", code, body)
+	}
+	if code, _ := postJSON(t, ts.URL+"/v1/recoveries", recover(fresh, g1)); code != 403 {
+		t.Fatalf("recovery below the guardian threshold: got %d, want 403", code)
+	}
+	code, body := postJSON(t, ts.URL+"/v1/recoveries", recover(fresh, g1, g3))
+	if code != 201 {
+		t.Fatalf("recovery: %d %s", code, body)
+	}
+	var st core.RecoveryState
+	json.Unmarshal(body, &st)
+	if st.Status != core.RecoveryPending {
+		t.Fatalf("a fresh recovery should be pending: %+v", st)
+	}
+	if eff, _ := time.Parse(time.RFC3339, st.EffectiveAt); eff.Before(time.Now().Add(6 * 24 * time.Hour)) {
+		t.Fatalf("recovery should wait out the delay: effective %s", st.EffectiveAt)
+	}
+	other := recover(rival, g2, g3)
+	if code, _ := postJSON(t, ts.URL+"/v1/recoveries", other); code != 409 {
+		t.Fatalf("second pending recovery: got %d, want 409", code)
+	}
+
+	// Until it takes effect the old key still speaks for the owner, and the
+	// pending recovery is public on the owner's agents.
+	if code, body := postJSON(t, ts.URL+"/v1/agents", mustCard(t, owner, agent, "worker-2")); code != 201 {
+		t.Fatalf("owner key during the waiting period: %d %s", code, body)
+	}
+	var profile struct {
+		Recoveries []core.RecoveryState `json:"recoveries"`
+	}
+	if getJSON(t, ts.URL+"/v1/agents/"+agent.DID, &profile); len(profile.Recoveries) != 1 || profile.Recoveries[0].Hash != st.Hash {
+		t.Fatalf("profile should show the pending recovery: %+v", profile)
+	}
+
+	veto := func(by *core.KeyPair) int {
+		v := core.NewRecoveryVeto(by.DID, st.Hash)
+		if err := v.Sign(by.Private); err != nil {
+			t.Fatal(err)
+		}
+		code, _ := postJSON(t, ts.URL+"/v1/recoveries/vetoes", v)
+		return code
+	}
+	if code := veto(g2); code != 403 {
+		t.Fatalf("veto by a guardian: got %d, want 403", code)
+	}
+	if code := veto(owner); code != 201 {
+		t.Fatalf("veto by the owner: got %d", code)
+	}
+	var list struct {
+		Recoveries []core.RecoveryState `json:"recoveries"`
+		Vetoes     []core.RecoveryVeto  `json:"vetoes"`
+	}
+	getJSON(t, ts.URL+"/v1/recoveries?owner="+owner.DID, &list)
+	if len(list.Recoveries) != 1 || list.Recoveries[0].Status != core.RecoveryVetoed || len(list.Vetoes) != 1 {
+		t.Fatalf("recovery should be vetoed: %+v", list)
+	}
+	if code, _ := postJSON(t, ts.URL+"/v1/recoveries", other); code != 201 {
+		t.Fatalf("new recovery once the last was vetoed: got %d", code)
+	}
+}
+
+// Synced guardian sets, recoveries and vetoes are held to the rules a direct
+// write is: a retired owner key names no guardians, and a veto is judged by
+// when it arrives, not when it claims to have been signed.
+func TestFederatedRecovery(t *testing.T) {
+	st, err := store.Open(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+	srv := &Server{Store: st, Name: "test", Version: "test"}
+	relay := func(kind string, v any) {
+		raw, _ := json.Marshal(v)
+		srv.ingestFederated(kind, raw)
+	}
+
+	owner, _ := core.GenerateKeyPair()
+	fresh, _ := core.GenerateKeyPair()
+	g1, _ := core.GenerateKeyPair()
+	retired, _ := core.GenerateKeyPair()
+	successor, _ := core.GenerateKeyPair()
+	now := time.Now().UTC()
+
+	orot := core.NewOwnerRotation(retired.DID, successor.DID)
+	if err := orot.Sign(retired.Private, successor.Private); err != nil {
+		t.Fatal(err)
+	}
+	relay("owner_rotation", orot)
+	stale := core.NewGuardianSet(retired.DID, 1, g1.DID)
+	stale.IssuedAt = now.Add(-time.Hour).Format(time.RFC3339)
+	if err := stale.Sign(retired.Private); err != nil {
+		t.Fatal(err)
+	}
+	relay("guardian_set", stale)
+	if sets, _ := st.GuardianSets(retired.DID); len(sets) != 0 {
+		t.Fatal("a guardian set signed by a retired owner key was accepted")
+	}
+
+	set := core.NewGuardianSet(owner.DID, 1, g1.DID)
+	set.DelayDays = 1
+	set.IssuedAt = now.AddDate(0, 0, -3).Format(time.RFC3339)
+	if err := set.Sign(owner.Private); err != nil {
+		t.Fatal(err)
+	}
+	relay("guardian_set", set)
+	setHash, _ := set.Hash()
+	rec := core.NewRecovery(owner.DID, fresh.DID, setHash)
+	rec.IssuedAt = now.AddDate(0, 0, -2).Format(time.RFC3339)
+	if err := rec.SignNew(fresh.Private); err != nil {
+		t.Fatal(err)
+	}
+	if err := rec.AddGuardianSig(g1); err != nil {
+		t.Fatal(err)
+	}
+	relay("recovery", rec)
+	recHash, _ := rec.Hash()
+	// It reached this registry two days ago, so its day's wait is over.
+	if _, err := st.DB().Exec(`UPDATE recoveries SET received_at = ? WHERE hash = ?`, rec.IssuedAt, recHash); err != nil {
+		t.Fatal(err)
+	}
+
+	veto := core.NewRecoveryVeto(owner.DID, recHash)
+	veto.IssuedAt = now.AddDate(0, 0, -2).Add(time.Hour).Format(time.RFC3339)
+	if err := veto.Sign(owner.Private); err != nil {
+		t.Fatal(err)
+	}
+	relay("recovery_veto", veto)
+	if vetoes, _, _ := st.AllRecoveryVetoes(); len(vetoes) != 0 {
+		t.Fatal("a veto arriving after the recovery took effect was accepted")
+	}
+	states, _ := st.RecoveryStates(now)
+	if len(states) != 1 || states[0].Status != core.RecoveryComplete {
+		t.Fatalf("recovery should be complete despite the backdated veto: %+v", states)
+	}
+}
+
+// Two workers issue for one principal under owner-signed delegations; their
+// attestations count for the principal until a worker's key is revoked.
+func TestDelegation(t *testing.T) {
+	ts, cleanup := testEnv(t)
+	defer cleanup()
+
+	owner, _ := core.GenerateKeyPair()
+	principal, _ := core.GenerateKeyPair()
+	w1, _ := core.GenerateKeyPair()
+	w2, _ := core.GenerateKeyPair()
+	stranger, _ := core.GenerateKeyPair()
+	subOwner, _ := core.GenerateKeyPair()
+	subject, _ := core.GenerateKeyPair()
+	postJSON(t, ts.URL+"/v1/agents", mustCard(t, owner, principal, "issuer"))
+	postJSON(t, ts.URL+"/v1/agents", mustCard(t, subOwner, subject, "subject"))
+
+	now := time.Now().UTC().Truncate(time.Second)
+	delegate := func(by, worker *core.KeyPair) (int, string) {
+		d := core.NewDelegation(by.DID, principal.DID, worker.DID, now.Add(24*time.Hour))
+		d.IssuedAt = now.Add(-time.Hour).Format(time.RFC3339)
+		d.Types = []string{core.TypeTaskCompleted}
+		if err := d.Sign(by.Private); err != nil {
+			t.Fatal(err)
+		}
+		code, _ := postJSON(t, ts.URL+"/v1/delegations", d)
+		h, _ := d.Hash()
+		return code, h
+	}
+	if code, _ := delegate(stranger, w1); code != 403 {
+		t.Fatalf("delegation by a stranger: got %d, want 403", code)
+	}
+	c1, h1 := delegate(owner, w1)
+	c2, h2 := delegate(owner, w2)
+	if c1 != 201 || c2 != 201 {
+		t.Fatalf("delegations by the owner: got %d and %d", c1, c2)
+	}
+
+	prev := map[string]string{}
+	attest := func(worker *core.KeyPair, delegation, typ string, at time.Time) int {
+		a := core.NewAttestation(typ, worker.DID, subject.DID)
+		a.OnBehalfOf, a.Delegation = principal.DID, delegation
+		a.IssuedAt = at.Format(time.RFC3339)
+		a.Prev = prev[worker.DID]
+		if err := a.Sign(worker.Private); err != nil {
+			t.Fatal(err)
+		}
+		code, _ := postJSON(t, ts.URL+"/v1/attestations", a)
+		if code == 201 {
+			prev[worker.DID], _ = a.Hash()
+		}
+		return code
+	}
+	if code := attest(w1, h1, core.TypeTaskCompleted, now.Add(-30*time.Minute)); code != 201 {
+		t.Fatalf("delegated attestation from w1: got %d", code)
+	}
+	if code := attest(w2, h2, core.TypeTaskCompleted, now.Add(-30*time.Minute)); code != 201 {
+		t.Fatalf("delegated attestation from w2 on its own chain: got %d", code)
+	}
+	if code := attest(w1, h1, core.TypeEndorsement, now.Add(-20*time.Minute)); code != 403 {
+		t.Fatalf("type outside the delegation's scope: got %d, want 403", code)
+	}
+	if code := attest(w2, h1, core.TypeTaskCompleted, now.Add(-20*time.Minute)); code != 403 {
+		t.Fatalf("attestation under another worker's delegation: got %d, want 403", code)
+	}
+
+	type scoreObj struct {
+		Inputs struct {
+			Completions int `json:"completions"`
+		} `json:"inputs"`
+	}
+	var sc scoreObj
+	if getJSON(t, ts.URL+"/v1/score/"+subject.DID, &sc); sc.Inputs.Completions != 2 {
+		t.Fatalf("both delegated completions should count: %+v", sc)
+	}
+
+	rev := core.NewRevocation(owner.DID, w1.DID, now.Add(-10*time.Minute))
+	if err := rev.Sign(owner.Private); err != nil {
+		t.Fatal(err)
+	}
+	if code, body := postJSON(t, ts.URL+"/v1/revocations", rev); code != 201 {
+		t.Fatalf("revoking a delegate key: %d %s", code, body)
+	}
+	if code := attest(w1, h1, core.TypeTaskCompleted, now.Add(-5*time.Minute)); code != 403 {
+		t.Fatalf("a revoked delegate must not write: got %d", code)
+	}
+	if code, _ := delegate(owner, w1); code != 403 {
+		t.Fatalf("re-delegating to a revoked key: got %d, want 403", code)
+	}
+	var list struct {
+		Delegations []core.Delegation `json:"delegations"`
+	}
+	if getJSON(t, ts.URL+"/v1/delegations?did="+principal.DID, &list); len(list.Delegations) != 2 {
+		t.Fatalf("both delegations should be listed: %+v", list)
+	}
+}
+
+// localDomain routes every request to a local stand-in for the domain.
+type localDomain struct{ target string }
+
+func (l localDomain) RoundTrip(r *http.Request) (*http.Response, error) {
+	r = r.Clone(r.Context())
+	r.URL.Scheme, r.URL.Host = "http", strings.TrimPrefix(l.target, "http://")
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+// A card owned by did:web:acme.example has its domain checked once written,
+// directly or over federation, and the profile shows the outcome: verified for
+// a key the domain's DID document lists, not for any other.
+func TestDIDWebOwner(t *testing.T) {
+	owner, _ := core.GenerateKeyPair()
+	squatter, _ := core.GenerateKeyPair()
+	agent, _ := core.GenerateKeyPair()
+	other, _ := core.GenerateKeyPair()
+	relayed, _ := core.GenerateKeyPair()
+	const did = "did:web:acme.example"
+	doc, _ := core.NewDIDDocument(did, owner.DID)
+	var fetches atomic.Int32
+	domain := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/.well-known/did.json" {
+			http.NotFound(w, r)
+			return
+		}
+		fetches.Add(1)
+		_ = json.NewEncoder(w).Encode(doc)
+	}))
+	defer domain.Close()
+
+	st, err := store.Open(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+	srv := &Server{Store: st, Name: "test", Version: "test",
+		DIDWeb: core.NewDIDWebResolver(&http.Client{Transport: localDomain{domain.URL}})}
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+
+	card := func(agent, key *core.KeyPair) *core.Card {
+		c := core.NewCard(agent.DID, did, "acme-agent")
+		c.OwnerKey = key.DID
+		if err := c.Sign(agent.Private, key.Private); err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+	type ownerDomain struct {
+		Domain   string `json:"domain"`
+		Verified bool   `json:"verified"`
+		Pending  bool   `json:"pending"`
+		Error    string `json:"error"`
+	}
+	checked := func(did string) ownerDomain {
+		t.Helper()
+		for range 200 {
+			var profile struct {
+				OwnerDomain ownerDomain `json:"owner_domain"`
+			}
+			if getJSON(t, ts.URL+"/v1/agents/"+did, &profile); !profile.OwnerDomain.Pending {
+				return profile.OwnerDomain
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+		t.Fatalf("%s: domain check never completed", did)
+		return ownerDomain{}
+	}
+	if code, body := postJSON(t, ts.URL+"/v1/agents", card(other, squatter)); code != 201 {
+		t.Fatalf("card signed by a key the domain does not list: %d %s", code, body)
+	}
+	if d := checked(other.DID); d.Verified || d.Error == "" {
+		t.Fatalf("a key the domain does not list should not verify: %+v", d)
+	}
+	if code, body := postJSON(t, ts.URL+"/v1/agents", card(agent, owner)); code != 201 {
+		t.Fatalf("card signed by the domain's key: %d %s", code, body)
+	}
+	if d := checked(agent.DID); !d.Verified || d.Domain != "acme.example" {
+		t.Fatalf("profile should show the verified domain: %+v", d)
+	}
+	// A federated card is checked too; the owner key's outcome is reused, so
+	// viewing profiles fetches nothing more.
+	before := fetches.Load()
+	raw, _ := json.Marshal(card(relayed, owner))
+	srv.ingestFederated("card", raw)
+	for range 3 {
+		if d := checked(relayed.DID); !d.Verified {
+			t.Fatalf("federated card should show the verified domain: %+v", d)
+		}
+	}
+	if n := fetches.Load(); n != before {
+		t.Fatalf("fresh outcome refetched: %d fetches, want %d", n, before)
+	}
+}
+
+// The server's did:web client refuses to dial its own network.
+func TestDIDWebPublicOnly(t *testing.T) {
+	for _, addr := range []string{"127.0.0.1:443", "[::1]:443", "169.254.169.254:80", "10.0.0.8:443",
+		"192.168.1.1:443", "[fe80::1]:443", "0.0.0.0:443", "[::ffff:127.0.0.1]:443"} {
+		if publicAddrOnly("tcp", addr, nil) == nil {
+			t.Errorf("%s: dial allowed", addr)
+		}
+	}
+	if err := publicAddrOnly("tcp", "93.184.216.34:443", nil); err != nil {
+		t.Errorf("public address refused: %v", err)
+	}
+}
+
+// A decimal amount verifies however it is spelled, but a body whose meaning
+// depends on the parser (a repeated key) is refused before its signature is
+// even checked.
+func TestAttestationJSONStrict(t *testing.T) {
+	ts, cleanup := testEnv(t)
+	defer cleanup()
+	owner, _ := core.GenerateKeyPair()
+	agent, _ := core.GenerateKeyPair()
+	issuerOwner, _ := core.GenerateKeyPair()
+	issuer, _ := core.GenerateKeyPair()
+	postJSON(t, ts.URL+"/v1/agents", mustCard(t, owner, agent, "payee"))
+	postJSON(t, ts.URL+"/v1/agents", mustCard(t, issuerOwner, issuer, "payer"))
+
+	a := core.NewAttestation(core.TypePaymentReceipt, issuer.DID, agent.DID)
+	a.Body = map[string]any{"amount": 19.9, "currency": "USD"}
+	if err := a.Sign(issuer.Private); err != nil {
+		t.Fatal(err)
+	}
+	raw, _ := json.Marshal(a)
+	dup := strings.Replace(string(raw), `"amount":19.9`, `"amount":0.01,"amount":19.9`, 1)
+	if code, body := postJSON(t, ts.URL+"/v1/attestations", json.RawMessage(dup)); code != 400 || !strings.Contains(string(body), "duplicate key") {
+		t.Fatalf("duplicate key: want 400, got %d: %s", code, body)
+	}
+	respelled := strings.Replace(string(raw), `"amount":19.9`, `"amount":1.990e1`, 1)
+	if code, body := postJSON(t, ts.URL+"/v1/attestations", json.RawMessage(respelled)); code != 201 {
+		t.Fatalf("decimal amount: want 201, got %d: %s", code, body)
+	}
+}
+
+// An issuer withdraws its own dispute with a retraction that chains after it;
+// the profile then shows the dispute as retracted. Nobody else can retract it.
+func TestRetraction(t *testing.T) {
+	ts, cleanup := testEnv(t)
+	defer cleanup()
+	owner, _ := core.GenerateKeyPair()
+	agent, _ := core.GenerateKeyPair()
+	issuerOwner, _ := core.GenerateKeyPair()
+	issuer, _ := core.GenerateKeyPair()
+	other, _ := core.GenerateKeyPair()
+	postJSON(t, ts.URL+"/v1/agents", mustCard(t, owner, agent, "subject"))
+	postJSON(t, ts.URL+"/v1/agents", mustCard(t, issuerOwner, issuer, "issuer"))
+	postJSON(t, ts.URL+"/v1/agents", mustCard(t, issuerOwner, other, "other"))
+
+	d := core.NewAttestation(core.TypeTaskDisputed, issuer.DID, agent.DID)
+	_ = d.Sign(issuer.Private)
+	if code, body := postJSON(t, ts.URL+"/v1/attestations", d); code != 201 {
+		t.Fatalf("dispute: %d %s", code, body)
+	}
+	dh, _ := d.Hash()
+	retract := func(k *core.KeyPair, target, prev string) *core.Attestation {
+		r := core.NewAttestation(core.TypeRetraction, k.DID, agent.DID)
+		r.Prev = prev
+		r.Body = map[string]any{"retracts": target, "reason": "filed in error"}
+		_ = r.Sign(k.Private)
+		return r
+	}
+	if code, body := postJSON(t, ts.URL+"/v1/attestations", retract(other, dh, "")); code != 400 {
+		t.Fatalf("retracting another issuer's dispute: want 400, got %d %s", code, body)
+	}
+	if code, body := postJSON(t, ts.URL+"/v1/attestations", retract(issuer, "blake3:00", dh)); code != 404 {
+		t.Fatalf("retracting an unknown attestation: want 404, got %d %s", code, body)
+	}
+	r := retract(issuer, dh, dh)
+	if code, body := postJSON(t, ts.URL+"/v1/attestations", r); code != 201 {
+		t.Fatalf("retraction: %d %s", code, body)
+	}
+	rh, _ := r.Hash()
+	var profile struct {
+		Retractions []struct {
+			Retracted, Retraction, Reason string
+			Verified                      bool
+		} `json:"retractions"`
+	}
+	getJSON(t, ts.URL+"/v1/agents/"+agent.DID, &profile)
+	if len(profile.Retractions) != 1 {
+		t.Fatalf("profile should list the retraction: %+v", profile)
+	}
+	if got := profile.Retractions[0]; got.Retracted != dh || got.Retraction != rh || !got.Verified || got.Reason != "filed in error" {
+		t.Fatalf("unexpected retraction view: %+v", got)
+	}
+}
+
+// Attestations outside their validity window stay listed, marked, and drop
+// out of the score.
+func TestValidityWindowListed(t *testing.T) {
+	ts, cleanup := testEnv(t)
+	defer cleanup()
+	owner, _ := core.GenerateKeyPair()
+	agent, _ := core.GenerateKeyPair()
+	issuerOwner, _ := core.GenerateKeyPair()
+	postJSON(t, ts.URL+"/v1/agents", mustCard(t, owner, agent, "subject"))
+	now := time.Now().UTC()
+	var hashes []string
+	for _, window := range [][2]time.Duration{{0, 0}, {-48 * time.Hour, -24 * time.Hour}, {24 * time.Hour, 48 * time.Hour}} {
+		issuer, _ := core.GenerateKeyPair()
+		postJSON(t, ts.URL+"/v1/agents", mustCard(t, issuerOwner, issuer, "issuer"))
+		a := core.NewAttestation(core.TypeEndorsement, issuer.DID, agent.DID)
+		a.IssuedAt = now.Add(-72 * time.Hour).Format(time.RFC3339)
+		if window[1] != 0 {
+			a.NotBefore = now.Add(window[0]).Format(time.RFC3339)
+			a.ExpiresAt = now.Add(window[1]).Format(time.RFC3339)
+		}
+		_ = a.Sign(issuer.Private)
+		if code, body := postJSON(t, ts.URL+"/v1/attestations", a); code != 201 {
+			t.Fatalf("attestation: %d %s", code, body)
+		}
+		h, _ := a.Hash()
+		hashes = append(hashes, h)
+	}
+	var list struct {
+		Attestations []*core.Attestation `json:"attestations"`
+		Windows      map[string]string   `json:"windows"`
+	}
+	getJSON(t, ts.URL+"/v1/agents/"+agent.DID+"/attestations", &list)
+	if len(list.Attestations) != 3 || len(list.Windows) != 2 ||
+		list.Windows[hashes[1]] != core.WindowExpired || list.Windows[hashes[2]] != core.WindowNotYetValid {
+		t.Fatalf("bounded records should be listed and marked: %d listed, windows %v", len(list.Attestations), list.Windows)
+	}
+	var sc score.Output
+	getJSON(t, ts.URL+"/v1/score/"+agent.DID, &sc)
+	if sc.Inputs.Endorsements != 1 {
+		t.Fatalf("only the endorsement in effect should count: %+v", sc.Inputs)
+	}
+}
+
+// A body that fails its type's schema is stored with warnings by default, and
+// refused by a strict instance.
+func TestBodySchemaOnIngest(t *testing.T) {
+	for _, strict := range []bool{false, true} {
+		st, err := store.Open(":memory:")
+		if err != nil {
+			t.Fatal(err)
+		}
+		ts := httptest.NewServer((&Server{Store: st, Name: "test", Version: "test", StrictBodies: strict}).Handler())
+		owner, _ := core.GenerateKeyPair()
+		agent, _ := core.GenerateKeyPair()
+		issuer, _ := core.GenerateKeyPair()
+		postJSON(t, ts.URL+"/v1/agents", mustCard(t, owner, agent, "subject"))
+
+		a := core.NewAttestation(core.TypePaymentReceipt, issuer.DID, agent.DID)
+		a.Body = map[string]any{"amount": "a lot", "rail": "x402"}
+		_ = a.Sign(issuer.Private)
+		code, body := postJSON(t, ts.URL+"/v1/attestations", a)
+		var resp struct {
+			Warnings []string `json:"warnings"`
+			Error    string   `json:"error"`
+		}
+		_ = json.Unmarshal(body, &resp)
+		switch {
+		case strict && (code != 400 || !strings.Contains(resp.Error, "body.currency: required")):
+			t.Fatalf("strict: want 400 naming the missing currency, got %d %s", code, body)
+		case !strict && (code != 201 || len(resp.Warnings) != 2):
+			t.Fatalf("lenient: want 201 with two warnings, got %d %s", code, body)
+		}
+		var wk struct {
+			BodySchemas struct {
+				Version string `json:"version"`
+				Strict  bool   `json:"strict"`
+			} `json:"body_schemas"`
+		}
+		getJSON(t, ts.URL+"/.well-known/moltnet", &wk)
+		if wk.BodySchemas.Version != core.BodySchemaVersion || wk.BodySchemas.Strict != strict {
+			t.Fatalf("well-known should advertise the schema mode: %+v", wk.BodySchemas)
+		}
+		ts.Close()
+		st.Close()
+	}
+}
+
+func TestEquivocation(t *testing.T) {
+	ts, cleanup := testEnv(t)
+	defer cleanup()
+	owner, _ := core.GenerateKeyPair()
+	agent, _ := core.GenerateKeyPair()
+	issuer, _ := core.GenerateKeyPair()
+	postJSON(t, ts.URL+"/v1/agents", mustCard(t, owner, agent, "subject"))
+	postJSON(t, ts.URL+"/v1/agents", mustCard(t, owner, issuer, "issuer"))
+
+	branch := func(id string) *core.Attestation {
+		a := core.NewAttestation(core.TypeTaskCompleted, issuer.DID, agent.DID)
+		a.Body = map[string]any{"task_id": id}
+		_ = a.Sign(issuer.Private)
+		return a
+	}
+	if code, body := postJSON(t, ts.URL+"/v1/attestations", branch("one")); code != 201 {
+		t.Fatalf("first branch: %d %s", code, body)
+	}
+	code, body := postJSON(t, ts.URL+"/v1/attestations", branch("two"))
+	if code != 409 || !strings.Contains(string(body), "equivocation") {
+		t.Fatalf("second branch: want 409 naming the equivocation, got %d %s", code, body)
+	}
+	var list struct {
+		Equivocations []*core.Equivocation `json:"equivocations"`
+	}
+	getJSON(t, ts.URL+"/v1/equivocations?issuer="+issuer.DID, &list)
+	if len(list.Equivocations) != 1 || list.Equivocations[0].Verify() != nil {
+		t.Fatalf("the refused branch should leave a proof: %+v", list)
+	}
+	// The proof is portable: another instance takes it as is.
+	ts2, cleanup2 := testEnv(t)
+	defer cleanup2()
+	if code, body := postJSON(t, ts2.URL+"/v1/equivocations", list.Equivocations[0]); code != 201 {
+		t.Fatalf("submitting the proof: %d %s", code, body)
+	}
+	forged := *list.Equivocations[0]
+	forged.B = branch("three")
+	forged.B.Body["task_id"] = "tampered"
+	if code, _ := postJSON(t, ts2.URL+"/v1/equivocations", &forged); code != 400 {
+		t.Fatalf("a proof with a bad signature: want 400, got %d", code)
+	}
+	var profile struct {
+		Equivocations []struct {
+			Prev     string
+			Verified bool
+		} `json:"equivocations"`
+	}
+	getJSON(t, ts.URL+"/v1/agents/"+issuer.DID, &profile)
+	if len(profile.Equivocations) != 1 || !profile.Equivocations[0].Verified {
+		t.Fatalf("profile should flag the issuer: %+v", profile)
+	}
+	var sc score.Output
+	getJSON(t, ts.URL+"/v1/score/"+issuer.DID+"?algorithm="+url.QueryEscape(score.AlgorithmV2), &sc)
+	if sc.Inputs.Equivocations != 1 {
+		t.Fatalf("the v2 score should take the proof as a penalty: %+v", sc.Inputs)
+	}
+	var v1 score.Output
+	getJSON(t, ts.URL+"/v1/score/"+issuer.DID, &v1)
+	if v1.Inputs.Equivocations != 0 {
+		t.Fatalf("the v1 score should apply no penalty: %+v", v1.Inputs)
+	}
+}
+
+// A co-signed attestation needs every issuer's signature and chain head, moves
+// each head, and scores as one completion.
+func TestCoSignedAttestation(t *testing.T) {
+	ts, cleanup := testEnv(t)
+	defer cleanup()
+	owner, _ := core.GenerateKeyPair()
+	agent, _ := core.GenerateKeyPair()
+	lead, _ := core.GenerateKeyPair()
+	co, _ := core.GenerateKeyPair()
+	postJSON(t, ts.URL+"/v1/agents", mustCard(t, owner, agent, "subject"))
+	for _, kp := range []*core.KeyPair{lead, co} {
+		o, _ := core.GenerateKeyPair()
+		postJSON(t, ts.URL+"/v1/agents", mustCard(t, o, kp, "issuer"))
+	}
+	prior := core.NewAttestation(core.TypeEndorsement, co.DID, agent.DID)
+	_ = prior.Sign(co.Private)
+	if code, body := postJSON(t, ts.URL+"/v1/attestations", prior); code != 201 {
+		t.Fatalf("prior: %d %s", code, body)
+	}
+	ph, _ := prior.Hash()
+
+	joint := core.NewAttestation(core.TypeTaskCompleted, lead.DID, agent.DID)
+	joint.CoIssuers = []core.CoIssuer{{Issuer: co.DID, Prev: ph}}
+	_ = joint.Sign(lead.Private)
+	if code, _ := postJSON(t, ts.URL+"/v1/attestations", joint); code != 400 {
+		t.Fatalf("without the co-signature: want 400, got %d", code)
+	}
+	stale := *joint
+	stale.CoIssuers = []core.CoIssuer{{Issuer: co.DID}}
+	_ = stale.Sign(lead.Private)
+	_ = stale.AddCoSig(co)
+	if code, body := postJSON(t, ts.URL+"/v1/attestations", &stale); code != 409 || !strings.Contains(string(body), "co-issuer") {
+		t.Fatalf("a stale co-issuer prev: want 409 naming the co-issuer, got %d %s", code, body)
+	}
+	_ = joint.AddCoSig(co)
+	if code, body := postJSON(t, ts.URL+"/v1/attestations", joint); code != 201 {
+		t.Fatalf("co-signed: %d %s", code, body)
+	}
+	h, _ := joint.Hash()
+	for _, did := range []string{lead.DID, co.DID} {
+		var head struct{ Head string }
+		getJSON(t, ts.URL+"/v1/issuers/"+did+"/head", &head)
+		if head.Head != h {
+			t.Fatalf("%s head: %q, want %q", did, head.Head, h)
+		}
+	}
+	var sc score.Output
+	getJSON(t, ts.URL+"/v1/score/"+agent.DID, &sc)
+	if sc.Inputs.Completions != 1 || sc.Inputs.DistinctIssuers != 2 {
+		t.Fatalf("one completion (plus co's endorsement) from two issuers: %+v", sc.Inputs)
+	}
+}
+
+func TestGraphEndpoint(t *testing.T) {
+	ts, cleanup := testEnv(t)
+	defer cleanup()
+
+	owner, _ := core.GenerateKeyPair()
+	subject, _ := core.GenerateKeyPair()
+	issuer, _ := core.GenerateKeyPair()
+
+	postJSON(t, ts.URL+"/v1/agents", mustCard(t, owner, subject, "subject", "code.review"))
+	postJSON(t, ts.URL+"/v1/agents", mustCard(t, owner, issuer, "issuer"))
+
+	// Two completed tasks from issuer -> subject should collapse to one weighted edge.
+	head := ""
+	for i := 0; i < 2; i++ {
+		a := core.NewAttestation(core.TypeTaskCompleted, issuer.DID, subject.DID)
+		a.Prev = head
+		if err := a.Sign(issuer.Private); err != nil {
+			t.Fatal(err)
+		}
+		if code, body := postJSON(t, ts.URL+"/v1/attestations", a); code != 201 {
+			t.Fatalf("attest %d: %d %s", i, code, body)
+		}
+		head, _ = a.Hash()
+	}
+
+	var graph struct {
+		Nodes []struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+		} `json:"nodes"`
+		Edges []struct {
+			Source string `json:"source"`
+			Target string `json:"target"`
+			Type   string `json:"type"`
+			Count  int    `json:"count"`
+		} `json:"edges"`
+	}
+	if code := getJSON(t, ts.URL+"/v1/graph", &graph); code != 200 {
+		t.Fatalf("graph: %d", code)
+	}
+	if len(graph.Nodes) < 2 {
+		t.Fatalf("expected >=2 nodes, got %d", len(graph.Nodes))
+	}
+	var found bool
+	for _, e := range graph.Edges {
+		if e.Source == issuer.DID && e.Target == subject.DID && e.Type == core.TypeTaskCompleted {
+			found = true
+			if e.Count != 2 {
+				t.Fatalf("expected edge count 2, got %d", e.Count)
+			}
+		}
+	}
+	if !found {
+		t.Fatalf("expected an issuer->subject task.completed edge; edges=%+v", graph.Edges)
+	}
+}
+
+func TestCardForkSurfaced(t *testing.T) {
+	ts, cleanup := testEnv(t)
+	defer cleanup()
+
+	owner, _ := core.GenerateKeyPair()
+	agent, _ := core.GenerateKeyPair()
+
+	genesis := mustCard(t, owner, agent, "genesis")
+	if code, _ := postJSON(t, ts.URL+"/v1/agents", genesis); code != 201 {
+		t.Fatal("genesis register failed")
+	}
+	gh, _ := genesis.Hash()
+
+	// A competing version branching from genesis with a different name.
+	fork := core.NewCard(agent.DID, owner.DID, "fork-branch")
+	fork.Prev = gh
+	fork.Version = "9.9.9"
+	if err := fork.Sign(agent.Private, owner.Private); err != nil {
+		t.Fatal(err)
+	}
+	// It has prev=genesis, but the head is still genesis... make head advance first.
+	update := core.NewCard(agent.DID, owner.DID, "legit-update")
+	update.Prev = gh
+	if err := update.Sign(agent.Private, owner.Private); err != nil {
+		t.Fatal(err)
+	}
+	postJSON(t, ts.URL+"/v1/agents", update) // head -> legit-update
+	postJSON(t, ts.URL+"/v1/agents", fork)   // branches from genesis, not head -> fork
+
+	var resp struct {
+		Card struct {
+			Name string `json:"name"`
+		} `json:"card"`
+		Fork *struct {
+			CompetingHash string `json:"competing_hash"`
+		} `json:"fork"`
+	}
+	if code := getJSON(t, ts.URL+"/v1/agents/"+agent.DID, &resp); code != 200 {
+		t.Fatalf("get: %d", code)
+	}
+	if resp.Card.Name != "legit-update" {
+		t.Fatalf("head should be legit-update, got %q", resp.Card.Name)
+	}
+	if resp.Fork == nil {
+		t.Fatal("expected fork to be surfaced on the agent response")
+	}
+	fh, _ := fork.Hash()
+	if resp.Fork.CompetingHash != fh {
+		t.Fatalf("fork competing hash mismatch")
+	}
+}
+
+func TestForkResolution(t *testing.T) {
+	ts, cleanup := testEnv(t)
+	defer cleanup()
+	owner, _ := core.GenerateKeyPair()
+	agent, _ := core.GenerateKeyPair()
+	stranger, _ := core.GenerateKeyPair()
+
+	genesis := mustCard(t, owner, agent, "genesis")
+	postJSON(t, ts.URL+"/v1/agents", genesis)
+	gh, _ := genesis.Hash()
+	branch := func(name string) *core.Card {
+		c := core.NewCard(agent.DID, owner.DID, name)
+		c.Prev = gh
+		_ = c.Sign(agent.Private, owner.Private)
+		return c
+	}
+	update, fork := branch("update"), branch("fork")
+	postJSON(t, ts.URL+"/v1/agents", update)
+	postJSON(t, ts.URL+"/v1/agents", fork)
+	uh, _ := update.Hash()
+	fh, _ := fork.Hash()
+
+	forged := core.NewForkResolution(genesis, fh, uh)
+	forged.Owner = stranger.DID
+	_ = forged.Sign(agent.Private, stranger.Private)
+	if code, body := postJSON(t, ts.URL+"/v1/fork-resolutions", forged); code != 403 {
+		t.Fatalf("a resolution not from the card's owner: want 403, got %d %s", code, body)
+	}
+	stale := core.NewForkResolution(genesis, gh, uh)
+	_ = stale.Sign(agent.Private, owner.Private)
+	if code, body := postJSON(t, ts.URL+"/v1/fork-resolutions", stale); code != 409 {
+		t.Fatalf("a resolution that settles no fork: want 409, got %d %s", code, body)
+	}
+	fr := core.NewForkResolution(genesis, fh, uh)
+	_ = fr.Sign(agent.Private, owner.Private)
+	if code, body := postJSON(t, ts.URL+"/v1/fork-resolutions", fr); code != 201 {
+		t.Fatalf("resolution: %d %s", code, body)
+	}
+	var resp struct {
+		Card struct {
+			Name string `json:"name"`
+		} `json:"card"`
+		Fork            *struct{}              `json:"fork"`
+		ForkResolutions []*core.ForkResolution `json:"fork_resolutions"`
+	}
+	getJSON(t, ts.URL+"/v1/agents/"+agent.DID, &resp)
+	if resp.Card.Name != "fork" || resp.Fork != nil || len(resp.ForkResolutions) != 1 {
+		t.Fatalf("head should move to the chosen branch and the fork be resolved: %+v", resp)
+	}
+}
+
+// After an owner rotation the card head still names the retired key; the
+// fork is then resolved by its successor, over HTTP and over federation, and
+// no longer by the retired key.
+func TestForkResolutionAfterOwnerRotation(t *testing.T) {
+	st, err := store.Open(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+	srv := &Server{Store: st, Name: "test", Version: "test"}
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+	o1, _ := core.GenerateKeyPair()
+	o2, _ := core.GenerateKeyPair()
+
+	type forked struct {
+		agent          *core.KeyPair
+		genesis        *core.Card
+		kept, setAside string
+	}
+	fork := func() forked {
+		agent, _ := core.GenerateKeyPair()
+		genesis := mustCard(t, o1, agent, "genesis")
+		postJSON(t, ts.URL+"/v1/agents", genesis)
+		gh, _ := genesis.Hash()
+		var hs []string
+		for _, name := range []string{"update", "fork"} {
+			c := core.NewCard(agent.DID, o1.DID, name)
+			c.Prev = gh
+			_ = c.Sign(agent.Private, o1.Private)
+			postJSON(t, ts.URL+"/v1/agents", c)
+			h, _ := c.Hash()
+			hs = append(hs, h)
+		}
+		return forked{agent, genesis, hs[1], hs[0]}
+	}
+	resolve := func(f forked, by *core.KeyPair) *core.ForkResolution {
+		fr := core.NewForkResolution(f.genesis, f.kept, f.setAside)
+		fr.Owner = by.DID
+		_ = fr.Sign(f.agent.Private, by.Private)
+		return fr
+	}
+	open := func(f forked) bool {
+		var resp struct {
+			Fork *struct{} `json:"fork"`
+		}
+		getJSON(t, ts.URL+"/v1/agents/"+f.agent.DID, &resp)
+		return resp.Fork != nil
+	}
+	direct, relayed := fork(), fork()
+	orot := core.NewOwnerRotation(o1.DID, o2.DID)
+	_ = orot.Sign(o1.Private, o2.Private)
+	if code, body := postJSON(t, ts.URL+"/v1/owner-rotations", orot); code != 201 {
+		t.Fatalf("owner rotation: %d %s", code, body)
+	}
+
+	if code, _ := postJSON(t, ts.URL+"/v1/fork-resolutions", resolve(direct, o1)); code != 403 {
+		t.Fatalf("a resolution by the retired owner key: want 403, got %d", code)
+	}
+	if code, body := postJSON(t, ts.URL+"/v1/fork-resolutions", resolve(direct, o2)); code != 201 || open(direct) {
+		t.Fatalf("a resolution by the successor key: %d %s", code, body)
+	}
+
+	raw, _ := json.Marshal(resolve(relayed, o1))
+	srv.ingestFederated("fork_resolution", raw)
+	if !open(relayed) {
+		t.Fatal("a federated resolution by the retired owner key settled the fork")
+	}
+	raw, _ = json.Marshal(resolve(relayed, o2))
+	srv.ingestFederated("fork_resolution", raw)
+	if open(relayed) {
+		t.Fatal("a federated resolution by the successor key should settle the fork")
+	}
+}
+
+func TestAttestationPagination(t *testing.T) {
+	ts, cleanup := testEnv(t)
+	defer cleanup()
+
+	owner, _ := core.GenerateKeyPair()
+	subject, _ := core.GenerateKeyPair()
+	issuer, _ := core.GenerateKeyPair()
+	postJSON(t, ts.URL+"/v1/agents", mustCard(t, owner, subject, "subject"))
+	postJSON(t, ts.URL+"/v1/agents", mustCard(t, owner, issuer, "issuer"))
+
+	const total = 5
+	head := ""
+	for i := 0; i < total; i++ {
+		a := core.NewAttestation(core.TypeTaskCompleted, issuer.DID, subject.DID)
+		a.Prev = head
+		a.IssuedAt = time.Now().UTC().Add(time.Duration(i) * time.Second).Format(time.RFC3339)
+		if err := a.Sign(issuer.Private); err != nil {
+			t.Fatal(err)
+		}
+		if code, body := postJSON(t, ts.URL+"/v1/attestations", a); code != 201 {
+			t.Fatalf("attest %d: %d %s", i, code, body)
+		}
+		head, _ = a.Hash()
+	}
+
+	type page struct {
+		Attestations []core.Attestation `json:"attestations"`
+		Total        int                `json:"total"`
+		Limit        int                `json:"limit"`
+		Offset       int                `json:"offset"`
+		NextOffset   *int               `json:"next_offset"`
+	}
+	var p1 page
+	getJSON(t, ts.URL+"/v1/agents/"+subject.DID+"/attestations?limit=2&offset=0", &p1)
+	if p1.Total != total {
+		t.Fatalf("total = %d, want %d", p1.Total, total)
+	}
+	if len(p1.Attestations) != 2 {
+		t.Fatalf("page 1 len = %d, want 2", len(p1.Attestations))
+	}
+	if p1.NextOffset == nil || *p1.NextOffset != 2 {
+		t.Fatalf("next_offset = %v, want 2", p1.NextOffset)
+	}
+
+	// Last page: offset 4 -> 1 item, no next.
+	var p3 page
+	getJSON(t, ts.URL+"/v1/agents/"+subject.DID+"/attestations?limit=2&offset=4", &p3)
+	if len(p3.Attestations) != 1 {
+		t.Fatalf("last page len = %d, want 1", len(p3.Attestations))
+	}
+	if p3.NextOffset != nil {
+		t.Fatalf("expected no next_offset on last page, got %v", *p3.NextOffset)
+	}
+}
+
+func TestSearchPagination(t *testing.T) {
+	ts, cleanup := testEnv(t)
+	defer cleanup()
+	owner, _ := core.GenerateKeyPair()
+	for i := 0; i < 5; i++ {
+		agent, _ := core.GenerateKeyPair()
+		postJSON(t, ts.URL+"/v1/agents", mustCard(t, owner, agent, "searchable-agent", "code.review"))
+	}
+	var resp struct {
+		Count      int  `json:"count"`
+		Total      int  `json:"total"`
+		NextOffset *int `json:"next_offset"`
+	}
+	getJSON(t, ts.URL+"/v1/search?cap=code.review&limit=2&offset=0", &resp)
+	if resp.Total != 5 {
+		t.Fatalf("total = %d, want 5", resp.Total)
+	}
+	if resp.Count != 2 {
+		t.Fatalf("count = %d, want 2 (page size)", resp.Count)
+	}
+	if resp.NextOffset == nil || *resp.NextOffset != 2 {
+		t.Fatalf("next_offset = %v, want 2", resp.NextOffset)
+	}
+}
+
+func TestA2AResolution(t *testing.T) {
+	ts, cleanup := testEnv(t)
+	defer cleanup()
+
+	owner, _ := core.GenerateKeyPair()
+	agent, _ := core.GenerateKeyPair()
+
+	c := core.NewCard(agent.DID, owner.DID, "resolver-agent")
+	c.Description = "does resolvable things"
+	c.Version = "1.2.3"
+	c.Capabilities = []core.Capability{{Tag: "code.review", Desc: "reviews PRs"}}
+	c.Protocols = map[string]any{
+		"a2a":  map[string]any{"agent_card": "https://agent.example/.well-known/agent.json"},
+		"http": map[string]any{"endpoint": "https://agent.example/v1"},
+	}
+	if err := c.Sign(agent.Private, owner.Private); err != nil {
+		t.Fatal(err)
+	}
+	if code, body := postJSON(t, ts.URL+"/v1/agents", c); code != 201 {
+		t.Fatalf("register: %d %s", code, body)
+	}
+
+	var a2a struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+		URL     string `json:"url"`
+		Skills  []struct {
+			ID   string   `json:"id"`
+			Tags []string `json:"tags"`
+		} `json:"skills"`
+		Moltnet struct {
+			DID       string  `json:"did"`
+			MoltScore float64 `json:"moltscore"`
+			Verify    string  `json:"verify"`
+		} `json:"x-moltnet"`
+	}
+	if code := getJSON(t, ts.URL+"/v1/agents/"+agent.DID+"/a2a", &a2a); code != 200 {
+		t.Fatalf("a2a: %d", code)
+	}
+	if a2a.Name != "resolver-agent" || a2a.Version != "1.2.3" {
+		t.Fatalf("name/version mismatch: %+v", a2a)
+	}
+	if a2a.URL != "https://agent.example/.well-known/agent.json" {
+		t.Fatalf("url = %q", a2a.URL)
+	}
+	if len(a2a.Skills) != 1 || a2a.Skills[0].ID != "code.review" {
+		t.Fatalf("skills = %+v", a2a.Skills)
+	}
+	if a2a.Moltnet.DID != agent.DID {
+		t.Fatalf("x-moltnet.did = %q, want %q", a2a.Moltnet.DID, agent.DID)
+	}
+	if a2a.Moltnet.Verify == "" {
+		t.Fatal("expected x-moltnet.verify link")
+	}
+}
+
+func TestOpenAPISpec(t *testing.T) {
+	ts, cleanup := testEnv(t)
+	defer cleanup()
+
+	resp, err := http.Get(ts.URL + "/openapi.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		t.Fatalf("status %d", resp.StatusCode)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "application/json" {
+		t.Fatalf("content-type = %q", ct)
+	}
+	var doc struct {
+		OpenAPI string         `json:"openapi"`
+		Info    map[string]any `json:"info"`
+		Paths   map[string]any `json:"paths"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		t.Fatalf("openapi.json is not valid JSON: %v", err)
+	}
+	if !strings.HasPrefix(doc.OpenAPI, "3.") {
+		t.Fatalf("expected OpenAPI 3.x, got %q", doc.OpenAPI)
+	}
+	for _, p := range []string{"/v1/agents", "/v1/agents/{did}", "/v1/search", "/v1/attestations", "/v1/score/{did}"} {
+		if _, ok := doc.Paths[p]; !ok {
+			t.Fatalf("openapi paths missing %q", p)
+		}
+	}
+}
+
+func TestHealthz(t *testing.T) {
+	ts, cleanup := testEnv(t)
+	defer cleanup()
+	var resp struct {
+		Status string `json:"status"`
+	}
+	if code := getJSON(t, ts.URL+"/healthz", &resp); code != 200 {
+		t.Fatalf("healthz status %d", code)
+	}
+	if resp.Status != "ok" {
+		t.Fatalf("healthz body = %q", resp.Status)
+	}
+}
+
+func TestUnknownAgentIs404(t *testing.T) {
+	ts, cleanup := testEnv(t)
+	defer cleanup()
+	if code := getJSON(t, ts.URL+"/v1/agents/did:key:zNope", nil); code != 404 {
+		t.Fatalf("expected 404, got %d", code)
+	}
+}
+
+// v1 and v2 are served side by side, each tagged with its algorithm; v2 also
+// names its basis and day. An anchored issuer's completion lifts the v2 score,
+// while the same completion from an unanchored stranger would not.
+func TestScoreV2SideBySide(t *testing.T) {
+	st, err := store.Open(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+	anchor, _ := core.GenerateKeyPair()
+	srv := &Server{Store: st, Name: "test", Version: "test", Anchors: []string{anchor.DID}}
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+
+	owner, _ := core.GenerateKeyPair()
+	agent, _ := core.GenerateKeyPair()
+	postJSON(t, ts.URL+"/v1/agents", mustCard(t, owner, agent, "subject"))
+
+	type scoreObj struct {
+		Algorithm      string  `json:"algorithm"`
+		Score          float64 `json:"score"`
+		Basis          string  `json:"basis"`
+		ComputedForDay string  `json:"computed_for_day"`
+	}
+	var before scoreObj
+	getJSON(t, ts.URL+"/v1/score/"+agent.DID+"?algorithm=moltscore/v2", &before)
+
+	a := core.NewAttestation(core.TypeTaskCompleted, anchor.DID, agent.DID)
+	if err := a.Sign(anchor.Private); err != nil {
+		t.Fatal(err)
+	}
+	if code, body := postJSON(t, ts.URL+"/v1/attestations", a); code != 201 {
+		t.Fatalf("attest: %d %s", code, body)
+	}
+
+	var v1, v2 scoreObj
+	if code := getJSON(t, ts.URL+"/v1/score/"+agent.DID, &v1); code != 200 || v1.Algorithm != "moltscore/v1" {
+		t.Fatalf("default score: %d %+v", code, v1)
+	}
+	if code := getJSON(t, ts.URL+"/v1/score/"+agent.DID+"?algorithm=moltscore/v2", &v2); code != 200 {
+		t.Fatalf("v2 score: %d", code)
+	}
+	if v2.Algorithm != "moltscore/v2" || v2.Basis == "" || v2.ComputedForDay == "" {
+		t.Fatalf("v2 object must carry its tag, basis and day: %+v", v2)
+	}
+	if v2.Score <= before.Score {
+		t.Fatalf("anchored completion should lift v2: before=%.1f after=%.1f", before.Score, v2.Score)
+	}
+	var agentResp struct {
+		Score   scoreObj `json:"score"`
+		ScoreV2 scoreObj `json:"score_v2"`
+	}
+	getJSON(t, ts.URL+"/v1/agents/"+agent.DID, &agentResp)
+	if agentResp.Score.Algorithm != "moltscore/v1" || agentResp.ScoreV2.Algorithm != "moltscore/v2" {
+		t.Fatalf("agent response should carry both tagged scores: %+v", agentResp)
+	}
+	if code := getJSON(t, ts.URL+"/v1/score/"+agent.DID+"?algorithm=nope", nil); code != 400 {
+		t.Fatalf("unknown algorithm: got %d, want 400", code)
+	}
+}
+
+// The published basis is the one the scores are computed against: the document
+// at /v1/score/basis, the hash in /.well-known/moltnet and the `basis` on every
+// v2 score object must all agree.
+func TestScoreBasisPublished(t *testing.T) {
+	st, err := store.Open(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+	anchor, _ := core.GenerateKeyPair()
+	srv := &Server{Store: st, Name: "test", Version: "test", Anchors: []string{anchor.DID}}
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/v1/score/basis")
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	b, err := score.ParseBasis(raw)
+	if err != nil {
+		t.Fatalf("served basis does not parse: %v", err)
+	}
+	if len(b.Anchors) != 1 || b.Anchors[0] != anchor.DID {
+		t.Fatalf("served basis should carry the configured anchors: %+v", b.Anchors)
+	}
+	hash, _ := b.Hash()
+
+	var wk struct {
+		ScoreBasis struct {
+			Hash string `json:"hash"`
+			URL  string `json:"url"`
+		} `json:"score_basis"`
+	}
+	getJSON(t, ts.URL+"/.well-known/moltnet", &wk)
+	if wk.ScoreBasis.Hash != hash || wk.ScoreBasis.URL != "/v1/score/basis" {
+		t.Fatalf("well-known score_basis = %+v, want hash %s", wk.ScoreBasis, hash)
+	}
+
+	owner, _ := core.GenerateKeyPair()
+	agent, _ := core.GenerateKeyPair()
+	postJSON(t, ts.URL+"/v1/agents", mustCard(t, owner, agent, "subject"))
+	var out struct {
+		Basis string `json:"basis"`
+	}
+	getJSON(t, ts.URL+"/v1/score/"+agent.DID+"?algorithm=moltscore/v2", &out)
+	if out.Basis != hash {
+		t.Fatalf("v2 score basis %s, published %s", out.Basis, hash)
+	}
+}
+
+// GET /v1/attestations pages the whole registry in insertion order, and a
+// cursor taken before new writes still resumes exactly where it left off.
+func TestAllAttestationsCursor(t *testing.T) {
+	ts, cleanup := testEnv(t)
+	defer cleanup()
+
+	owner, _ := core.GenerateKeyPair()
+	issuer, _ := core.GenerateKeyPair()
+	var agents []*core.KeyPair
+	for i := 0; i < 3; i++ {
+		a, _ := core.GenerateKeyPair()
+		postJSON(t, ts.URL+"/v1/agents", mustCard(t, owner, a, "agent"))
+		agents = append(agents, a)
+	}
+	prev := ""
+	attest := func(subject string) string {
+		a := core.NewAttestation(core.TypeTaskCompleted, issuer.DID, subject)
+		a.Prev = prev
+		if err := a.Sign(issuer.Private); err != nil {
+			t.Fatal(err)
+		}
+		if code, body := postJSON(t, ts.URL+"/v1/attestations", a); code != 201 {
+			t.Fatalf("attest: %d %s", code, body)
+		}
+		prev, _ = a.Hash()
+		return prev
+	}
+	var want []string
+	for i := 0; i < 5; i++ {
+		want = append(want, attest(agents[i%3].DID))
+	}
+
+	type page struct {
+		Attestations []*core.Attestation `json:"attestations"`
+		Cursor       int64               `json:"cursor"`
+		Latest       int64               `json:"latest"`
+	}
+	var got []string
+	var since int64
+	pull := func() {
+		for {
+			var p page
+			if code := getJSON(t, ts.URL+"/v1/attestations?limit=2&since="+strconv.FormatInt(since, 10), &p); code != 200 {
+				t.Fatalf("page: %d", code)
+			}
+			for _, a := range p.Attestations {
+				h, _ := a.Hash()
+				got = append(got, h)
+			}
+			since = p.Cursor
+			if p.Cursor == p.Latest {
+				return
+			}
+		}
+	}
+	pull()
+	want = append(want, attest(agents[0].DID))
+	pull()
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("pages out of insertion order or incomplete:\n got %v\nwant %v", got, want)
+	}
+}
+
+// A cap= search ranks by the per-capability score, not overall reputation: a
+// generalist with lots of unrelated work loses to a specialist on the
+// specialist's tag.
+func TestSearchRanksByCapabilityScore(t *testing.T) {
+	ts, cleanup := testEnv(t)
+	defer cleanup()
+
+	owner, _ := core.GenerateKeyPair()
+	generalist, _ := core.GenerateKeyPair()
+	specialist, _ := core.GenerateKeyPair()
+	postJSON(t, ts.URL+"/v1/agents", mustCard(t, owner, generalist, "generalist", "code.review", "data.etl"))
+	postJSON(t, ts.URL+"/v1/agents", mustCard(t, owner, specialist, "specialist", "code.review", "data.etl"))
+
+	attest := func(subject, capability string) {
+		issuer, _ := core.GenerateKeyPair()
+		a := core.NewAttestation(core.TypeTaskCompleted, issuer.DID, subject)
+		a.Body = map[string]any{"capability": capability}
+		if err := a.Sign(issuer.Private); err != nil {
+			t.Fatal(err)
+		}
+		if code, body := postJSON(t, ts.URL+"/v1/attestations", a); code != 201 {
+			t.Fatalf("attest: %d %s", code, body)
+		}
+	}
+	for i := 0; i < 4; i++ {
+		attest(generalist.DID, "data.etl")
+	}
+	attest(specialist.DID, "code.review")
+
+	var scoreResp struct {
+		Capabilities map[string]struct {
+			Score float64 `json:"score"`
+		} `json:"capabilities"`
+	}
+	getJSON(t, ts.URL+"/v1/score/"+specialist.DID, &scoreResp)
+	if _, ok := scoreResp.Capabilities["code.review"]; !ok {
+		t.Fatalf("score should carry the capability vector: %+v", scoreResp)
+	}
+
+	type result struct {
+		ID              string   `json:"id"`
+		Score           float64  `json:"score"`
+		CapabilityScore *float64 `json:"capability_score"`
+	}
+	var resp struct {
+		Results []result `json:"results"`
+	}
+	getJSON(t, ts.URL+"/v1/search?cap=code.review", &resp)
+	if len(resp.Results) != 2 || resp.Results[0].ID != specialist.DID || resp.Results[0].CapabilityScore == nil {
+		t.Fatalf("code.review search should rank the specialist first: %+v", resp.Results)
+	}
+	if resp.Results[0].Score >= resp.Results[1].Score {
+		t.Fatalf("sanity: the generalist should have the higher overall score: %+v", resp.Results)
+	}
+	getJSON(t, ts.URL+"/v1/search?cap=data.etl", &resp)
+	if resp.Results[0].ID != generalist.DID {
+		t.Fatalf("data.etl search should rank the generalist first: %+v", resp.Results)
+	}
+}
+
+// Under a v2 default, cap= ranks by the v2 capability vector, with untagged
+// agents at the v2 no-history baseline; a tag outside the capability charset
+// is refused.
+func TestSearchCapabilityUnderV2(t *testing.T) {
+	st, err := store.Open(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+	anchor, _ := core.GenerateKeyPair()
+	srv := &Server{Store: st, Name: "test", Version: "test", Algorithms: score.Builtin([]string{anchor.DID}), DefaultAlgorithm: score.AlgorithmV2}
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+
+	owner, _ := core.GenerateKeyPair()
+	specialist, _ := core.GenerateKeyPair()
+	idle, _ := core.GenerateKeyPair()
+	postJSON(t, ts.URL+"/v1/agents", mustCard(t, owner, specialist, "specialist", "code.review"))
+	postJSON(t, ts.URL+"/v1/agents", mustCard(t, owner, idle, "idle", "code.review"))
+	a := core.NewAttestation(core.TypeTaskCompleted, anchor.DID, specialist.DID)
+	a.Body = map[string]any{"capability": "code.review"}
+	if err := a.Sign(anchor.Private); err != nil {
+		t.Fatal(err)
+	}
+	if code, body := postJSON(t, ts.URL+"/v1/attestations", a); code != 201 {
+		t.Fatalf("attest: %d %s", code, body)
+	}
+
+	var v2 score.Output
+	getJSON(t, ts.URL+"/v1/score/"+specialist.DID, &v2)
+	want, ok := v2.Capabilities["code.review"]
+	if v2.Algorithm != score.AlgorithmV2 || !ok {
+		t.Fatalf("v2 score should carry the capability vector: %+v", v2)
+	}
+	var resp struct {
+		Results []struct {
+			ID              string   `json:"id"`
+			CapabilityScore *float64 `json:"capability_score"`
+		} `json:"results"`
+	}
+	getJSON(t, ts.URL+"/v1/search?cap=code.review", &resp)
+	if len(resp.Results) != 2 || resp.Results[0].ID != specialist.DID || resp.Results[1].CapabilityScore == nil {
+		t.Fatalf("code.review search should rank the specialist first: %+v", resp.Results)
+	}
+	if got := *resp.Results[0].CapabilityScore; got != want.Score {
+		t.Fatalf("capability score %v, want the v2 vector's %v", got, want.Score)
+	}
+	baseline := score.V2{Basis: score.DefaultBasis([]string{anchor.DID})}.Score(score.Input{Now: time.Now()}).Score
+	if got := *resp.Results[1].CapabilityScore; got != baseline {
+		t.Fatalf("untagged agent ranks at %v, want the v2 baseline %v", got, baseline)
+	}
+
+	if code := getJSON(t, ts.URL+"/v1/search?cap="+url.QueryEscape(`x".score') OR 1=1 --`), nil); code != 400 {
+		t.Fatalf("malformed capability tag: got %d, want 400", code)
+	}
+}
+
+// ?at= recomputes over the chain as the registry held it at that instant, and
+// the series endpoint samples the same computation over time.
+func TestHistoricalScore(t *testing.T) {
+	st, err := store.Open(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+	ts := httptest.NewServer((&Server{Store: st, Name: "test", Version: "test"}).Handler())
+	defer ts.Close()
+
+	owner, _ := core.GenerateKeyPair()
+	agent, _ := core.GenerateKeyPair()
+	postJSON(t, ts.URL+"/v1/agents", mustCard(t, owner, agent, "subject"))
+
+	hired := time.Now().UTC().Add(-48 * time.Hour).Truncate(time.Second)
+	// attest stores an attestation issued at issued and, as far as the
+	// registry knows, received at received.
+	attest := func(typ string, issued, received time.Time) {
+		issuer, _ := core.GenerateKeyPair()
+		a := core.NewAttestation(typ, issuer.DID, agent.DID)
+		a.IssuedAt = issued.Format(time.RFC3339)
+		if err := a.Sign(issuer.Private); err != nil {
+			t.Fatal(err)
+		}
+		if code, body := postJSON(t, ts.URL+"/v1/attestations", a); code != 201 {
+			t.Fatalf("attest: %d %s", code, body)
+		}
+		h, _ := a.Hash()
+		if _, err := st.DB().Exec(`UPDATE attestations SET received_at = ? WHERE hash = ?`, received.Format(time.RFC3339), h); err != nil {
+			t.Fatal(err)
+		}
+	}
+	arrive := func(typ string, at time.Time) { attest(typ, at, at) }
+	arrive(core.TypeTaskCompleted, hired.Add(-24*time.Hour))
+	arrive(core.TypeIncident, hired.Add(24*time.Hour))
+
+	type scoreObj struct {
+		Score      float64 `json:"score"`
+		ComputedAt string  `json:"computed_at"`
+		Inputs     struct {
+			Completions int `json:"completions"`
+			Incidents   int `json:"incidents"`
+		} `json:"inputs"`
+	}
+	var then, now scoreObj
+	if code := getJSON(t, ts.URL+"/v1/score/"+agent.DID+"?at="+hired.Format(time.RFC3339), &then); code != 200 {
+		t.Fatalf("historical score: %d", code)
+	}
+	getJSON(t, ts.URL+"/v1/score/"+agent.DID, &now)
+	if then.Inputs.Completions != 1 || then.Inputs.Incidents != 0 || then.ComputedAt != hired.Format(time.RFC3339) {
+		t.Fatalf("score at hiring should see only the earlier completion, at that clock: %+v", then)
+	}
+	if then.Score <= now.Score {
+		t.Fatalf("the later incident should have lowered the score since: then=%.1f now=%.1f", then.Score, now.Score)
+	}
+	// A completion backdated to before the hiring but published afterwards
+	// does not rewrite the score already served for that instant.
+	attest(core.TypeTaskCompleted, hired.Add(-time.Hour), hired.Add(26*time.Hour))
+	var again scoreObj
+	getJSON(t, ts.URL+"/v1/score/"+agent.DID+"?at="+hired.Format(time.RFC3339), &again)
+	if again != then {
+		t.Fatalf("a backdated record moved the as-of score: was %+v, now %+v", then, again)
+	}
+	if code := getJSON(t, ts.URL+"/v1/score/"+agent.DID+"?at=yesterday", nil); code != 400 {
+		t.Fatalf("bad at: got %d, want 400", code)
+	}
+
+	var series struct {
+		Points []struct {
+			At    string  `json:"at"`
+			Score float64 `json:"score"`
+		} `json:"points"`
+	}
+	if code := getJSON(t, ts.URL+"/v1/score/"+agent.DID+"/series?step=24h&to="+hired.Add(48*time.Hour).Format(time.RFC3339), &series); code != 200 {
+		t.Fatalf("series: %d", code)
+	}
+	// From the first attestation, daily, to `to`: hired-24h, hired, hired+24h, hired+48h.
+	if len(series.Points) != 4 || series.Points[1].At != hired.Format(time.RFC3339) || series.Points[1].Score != then.Score {
+		t.Fatalf("series should sample the historical score: %+v", series.Points)
+	}
+	if series.Points[3].Score >= series.Points[1].Score {
+		t.Fatalf("series should show the drop after the incident: %+v", series.Points)
+	}
+	if code := getJSON(t, ts.URL+"/v1/score/"+agent.DID+"/series?step=1m&from=2020-01-01T00:00:00Z", nil); code != 400 {
+		t.Fatalf("unbounded series: got %d, want 400", code)
+	}
+}
+
+// Scoring models are pluggable: ?algorithm= picks any registered one, the
+// instance default drives profiles and search, and each model has its own cache
+// row.
+func TestScoreSimulate(t *testing.T) {
+	ts, cleanup := testEnv(t)
+	defer cleanup()
+
+	owner, _ := core.GenerateKeyPair()
+	agent, _ := core.GenerateKeyPair()
+	postJSON(t, ts.URL+"/v1/agents", mustCard(t, owner, agent, "subject"))
+	var first *core.KeyPair
+	for i := range 2 {
+		issuer, _ := core.GenerateKeyPair()
+		if i == 0 {
+			first = issuer
+		}
+		a := core.NewAttestation(core.TypeTaskCompleted, issuer.DID, agent.DID)
+		if err := a.Sign(issuer.Private); err != nil {
+			t.Fatal(err)
+		}
+		if code, body := postJSON(t, ts.URL+"/v1/attestations", a); code != 201 {
+			t.Fatalf("attest: %d %s", code, body)
+		}
+	}
+	type scoreObj struct {
+		Score  float64 `json:"score"`
+		Inputs struct {
+			Completions int `json:"completions"`
+			Disputes    int `json:"disputes"`
+		} `json:"inputs"`
+	}
+	var live scoreObj
+	getJSON(t, ts.URL+"/v1/score/"+agent.DID, &live)
+
+	req := map[string]any{
+		"add": []map[string]any{
+			{"type": core.TypeTaskDisputed, "issuer": "did:key:zHypotheticalA"},
+			{"type": core.TypeTaskDisputed, "issuer": "did:key:zHypotheticalB"},
+		},
+		"exclude_issuers": []string{first.DID},
+	}
+	code, body := postJSON(t, ts.URL+"/v1/score/"+agent.DID+"/simulate", req)
+	if code != 200 {
+		t.Fatalf("simulate: %d %s", code, body)
+	}
+	var sim struct {
+		Before, After scoreObj
+		Excluded      []string `json:"excluded"`
+	}
+	if err := json.Unmarshal(body, &sim); err != nil {
+		t.Fatal(err)
+	}
+	if sim.Before.Score != live.Score || sim.Before.Inputs.Completions != 2 {
+		t.Fatalf("before should be the live score: %+v vs %+v", sim.Before, live)
+	}
+	if sim.After.Inputs.Completions != 1 || sim.After.Inputs.Disputes != 2 || sim.After.Score >= sim.Before.Score || len(sim.Excluded) != 1 {
+		t.Fatalf("after should drop one completion and add two disputes: %+v", sim)
+	}
+
+	// Nothing was persisted: neither the hypothetical records nor the score.
+	var again scoreObj
+	getJSON(t, ts.URL+"/v1/score/"+agent.DID, &again)
+	if again.Score != live.Score || again.Inputs.Disputes != 0 {
+		t.Fatalf("simulation leaked into the live score: %+v", again)
+	}
+
+	// Excluding an issuer drops what it co-signed and what a delegate signed
+	// on its behalf, not only what it signed itself.
+	pOwner, _ := core.GenerateKeyPair()
+	principal, _ := core.GenerateKeyPair()
+	worker, _ := core.GenerateKeyPair()
+	lead, _ := core.GenerateKeyPair()
+	postJSON(t, ts.URL+"/v1/agents", mustCard(t, pOwner, principal, "principal"))
+	d := core.NewDelegation(pOwner.DID, principal.DID, worker.DID, time.Now().Add(24*time.Hour))
+	d.IssuedAt = time.Now().UTC().Add(-time.Hour).Format(time.RFC3339)
+	if err := d.Sign(pOwner.Private); err != nil {
+		t.Fatal(err)
+	}
+	if code, body := postJSON(t, ts.URL+"/v1/delegations", d); code != 201 {
+		t.Fatalf("delegation: %d %s", code, body)
+	}
+	dh, _ := d.Hash()
+	delegated := core.NewAttestation(core.TypeTaskCompleted, worker.DID, agent.DID)
+	delegated.OnBehalfOf, delegated.Delegation = principal.DID, dh
+	if err := delegated.Sign(worker.Private); err != nil {
+		t.Fatal(err)
+	}
+	cosigned := core.NewAttestation(core.TypeTaskCompleted, lead.DID, agent.DID)
+	cosigned.CoIssuers = []core.CoIssuer{{Issuer: principal.DID}}
+	if err := cosigned.Sign(lead.Private); err != nil {
+		t.Fatal(err)
+	}
+	if err := cosigned.AddCoSig(principal); err != nil {
+		t.Fatal(err)
+	}
+	for _, a := range []*core.Attestation{delegated, cosigned} {
+		if code, body := postJSON(t, ts.URL+"/v1/attestations", a); code != 201 {
+			t.Fatalf("attest: %d %s", code, body)
+		}
+	}
+	code, body = postJSON(t, ts.URL+"/v1/score/"+agent.DID+"/simulate", map[string]any{"exclude_issuers": []string{principal.DID}})
+	if code != 200 {
+		t.Fatalf("simulate: %d %s", code, body)
+	}
+	sim.Excluded = nil
+	if err := json.Unmarshal(body, &sim); err != nil {
+		t.Fatal(err)
+	}
+	if len(sim.Excluded) != 2 || sim.After.Inputs.Completions != sim.Before.Inputs.Completions-2 {
+		t.Fatalf("the principal's delegated and co-signed records should be dropped: %+v", sim)
+	}
+
+	bad := map[string]any{"add": []map[string]any{{"type": "task.nonsense", "issuer": first.DID}}}
+	if code, _ := postJSON(t, ts.URL+"/v1/score/"+agent.DID+"/simulate", bad); code != 400 {
+		t.Fatalf("unknown type: got %d, want 400", code)
+	}
+}
+
+func TestScoreAlgorithmRegistry(t *testing.T) {
+	st, err := store.Open(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+	anchor, _ := core.GenerateKeyPair()
+	algs := score.Builtin([]string{anchor.DID})
+	strict := score.DefaultBasis([]string{anchor.DID})
+	strict.Params.Damping = 0.5
+	if err := algs.Register(score.V2{Label: "acme/strict", Basis: strict}); err != nil {
+		t.Fatal(err)
+	}
+	srv := &Server{Store: st, Name: "test", Version: "test", Algorithms: algs, DefaultAlgorithm: score.AlgorithmV2}
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+
+	owner, _ := core.GenerateKeyPair()
+	agent, _ := core.GenerateKeyPair()
+	postJSON(t, ts.URL+"/v1/agents", mustCard(t, owner, agent, "subject"))
+	a := core.NewAttestation(core.TypeTaskCompleted, anchor.DID, agent.DID)
+	if err := a.Sign(anchor.Private); err != nil {
+		t.Fatal(err)
+	}
+	postJSON(t, ts.URL+"/v1/attestations", a)
+
+	var prof struct {
+		Score score.Output `json:"score"`
+	}
+	getJSON(t, ts.URL+"/v1/agents/"+agent.DID, &prof)
+	if prof.Score.Algorithm != score.AlgorithmV2 {
+		t.Fatalf("profile should show the instance default, got %s", prof.Score.Algorithm)
+	}
+	var custom score.Output
+	if code := getJSON(t, ts.URL+"/v1/score/"+agent.DID+"?algorithm=acme/strict", &custom); code != 200 || custom.Algorithm != "acme/strict" {
+		t.Fatalf("custom model: %d %+v", code, custom)
+	}
+	if want, _ := strict.Hash(); custom.Basis != want {
+		t.Fatalf("custom model basis %s, want %s", custom.Basis, want)
+	}
+	var basis score.Basis
+	if code := getJSON(t, ts.URL+"/v1/score/basis?algorithm=acme/strict", &basis); code != 200 || basis.Params.Damping != 0.5 {
+		t.Fatalf("custom basis document: %d %+v", code, basis.Params)
+	}
+	if code := getJSON(t, ts.URL+"/v1/score/"+agent.DID+"?algorithm=moltscore/v9", nil); code != 400 {
+		t.Fatalf("unknown algorithm: got %d, want 400", code)
+	}
+	if code := getJSON(t, ts.URL+"/v1/score/"+agent.DID+"?algorithm=acme/strict&explain=1", nil); code != 400 {
+		t.Fatalf("explain on a model without explanations: got %d, want 400", code)
+	}
+	for alg, want := range map[string]float64{score.AlgorithmV2: prof.Score.Score, "acme/strict": custom.Score} {
+		if v, ok, _ := st.CachedScore(agent.DID, alg); !ok || v != want {
+			t.Errorf("%s cache row: %v %v, want %v", alg, v, ok, want)
+		}
+	}
+	// v1 stays cached even when not the default: it is v1's issuer weighting.
+	if _, ok, _ := st.CachedScore(agent.DID, score.AlgorithmV1); !ok {
+		t.Error("moltscore/v1 row should be kept current")
+	}
+	var wk struct {
+		ScoreAlgorithms struct {
+			Default   string   `json:"default"`
+			Available []string `json:"available"`
+		} `json:"score_algorithms"`
+	}
+	getJSON(t, ts.URL+"/.well-known/moltnet", &wk)
+	if wk.ScoreAlgorithms.Default != score.AlgorithmV2 || len(wk.ScoreAlgorithms.Available) != 3 {
+		t.Fatalf("well-known should list the models: %+v", wk.ScoreAlgorithms)
+	}
+}
+
+// When an issuer's score moves, the subjects it attested are re-scored by the
+// recompute engine — up to the depth bound — and /v1/stats reports the
+// staleness in between.
+func TestRecomputePropagates(t *testing.T) {
+	st, err := store.Open(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+	srv := &Server{Store: st, Name: "test", Version: "test", RecomputeDepth: 1}
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+
+	register := func(name string) *core.KeyPair {
+		owner, _ := core.GenerateKeyPair()
+		agent, _ := core.GenerateKeyPair()
+		postJSON(t, ts.URL+"/v1/agents", mustCard(t, owner, agent, name))
+		return agent
+	}
+	attest := func(issuer, subject *core.KeyPair) {
+		a := core.NewAttestation(core.TypeTaskCompleted, issuer.DID, subject.DID)
+		a.Prev, _ = st.IssuerHead(issuer.DID)
+		if err := a.Sign(issuer.Private); err != nil {
+			t.Fatal(err)
+		}
+		if code, body := postJSON(t, ts.URL+"/v1/attestations", a); code != 201 {
+			t.Fatalf("attest: %d %s", code, body)
+		}
+	}
+	issuer, subject, downstream := register("issuer"), register("subject"), register("downstream")
+	attest(issuer, subject)
+	attest(subject, downstream)
+	srv.drainRecompute()
+	cached := func(a *core.KeyPair) float64 {
+		v, _, _ := st.CachedScore(a.DID, score.AlgorithmV1)
+		return v
+	}
+	subjectBefore, downstreamBefore := cached(subject), cached(downstream)
+
+	// The issuer's standing rises; only the issuer is re-scored inline.
+	for i := 0; i < 3; i++ {
+		attest(register("peer"), issuer)
+	}
+	type stats struct {
+		Recompute struct {
+			Stale        int   `json:"stale"`
+			Changed      int64 `json:"changed"`
+			DepthLimited int64 `json:"depth_limited"`
+		} `json:"recompute"`
+	}
+	var before, after stats
+	getJSON(t, ts.URL+"/v1/stats", &before)
+	if before.Recompute.Stale != 1 || cached(subject) != subjectBefore {
+		t.Fatalf("the subject should be queued, not yet re-scored: %+v", before.Recompute)
+	}
+
+	srv.drainRecompute()
+	getJSON(t, ts.URL+"/v1/stats", &after)
+	if cached(subject) <= subjectBefore {
+		t.Fatalf("the subject's score should follow its issuer's: %v -> %v", subjectBefore, cached(subject))
+	}
+	if after.Recompute.Stale != 0 || after.Recompute.Changed <= before.Recompute.Changed {
+		t.Fatalf("drained engine should report the change: %+v", after.Recompute)
+	}
+	// Depth 1: the subject moved, but its own subjects are beyond the bound.
+	if cached(downstream) != downstreamBefore || after.Recompute.DepthLimited != before.Recompute.DepthLimited+1 {
+		t.Fatalf("propagation should stop at the depth bound: downstream %v -> %v, %+v",
+			downstreamBefore, cached(downstream), after.Recompute)
+	}
+}
+
+func TestRecomputeQueueBounded(t *testing.T) {
+	q := recomputeQueue{limit: 2}
+	q.push("did:a", 0)
+	q.push("did:b", 1)
+	q.push("did:c", 0) // full: dropped
+	q.push("did:a", 3) // already queued: coalesced, not dropped
+	snap := q.snapshot(8)
+	if snap["stale"] != 2 || snap["dropped"] != int64(1) {
+		t.Fatalf("snapshot = %v, want 2 stale and 1 dropped", snap)
+	}
+	if did, j, _ := q.pop(); did != "did:a" || j.depth != 0 {
+		t.Fatalf("pop = %s at depth %d, want did:a at depth 0", did, j.depth)
+	}
+	q.push("did:c", 0) // room again
+	if snap := q.snapshot(8); snap["stale"] != 2 {
+		t.Fatalf("stale = %v after a pop and a push, want 2", snap["stale"])
 	}
 }
@@ -0,0 +1,174 @@
+package server
+
+import (
+	"encoding/json"
+	"maps"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/moltnet/moltnet/core"
+	"github.com/moltnet/moltnet/score"
+)
+
+// maxSimulatedAttestations bounds how many hypothetical records one simulation
+// may add.
+const maxSimulatedAttestations = 256
+
+// simulateRequest is the body of POST /v1/score/{did}/simulate.
+type simulateRequest struct {
+	Algorithm string `json:"algorithm,omitempty"`
+	// Add are hypothetical attestations about the subject. They are unsigned:
+	// the simulation takes them at face value and never stores them.
+	Add []simulatedAttestation `json:"add,omitempty"`
+	// ExcludeHashes and ExcludeIssuers drop stored attestations from the set:
+	// by issuer, every record the DID signed, co-signed, or had a delegate
+	// sign on its behalf.
+	ExcludeHashes  []string `json:"exclude_hashes,omitempty"`
+	ExcludeIssuers []string `json:"exclude_issuers,omitempty"`
+	Explain        bool     `json:"explain,omitempty"`
+}
+
+// simulatedAttestation is a hypothetical record; the subject is always the
+// simulated DID and issued_at defaults to now.
+type simulatedAttestation struct {
+	Type     string         `json:"type"`
+	Issuer   string         `json:"issuer"`
+	IssuedAt string         `json:"issued_at,omitempty"`
+	Body     map[string]any `json:"body,omitempty"`
+}
+
+// handleScoreSimulate answers "what would this agent's score be if…": it adds
+// hypothetical attestations and drops stored ones by hash or issuer, then runs
+// the model over the modified set exactly as GET /v1/score/{did} does. Both the
+// live and the simulated output are returned; nothing is cached or persisted.
+func (s *Server) handleScoreSimulate(w http.ResponseWriter, r *http.Request) {
+	did := r.PathValue("did")
+	c, err := s.Store.GetCard(did)
+	if err != nil || c == nil {
+		writeErr(w, http.StatusNotFound, "agent not found")
+		return
+	}
+	var req simulateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErr(w, http.StatusBadRequest, "invalid simulation json: "+err.Error())
+		return
+	}
+	if len(req.Add) > maxSimulatedAttestations {
+		writeErr(w, http.StatusBadRequest, "at most "+strconv.Itoa(maxSimulatedAttestations)+" attestations may be added")
+		return
+	}
+	alg, ok := s.algorithm(req.Algorithm)
+	if !ok {
+		writeErr(w, http.StatusBadRequest, "unknown algorithm "+strconv.Quote(req.Algorithm))
+		return
+	}
+	ex, canExplain := alg.(score.Explainer)
+	if req.Explain && !canExplain {
+		writeErr(w, http.StatusBadRequest, "explain is not available for "+alg.Name())
+		return
+	}
+
+	var added []*core.Attestation
+	for i, h := range req.Add {
+		if !core.ValidType(h.Type) {
+			writeErr(w, http.StatusBadRequest, "add["+strconv.Itoa(i)+"]: unknown type "+strconv.Quote(h.Type))
+			return
+		}
+		if h.Issuer == "" {
+			writeErr(w, http.StatusBadRequest, "add["+strconv.Itoa(i)+"]: issuer is required")
+			return
+		}
+		a := core.NewAttestation(h.Type, h.Issuer, did)
+		if h.IssuedAt != "" {
+			t, err := time.Parse(time.RFC3339, h.IssuedAt)
+			if err != nil {
+				writeErr(w, http.StatusBadRequest, "add["+strconv.Itoa(i)+"]: issued_at must be an RFC 3339 timestamp")
+				return
+			}
+			a.IssuedAt = t.UTC().Format(time.RFC3339)
+		}
+		a.Body = h.Body
+		added = append(added, a)
+	}
+
+	in, err := s.scoreInput(alg, did)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	run := func(in score.Input) score.Output {
+		if req.Explain {
+			return ex.Explain(in)
+		}
+		return alg.Score(in)
+	}
+	before := run(in)
+
+	drop := map[string]bool{}
+	for _, h := range req.ExcludeHashes {
+		drop[h] = true
+	}
+	dropIssuer := map[string]bool{}
+	for _, iss := range req.ExcludeIssuers {
+		dropIssuer[iss] = true
+	}
+	byIssuer := func(a *core.Attestation) bool {
+		for _, p := range append(a.Issuers(), a.Principals()...) {
+			if dropIssuer[p] {
+				return true
+			}
+		}
+		return false
+	}
+	var excluded []string
+	keep := func(atts []*core.Attestation, record bool) []*core.Attestation {
+		var out []*core.Attestation
+		for _, a := range atts {
+			h, _ := a.Hash()
+			if drop[h] || byIssuer(a) {
+				if record {
+					excluded = append(excluded, h)
+				}
+				continue
+			}
+			out = append(out, a)
+		}
+		return out
+	}
+	sim := in
+	sim.Attestations = append(keep(in.Attestations, true), added...)
+	if in.Graph != nil {
+		sim.Graph = append(keep(in.Graph, false), added...)
+	}
+	if in.IssuerWeights != nil {
+		// Copy before weighing the hypothetical issuers, so before's input is
+		// left as it was.
+		sim.IssuerWeights = maps.Clone(in.IssuerWeights)
+		sim.OwnerOf = maps.Clone(in.OwnerOf)
+		owners, err := s.Store.OwnerSuccession()
+		if err != nil {
+			writeErr(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		s.weighIssuers(&sim, added, owners)
+	}
+	after := run(sim)
+
+	if excluded == nil {
+		excluded = []string{}
+	}
+	if added == nil {
+		added = []*core.Attestation{}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"did":       did,
+		"algorithm": alg.Name(),
+		"before":    before,
+		"after":     after,
+		"delta":     math.Round((after.Score-before.Score)*10) / 10,
+		"added":     added,
+		"excluded":  excluded,
+	})
+}
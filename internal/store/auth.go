@@ -2,6 +2,7 @@ package store
 
 import (
 	"database/sql"
+	"strings"
 	"time"
 )
 
@@ -131,6 +132,12 @@ func (s *Store) DeleteSession(tokenHash string) error {
 	return err
 }
 
+// DeleteOwnerSessions signs an owner key out everywhere (its key was rotated).
+func (s *Store) DeleteOwnerSessions(ownerDID string) error {
+	_, err := s.db.Exec(`DELETE FROM sessions WHERE owner_did = ?`, ownerDID)
+	return err
+}
+
 // ---- API keys --------------------------------------------------------------
 
 // APIKey is a per-agent programmatic credential. The raw key is shown to the
@@ -215,14 +222,37 @@ func (s *Store) RevokeAPIKey(id, ownerDID, revokedAt string) (bool, error) {
 	return n > 0, nil
 }
 
-// AgentsByOwner lists every agent whose card owner is ownerDID, newest first,
-// with its cached score. This is the data behind the user dashboard.
-func (s *Store) AgentsByOwner(ownerDID string) ([]Agent, error) {
+// AgentsByOwner lists every agent ownerDID currently owns, newest first, with
+// its cached score under algorithm: those whose card owner is ownerDID or an
+// owner key it succeeded. A retired owner key owns nothing. This is the data
+// behind the user dashboard.
+func (s *Store) AgentsByOwner(ownerDID, algorithm string) ([]Agent, error) {
+	succ, err := s.OwnerSuccession()
+	if err != nil {
+		return nil, err
+	}
+	var keys []string
+	owners, err := s.dids(`SELECT DISTINCT COALESCE(json_extract(card_json, '$.owner'),'') FROM agents`)
+	if err != nil {
+		return nil, err
+	}
+	for _, o := range owners {
+		if c, err := succ.Current(o); err == nil && c == ownerDID {
+			keys = append(keys, o)
+		}
+	}
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	args := []any{algorithm}
+	for _, k := range keys {
+		args = append(args, k)
+	}
 	rows, err := s.db.Query(
 		`SELECT a.did, a.name, COALESCE(a.description,''), COALESCE(a.capabilities,''), COALESCE(s.score,0)
-         FROM agents a LEFT JOIN scores s ON s.did = a.did
-         WHERE json_extract(a.card_json, '$.owner') = ?
-         ORDER BY a.updated_at DESC`, ownerDID)
+         FROM agents a LEFT JOIN scores s ON s.did = a.did AND s.algorithm = ?
+         WHERE json_extract(a.card_json, '$.owner') IN (?`+strings.Repeat(", ?", len(keys)-1)+`)
+         ORDER BY a.updated_at DESC`, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,76 @@
+package store
+
+import (
+	"encoding/json"
+
+	"github.com/moltnet/moltnet/core"
+)
+
+// PutDelegation stores an owner-signed issuing-key delegation. Returns true if
+// newly inserted.
+func (s *Store) PutDelegation(d *core.Delegation) (bool, error) {
+	hash, err := d.Hash()
+	if err != nil {
+		return false, err
+	}
+	raw, err := json.Marshal(d)
+	if err != nil {
+		return false, err
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(
+		`INSERT INTO delegations (hash, owner, principal, delegate, expires_at, issued_at, raw_json)
+         VALUES (?, ?, ?, ?, ?, ?, ?) ON CONFLICT(hash) DO NOTHING`,
+		hash, d.Owner, d.Principal, d.Delegate, d.ExpiresAt, d.IssuedAt, string(raw))
+	if err != nil {
+		return false, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return false, tx.Commit()
+	}
+	if err = appendEvent(tx, "delegation", hash, string(raw), d.IssuedAt); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+// GetDelegation returns the delegation with the given hash, or nil.
+func (s *Store) GetDelegation(hash string) (*core.Delegation, error) {
+	ds, err := s.delegations(`SELECT raw_json FROM delegations WHERE hash = ?`, hash)
+	if err != nil || len(ds) == 0 {
+		return nil, err
+	}
+	return ds[0], nil
+}
+
+// AllDelegations returns every delegation, oldest first.
+func (s *Store) AllDelegations() ([]*core.Delegation, error) {
+	return s.delegations(`SELECT raw_json FROM delegations ORDER BY issued_at ASC`)
+}
+
+// DelegationsFor returns the delegations naming did as principal or as
+// delegate, oldest first.
+func (s *Store) DelegationsFor(did string) ([]*core.Delegation, error) {
+	return s.delegations(`SELECT raw_json FROM delegations WHERE principal = ? OR delegate = ? ORDER BY issued_at ASC`, did, did)
+}
+
+func (s *Store) delegations(query string, args ...any) ([]*core.Delegation, error) {
+	raws, err := s.rawRecords(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*core.Delegation, 0, len(raws))
+	for _, raw := range raws {
+		var d core.Delegation
+		if err := json.Unmarshal([]byte(raw), &d); err != nil {
+			return nil, err
+		}
+		out = append(out, &d)
+	}
+	return out, nil
+}
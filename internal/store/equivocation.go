@@ -0,0 +1,89 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	"github.com/moltnet/moltnet/core"
+)
+
+// PutEquivocation stores a verified equivocation proof, such as one a peer
+// federated. Returns true if newly inserted.
+func (s *Store) PutEquivocation(e *core.Equivocation) (bool, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+	inserted, err := putEquivocation(tx, e)
+	if err != nil || !inserted {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+// putEquivocation stores e within tx and emits a federation event when it is
+// new, so the proof reaches instances that only ever saw one branch.
+func putEquivocation(tx *sql.Tx, e *core.Equivocation) (bool, error) {
+	hash, err := e.Hash()
+	if err != nil {
+		return false, err
+	}
+	raw, err := json.Marshal(e)
+	if err != nil {
+		return false, err
+	}
+	ha, hb := e.Hashes()
+	at := max(e.A.IssuedAt, e.B.IssuedAt)
+	res, err := tx.Exec(
+		`INSERT INTO equivocations (hash, issuer, prev, a_hash, b_hash, issued_at, raw_json, received_at)
+         VALUES (?, ?, ?, ?, ?, ?, ?, ?) ON CONFLICT(hash) DO NOTHING`,
+		hash, e.Issuer, e.Prev, ha, hb, at, string(raw), nowRFC3339())
+	if err != nil {
+		return false, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return false, nil
+	}
+	if err := appendEvent(tx, "equivocation", hash, string(raw), at); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// EquivocationsFor returns the proofs against issuer, oldest first.
+func (s *Store) EquivocationsFor(issuer string) ([]*core.Equivocation, error) {
+	return s.equivocations(`SELECT raw_json FROM equivocations WHERE issuer = ? ORDER BY issued_at ASC`, issuer)
+}
+
+// AllEquivocations returns every stored proof, oldest first.
+func (s *Store) AllEquivocations() ([]*core.Equivocation, error) {
+	return s.equivocations(`SELECT raw_json FROM equivocations ORDER BY issued_at ASC`)
+}
+
+func (s *Store) equivocations(query string, args ...any) ([]*core.Equivocation, error) {
+	raws, err := s.rawRecords(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*core.Equivocation, 0, len(raws))
+	for _, raw := range raws {
+		var e core.Equivocation
+		if err := json.Unmarshal([]byte(raw), &e); err != nil {
+			return nil, err
+		}
+		out = append(out, &e)
+	}
+	return out, nil
+}
+
+// Successor returns one stored attestation on issuer's chain, led or
+// co-signed, that names prev as its predecessor there, or nil if there is none.
+func (s *Store) Successor(issuer, prev string) (*core.Attestation, error) {
+	atts, err := s.queryAttestations(
+		`SELECT raw_json FROM issuer_links WHERE issuer = ? AND prev = ? ORDER BY issued_at ASC LIMIT 1`, issuer, prev)
+	if err != nil || len(atts) == 0 {
+		return nil, err
+	}
+	return atts[0], nil
+}
@@ -0,0 +1,127 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	"github.com/moltnet/moltnet/core"
+)
+
+// PutForkResolution stores a verified fork resolution: it marks the open forks
+// between its branches resolved and makes its head the agent's current card,
+// then emits a federation event. A resolution that settles no open fork held
+// here, or whose chosen version is not held, changes nothing and is not
+// stored; settled reports whether it was applied.
+func (s *Store) PutForkResolution(r *core.ForkResolution) (settled bool, err error) {
+	hash, err := r.Hash()
+	if err != nil {
+		return false, err
+	}
+	raw, err := json.Marshal(r)
+	if err != nil {
+		return false, err
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	open, err := openForks(tx, r.Agent)
+	if err != nil {
+		return false, err
+	}
+	var forks []Fork
+	for _, f := range open {
+		if r.Settles(f.HeadHash, f.CompetingHash) {
+			forks = append(forks, f)
+		}
+	}
+	if len(forks) == 0 {
+		return false, nil
+	}
+	var cardJSON string
+	err = tx.QueryRow(`SELECT card_json FROM card_history WHERE did = ? AND card_hash = ? LIMIT 1`,
+		r.Agent, r.Head).Scan(&cardJSON)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	var head core.Card
+	if err := json.Unmarshal([]byte(cardJSON), &head); err != nil {
+		return false, err
+	}
+
+	res, err := tx.Exec(
+		`INSERT INTO fork_resolutions (hash, did, head, issued_at, raw_json) VALUES (?, ?, ?, ?, ?)
+         ON CONFLICT(hash) DO NOTHING`,
+		hash, r.Agent, r.Head, r.IssuedAt, string(raw))
+	if err != nil {
+		return false, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return false, nil
+	}
+	for _, f := range forks {
+		if _, err := tx.Exec(`UPDATE forks SET resolved_by = ? WHERE did = ? AND competing_hash = ?`,
+			hash, f.DID, f.CompetingHash); err != nil {
+			return false, err
+		}
+	}
+	if err := setHead(tx, &head, r.Head, cardJSON); err != nil {
+		return false, err
+	}
+	if err := appendEvent(tx, "fork_resolution", hash, string(raw), r.IssuedAt); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+// openForks returns did's unresolved forks, oldest first.
+func openForks(tx *sql.Tx, did string) ([]Fork, error) {
+	rows, err := tx.Query(
+		`SELECT did, head_hash, competing_hash, COALESCE(detected_at,'')
+         FROM forks WHERE did = ? AND resolved_by IS NULL ORDER BY detected_at ASC`, did)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []Fork
+	for rows.Next() {
+		var f Fork
+		if err := rows.Scan(&f.DID, &f.HeadHash, &f.CompetingHash, &f.DetectedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, f)
+	}
+	return out, rows.Err()
+}
+
+// ForkResolutionsFor returns the resolutions applied to did's card forks,
+// oldest first.
+func (s *Store) ForkResolutionsFor(did string) ([]*core.ForkResolution, error) {
+	return s.forkResolutions(`SELECT raw_json FROM fork_resolutions WHERE did = ? ORDER BY issued_at ASC`, did)
+}
+
+// AllForkResolutions returns every applied fork resolution, oldest first.
+func (s *Store) AllForkResolutions() ([]*core.ForkResolution, error) {
+	return s.forkResolutions(`SELECT raw_json FROM fork_resolutions ORDER BY issued_at ASC`)
+}
+
+func (s *Store) forkResolutions(query string, args ...any) ([]*core.ForkResolution, error) {
+	raws, err := s.rawRecords(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*core.ForkResolution, 0, len(raws))
+	for _, raw := range raws {
+		var r core.ForkResolution
+		if err := json.Unmarshal([]byte(raw), &r); err != nil {
+			return nil, err
+		}
+		out = append(out, &r)
+	}
+	return out, nil
+}
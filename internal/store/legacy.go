@@ -0,0 +1,63 @@
+package store
+
+import "github.com/moltnet/moltnet/core"
+
+// LegacyRecord is a stored signed record whose canonical form changed when
+// canonicalization moved to RFC 8785 (see core.LegacyCanonicalDiffs): its
+// signature no longer verifies and its hash is not what it was, so nothing
+// that links to it does either.
+type LegacyRecord struct {
+	Table string   `json:"table"`
+	Key   string   `json:"key"` // the row's hash, or an agent's DID
+	Diffs []string `json:"diffs"`
+}
+
+// legacyColumns names, for each table holding signed records, its key column
+// and the column with the record as stored.
+var legacyColumns = []struct{ table, key, record string }{
+	{"agents", "did", "card_json"},
+	{"card_history", "card_hash", "card_json"},
+	{"attestations", "hash", "raw_json"},
+	{"rotations", "hash", "raw_json"},
+	{"revocations", "hash", "raw_json"},
+	{"owner_rotations", "hash", "raw_json"},
+	{"guardian_sets", "hash", "raw_json"},
+	{"recoveries", "hash", "raw_json"},
+	{"recovery_vetoes", "hash", "raw_json"},
+	{"delegations", "hash", "raw_json"},
+	{"equivocations", "hash", "raw_json"},
+	{"fork_resolutions", "hash", "raw_json"},
+	{"tasks", "id", "offer_json"},
+}
+
+// LegacyRecords scans every stored signed record for ones written in a form
+// RFC 8785 canonicalizes differently, or that no longer parse under its
+// stricter input rules. Nothing is changed: a record is the bytes its signers
+// signed, so it cannot be rewritten, only re-issued by them.
+func (s *Store) LegacyRecords() ([]LegacyRecord, error) {
+	var out []LegacyRecord
+	for _, c := range legacyColumns {
+		rows, err := s.db.Query(`SELECT ` + c.key + `, ` + c.record + ` FROM ` + c.table)
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			var key, raw string
+			if err := rows.Scan(&key, &raw); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			diffs, err := core.LegacyCanonicalDiffs([]byte(raw))
+			if err != nil {
+				diffs = []string{err.Error()}
+			}
+			if len(diffs) > 0 {
+				out = append(out, LegacyRecord{Table: c.table, Key: key, Diffs: diffs})
+			}
+		}
+		if err := rows.Close(); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,49 @@
+package store
+
+import "database/sql"
+
+// OwnerDomain is the last check of a did:web owner's domain linkage: whether
+// the domain's DID document listed the owner key, and why not.
+type OwnerDomain struct {
+	Owner     string `json:"did"`
+	OwnerKey  string `json:"owner_key"`
+	Verified  bool   `json:"verified"`
+	Error     string `json:"error,omitempty"`
+	CheckedAt string `json:"checked_at"`
+}
+
+// RecordOwnerDomain stores the outcome of a domain check, replacing the last
+// one for the same owner and key.
+func (s *Store) RecordOwnerDomain(d *OwnerDomain) error {
+	v := 0
+	if d.Verified {
+		v = 1
+	}
+	_, err := s.db.Exec(
+		`INSERT INTO owner_domains (owner, owner_key, verified, error, checked_at) VALUES (?, ?, ?, ?, ?)
+         ON CONFLICT(owner, owner_key) DO UPDATE SET verified=excluded.verified, error=excluded.error, checked_at=excluded.checked_at`,
+		d.Owner, d.OwnerKey, v, d.Error, d.CheckedAt)
+	return err
+}
+
+// GetOwnerDomain returns the last domain check of owner and key, or (nil, nil)
+// if there has been none.
+func (s *Store) GetOwnerDomain(owner, key string) (*OwnerDomain, error) {
+	d := OwnerDomain{Owner: owner, OwnerKey: key}
+	var verified int
+	var errMsg *string
+	err := s.db.QueryRow(
+		`SELECT verified, error, checked_at FROM owner_domains WHERE owner=? AND owner_key=?`, owner, key).
+		Scan(&verified, &errMsg, &d.CheckedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	d.Verified = verified == 1
+	if errMsg != nil {
+		d.Error = *errMsg
+	}
+	return &d, nil
+}
@@ -0,0 +1,229 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"maps"
+	"time"
+
+	"github.com/moltnet/moltnet/core"
+)
+
+// Social recovery persistence: owner-signed guardian sets, the guardian-signed
+// recoveries that invoke them, and owner vetoes. Whether a recovery holds is
+// decided by core.Recoveries over all three; the store adds only the time each
+// recovery and veto reached this registry, which their signers cannot backdate.
+
+// PutGuardianSet stores an owner's guardian set. Returns true if newly
+// inserted.
+func (s *Store) PutGuardianSet(g *core.GuardianSet) (bool, error) {
+	hash, err := g.Hash()
+	if err != nil {
+		return false, err
+	}
+	raw, err := json.Marshal(g)
+	if err != nil {
+		return false, err
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(
+		`INSERT INTO guardian_sets (hash, owner, issued_at, raw_json)
+         VALUES (?, ?, ?, ?) ON CONFLICT(hash) DO NOTHING`,
+		hash, g.Owner, g.IssuedAt, string(raw))
+	if err != nil {
+		return false, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return false, tx.Commit()
+	}
+	if err = appendEvent(tx, "guardian_set", hash, string(raw), g.IssuedAt); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+// GuardianSets returns the guardian sets of owner, or of every owner if owner
+// is "", oldest first.
+func (s *Store) GuardianSets(owner string) ([]*core.GuardianSet, error) {
+	q, args := `SELECT raw_json FROM guardian_sets ORDER BY issued_at ASC`, []any{}
+	if owner != "" {
+		q, args = `SELECT raw_json FROM guardian_sets WHERE owner = ? ORDER BY issued_at ASC`, []any{owner}
+	}
+	raws, err := s.rawRecords(q, args...)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*core.GuardianSet, 0, len(raws))
+	for _, raw := range raws {
+		var g core.GuardianSet
+		if err := json.Unmarshal([]byte(raw), &g); err != nil {
+			return nil, err
+		}
+		out = append(out, &g)
+	}
+	return out, nil
+}
+
+// PutRecovery stores a recovery, noting when it arrived: its waiting period
+// runs from then if that is later than the issued_at its signers chose.
+// Returns true if newly inserted.
+func (s *Store) PutRecovery(r *core.Recovery) (bool, error) {
+	hash, err := r.Hash()
+	if err != nil {
+		return false, err
+	}
+	raw, err := json.Marshal(r)
+	if err != nil {
+		return false, err
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(
+		`INSERT INTO recoveries (hash, owner, new_owner, issued_at, received_at, raw_json)
+         VALUES (?, ?, ?, ?, ?, ?) ON CONFLICT(hash) DO NOTHING`,
+		hash, r.Owner, r.NewOwner, r.IssuedAt, time.Now().UTC().Format(time.RFC3339), string(raw))
+	if err != nil {
+		return false, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return false, tx.Commit()
+	}
+	if err = appendEvent(tx, "recovery", hash, string(raw), r.IssuedAt); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+// AllRecoveries returns every recovery, oldest first, with the time each
+// (by hash) reached this registry.
+func (s *Store) AllRecoveries() ([]*core.Recovery, map[string]time.Time, error) {
+	rows, err := s.db.Query(`SELECT hash, received_at, raw_json FROM recoveries ORDER BY issued_at ASC`)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+	var out []*core.Recovery
+	received := map[string]time.Time{}
+	for rows.Next() {
+		var hash, at, raw string
+		if err := rows.Scan(&hash, &at, &raw); err != nil {
+			return nil, nil, err
+		}
+		var r core.Recovery
+		if err := json.Unmarshal([]byte(raw), &r); err != nil {
+			return nil, nil, err
+		}
+		out = append(out, &r)
+		if t, err := time.Parse(time.RFC3339, at); err == nil {
+			received[hash] = t
+		}
+	}
+	return out, received, rows.Err()
+}
+
+// PutRecoveryVeto stores an owner's veto, noting when it arrived: it counts
+// from then if that is later than its issued_at. Returns true if newly
+// inserted.
+func (s *Store) PutRecoveryVeto(v *core.RecoveryVeto) (bool, error) {
+	hash, err := v.Hash()
+	if err != nil {
+		return false, err
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return false, err
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(
+		`INSERT INTO recovery_vetoes (hash, owner, recovery, issued_at, raw_json, received_at)
+         VALUES (?, ?, ?, ?, ?, ?) ON CONFLICT(hash) DO NOTHING`,
+		hash, v.Owner, v.Recovery, v.IssuedAt, string(raw), nowRFC3339())
+	if err != nil {
+		return false, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return false, tx.Commit()
+	}
+	if err = appendEvent(tx, "recovery_veto", hash, string(raw), v.IssuedAt); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+// AllRecoveryVetoes returns every veto, oldest first, with the time each (by
+// hash) reached this registry.
+func (s *Store) AllRecoveryVetoes() ([]*core.RecoveryVeto, map[string]time.Time, error) {
+	rows, err := s.db.Query(`SELECT hash, received_at, raw_json FROM recovery_vetoes ORDER BY issued_at ASC`)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+	out := []*core.RecoveryVeto{}
+	received := map[string]time.Time{}
+	for rows.Next() {
+		var hash, raw string
+		var at sql.NullString
+		if err := rows.Scan(&hash, &at, &raw); err != nil {
+			return nil, nil, err
+		}
+		var v core.RecoveryVeto
+		if err := json.Unmarshal([]byte(raw), &v); err != nil {
+			return nil, nil, err
+		}
+		out = append(out, &v)
+		if t, err := time.Parse(time.RFC3339, at.String); err == nil {
+			received[hash] = t
+		}
+	}
+	return out, received, rows.Err()
+}
+
+// RecoveryStates judges every stored recovery at now.
+func (s *Store) RecoveryStates(now time.Time) ([]core.RecoveryState, error) {
+	sets, err := s.GuardianSets("")
+	if err != nil {
+		return nil, err
+	}
+	recs, received, err := s.AllRecoveries()
+	if err != nil || len(recs) == 0 {
+		return nil, err
+	}
+	vetoes, vetoed, err := s.AllRecoveryVetoes()
+	if err != nil {
+		return nil, err
+	}
+	maps.Copy(received, vetoed)
+	return core.Recoveries(sets, recs, vetoes, received, now), nil
+}
+
+// rawRecords runs a query selecting one raw_json column.
+func (s *Store) rawRecords(query string, args ...any) ([]string, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []string
+	for rows.Next() {
+		var raw string
+		if err := rows.Scan(&raw); err != nil {
+			return nil, err
+		}
+		out = append(out, raw)
+	}
+	return out, rows.Err()
+}
@@ -8,6 +8,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/moltnet/moltnet/core"
 	"github.com/moltnet/moltnet/score"
@@ -46,15 +47,34 @@ CREATE TABLE IF NOT EXISTS attestations (
     type      TEXT NOT NULL,
     prev      TEXT,
     issued_at TEXT,
-    raw_json  TEXT NOT NULL
+    raw_json  TEXT NOT NULL,
+    received_at TEXT           -- when this instance first stored it
 );
 CREATE INDEX IF NOT EXISTS idx_att_subject ON attestations(subject);
 CREATE INDEX IF NOT EXISTS idx_att_issuer  ON attestations(issuer);
+CREATE INDEX IF NOT EXISTS idx_att_prev    ON attestations(issuer, prev);
+CREATE TABLE IF NOT EXISTS attestation_co_issuers (
+    hash      TEXT NOT NULL,
+    issuer    TEXT NOT NULL,
+    prev      TEXT,
+    issued_at TEXT,
+    PRIMARY KEY (hash, issuer)
+);
+CREATE INDEX IF NOT EXISTS idx_coiss_prev ON attestation_co_issuers(issuer, prev);
+-- issuer_links is every attestation on every chain it sits on: once for its
+-- issuer and once for each co-issuer of a co-signed attestation.
+CREATE VIEW IF NOT EXISTS issuer_links AS
+    SELECT hash, issuer, subject, type, prev, issued_at, raw_json FROM attestations
+    UNION ALL
+    SELECT c.hash, c.issuer, a.subject, a.type, c.prev, c.issued_at, a.raw_json
+    FROM attestation_co_issuers c JOIN attestations a ON a.hash = c.hash;
 CREATE TABLE IF NOT EXISTS scores (
-    did        TEXT PRIMARY KEY,
+    did        TEXT NOT NULL,
+    algorithm  TEXT NOT NULL DEFAULT 'moltscore/v1',
     score      REAL NOT NULL,
     output_json TEXT NOT NULL,
-    updated_at TEXT
+    updated_at TEXT,
+    PRIMARY KEY (did, algorithm)
 );
 CREATE TABLE IF NOT EXISTS liveness (
     did         TEXT PRIMARY KEY,
@@ -65,6 +85,14 @@ CREATE TABLE IF NOT EXISTS liveness (
     latency_ms  INTEGER,
     checked_at  TEXT
 );
+CREATE TABLE IF NOT EXISTS owner_domains (
+    owner      TEXT NOT NULL,       -- a did:web owner
+    owner_key  TEXT NOT NULL,       -- the did:key its cards name as owner_key
+    verified   INTEGER NOT NULL,    -- whether the domain's DID document listed it
+    error      TEXT,
+    checked_at TEXT NOT NULL,
+    PRIMARY KEY (owner, owner_key)
+);
 CREATE TABLE IF NOT EXISTS events (
     seq      INTEGER PRIMARY KEY AUTOINCREMENT,
     kind     TEXT NOT NULL,       -- 'card' | 'attestation'
@@ -85,13 +113,86 @@ CREATE TABLE IF NOT EXISTS rotations (
     raw_json  TEXT NOT NULL
 );
 CREATE INDEX IF NOT EXISTS idx_rot_old ON rotations(old_agent);
+CREATE TABLE IF NOT EXISTS revocations (
+    hash         TEXT PRIMARY KEY,
+    owner        TEXT NOT NULL,
+    agent        TEXT NOT NULL,
+    effective_at TEXT NOT NULL,
+    issued_at    TEXT,
+    raw_json     TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_rev_agent ON revocations(agent);
+CREATE TABLE IF NOT EXISTS owner_rotations (
+    hash        TEXT PRIMARY KEY,
+    old_owner   TEXT NOT NULL,
+    new_owner   TEXT NOT NULL,
+    issued_at   TEXT,
+    raw_json    TEXT NOT NULL,
+    received_at TEXT           -- when this instance first stored it
+);
+CREATE INDEX IF NOT EXISTS idx_orot_old ON owner_rotations(old_owner);
+CREATE TABLE IF NOT EXISTS guardian_sets (
+    hash      TEXT PRIMARY KEY,
+    owner     TEXT NOT NULL,
+    issued_at TEXT,
+    raw_json  TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_gset_owner ON guardian_sets(owner);
+CREATE TABLE IF NOT EXISTS recoveries (
+    hash        TEXT PRIMARY KEY,
+    owner       TEXT NOT NULL,
+    new_owner   TEXT NOT NULL,
+    issued_at   TEXT,
+    received_at TEXT NOT NULL,
+    raw_json    TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_recov_owner ON recoveries(owner);
+CREATE TABLE IF NOT EXISTS recovery_vetoes (
+    hash        TEXT PRIMARY KEY,
+    owner       TEXT NOT NULL,
+    recovery    TEXT NOT NULL,
+    issued_at   TEXT,
+    raw_json    TEXT NOT NULL,
+    received_at TEXT           -- when this instance first stored it
+);
+CREATE TABLE IF NOT EXISTS delegations (
+    hash        TEXT PRIMARY KEY,
+    owner       TEXT NOT NULL,
+    principal   TEXT NOT NULL,
+    delegate    TEXT NOT NULL,
+    expires_at  TEXT NOT NULL,
+    issued_at   TEXT,
+    raw_json    TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_deleg_principal ON delegations(principal);
+CREATE INDEX IF NOT EXISTS idx_deleg_delegate ON delegations(delegate);
+CREATE TABLE IF NOT EXISTS equivocations (
+    hash      TEXT PRIMARY KEY,
+    issuer    TEXT NOT NULL,
+    prev      TEXT NOT NULL,
+    a_hash    TEXT NOT NULL,
+    b_hash    TEXT NOT NULL,
+    issued_at TEXT,            -- the later of the two attestations' issued_at
+    raw_json  TEXT NOT NULL,   -- the self-contained proof
+    received_at TEXT           -- when this instance first stored it
+);
+CREATE INDEX IF NOT EXISTS idx_equiv_issuer ON equivocations(issuer);
 CREATE TABLE IF NOT EXISTS forks (
     did            TEXT NOT NULL,
     head_hash      TEXT NOT NULL,
     competing_hash TEXT NOT NULL,
     detected_at    TEXT,
+    resolved_by    TEXT,      -- hash of the fork resolution that settled it
     PRIMARY KEY (did, competing_hash)
 );
+CREATE TABLE IF NOT EXISTS fork_resolutions (
+    hash      TEXT PRIMARY KEY,
+    did       TEXT NOT NULL,
+    head      TEXT NOT NULL,
+    issued_at TEXT,
+    raw_json  TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_forkres_did ON fork_resolutions(did);
 CREATE TABLE IF NOT EXISTS challenges (
     nonce      TEXT PRIMARY KEY,
     issued_at  TEXT NOT NULL,
@@ -165,10 +266,26 @@ CREATE TABLE IF NOT EXISTS task_applications (
 // carries only 4 random chars and is therefore NOT unique — two of an owner's
 // keys could collide and revocation would silently revoke the wrong one. Keys
 // now carry a unique id; existing rows are backfilled from their (unique) hash.
+//
+// forks.resolved_by: forks could not be resolved before fork resolutions; every
+// existing fork stays open.
+//
+// attestations/equivocations/owner_rotations/recovery_vetoes.received_at: what was stored before receive times
+// were kept is taken to have arrived when it says it was issued — the best
+// estimate there is.
 var migrations = []string{
 	`ALTER TABLE api_keys ADD COLUMN id TEXT NOT NULL DEFAULT ''`,
 	`UPDATE api_keys SET id = substr(key_hash, 1, 12) WHERE id IS NULL OR id = ''`,
 	`CREATE UNIQUE INDEX IF NOT EXISTS idx_key_id ON api_keys(id)`,
+	`ALTER TABLE forks ADD COLUMN resolved_by TEXT`,
+	`ALTER TABLE attestations ADD COLUMN received_at TEXT`,
+	`UPDATE attestations SET received_at = issued_at WHERE received_at IS NULL`,
+	`ALTER TABLE equivocations ADD COLUMN received_at TEXT`,
+	`UPDATE equivocations SET received_at = issued_at WHERE received_at IS NULL`,
+	`ALTER TABLE owner_rotations ADD COLUMN received_at TEXT`,
+	`UPDATE owner_rotations SET received_at = issued_at WHERE received_at IS NULL`,
+	`ALTER TABLE recovery_vetoes ADD COLUMN received_at TEXT`,
+	`UPDATE recovery_vetoes SET received_at = issued_at WHERE received_at IS NULL`,
 }
 
 // Open opens (creating if needed) a SQLite-backed store at path. Use ":memory:"
@@ -193,9 +310,44 @@ func Open(path string) (*Store, error) {
 			return nil, fmt.Errorf("migrate: %w", err)
 		}
 	}
+	if err := migrateScores(db); err != nil {
+		return nil, fmt.Errorf("migrate: %w", err)
+	}
 	return &Store{db: db}, nil
 }
 
+// migrateScores rebuilds a scores table from before per-algorithm rows, keyed
+// by did alone, into the (did, algorithm) shape. SQLite cannot change a primary
+// key in place. The old rows were all moltscore/v1, so they are kept as such.
+func migrateScores(db *sql.DB) error {
+	if _, err := db.Exec(`SELECT algorithm FROM scores LIMIT 0`); err == nil {
+		return nil
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for _, stmt := range []string{
+		`ALTER TABLE scores RENAME TO scores_old`,
+		`CREATE TABLE scores (
+		    did        TEXT NOT NULL,
+		    algorithm  TEXT NOT NULL DEFAULT 'moltscore/v1',
+		    score      REAL NOT NULL,
+		    output_json TEXT NOT NULL,
+		    updated_at TEXT,
+		    PRIMARY KEY (did, algorithm))`,
+		`INSERT INTO scores (did, algorithm, score, output_json, updated_at)
+		    SELECT did, 'moltscore/v1', score, output_json, updated_at FROM scores_old`,
+		`DROP TABLE scores_old`,
+	} {
+		if _, err := tx.Exec(stmt); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
 func (s *Store) Close() error { return s.db.Close() }
 
 // DB exposes the underlying *sql.DB so server helpers can run one-off read
@@ -241,9 +393,20 @@ func (s *Store) PutCard(c *core.Card) (bool, error) {
 	// Fork rule: once an agent exists, a valid card whose `prev` is not the
 	// current head is a competing branch — not a linear update. Store it in
 	// history and flag the fork, but do NOT move the head. (New agents and cards
-	// that chain onto the head advance normally.)
+	// that chain onto the head advance normally.) A version a fork resolution
+	// already set aside against the head is a replay, and changes nothing.
 	isFork := existing != "" && c.Prev != existing
 	if isFork {
+		var settled int
+		if err := tx.QueryRow(
+			`SELECT COUNT(*) FROM forks WHERE did = ? AND resolved_by IS NOT NULL
+             AND ((head_hash = ? AND competing_hash = ?) OR (head_hash = ? AND competing_hash = ?))`,
+			c.ID, existing, hash, hash, existing).Scan(&settled); err != nil {
+			return false, err
+		}
+		if settled > 0 {
+			return false, tx.Commit()
+		}
 		if _, err = tx.Exec(
 			`INSERT INTO card_history (did, card_hash, card_json, ts) VALUES (?, ?, ?, ?)`,
 			c.ID, hash, string(raw), c.CreatedAt); err != nil {
@@ -261,17 +424,7 @@ func (s *Store) PutCard(c *core.Card) (bool, error) {
 		return true, tx.Commit()
 	}
 
-	_, err = tx.Exec(`
-        INSERT INTO agents (did, name, description, capabilities, card_hash, card_json, version, created_at, updated_at)
-        VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
-        ON CONFLICT(did) DO UPDATE SET
-            name=excluded.name, description=excluded.description,
-            capabilities=excluded.capabilities, card_hash=excluded.card_hash,
-            card_json=excluded.card_json, version=excluded.version,
-            updated_at=excluded.updated_at`,
-		c.ID, c.Name, c.Description, capabilityBlob(c), hash, string(raw), c.Version,
-		c.CreatedAt, c.CreatedAt)
-	if err != nil {
+	if err = setHead(tx, c, hash, string(raw)); err != nil {
 		return false, err
 	}
 	if _, err = tx.Exec(
@@ -285,21 +438,39 @@ func (s *Store) PutCard(c *core.Card) (bool, error) {
 	return true, tx.Commit()
 }
 
+// setHead makes c, with the given hash and JSON, the agent's current card.
+func setHead(tx *sql.Tx, c *core.Card, hash, raw string) error {
+	_, err := tx.Exec(`
+        INSERT INTO agents (did, name, description, capabilities, card_hash, card_json, version, created_at, updated_at)
+        VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+        ON CONFLICT(did) DO UPDATE SET
+            name=excluded.name, description=excluded.description,
+            capabilities=excluded.capabilities, card_hash=excluded.card_hash,
+            card_json=excluded.card_json, version=excluded.version,
+            updated_at=excluded.updated_at`,
+		c.ID, c.Name, c.Description, capabilityBlob(c), hash, raw, c.Version,
+		c.CreatedAt, c.CreatedAt)
+	return err
+}
+
 // Fork records a detected card-version fork: two competing valid cards for the
-// same DID that branch from the same history point.
+// same DID that branch from the same history point. ResolvedBy is the hash of
+// the fork resolution that settled it, if one has.
 type Fork struct {
 	DID           string `json:"did"`
 	HeadHash      string `json:"head_hash"`
 	CompetingHash string `json:"competing_hash"`
 	DetectedAt    string `json:"detected_at"`
+	ResolvedBy    string `json:"resolved_by,omitempty"`
 }
 
-// GetFork returns the most recent fork for a DID, or (nil, nil) if none.
+// GetFork returns the most recent unresolved fork for a DID, or (nil, nil) if
+// none.
 func (s *Store) GetFork(did string) (*Fork, error) {
 	var f Fork
 	err := s.db.QueryRow(
 		`SELECT did, head_hash, competing_hash, COALESCE(detected_at,'')
-         FROM forks WHERE did = ? ORDER BY detected_at DESC LIMIT 1`, did).
+         FROM forks WHERE did = ? AND resolved_by IS NULL ORDER BY detected_at DESC LIMIT 1`, did).
 		Scan(&f.DID, &f.HeadHash, &f.CompetingHash, &f.DetectedAt)
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -358,10 +529,11 @@ func (s *Store) CardHistory(did string) ([]*core.Card, error) {
 }
 
 // IssuerHead returns the hash of an issuer's most recent attestation (its chain
-// head), or "" if the issuer has none.
+// head), or "" if the issuer has none. Co-signed attestations count on each
+// of their issuers' chains.
 func (s *Store) IssuerHead(issuer string) (string, error) {
 	rows, err := s.db.Query(
-		`SELECT raw_json FROM attestations WHERE issuer = ? ORDER BY issued_at ASC`, issuer)
+		`SELECT raw_json FROM issuer_links WHERE issuer = ? ORDER BY issued_at ASC`, issuer)
 	if err != nil {
 		return "", err
 	}
@@ -387,6 +559,11 @@ func (s *Store) IssuerHead(issuer string) (string, error) {
 
 // PutAttestation stores a verified attestation. Idempotent on content hash: a
 // duplicate (e.g. re-synced from a peer) is ignored and reports inserted=false.
+//
+// An attestation whose issuer already has another stored attestation with the
+// same prev is stored too — federated records arrive without the head check —
+// but the pair is kept as an equivocation proof (see PutEquivocation). A
+// co-signed attestation is checked on each of its issuers' chains.
 func (s *Store) PutAttestation(a *core.Attestation) (bool, error) {
 	hash, err := a.Hash()
 	if err != nil {
@@ -403,10 +580,10 @@ func (s *Store) PutAttestation(a *core.Attestation) (bool, error) {
 	defer tx.Rollback()
 
 	res, err := tx.Exec(
-		`INSERT INTO attestations (hash, issuer, subject, type, prev, issued_at, raw_json)
-         VALUES (?, ?, ?, ?, ?, ?, ?)
+		`INSERT INTO attestations (hash, issuer, subject, type, prev, issued_at, raw_json, received_at)
+         VALUES (?, ?, ?, ?, ?, ?, ?, ?)
          ON CONFLICT(hash) DO NOTHING`,
-		hash, a.Issuer, a.Subject, a.Type, a.Prev, a.IssuedAt, string(raw))
+		hash, a.Issuer, a.Subject, a.Type, a.Prev, a.IssuedAt, string(raw), nowRFC3339())
 	if err != nil {
 		return false, err
 	}
@@ -414,9 +591,40 @@ func (s *Store) PutAttestation(a *core.Attestation) (bool, error) {
 	if n == 0 {
 		return false, tx.Commit() // already have it
 	}
+	for _, c := range a.CoIssuers {
+		if _, err := tx.Exec(
+			`INSERT INTO attestation_co_issuers (hash, issuer, prev, issued_at) VALUES (?, ?, ?, ?)`,
+			hash, c.Issuer, c.Prev, a.IssuedAt); err != nil {
+			return false, err
+		}
+	}
 	if err = appendEvent(tx, "attestation", hash, string(raw), a.IssuedAt); err != nil {
 		return false, err
 	}
+	for _, issuer := range a.Issuers() {
+		prev, _ := a.PrevOf(issuer)
+		var sibling string
+		err = tx.QueryRow(`SELECT raw_json FROM issuer_links WHERE issuer = ? AND prev = ? AND hash != ? LIMIT 1`,
+			issuer, prev, hash).Scan(&sibling)
+		if err == sql.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			return false, err
+		}
+		var other core.Attestation
+		if err := json.Unmarshal([]byte(sibling), &other); err != nil {
+			return false, err
+		}
+		for _, e := range core.FindEquivocations([]*core.Attestation{&other, a}) {
+			if e.Issuer != issuer {
+				continue
+			}
+			if _, err := putEquivocation(tx, e); err != nil {
+				return false, err
+			}
+		}
+	}
 	return true, tx.Commit()
 }
 
@@ -475,6 +683,148 @@ func (s *Store) AllRotations() ([]*core.Rotation, error) {
 	return out, rows.Err()
 }
 
+// PutRevocation stores a verified key revocation record. Idempotent on content
+// hash; emits a federation event when newly stored.
+func (s *Store) PutRevocation(r *core.Revocation) (bool, error) {
+	hash, err := r.Hash()
+	if err != nil {
+		return false, err
+	}
+	raw, err := json.Marshal(r)
+	if err != nil {
+		return false, err
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(
+		`INSERT INTO revocations (hash, owner, agent, effective_at, issued_at, raw_json)
+         VALUES (?, ?, ?, ?, ?, ?) ON CONFLICT(hash) DO NOTHING`,
+		hash, r.Owner, r.Agent, r.EffectiveAt, r.IssuedAt, string(raw))
+	if err != nil {
+		return false, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return false, tx.Commit()
+	}
+	if err = appendEvent(tx, "revocation", hash, string(raw), r.IssuedAt); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+// AllRevocations returns every revocation record, oldest first.
+func (s *Store) AllRevocations() ([]*core.Revocation, error) {
+	return s.revocations(`SELECT raw_json FROM revocations ORDER BY issued_at ASC`)
+}
+
+// RevocationsFor returns the revocations of one agent key, oldest first.
+func (s *Store) RevocationsFor(agentDID string) ([]*core.Revocation, error) {
+	return s.revocations(`SELECT raw_json FROM revocations WHERE agent = ? ORDER BY issued_at ASC`, agentDID)
+}
+
+func (s *Store) revocations(query string, args ...any) ([]*core.Revocation, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []*core.Revocation
+	for rows.Next() {
+		var raw string
+		if err := rows.Scan(&raw); err != nil {
+			return nil, err
+		}
+		var r core.Revocation
+		if err := json.Unmarshal([]byte(raw), &r); err != nil {
+			return nil, err
+		}
+		out = append(out, &r)
+	}
+	return out, rows.Err()
+}
+
+// PutOwnerRotation stores a verified owner-rotation record. Idempotent on
+// content hash; emits a federation event when newly stored.
+func (s *Store) PutOwnerRotation(r *core.OwnerRotation) (bool, error) {
+	hash, err := r.Hash()
+	if err != nil {
+		return false, err
+	}
+	raw, err := json.Marshal(r)
+	if err != nil {
+		return false, err
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(
+		`INSERT INTO owner_rotations (hash, old_owner, new_owner, issued_at, raw_json, received_at)
+         VALUES (?, ?, ?, ?, ?, ?) ON CONFLICT(hash) DO NOTHING`,
+		hash, r.OldOwner, r.NewOwner, r.IssuedAt, string(raw), nowRFC3339())
+	if err != nil {
+		return false, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return false, tx.Commit()
+	}
+	if err = appendEvent(tx, "owner_rotation", hash, string(raw), r.IssuedAt); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+// AllOwnerRotations returns every owner-rotation record, oldest first.
+func (s *Store) AllOwnerRotations() ([]*core.OwnerRotation, error) {
+	rows, err := s.db.Query(`SELECT raw_json FROM owner_rotations ORDER BY issued_at ASC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []*core.OwnerRotation
+	for rows.Next() {
+		var raw string
+		if err := rows.Scan(&raw); err != nil {
+			return nil, err
+		}
+		var r core.OwnerRotation
+		if err := json.Unmarshal([]byte(raw), &r); err != nil {
+			return nil, err
+		}
+		out = append(out, &r)
+	}
+	return out, rows.Err()
+}
+
+// OwnerSuccession resolves the stored owner rotations, and the guardian
+// recoveries complete by now. A stored rotation retires its key from when it
+// was received here.
+func (s *Store) OwnerSuccession() (core.OwnerSuccession, error) {
+	rots, err := s.AllOwnerRotations()
+	if err != nil {
+		return core.OwnerSuccession{}, err
+	}
+	states, err := s.RecoveryStates(time.Now().UTC())
+	if err != nil {
+		return core.OwnerSuccession{}, err
+	}
+	succ := core.NewOwnerSuccession(append(rots, core.RecoveredOwners(states)...))
+	rows, err := s.db.Query(`SELECT hash, received_at FROM owner_rotations`)
+	if err != nil {
+		return core.OwnerSuccession{}, err
+	}
+	if succ.Received, err = scanReceived(rows); err != nil {
+		return core.OwnerSuccession{}, err
+	}
+	return succ, nil
+}
+
 // Event is a single entry in the federation change feed.
 type Event struct {
 	Seq    int64           `json:"seq"`
@@ -559,6 +909,85 @@ func (s *Store) AttestationsForSubjectPaged(did string, limit, offset int) ([]*c
 	return atts, total, err
 }
 
+// AllAttestations returns every attestation in the registry, oldest first.
+// moltscore/v2 issuer weights are a property of the whole graph, so they are
+// computed over this full set rather than one subject's slice.
+func (s *Store) AllAttestations() ([]*core.Attestation, error) {
+	return s.queryAttestations(`SELECT raw_json FROM attestations ORDER BY issued_at ASC`)
+}
+
+// Received returns when this instance first stored each attestation and
+// equivocation proof, keyed by content hash — the clock an as-of score places
+// records by (score.Input.Received), since a signed issued_at can be backdated.
+func (s *Store) Received() (map[string]time.Time, error) {
+	rows, err := s.db.Query(`SELECT hash, received_at FROM attestations
+        UNION ALL SELECT hash, received_at FROM equivocations`)
+	if err != nil {
+		return nil, err
+	}
+	return scanReceived(rows)
+}
+
+// scanReceived collects (hash, received_at) rows, skipping unparseable times.
+func scanReceived(rows *sql.Rows) (map[string]time.Time, error) {
+	defer rows.Close()
+	out := map[string]time.Time{}
+	for rows.Next() {
+		var hash string
+		var at sql.NullString
+		if err := rows.Scan(&hash, &at); err != nil {
+			return nil, err
+		}
+		if t, err := time.Parse(time.RFC3339, at.String); err == nil {
+			out[hash] = t
+		}
+	}
+	return out, rows.Err()
+}
+
+// AttestationsSince returns up to limit attestations stored after cursor, in
+// insertion order, plus the cursor to resume from. The cursor is the sequence
+// number of the federation event each attestation emitted when it was stored:
+// that sequence is AUTOINCREMENT (never reused, unlike a bare rowid) and written
+// in the same transaction, so a page never shifts as new records arrive and a
+// client can resume from the last cursor it saw.
+func (s *Store) AttestationsSince(cursor int64, limit int) ([]*core.Attestation, int64, error) {
+	if limit <= 0 || limit > 500 {
+		limit = 200
+	}
+	rows, err := s.db.Query(
+		`SELECT e.seq, a.raw_json FROM events e JOIN attestations a ON a.hash = e.hash
+         WHERE e.kind = 'attestation' AND e.seq > ? ORDER BY e.seq ASC LIMIT ?`,
+		cursor, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+	var out []*core.Attestation
+	for rows.Next() {
+		var raw string
+		if err := rows.Scan(&cursor, &raw); err != nil {
+			return nil, 0, err
+		}
+		var a core.Attestation
+		if err := json.Unmarshal([]byte(raw), &a); err != nil {
+			return nil, 0, err
+		}
+		out = append(out, &a)
+	}
+	return out, cursor, rows.Err()
+}
+
+// LatestAttestationSeq returns the cursor of the newest stored attestation (0 if
+// none); a client paging AttestationsSince has caught up when it reaches it.
+func (s *Store) LatestAttestationSeq() (int64, error) {
+	var seq sql.NullInt64
+	if err := s.db.QueryRow(`SELECT MAX(seq) FROM events WHERE kind = 'attestation'`).Scan(&seq); err != nil {
+		return 0, err
+	}
+	return seq.Int64, nil
+}
+
 func (s *Store) queryAttestations(q string, args ...any) ([]*core.Attestation, error) {
 	rows, err := s.db.Query(q, args...)
 	if err != nil {
@@ -580,24 +1009,25 @@ func (s *Store) queryAttestations(q string, args ...any) ([]*core.Attestation, e
 	return out, rows.Err()
 }
 
-// SetScore caches a computed score for a DID.
+// SetScore caches a computed score for a DID, one row per algorithm
+// (out.Algorithm).
 func (s *Store) SetScore(did string, out score.Output) error {
 	raw, err := json.Marshal(out)
 	if err != nil {
 		return err
 	}
 	_, err = s.db.Exec(
-		`INSERT INTO scores (did, score, output_json, updated_at) VALUES (?, ?, ?, ?)
-         ON CONFLICT(did) DO UPDATE SET score=excluded.score, output_json=excluded.output_json, updated_at=excluded.updated_at`,
-		did, out.Score, string(raw), out.ComputedAt)
+		`INSERT INTO scores (did, algorithm, score, output_json, updated_at) VALUES (?, ?, ?, ?, ?)
+         ON CONFLICT(did, algorithm) DO UPDATE SET score=excluded.score, output_json=excluded.output_json, updated_at=excluded.updated_at`,
+		did, out.Algorithm, out.Score, string(raw), out.ComputedAt)
 	return err
 }
 
-// CachedScore returns the cached score value for a DID (0 if none), used as an
-// issuer weight input.
-func (s *Store) CachedScore(did string) (float64, bool, error) {
+// CachedScore returns a DID's cached score under algorithm (0 if none), used
+// as an issuer weight input.
+func (s *Store) CachedScore(did, algorithm string) (float64, bool, error) {
 	var v float64
-	err := s.db.QueryRow(`SELECT score FROM scores WHERE did = ?`, did).Scan(&v)
+	err := s.db.QueryRow(`SELECT score FROM scores WHERE did = ? AND algorithm = ?`, did, algorithm).Scan(&v)
 	if err == sql.ErrNoRows {
 		return 0, false, nil
 	}
@@ -704,12 +1134,40 @@ type Agent struct {
 	Description  string   `json:"description"`
 	Capabilities []string `json:"capabilities"`
 	Score        float64  `json:"score"`
+	// CapabilityScore is the agent's score for the searched capability tag, set
+	// only when searching by capability.
+	CapabilityScore *float64 `json:"capability_score,omitempty"`
+}
+
+// ValidCapability reports whether tag can be searched by: non-empty and made
+// only of ASCII letters, digits, '.', '_' and '-', as capability tags are
+// ("code.review", "data-etl").
+func ValidCapability(tag string) bool {
+	if tag == "" {
+		return false
+	}
+	for _, r := range tag {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '_', r == '-':
+		default:
+			return false
+		}
+	}
+	return true
 }
 
 // Search returns a page of agents matching a free-text query, an optional
-// capability tag, and a minimum score, ordered by score descending. It also
-// returns the total number of matches (ignoring limit/offset) for pagination.
-func (s *Store) Search(q, capTag string, minScore float64, limit, offset int) ([]Agent, int, error) {
+// capability tag, and a minimum score, ordered by score descending — or, when a
+// capability tag is given, by the per-capability score for that tag, with the
+// overall score breaking ties. Scores are the cached ones under alg. An agent
+// with no attestations tagged with the capability ranks at alg's no-history
+// baseline for it. It also returns the total number of matches (ignoring
+// limit/offset) for pagination. A capability tag that is not ValidCapability is
+// an error.
+func (s *Store) Search(q, capTag string, alg score.Algorithm, minScore float64, limit, offset int) ([]Agent, int, error) {
+	if capTag != "" && !ValidCapability(capTag) {
+		return nil, 0, fmt.Errorf("search: invalid capability tag %q", capTag)
+	}
 	if limit <= 0 || limit > 200 {
 		limit = 50
 	}
@@ -720,20 +1178,27 @@ func (s *Store) Search(q, capTag string, minScore float64, limit, offset int) ([
         WHERE (? = '' OR a.name LIKE '%'||?||'%' OR a.description LIKE '%'||?||'%' OR a.capabilities LIKE '%'||?||'%')
           AND (? = '' OR a.capabilities LIKE '%'||?||'%')
           AND COALESCE(s.score,0) >= ?`
-	filterArgs := []any{q, q, q, q, capTag, capTag, minScore}
+	filterArgs := []any{alg.Name(), q, q, q, q, capTag, capTag, minScore}
 
 	var total int
 	if err := s.db.QueryRow(
-		`SELECT COUNT(*) FROM agents a LEFT JOIN scores s ON s.did=a.did`+where, filterArgs...).
+		`SELECT COUNT(*) FROM agents a LEFT JOIN scores s ON s.did=a.did AND s.algorithm=?`+where, filterArgs...).
 		Scan(&total); err != nil {
 		return nil, 0, err
 	}
 
+	// The capability score is read from the cached score object; the JSON path
+	// quotes the tag because tags contain dots, and is bound, not spliced in.
+	capScore := `COALESCE(json_extract(s.output_json, ?), ?)`
+	capPath := `$.capabilities."` + capTag + `".score`
+	noHistory := alg.Score(score.Input{Now: time.Now()}).Score
+	args := append([]any{capPath, noHistory}, filterArgs...)
+	args = append(args, capTag, capPath, noHistory, limit, offset)
 	rows, err := s.db.Query(
-		`SELECT a.did, a.name, COALESCE(a.description,''), COALESCE(a.capabilities,''), COALESCE(s.score,0)
-         FROM agents a LEFT JOIN scores s ON s.did = a.did`+where+
-			` ORDER BY COALESCE(s.score,0) DESC LIMIT ? OFFSET ?`,
-		append(append([]any{}, filterArgs...), limit, offset)...)
+		`SELECT a.did, a.name, COALESCE(a.description,''), COALESCE(a.capabilities,''), COALESCE(s.score,0), `+capScore+`
+         FROM agents a LEFT JOIN scores s ON s.did = a.did AND s.algorithm = ?`+where+
+			` ORDER BY CASE WHEN ? = '' THEN 0 ELSE `+capScore+` END DESC, COALESCE(s.score,0) DESC LIMIT ? OFFSET ?`,
+		args...)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -742,9 +1207,13 @@ func (s *Store) Search(q, capTag string, minScore float64, limit, offset int) ([
 	for rows.Next() {
 		var a Agent
 		var caps string
-		if err := rows.Scan(&a.DID, &a.Name, &a.Description, &caps, &a.Score); err != nil {
+		var cs float64
+		if err := rows.Scan(&a.DID, &a.Name, &a.Description, &caps, &a.Score, &cs); err != nil {
 			return nil, 0, err
 		}
+		if capTag != "" {
+			a.CapabilityScore = &cs
+		}
 		if caps != "" {
 			a.Capabilities = splitFields(caps)
 		}
@@ -769,12 +1238,13 @@ type GraphEdge struct {
 	Count  int    `json:"count"`
 }
 
-// Graph returns the collaboration graph: registered agents as nodes and
-// attestations aggregated into weighted directed edges. If centerDID is
-// non-empty, only edges touching that DID (and their endpoints) are returned.
-func (s *Store) Graph(centerDID string) ([]GraphNode, []GraphEdge, error) {
+// Graph returns the collaboration graph: registered agents as nodes, scored
+// under algorithm, and attestations aggregated into weighted directed edges.
+// If centerDID is non-empty, only edges touching that DID (and their
+// endpoints) are returned.
+func (s *Store) Graph(centerDID, algorithm string) ([]GraphNode, []GraphEdge, error) {
 	nodeRows, err := s.db.Query(
-		`SELECT a.did, a.name, COALESCE(s.score,0) FROM agents a LEFT JOIN scores s ON s.did=a.did`)
+		`SELECT a.did, a.name, COALESCE(s.score,0) FROM agents a LEFT JOIN scores s ON s.did=a.did AND s.algorithm=?`, algorithm)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -788,7 +1258,7 @@ func (s *Store) Graph(centerDID string) ([]GraphNode, []GraphEdge, error) {
 		nodes = append(nodes, n)
 	}
 
-	q := `SELECT issuer, subject, type, COUNT(*) FROM attestations`
+	q := `SELECT issuer, subject, type, COUNT(*) FROM issuer_links`
 	var args []any
 	if centerDID != "" {
 		q += ` WHERE issuer = ? OR subject = ?`
@@ -811,6 +1281,60 @@ func (s *Store) Graph(centerDID string) ([]GraphNode, []GraphEdge, error) {
 	return nodes, edges, edgeRows.Err()
 }
 
+// Owners maps every registered agent DID to its current owner, for the score
+// independence rule: the owner on its current card, followed through any owner
+// rotations, so agents an owner signed before and after rotating its key are
+// recognised as one party's.
+func (s *Store) Owners() (map[string]string, error) {
+	succ, err := s.OwnerSuccession()
+	if err != nil {
+		return nil, err
+	}
+	rows, err := s.db.Query(`SELECT did, COALESCE(json_extract(card_json, '$.owner'),'') FROM agents`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := map[string]string{}
+	for rows.Next() {
+		var did, owner string
+		if err := rows.Scan(&did, &owner); err != nil {
+			return nil, err
+		}
+		out[did] = succ.Resolve(owner)
+	}
+	return out, rows.Err()
+}
+
+// SubjectsAttestedBy returns the distinct subjects issuer has attested, alone
+// or co-signing, sorted: the agents whose scores depend on issuer's through
+// its issuer weight.
+func (s *Store) SubjectsAttestedBy(issuer string) ([]string, error) {
+	return s.dids(`SELECT DISTINCT subject FROM issuer_links WHERE issuer = ? AND subject != issuer ORDER BY subject`, issuer)
+}
+
+// AgentDIDs returns every registered agent's DID, sorted.
+func (s *Store) AgentDIDs() ([]string, error) {
+	return s.dids(`SELECT did FROM agents ORDER BY did`)
+}
+
+func (s *Store) dids(query string, args ...any) ([]string, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []string
+	for rows.Next() {
+		var did string
+		if err := rows.Scan(&did); err != nil {
+			return nil, err
+		}
+		out = append(out, did)
+	}
+	return out, rows.Err()
+}
+
 // AgentCount returns the number of registered agents.
 func (s *Store) AgentCount() (int, error) {
 	var n int
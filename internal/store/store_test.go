@@ -1,9 +1,13 @@
 package store
 
 import (
+	"database/sql"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/moltnet/moltnet/core"
+	"github.com/moltnet/moltnet/score"
 )
 
 func signedCard(t *testing.T, owner, agent *core.KeyPair, name, prev string) *core.Card {
@@ -71,3 +75,211 @@ func TestCardForkDetection(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+// Scores are cached one row per algorithm, and a store from before that —
+// scores keyed by did alone — is rebuilt with its rows kept as moltscore/v1.
+// Two attestations from one issuer following the same prev — as federation
+// can deliver them — are both kept, and the pair becomes a federated proof.
+func TestEquivocationDetection(t *testing.T) {
+	st, err := Open(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+
+	issuer, _ := core.GenerateKeyPair()
+	subject, _ := core.GenerateKeyPair()
+	branch := func(typ string) *core.Attestation {
+		a := core.NewAttestation(typ, issuer.DID, subject.DID)
+		if err := a.Sign(issuer.Private); err != nil {
+			t.Fatal(err)
+		}
+		return a
+	}
+	a, b := branch(core.TypeTaskCompleted), branch(core.TypeTaskDisputed)
+	for _, x := range []*core.Attestation{a, b, a} {
+		if _, err := st.PutAttestation(x); err != nil {
+			t.Fatal(err)
+		}
+	}
+	eqs, err := st.EquivocationsFor(issuer.DID)
+	if err != nil || len(eqs) != 1 {
+		t.Fatalf("want one equivocation proof, got %d (%v)", len(eqs), err)
+	}
+	if err := eqs[0].Verify(); err != nil {
+		t.Fatalf("stored proof should verify on its own: %v", err)
+	}
+	if again, err := st.PutEquivocation(eqs[0]); err != nil || again {
+		t.Fatalf("re-storing the proof should be a no-op: %v %v", again, err)
+	}
+	var events int
+	if err := st.db.QueryRow(`SELECT COUNT(*) FROM events WHERE kind = 'equivocation'`).Scan(&events); err != nil || events != 1 {
+		t.Fatalf("the proof should be federated once, got %d events (%v)", events, err)
+	}
+}
+
+// A co-signed attestation sits on every issuer's chain: it moves each head,
+// and a second successor on a co-issuer's link is an equivocation.
+func TestCoSignedChains(t *testing.T) {
+	st, err := Open(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+
+	lead, _ := core.GenerateKeyPair()
+	co, _ := core.GenerateKeyPair()
+	subject, _ := core.GenerateKeyPair()
+	joint := core.NewAttestation(core.TypeTaskCompleted, lead.DID, subject.DID)
+	joint.CoIssuers = []core.CoIssuer{{Issuer: co.DID}}
+	_ = joint.Sign(lead.Private)
+	if err := joint.AddCoSig(co); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := st.PutAttestation(joint); err != nil {
+		t.Fatal(err)
+	}
+	h, _ := joint.Hash()
+	for _, issuer := range []string{lead.DID, co.DID} {
+		if head, err := st.IssuerHead(issuer); err != nil || head != h {
+			t.Fatalf("%s head: %q (%v)", issuer, head, err)
+		}
+		if subs, err := st.SubjectsAttestedBy(issuer); err != nil || len(subs) != 1 {
+			t.Fatalf("%s subjects: %v (%v)", issuer, subs, err)
+		}
+	}
+
+	fork := core.NewAttestation(core.TypeIncident, co.DID, subject.DID)
+	_ = fork.Sign(co.Private)
+	if _, err := st.PutAttestation(fork); err != nil {
+		t.Fatal(err)
+	}
+	if succ, err := st.Successor(co.DID, ""); err != nil || succ == nil {
+		t.Fatalf("successor on the co-issuer's chain: %v", err)
+	}
+	eqs, err := st.EquivocationsFor(co.DID)
+	if err != nil || len(eqs) != 1 || eqs[0].Verify() != nil {
+		t.Fatalf("want one proof against the co-issuer, got %d (%v)", len(eqs), err)
+	}
+	if eqs, _ := st.EquivocationsFor(lead.DID); len(eqs) != 0 {
+		t.Fatal("the lead's chain did not fork")
+	}
+}
+
+func TestScoresPerAlgorithm(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "old.db")
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`CREATE TABLE scores (did TEXT PRIMARY KEY, score REAL NOT NULL, output_json TEXT NOT NULL, updated_at TEXT);
+		INSERT INTO scores VALUES ('did:key:zOld', 42, '{}', '2026-01-01T00:00:00Z')`); err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+
+	st, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+	if v, ok, err := st.CachedScore("did:key:zOld", score.AlgorithmV1); err != nil || !ok || v != 42 {
+		t.Fatalf("migrated row: %v %v %v", v, ok, err)
+	}
+
+	now := time.Now().UTC()
+	v1 := score.Compute(nil, nil, nil, now)
+	v2 := score.ComputeV2(nil, nil, nil, score.DefaultBasis(nil), now)
+	v2.Score = 7
+	for _, out := range []score.Output{v1, v2} {
+		if err := st.SetScore("did:key:zNew", out); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if v, _, _ := st.CachedScore("did:key:zNew", score.AlgorithmV1); v != v1.Score {
+		t.Fatalf("v1 row: got %v want %v", v, v1.Score)
+	}
+	if v, _, _ := st.CachedScore("did:key:zNew", score.AlgorithmV2); v != 7 {
+		t.Fatalf("v2 row overwrote or was overwritten: got %v", v)
+	}
+}
+
+func TestForkResolution(t *testing.T) {
+	st, err := Open(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+	owner, _ := core.GenerateKeyPair()
+	agent, _ := core.GenerateKeyPair()
+
+	genesis := signedCard(t, owner, agent, "v1", "")
+	_, _ = st.PutCard(genesis)
+	gh, _ := genesis.Hash()
+	update := signedCard(t, owner, agent, "v2", gh)
+	_, _ = st.PutCard(update)
+	fork := signedCard(t, owner, agent, "vFork", gh)
+	_, _ = st.PutCard(fork)
+	uh, _ := update.Hash()
+	fh, _ := fork.Hash()
+
+	unrelated := core.NewForkResolution(genesis, fh, gh)
+	_ = unrelated.Sign(agent.Private, owner.Private)
+	if settled, err := st.PutForkResolution(unrelated); err != nil || settled {
+		t.Fatalf("a resolution between versions that did not fork should change nothing: %v %v", settled, err)
+	}
+	r := core.NewForkResolution(genesis, fh, uh)
+	_ = r.Sign(agent.Private, owner.Private)
+	if settled, err := st.PutForkResolution(r); err != nil || !settled {
+		t.Fatalf("resolution: settled=%v err=%v", settled, err)
+	}
+	if f, _ := st.GetFork(agent.DID); f != nil {
+		t.Fatalf("the fork should be resolved, got %+v", f)
+	}
+	if cur, _ := st.GetCard(agent.DID); cur == nil || cur.Name != "vFork" {
+		t.Fatalf("head should move to the chosen version, got %v", cur)
+	}
+	if settled, _ := st.PutForkResolution(r); settled {
+		t.Fatal("re-applying a resolution should be a no-op")
+	}
+	// A replay of the rejected version does not reopen the fork.
+	if changed, err := st.PutCard(update); err != nil || changed {
+		t.Fatalf("replayed rejected version: changed=%v err=%v", changed, err)
+	}
+	if f, _ := st.GetFork(agent.DID); f != nil {
+		t.Fatalf("a replay should not reopen the fork, got %+v", f)
+	}
+	if rs, _ := st.ForkResolutionsFor(agent.DID); len(rs) != 1 {
+		t.Fatalf("want 1 stored resolution, got %d", len(rs))
+	}
+}
+
+// A record stored in a form RFC 8785 canonicalizes differently is flagged;
+// one the server wrote in canonical form is not.
+func TestLegacyRecords(t *testing.T) {
+	st, err := Open(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+	issuer, _ := core.GenerateKeyPair()
+	a := core.NewAttestation(core.TypePaymentReceipt, issuer.DID, "did:key:zSubject")
+	a.Body = map[string]any{"amount": 2.5}
+	if err := a.Sign(issuer.Private); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := st.PutAttestation(a); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := st.db.Exec(`INSERT INTO attestations (hash, issuer, subject, type, raw_json) VALUES (?, ?, ?, ?, ?)`,
+		"blake3:old", issuer.DID, "did:key:zSubject", core.TypePaymentReceipt, `{"type":"payment.receipt","body":{"amount":2.50}}`); err != nil {
+		t.Fatal(err)
+	}
+	legacy, err := st.LegacyRecords()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(legacy) != 1 || legacy[0].Table != "attestations" || legacy[0].Key != "blake3:old" || len(legacy[0].Diffs) != 1 {
+		t.Fatalf("want only the old-form attestation flagged, got %+v", legacy)
+	}
+}
@@ -0,0 +1,237 @@
+package score
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/moltnet/moltnet/core"
+)
+
+// Input is everything a scoring algorithm may read about one subject.
+type Input struct {
+	Subject string
+	// Attestations are those about Subject, in chain order.
+	Attestations []*core.Attestation
+	// Graph is every attestation the caller holds. It is only gathered for
+	// algorithms whose Global reports true; otherwise it is nil.
+	Graph []*core.Attestation
+	// IssuerWeights are per-issuer weights in [0,1] for algorithms that take
+	// them from the caller (v1: the issuers' own cached scores / 100). nil
+	// weighs every issuer 1.0 — the trustless default.
+	IssuerWeights map[string]float64
+	// OwnerOf maps agent DIDs to their owners for the independence rule; nil
+	// disables it.
+	OwnerOf map[string]string
+	// Revoked are the cutoffs of compromised issuer keys: what such a key
+	// signed from its cutoff on is disregarded, in Attestations and Graph
+	// alike, as if it had never been issued.
+	Revoked core.Cutoffs
+	// Delegations are the verified issuing-key delegations: a delegated
+	// attestation counts for its principal only if one of them covers it,
+	// and is disregarded otherwise. nil disregards every delegated one.
+	Delegations core.Delegations
+	// Equivocations are proofs that issuers forked their own chains. Under
+	// moltscore/v2 those against Subject that verify are a penalty on its
+	// score, as heavy as an incident; the rest are ignored, and v1 applies
+	// none.
+	Equivocations []*core.Equivocation
+	// Received is when the caller first held each attestation and
+	// equivocation proof, keyed by content hash. AsOf places records by it
+	// rather than by their self-asserted issued_at, so a backdated record
+	// published later cannot rewrite a score already served for an earlier
+	// instant; a record missing from it was not yet held. nil (a verifier
+	// with no receive log) places records by issued_at.
+	Received map[string]time.Time
+	Now      time.Time
+}
+
+// trusted is the input without the attestations Revoked distrusts or
+// Delegations does not cover.
+func (in Input) trusted() Input {
+	in.Attestations = in.Delegations.Filter(in.Revoked.Filter(in.Attestations))
+	if in.Graph != nil {
+		in.Graph = in.Delegations.Filter(in.Revoked.Filter(in.Graph))
+	}
+	// A fork that a revoked key signed proves nothing about its owner: a thief
+	// could forge one to hurt its victim.
+	var eqs []*core.Equivocation
+	for _, e := range in.Equivocations {
+		if !in.Revoked.Revoked(e.A) && !in.Revoked.Revoked(e.B) {
+			eqs = append(eqs, e)
+		}
+	}
+	in.Equivocations = eqs
+	return in
+}
+
+// AsOf is the input as it stood at t: only the attestations held by then (see
+// Received), with t as the clock. issued_at still drives decay.
+func (in Input) AsOf(t time.Time) Input {
+	if in.Received == nil {
+		in.Attestations = AsOf(in.Attestations, t)
+		if in.Graph != nil {
+			in.Graph = AsOf(in.Graph, t)
+		}
+	} else {
+		in.Attestations = receivedBy(in.Attestations, in.Received, t)
+		if in.Graph != nil {
+			in.Graph = receivedBy(in.Graph, in.Received, t)
+		}
+	}
+	var eqs []*core.Equivocation
+	for _, e := range in.Equivocations {
+		ok := len(AsOf([]*core.Attestation{e.A, e.B}, t)) == 2
+		if in.Received != nil {
+			ok = held(e, in.Received, t)
+		}
+		if ok {
+			eqs = append(eqs, e)
+		}
+	}
+	in.Equivocations = eqs
+	in.Now = t
+	return in
+}
+
+// receivedBy returns the attestations of atts received at or before t, in
+// input order.
+func receivedBy(atts []*core.Attestation, received map[string]time.Time, t time.Time) []*core.Attestation {
+	var out []*core.Attestation
+	for _, a := range atts {
+		if held(a, received, t) {
+			out = append(out, a)
+		}
+	}
+	return out
+}
+
+// held reports whether the record hashed by h was received at or before t.
+func held(h interface{ Hash() (string, error) }, received map[string]time.Time, t time.Time) bool {
+	hash, err := h.Hash()
+	if err != nil {
+		return false
+	}
+	at, ok := received[hash]
+	return ok && !at.After(t)
+}
+
+// Algorithm is a named scoring model. Name is the version tag carried in the
+// algorithm field of every Output it produces, so scores from different models
+// can never be mistaken for one another.
+type Algorithm interface {
+	Name() string
+	// Global reports whether the model weighs issuers from the whole
+	// attestation graph (Input.Graph) rather than from Input.IssuerWeights.
+	Global() bool
+	Score(in Input) Output
+}
+
+// Explainer is implemented by algorithms that can break a score down per
+// attestation (Output.Explain).
+type Explainer interface {
+	Explain(in Input) Output
+}
+
+// V1 is moltscore/v1.
+type V1 struct{}
+
+func (V1) Name() string { return AlgorithmV1 }
+func (V1) Global() bool { return false }
+func (V1) Score(in Input) Output {
+	in = in.trusted()
+	return computeV1(in.Attestations, in.IssuerWeights, in.OwnerOf, in.Now)
+}
+func (V1) Explain(in Input) Output {
+	in = in.trusted()
+	return explainV1(in.Attestations, in.IssuerWeights, in.OwnerOf, in.Now)
+}
+
+// V2 is moltscore/v2 under a basis. Label, when set, names a custom model —
+// v2 under a basis of the operator's choosing — and replaces moltscore/v2 as
+// its Name and in its outputs; the basis hash still pins the parameters.
+type V2 struct {
+	Label string
+	Basis Basis
+}
+
+func (v V2) Name() string {
+	if v.Label != "" {
+		return v.Label
+	}
+	return AlgorithmV2
+}
+
+func (V2) Global() bool { return true }
+
+func (v V2) Score(in Input) Output {
+	in = in.trusted()
+	w := weights(in.Graph, in.OwnerOf, v.Basis, in.Now)
+	eq := equivocationPenalty(in.Equivocations, in.Subject, func(at string) float64 {
+		return v.Basis.decayV2(at, utcDay(in.Now), v.Basis.HalfLifeDays.Incident)
+	})
+	out := computeV2(in.Attestations, w, in.OwnerOf, v.Basis, eq, in.Now)
+	out.Algorithm = v.Name()
+	return out
+}
+
+// Registry is a set of algorithms by name. It is safe for concurrent use.
+type Registry struct {
+	mu   sync.RWMutex
+	algs map[string]Algorithm
+}
+
+// NewRegistry returns a registry holding algs.
+func NewRegistry(algs ...Algorithm) (*Registry, error) {
+	r := &Registry{algs: map[string]Algorithm{}}
+	for _, a := range algs {
+		if err := r.Register(a); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// Builtin returns a registry of the reference algorithms: moltscore/v1, and
+// moltscore/v2 under the default basis rooted at anchors.
+func Builtin(anchors []string) *Registry {
+	r, _ := NewRegistry(V1{}, V2{Basis: DefaultBasis(anchors)})
+	return r
+}
+
+// Register adds a. Names are unique: a model cannot shadow another, since
+// its scores would then be indistinguishable.
+func (r *Registry) Register(a Algorithm) error {
+	name := a.Name()
+	if name == "" {
+		return fmt.Errorf("score: algorithm has no name")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, dup := r.algs[name]; dup {
+		return fmt.Errorf("score: algorithm %q already registered", name)
+	}
+	r.algs[name] = a
+	return nil
+}
+
+// Lookup returns the algorithm registered under name.
+func (r *Registry) Lookup(name string) (Algorithm, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	a, ok := r.algs[name]
+	return a, ok
+}
+
+// Names lists the registered algorithms, sorted.
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.algs))
+	for n := range r.algs {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,55 @@
+package score
+
+import "github.com/moltnet/moltnet/core"
+
+// CapabilityScore is a score restricted to one capability tag, under the
+// algorithm of the score it belongs to.
+type CapabilityScore struct {
+	Score  float64 `json:"score"`
+	Inputs Inputs  `json:"inputs"`
+}
+
+// capabilityOf returns the capability tag an attestation is about
+// (body.capability, as `molt attest --capability` writes it), or "".
+func capabilityOf(a *core.Attestation) string {
+	c, _ := a.Body["capability"].(string)
+	return c
+}
+
+// capabilityScores computes the per-capability score vector: for every tag that
+// appears in some attestation's body.capability, the model (subjectScore) over
+// the attestations tagged with it. Untagged disputes and incidents count
+// against every capability — misconduct is not scoped to one skill — while
+// untagged positive signal raises only the overall score. Returns nil when
+// nothing is tagged, so an untagged chain's output is unchanged.
+//
+// A model that corroborates negatives (v2) scores each subset against the
+// corroborated set of the WHOLE chain: a report counts toward a capability
+// once corroborated anywhere, not only within its tag.
+func capabilityScores(atts []*core.Attestation, subjectScore func([]*core.Attestation) Output) map[string]CapabilityScore {
+	var out map[string]CapabilityScore
+	for _, a := range atts {
+		c := capabilityOf(a)
+		if c == "" {
+			continue
+		}
+		if _, done := out[c]; done {
+			continue
+		}
+		// Filter in input order, so summation order — and so the float
+		// result — matches a client filtering the chain itself.
+		var subset []*core.Attestation
+		for _, b := range atts {
+			switch bc := capabilityOf(b); {
+			case bc == c, bc == "" && (b.Type == core.TypeTaskDisputed || b.Type == core.TypeIncident):
+				subset = append(subset, b)
+			}
+		}
+		o := subjectScore(subset)
+		if out == nil {
+			out = map[string]CapabilityScore{}
+		}
+		out[c] = CapabilityScore{Score: o.Score, Inputs: o.Inputs}
+	}
+	return out
+}
@@ -3,6 +3,7 @@ package score
 import (
 	"encoding/json"
 	"os"
+	"reflect"
 	"testing"
 	"time"
 
@@ -17,11 +18,14 @@ func TestScoreConformance(t *testing.T) {
 		t.Fatal(err)
 	}
 	var vectors []struct {
-		Now          string           `json:"now"`
-		Attestations []map[string]any `json:"attestations"`
-		Expected     struct {
-			Score  float64 `json:"score"`
-			Inputs Inputs  `json:"inputs"`
+		Now           string             `json:"now"`
+		IssuerWeights map[string]float64 `json:"issuer_weights"`
+		OwnerOf       map[string]string  `json:"owner_of"`
+		Attestations  []map[string]any   `json:"attestations"`
+		Expected      struct {
+			Score        float64                    `json:"score"`
+			Inputs       Inputs                     `json:"inputs"`
+			Capabilities map[string]CapabilityScore `json:"capabilities"`
 		} `json:"expected"`
 	}
 	if err := json.Unmarshal(data, &vectors); err != nil {
@@ -37,19 +41,100 @@ func TestScoreConformance(t *testing.T) {
 		}
 		var atts []*core.Attestation
 		for _, m := range v.Attestations {
+			body, _ := m["body"].(map[string]any)
+			notBefore, _ := m["not_before"].(string)
+			expiresAt, _ := m["expires_at"].(string)
+			var coIssuers []core.CoIssuer
+			cos, _ := m["co_issuers"].([]any)
+			for _, c := range cos {
+				coIssuers = append(coIssuers, core.CoIssuer{Issuer: c.(map[string]any)["issuer"].(string)})
+			}
 			atts = append(atts, &core.Attestation{
-				Type:     m["type"].(string),
-				Issuer:   m["issuer"].(string),
-				Subject:  m["subject"].(string),
-				IssuedAt: m["issued_at"].(string),
+				Type:      m["type"].(string),
+				Issuer:    m["issuer"].(string),
+				Subject:   m["subject"].(string),
+				IssuedAt:  m["issued_at"].(string),
+				NotBefore: notBefore,
+				ExpiresAt: expiresAt,
+				Body:      body,
+				CoIssuers: coIssuers,
 			})
 		}
-		out := Compute(atts, nil, nil, now)
+		out := Compute(atts, v.IssuerWeights, v.OwnerOf, now)
 		if out.Score != v.Expected.Score {
 			t.Errorf("vector %d: score got %v want %v", i, out.Score, v.Expected.Score)
 		}
 		if out.Inputs != v.Expected.Inputs {
 			t.Errorf("vector %d: inputs got %+v want %+v", i, out.Inputs, v.Expected.Inputs)
 		}
+		if !reflect.DeepEqual(out.Capabilities, v.Expected.Capabilities) {
+			t.Errorf("vector %d: capabilities got %+v want %+v", i, out.Capabilities, v.Expected.Capabilities)
+		}
+	}
+}
+
+// TestScoreV2Conformance validates moltscore/v2 — the anchored issuer weights
+// as well as the subject score — against spec/conformance/score_v2_vectors.json.
+// Exact equality is intended: §5 makes every step bit-reproducible.
+func TestScoreV2Conformance(t *testing.T) {
+	data, err := os.ReadFile("../spec/conformance/score_v2_vectors.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var vectors []struct {
+		Now           string               `json:"now"`
+		Basis         Basis                `json:"basis"`
+		Subject       string               `json:"subject"`
+		Attestations  []*core.Attestation  `json:"attestations"`
+		Equivocations []*core.Equivocation `json:"equivocations"`
+		Expected      struct {
+			Weights        map[string]float64 `json:"weights"`
+			Score          float64            `json:"score"`
+			Inputs         Inputs             `json:"inputs"`
+			Basis          string             `json:"basis"`
+			ComputedForDay string             `json:"computed_for_day"`
+			Pending        *Pending           `json:"pending"`
+		} `json:"expected"`
+	}
+	if err := json.Unmarshal(data, &vectors); err != nil {
+		t.Fatal(err)
+	}
+	if len(vectors) == 0 {
+		t.Fatal("no score v2 vectors loaded")
+	}
+	for i, v := range vectors {
+		now, err := time.Parse(time.RFC3339, v.Now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w := Weights(v.Attestations, nil, v.Basis, now)
+		if len(w) != len(v.Expected.Weights) {
+			t.Errorf("vector %d: %d weights, want %d", i, len(w), len(v.Expected.Weights))
+		}
+		for did, want := range v.Expected.Weights {
+			if w[did] != want {
+				t.Errorf("vector %d: weight(%s) got %v want %v", i, did, w[did], want)
+			}
+		}
+		var subj []*core.Attestation
+		for _, a := range v.Attestations {
+			if a.Subject == v.Subject {
+				subj = append(subj, a)
+			}
+		}
+		out := V2{Basis: v.Basis}.Score(Input{Subject: v.Subject, Attestations: subj, Graph: v.Attestations,
+			Equivocations: v.Equivocations, Now: now})
+		if out.Score != v.Expected.Score {
+			t.Errorf("vector %d: score got %v want %v", i, out.Score, v.Expected.Score)
+		}
+		if out.Inputs != v.Expected.Inputs {
+			t.Errorf("vector %d: inputs got %+v want %+v", i, out.Inputs, v.Expected.Inputs)
+		}
+		if !reflect.DeepEqual(out.Pending, v.Expected.Pending) {
+			t.Errorf("vector %d: pending got %+v want %+v", i, out.Pending, v.Expected.Pending)
+		}
+		if out.Basis != v.Expected.Basis || out.ComputedForDay != v.Expected.ComputedForDay {
+			t.Errorf("vector %d: basis/day got %s %s want %s %s", i, out.Basis, out.ComputedForDay, v.Expected.Basis, v.Expected.ComputedForDay)
+		}
 	}
 }
@@ -0,0 +1,66 @@
+package score
+
+import (
+	"math"
+	"time"
+
+	"github.com/moltnet/moltnet/core"
+)
+
+// corroborated returns the negative attestations (task.disputed, incident) in
+// atts that count: those for which at least minIssuers independent reporters
+// filed the same type within windowDays of it, itself included. Reporters are
+// the principals the v2 independence rule keeps (see independentV2), one per
+// owner under ownerOf; an issuer with no known owner (or a nil ownerOf)
+// stands for itself, and each issuer of a co-signed negative reports it. The
+// subject never corroborates a report against itself, even by co-signing it.
+// An attestation the rule drops neither counts nor corroborates. A negative
+// whose issued_at does not parse cannot be placed in a window and stays
+// pending. minIssuers <= 1 disables the rule: every negative counts.
+func corroborated(atts []*core.Attestation, ownerOf map[string]string, minIssuers int, windowDays float64) map[*core.Attestation]bool {
+	type report struct {
+		a         *core.Attestation
+		at        time.Time
+		reporters []string
+	}
+	var negs []report
+	for _, a := range atts {
+		if a.Type != core.TypeTaskDisputed && a.Type != core.TypeIncident {
+			continue
+		}
+		issuers := independentV2(a, ownerOf)
+		if len(issuers) == 0 {
+			continue
+		}
+		at, err := time.Parse(time.RFC3339, a.IssuedAt)
+		if err != nil && minIssuers > 1 {
+			continue
+		}
+		// A co-signed report speaks for each of its independent issuers.
+		var reporters []string
+		for _, p := range issuers {
+			if o := ownerOf[p]; o != "" {
+				p = o
+			}
+			reporters = append(reporters, p)
+		}
+		negs = append(negs, report{a, at, reporters})
+	}
+
+	out := make(map[*core.Attestation]bool, len(negs))
+	window := time.Duration(windowDays * 24 * float64(time.Hour))
+	for _, n := range negs {
+		reporters := map[string]struct{}{}
+		for _, m := range negs {
+			if m.a.Type == n.a.Type && time.Duration(math.Abs(float64(m.at.Sub(n.at)))) <= window {
+				for _, r := range m.reporters {
+					reporters[r] = struct{}{}
+				}
+			}
+		}
+		if len(reporters) >= minIssuers {
+			out[n.a] = true
+		}
+	}
+	return out
+}
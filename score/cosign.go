@@ -0,0 +1,61 @@
+package score
+
+import "github.com/moltnet/moltnet/core"
+
+// A co-signed attestation is one event, however many issuers signed it: it
+// counts once, at the combined weight of the issuers the independence rule
+// keeps, and for diversity under the first of them. Co-issuers controlled by
+// the subject's owner are set aside; if none are left, the record is
+// self-dealing like any other.
+
+// independent returns the principals of a the v1 independence rule keeps, in
+// order: those whose owner is not subjectOwner ("" when unknown). For a record
+// with one issuer it is that issuer, or nothing if it is self-dealing.
+func independent(a *core.Attestation, ownerOf map[string]string, subjectOwner string) []string {
+	var out []string
+	for _, p := range a.Principals() {
+		if subjectOwner != "" && ownerOf[p] == subjectOwner {
+			continue
+		}
+		out = append(out, p)
+	}
+	return out
+}
+
+// independentV2 is independent under the v2 rule: it keeps the principals
+// that neither are the subject nor share an owner with it under ownerOf (nil
+// disables the owner check). A record that keeps none is dropped from v2
+// entirely.
+func independentV2(a *core.Attestation, ownerOf map[string]string) []string {
+	if a.Issuer == a.Subject {
+		return nil
+	}
+	var o string
+	if ownerOf != nil {
+		o = ownerOf[a.Subject]
+	}
+	var out []string
+	for _, p := range a.Principals() {
+		if p == a.Subject || o != "" && ownerOf[p] == o {
+			continue
+		}
+		out = append(out, p)
+	}
+	return out
+}
+
+// combinedWeight is the weight of one statement made jointly by issuers of
+// weights ws: the chance at least one is right were each independently right
+// with its weight, 1 − Π(1 − w). It is at least the largest weight and never
+// above 1, so a panel outweighs its best member without outweighing a fully
+// trusted issuer. One issuer keeps its weight exactly.
+func combinedWeight(ws []float64) float64 {
+	if len(ws) == 1 {
+		return ws[0]
+	}
+	miss := 1.0
+	for _, w := range ws {
+		miss *= 1 - min(max(w, 0), 1)
+	}
+	return 1 - miss
+}
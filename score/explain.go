@@ -0,0 +1,107 @@
+package score
+
+import (
+	"time"
+
+	"github.com/moltnet/moltnet/core"
+)
+
+// Explanation is the per-attestation breakdown of a moltscore/v1 score, so an
+// operator can see which record moved it, how much decay removed and what the
+// issuer weight did.
+type Explanation struct {
+	X            float64        `json:"x"` // sigmoid input; score = 100·sigmoid(x)
+	Attestations []Contribution `json:"attestations"`
+}
+
+// Contribution is one attestation's part in a score, in input order.
+type Contribution struct {
+	Hash   string `json:"hash"`
+	Type   string `json:"type"`
+	Issuer string `json:"issuer"`
+	// CoIssuers are the further issuers of a co-signed attestation, in order;
+	// IssuerWeight is then their weight combined with Issuer's.
+	CoIssuers []string `json:"co_issuers,omitempty"`
+	// Delegate is the sub-key that signed a delegated attestation; Issuer is
+	// then the principal it counts for.
+	Delegate     string  `json:"delegate,omitempty"`
+	IssuerWeight float64 `json:"issuer_weight"`
+	// Decay is the recency factor applied, in (0,1]; 0 for types that are never
+	// scored (self-claims, key rotations) and for dropped ones.
+	Decay float64 `json:"decay"`
+	// SelfDealing is set when the ownerOf independence rule dropped the
+	// attestation: its issuer shares an owner with the subject.
+	SelfDealing bool `json:"self_dealing"`
+	// Window is set, to core.WindowExpired or core.WindowNotYetValid, on an
+	// attestation outside its validity window; it then does not count.
+	Window string `json:"window,omitempty"`
+	// Marginal is x minus what x would be without this attestation: positive
+	// when it raised the score, negative when it lowered it. Marginals do not
+	// sum to x — the model is logarithmic and diversity counts issuers, not
+	// records — but each is exact for its own attestation.
+	Marginal float64 `json:"marginal"`
+}
+
+// term records how Compute treated one attestation.
+type term struct {
+	a             *core.Attestation
+	weight, decay float64
+	selfDealing   bool
+}
+
+// explainTerms builds the breakdown. xWithout(i) is the sigmoid input over the
+// chain without attestation i, recomputed in full.
+func explainTerms(terms []term, x float64, xWithout func(i int) float64) *Explanation {
+	ex := &Explanation{X: x, Attestations: make([]Contribution, 0, len(terms))}
+	for i, t := range terms {
+		h, _ := t.a.Hash()
+		c := Contribution{
+			Hash: h, Type: t.a.Type, Issuer: t.a.Principal(),
+			IssuerWeight: t.weight, Decay: t.decay,
+			SelfDealing: t.selfDealing,
+		}
+		if t.a.OnBehalfOf != "" {
+			c.Delegate = t.a.Issuer
+		}
+		c.CoIssuers = coIssuers(t.a)
+		if !t.selfDealing {
+			c.Marginal = x - xWithout(i)
+		}
+		ex.Attestations = append(ex.Attestations, c)
+	}
+	return ex
+}
+
+// withDropped merges the attestations outside their validity window at now,
+// which the score never saw, back into the breakdown of the live ones, in
+// input order.
+func withDropped(all, live []*core.Attestation, cs []Contribution, now time.Time) []Contribution {
+	if len(all) == len(live) {
+		return cs
+	}
+	out := make([]Contribution, 0, len(all))
+	j := 0
+	for _, a := range all {
+		if j < len(live) && live[j] == a {
+			out = append(out, cs[j])
+			j++
+			continue
+		}
+		h, _ := a.Hash()
+		c := Contribution{Hash: h, Type: a.Type, Issuer: a.Principal(), Window: a.Window(now)}
+		if a.OnBehalfOf != "" {
+			c.Delegate = a.Issuer
+		}
+		c.CoIssuers = coIssuers(a)
+		out = append(out, c)
+	}
+	return out
+}
+
+// coIssuers lists a's co-issuers, or nil if it has none.
+func coIssuers(a *core.Attestation) []string {
+	if len(a.CoIssuers) == 0 {
+		return nil
+	}
+	return a.Issuers()[1:]
+}
@@ -1,6 +1,8 @@
-// Package score implements MoltScore v1: a deterministic, open reputation
-// function computed locally from an agent's attestation set. Given the same
-// inputs, every client computes the same score. The registry serves a
+// Package score implements MoltScore: a deterministic, open reputation function
+// computed from signed attestations. moltscore/v1 (this file) works from one
+// agent's attestation set; moltscore/v2 (v2.go) weights issuers as an anchored
+// fixed point over the whole graph under an explicit, hashed basis. Given the
+// same inputs, every client computes the same score. The registry serves a
 // precomputed value for convenience, but nothing needs to trust it.
 package score
 
@@ -12,8 +14,8 @@ import (
 	"github.com/moltnet/moltnet/core"
 )
 
-// Algorithm is the version tag emitted with every score object.
-const Algorithm = "moltscore/v1"
+// AlgorithmV1 is the version tag emitted with every moltscore/v1 score object.
+const AlgorithmV1 = "moltscore/v1"
 
 // Model weights and constants. Design principles (issuer weighting, diversity
 // beats volume, recency decay, self-claims count zero) matter more than the
@@ -41,16 +43,38 @@ type Inputs struct {
 	Endorsements    int `json:"endorsements"`
 	Receipts        int `json:"receipts"`
 	DistinctIssuers int `json:"distinct_issuers"`
+	// Equivocations counts the proofs that the subject forked its own issuer
+	// chain (Input.Equivocations) that a moltscore/v2 score applied.
+	Equivocations int `json:"equivocations,omitempty"`
+}
+
+// Pending counts negative attestations that are signed and stored but not yet
+// corroborated, so they do not count toward a moltscore/v2 score.
+type Pending struct {
+	Disputes  int `json:"disputes"`
+	Incidents int `json:"incidents"`
 }
 
 // Output is the full score object, including the breakdown and the attestation
-// head it was computed over, so a client can reproduce it.
+// head it was computed over, so a client can reproduce it. Basis and
+// ComputedForDay are set only by moltscore/v2, whose result is pinned to an
+// explicit basis and a whole UTC day rather than an instant.
 type Output struct {
 	Algorithm       string  `json:"algorithm"`
 	Score           float64 `json:"score"`
 	Inputs          Inputs  `json:"inputs"`
 	ComputedAt      string  `json:"computed_at"`
 	AttestationHead string  `json:"attestation_head"`
+	Basis           string  `json:"basis,omitempty"`
+	ComputedForDay  string  `json:"computed_for_day,omitempty"`
+	// Pending is set when some negatives await corroboration (v2 only; v1
+	// counts every independent negative).
+	Pending *Pending `json:"pending,omitempty"`
+	// Capabilities is the per-capability score vector, keyed by the tag in
+	// body.capability; omitted when no attestation is tagged.
+	Capabilities map[string]CapabilityScore `json:"capabilities,omitempty"`
+	// Explain is the per-attestation breakdown, set only by Explain.
+	Explain *Explanation `json:"explain,omitempty"`
 }
 
 // Compute runs MoltScore v1 over an agent's attestations.
@@ -61,13 +85,70 @@ type Output struct {
 // nil weights everyone at 1.0, which is the correct default for a standalone,
 // trustless recomputation.
 //
+// Compute holds no delegations, so it disregards every delegated attestation,
+// as V1 does with no Input.Delegations; V1.Score, given the delegations that
+// cover them, counts each as its principal's (core.Attestation.Principal) in
+// the weighting, the independence rule and the diversity count.
+//
 // ownerOf optionally maps a DID (the subject and any issuer) to its controlling
 // owner DID. When supplied, any attestation whose issuer shares an owner with
 // the subject is DROPPED as self-dealing — wash trading or self-endorsement.
 // This independence rule lives in the function, not a server gate, so anyone who
 // can resolve the owners recomputes the same number; passing nil disables it
 // (the trustless uniform basis, as `molt verify` uses).
+//
+// Attestations tagged with a body.capability also yield a per-capability score
+// (Output.Capabilities); see capabilityScores.
+//
+// An attestation outside its validity window at now (core.InEffect) is scored
+// as if it had never been issued. Retractions are not applied: v1 scores a
+// retracted attestation like any other (ComputeV2 sets it aside), and the
+// retraction itself carries no weight.
 func Compute(atts []*core.Attestation, issuerWeights map[string]float64, ownerOf map[string]string, now time.Time) Output {
+	return computeV1(core.Delegations(nil).Filter(atts), issuerWeights, ownerOf, now)
+}
+
+func computeV1(atts []*core.Attestation, issuerWeights map[string]float64, ownerOf map[string]string, now time.Time) Output {
+	atts = core.InEffect(atts, now)
+	out, _ := compute(atts, issuerWeights, ownerOf, now, nil)
+	out.Capabilities = capabilityScores(atts, func(subset []*core.Attestation) Output {
+		o, _ := compute(subset, issuerWeights, ownerOf, now, nil)
+		return o
+	})
+	return out
+}
+
+// Explain is Compute with Output.Explain set: for every attestation, the issuer
+// weight and decay it was scored with, whether the independence rule dropped
+// it, and its marginal effect on the sigmoid input.
+// The score is identical. Expired (or not yet valid) attestations are listed
+// as such, with no effect; delegated ones are disregarded, as in Compute.
+func Explain(atts []*core.Attestation, issuerWeights map[string]float64, ownerOf map[string]string, now time.Time) Output {
+	return explainV1(core.Delegations(nil).Filter(atts), issuerWeights, ownerOf, now)
+}
+
+func explainV1(atts []*core.Attestation, issuerWeights map[string]float64, ownerOf map[string]string, now time.Time) Output {
+	all := atts
+	atts = core.InEffect(atts, now)
+	var terms []term
+	out, x := compute(atts, issuerWeights, ownerOf, now, &terms)
+	out.Capabilities = capabilityScores(atts, func(subset []*core.Attestation) Output {
+		o, _ := compute(subset, issuerWeights, ownerOf, now, nil)
+		return o
+	})
+	out.Explain = explainTerms(terms, x, func(i int) float64 {
+		rest := append(append([]*core.Attestation{}, atts[:i]...), atts[i+1:]...)
+		_, x := compute(rest, issuerWeights, ownerOf, now, nil)
+		return x
+	})
+	out.Explain.Attestations = withDropped(all, atts, out.Explain.Attestations, now)
+	return out
+}
+
+// compute is the v1 model over atts. It returns the score object and its
+// sigmoid input; when terms is non-nil it also records how each attestation
+// was treated, in input order.
+func compute(atts []*core.Attestation, issuerWeights map[string]float64, ownerOf map[string]string, now time.Time, terms *[]term) (Output, float64) {
 	const defaultIssuerWeight = 1.0
 
 	var weightedCompletions, weightedDisputes, weightedIncidents float64
@@ -94,50 +175,74 @@ func Compute(atts []*core.Attestation, issuerWeights map[string]float64, ownerOf
 	for _, a := range atts {
 		// Self-dealing: the issuer is controlled by the subject's own owner. Drop
 		// it entirely — it contributes to no weighted sum and no diversity count.
-		if subjectOwner != "" && ownerOf[a.Issuer] == subjectOwner {
+		// A co-signed attestation is self-dealing only if all its issuers are.
+		issuers := independent(a, ownerOf, subjectOwner)
+		if len(issuers) == 0 {
+			if terms != nil {
+				*terms = append(*terms, term{a: a, selfDealing: true})
+			}
 			continue
 		}
-		iw := weightOf(a.Issuer)
+		ws := make([]float64, len(issuers))
+		for i, p := range issuers {
+			ws[i] = weightOf(p)
+		}
+		iw := combinedWeight(ws)
+		lead := issuers[0]
+		t := term{a: a, weight: iw}
 		switch a.Type {
 		case core.TypeTaskCompleted:
 			in.Completions++
-			weightedCompletions += iw * decay(a.IssuedAt, now, halfLifePositiveDays)
-			positiveIssuers[a.Issuer] = struct{}{}
+			t.decay = decay(a.IssuedAt, now, halfLifePositiveDays)
+			weightedCompletions += iw * t.decay
+			positiveIssuers[lead] = struct{}{}
 		case core.TypeEndorsement:
 			in.Endorsements++
-			weightedCompletions += endorsementWeight * iw * decay(a.IssuedAt, now, halfLifePositiveDays)
-			positiveIssuers[a.Issuer] = struct{}{}
+			t.decay = decay(a.IssuedAt, now, halfLifePositiveDays)
+			weightedCompletions += endorsementWeight * iw * t.decay
+			positiveIssuers[lead] = struct{}{}
 		case core.TypePaymentReceipt:
 			in.Receipts++
-			weightedCompletions += receiptWeight * iw * decay(a.IssuedAt, now, halfLifePositiveDays)
-			positiveIssuers[a.Issuer] = struct{}{}
+			t.decay = decay(a.IssuedAt, now, halfLifePositiveDays)
+			weightedCompletions += receiptWeight * iw * t.decay
+			positiveIssuers[lead] = struct{}{}
 		case core.TypeTaskDisputed:
 			in.Disputes++
-			weightedDisputes += iw * decay(a.IssuedAt, now, halfLifeDisputeDays)
+			t.decay = decay(a.IssuedAt, now, halfLifeDisputeDays)
+			weightedDisputes += iw * t.decay
 		case core.TypeIncident:
 			in.Incidents++
-			weightedIncidents += iw * decay(a.IssuedAt, now, halfLifeIncidentDays)
+			t.decay = decay(a.IssuedAt, now, halfLifeIncidentDays)
+			weightedIncidents += iw * t.decay
 		case core.TypeSelfClaim:
 			// Weight zero. Always. (Displayed elsewhere, never scored.)
 		case core.TypeKeyRotation:
 			// Continuity event, not a reputation signal.
 		}
+		if terms != nil {
+			*terms = append(*terms, t)
+		}
 	}
 	in.DistinctIssuers = len(positiveIssuers)
+	x := sigmoidInput(weightedCompletions, in.DistinctIssuers, weightedDisputes, weightedIncidents)
 
-	x := wCompletions*math.Log(1+weightedCompletions) +
-		wDiversity*math.Log(1+float64(in.DistinctIssuers)) -
-		wDisputes*weightedDisputes -
-		wIncidents*weightedIncidents -
-		baseline
-
-	return Output{
-		Algorithm:       Algorithm,
+	out := Output{
+		Algorithm:       AlgorithmV1,
 		Score:           round1(100 * sigmoid(x)),
 		Inputs:          in,
 		ComputedAt:      now.UTC().Format(time.RFC3339),
 		AttestationHead: head(atts),
 	}
+	return out, x
+}
+
+// sigmoidInput is the v1 model: score = 100·sigmoid(x).
+func sigmoidInput(completions float64, distinctIssuers int, disputes, incidents float64) float64 {
+	return wCompletions*math.Log(1+completions) +
+		wDiversity*math.Log(1+float64(distinctIssuers)) -
+		wDisputes*disputes -
+		wIncidents*incidents -
+		baseline
 }
 
 func sigmoid(x float64) float64 { return 1.0 / (1.0 + math.Exp(-x)) }
@@ -173,3 +278,17 @@ func head(atts []*core.Attestation) string {
 }
 
 func round1(v float64) float64 { return math.Round(v*10) / 10 }
+
+// AsOf returns the attestations issued at or before t, in input order — the
+// chain as it stood at that instant. Computing over it with now = t gives the
+// score as it was then. An attestation whose issued_at does not parse cannot be
+// placed in time and is excluded.
+func AsOf(atts []*core.Attestation, t time.Time) []*core.Attestation {
+	var out []*core.Attestation
+	for _, a := range atts {
+		if at, err := time.Parse(time.RFC3339, a.IssuedAt); err == nil && !at.After(t) {
+			out = append(out, a)
+		}
+	}
+	return out
+}
@@ -1,6 +1,7 @@
 package score
 
 import (
+	"math"
 	"testing"
 	"time"
 
@@ -114,3 +115,290 @@ func TestFreshIssuersWeightedLow(t *testing.T) {
 		t.Fatalf("unknown issuer should count for less: weighted=%.2f trustless=%.2f", weighted, trustless)
 	}
 }
+
+// Explain reports the same score as Compute, plus one contribution per input
+// attestation whose marginal is exactly the leave-one-out change in x.
+func TestExplainMarginals(t *testing.T) {
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	atts := []*core.Attestation{
+		att(core.TypeTaskCompleted, "did:key:zA", now.AddDate(0, 0, -30)),
+		att(core.TypeTaskCompleted, "did:key:zA", now),
+		att(core.TypeEndorsement, "did:key:zB", now),
+		att(core.TypeIncident, "did:key:zC", now.AddDate(0, 0, -100)),
+		att(core.TypeTaskCompleted, "did:key:zSibling", now),
+		att(core.TypeSelfClaim, "did:key:zSubject", now),
+	}
+	weights := map[string]float64{"did:key:zA": 0.8, "did:key:zB": 0.5, "did:key:zC": 1}
+	owners := map[string]string{"did:key:zSubject": "did:key:zOwner", "did:key:zSibling": "did:key:zOwner"}
+
+	out := Explain(atts, weights, owners, now)
+	if plain := Compute(atts, weights, owners, now); plain.Score != out.Score || plain.Explain != nil {
+		t.Fatalf("explain must not change the score: %v vs %v", plain.Score, out.Score)
+	}
+	ex := out.Explain
+	if ex == nil || len(ex.Attestations) != len(atts) {
+		t.Fatalf("want one contribution per attestation, got %+v", ex)
+	}
+	xOf := func(atts []*core.Attestation) float64 {
+		e := Explain(atts, weights, owners, now).Explain
+		if e == nil {
+			return sigmoidInput(0, 0, 0, 0)
+		}
+		return e.X
+	}
+	for i, c := range ex.Attestations {
+		without := append(append([]*core.Attestation{}, atts[:i]...), atts[i+1:]...)
+		if want := ex.X - xOf(without); math.Abs(c.Marginal-want) > 1e-9 {
+			t.Errorf("%d (%s): marginal %v, leave-one-out %v", i, c.Type, c.Marginal, want)
+		}
+	}
+	if c := ex.Attestations[4]; !c.SelfDealing || c.Marginal != 0 {
+		t.Errorf("same-owner attestation should be dropped with no effect: %+v", c)
+	}
+	if c := ex.Attestations[3]; c.Marginal >= 0 || c.IssuerWeight != 1 || c.Decay >= 1 {
+		t.Errorf("aged incident should lower x with decay < 1: %+v", c)
+	}
+	if c := ex.Attestations[0]; c.Decay >= ex.Attestations[1].Decay {
+		t.Errorf("older completion should decay more: %v vs %v", c.Decay, ex.Attestations[1].Decay)
+	}
+}
+
+// The capability vector scores each tag from its own attestations; untagged
+// incidents count against every capability.
+func TestCapabilityScores(t *testing.T) {
+	now := time.Now()
+	tagged := func(typ, issuer, capability string) *core.Attestation {
+		a := att(typ, issuer, now)
+		a.Body = map[string]any{"capability": capability}
+		return a
+	}
+	atts := []*core.Attestation{
+		tagged(core.TypeTaskCompleted, "did:key:zA", "code.review"),
+		tagged(core.TypeTaskCompleted, "did:key:zB", "code.review"),
+		tagged(core.TypeTaskCompleted, "did:key:zC", "code.review"),
+		tagged(core.TypeTaskCompleted, "did:key:zA", "code.security-audit"),
+		att(core.TypeTaskCompleted, "did:key:zD", now),
+	}
+	out := Compute(atts, nil, nil, now)
+	review, audit := out.Capabilities["code.review"], out.Capabilities["code.security-audit"]
+	if len(out.Capabilities) != 2 || review.Inputs.Completions != 3 || audit.Inputs.Completions != 1 {
+		t.Fatalf("unexpected capability vector: %+v", out.Capabilities)
+	}
+	if review.Score <= audit.Score || review.Score >= out.Score {
+		t.Fatalf("want audit < review < overall: %v %v %v", audit.Score, review.Score, out.Score)
+	}
+
+	withIncident := Compute(append(atts, att(core.TypeIncident, "did:key:zE", now)), nil, nil, now)
+	if got := withIncident.Capabilities["code.security-audit"]; got.Score >= audit.Score || got.Inputs.Incidents != 1 {
+		t.Fatalf("untagged incident should lower every capability: %+v", got)
+	}
+	if Compute(atts[4:], nil, nil, now).Capabilities != nil {
+		t.Fatalf("an untagged chain should have no capability vector")
+	}
+}
+
+// A historical score is the score of the chain as it stood, at that clock:
+// later attestations do not exist yet, and decay is measured from then.
+func TestAsOf(t *testing.T) {
+	hired := time.Date(2026, 8, 1, 12, 0, 0, 0, time.UTC)
+	atts := []*core.Attestation{
+		att(core.TypeTaskCompleted, "did:key:zA", hired.AddDate(0, -2, 0)),
+		att(core.TypeTaskCompleted, "did:key:zB", hired),
+		att(core.TypeIncident, "did:key:zC", hired.Add(time.Second)),
+	}
+	then := AsOf(atts, hired)
+	if len(then) != 2 {
+		t.Fatalf("want the 2 attestations issued by %s, got %d", hired, len(then))
+	}
+	past := Compute(then, nil, nil, hired)
+	if now := Compute(atts, nil, nil, hired.AddDate(0, 1, 0)); now.Score >= past.Score {
+		t.Fatalf("the later incident should not be visible at the hiring date: then=%v now=%v", past.Score, now.Score)
+	}
+	if past.ComputedAt != "2026-08-01T12:00:00Z" {
+		t.Fatalf("historical score should be computed at the requested clock, got %s", past.ComputedAt)
+	}
+}
+
+func TestRevokedIssuerDisregarded(t *testing.T) {
+	cut := time.Date(2026, 8, 1, 12, 0, 0, 0, time.UTC)
+	now := cut.AddDate(0, 0, 7)
+	atts := []*core.Attestation{
+		att(core.TypeTaskCompleted, "did:key:zStolen", cut.Add(-time.Hour)),
+		att(core.TypeTaskCompleted, "did:key:zB", cut),
+	}
+	// After the theft, the stolen key vouches for the subject over and over.
+	for i := 1; i <= 5; i++ {
+		atts = append(atts, att(core.TypeTaskCompleted, "did:key:zStolen", cut.Add(time.Duration(i)*time.Hour)))
+	}
+	revoked := core.Cutoffs{"did:key:zStolen": cut}
+	in := Input{Subject: "did:key:zSubject", Attestations: atts, Now: now}
+	all := V1{}.Score(in)
+	in.Revoked = revoked
+	got := V1{}.Score(in)
+	if got.Inputs.Completions != 2 || got.Score >= all.Score {
+		t.Fatalf("post-cutoff attestations of the revoked key should not count: with=%+v without=%+v", got.Inputs, all.Inputs)
+	}
+	if want := (V1{}).Score(Input{Attestations: atts[:2], Now: now}); got.Score != want.Score {
+		t.Fatalf("score should be as if they were never issued: got %v want %v", got.Score, want.Score)
+	}
+	in.Graph = atts
+	if v2 := (V2{Basis: DefaultBasis(nil)}).Score(in); v2.Inputs.Completions != 2 {
+		t.Fatalf("v2 should disregard them too: %+v", v2.Inputs)
+	}
+}
+
+// A busy issuer's workers sign under delegations from its owner: their
+// attestations count as the principal's — at its weight, as one issuer — and
+// only under a delegation that covers them.
+func TestDelegatedAttributedToPrincipal(t *testing.T) {
+	now := time.Date(2026, 8, 1, 12, 0, 0, 0, time.UTC)
+	owner, _ := core.GenerateKeyPair()
+	principal, _ := core.GenerateKeyPair()
+	var atts []*core.Attestation
+	var ds []*core.Delegation
+	for i := 0; i < 3; i++ {
+		worker, _ := core.GenerateKeyPair()
+		d := core.NewDelegation(owner.DID, principal.DID, worker.DID, now.AddDate(0, 1, 0))
+		d.IssuedAt = now.AddDate(0, 0, -1).Format(time.RFC3339)
+		_ = d.Sign(owner.Private)
+		h, _ := d.Hash()
+		ds = append(ds, d)
+		a := att(core.TypeTaskCompleted, worker.DID, now.Add(-time.Hour))
+		a.OnBehalfOf, a.Delegation = principal.DID, h
+		atts = append(atts, a)
+	}
+	weights := map[string]float64{principal.DID: 1.0}
+	in := Input{Subject: "did:key:zSubject", Attestations: atts, IssuerWeights: weights, Now: now}
+	if got := (V1{}).Score(in); got.Inputs.Completions != 0 {
+		t.Fatalf("without their delegations, delegated attestations should not count: %+v", got.Inputs)
+	}
+	if got := Compute(atts, weights, nil, now); got.Inputs.Completions != 0 {
+		t.Fatalf("Compute holds no delegations and should not count them: %+v", got.Inputs)
+	}
+	in.Delegations = core.NewDelegations(ds)
+	got := V1{}.Score(in)
+	if got.Inputs.Completions != 3 || got.Inputs.DistinctIssuers != 1 {
+		t.Fatalf("workers should count as their one principal: %+v", got.Inputs)
+	}
+	direct := []*core.Attestation{
+		att(core.TypeTaskCompleted, principal.DID, now.Add(-time.Hour)),
+		att(core.TypeTaskCompleted, principal.DID, now.Add(-time.Hour)),
+		att(core.TypeTaskCompleted, principal.DID, now.Add(-time.Hour)),
+	}
+	if want := Compute(direct, weights, nil, now); got.Score != want.Score {
+		t.Fatalf("delegated score %v should equal the principal issuing itself, %v", got.Score, want.Score)
+	}
+}
+
+func TestEquivocationPenalty(t *testing.T) {
+	now := time.Date(2026, 8, 1, 12, 0, 0, 0, time.UTC)
+	subject, _ := core.GenerateKeyPair()
+	fork := func(id string) *core.Attestation {
+		a := core.NewAttestation(core.TypeTaskCompleted, subject.DID, "did:key:zOther")
+		a.IssuedAt = now.Add(-time.Hour).Format(time.RFC3339)
+		a.Prev = "blake3:root"
+		a.Body = map[string]any{"task_id": id}
+		_ = a.Sign(subject.Private)
+		return a
+	}
+	e, err := core.NewEquivocation(fork("one"), fork("two"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var atts []*core.Attestation
+	for i := 0; i < 4; i++ {
+		a := att(core.TypeTaskCompleted, "did:key:z"+string(rune('A'+i)), now.Add(-time.Hour))
+		a.Subject = subject.DID
+		atts = append(atts, a)
+	}
+	in := Input{Subject: subject.DID, Attestations: atts, Equivocations: []*core.Equivocation{e, e}, Now: now}
+	v2 := V2{Basis: DefaultBasis(nil)}
+	before := v2.Score(Input{Subject: subject.DID, Attestations: atts, Now: now})
+	if got := v2.Score(in); got.Inputs.Equivocations != 1 || got.Score >= before.Score {
+		t.Fatalf("one proof should count once and lower the score: %.1f -> %.1f %+v", before.Score, got.Score, got.Inputs)
+	}
+	if got := (V1{}).Score(in); got.Inputs.Equivocations != 0 || got.Score != (V1{}).Score(Input{Subject: subject.DID, Attestations: atts, Now: now}).Score {
+		t.Fatalf("v1 should apply no equivocation penalty: %+v", got.Inputs)
+	}
+	if got := v2.Score(Input{Subject: "did:key:zSomeoneElse", Attestations: atts, Equivocations: in.Equivocations, Now: now}); got.Inputs.Equivocations != 0 {
+		t.Fatalf("a proof against another issuer should be ignored: %+v", got.Inputs)
+	}
+	if got := v2.Score(in.AsOf(now.Add(-2 * time.Hour))); got.Inputs.Equivocations != 0 {
+		t.Fatalf("a fork after the as-of time should not count: %+v", got.Inputs)
+	}
+}
+
+// An expired or not-yet-valid attestation counts for nothing, under either
+// algorithm, and stays in the explanation marked with its window.
+func TestValidityWindowExcluded(t *testing.T) {
+	now := time.Date(2026, 8, 1, 12, 0, 0, 0, time.UTC)
+	var atts []*core.Attestation
+	for i := 0; i < 3; i++ {
+		atts = append(atts, att(core.TypeTaskCompleted, "did:key:z"+string(rune('A'+i)), now.Add(-48*time.Hour)))
+	}
+	expired := att(core.TypeEndorsement, "did:key:zAuditor", now.Add(-100*24*time.Hour))
+	expired.ExpiresAt = now.Add(-10 * 24 * time.Hour).Format(time.RFC3339)
+	future := att(core.TypeTaskCompleted, "did:key:zClient", now.Add(-48*time.Hour))
+	future.NotBefore = now.Add(72 * time.Hour).Format(time.RFC3339)
+	bounded := append(append([]*core.Attestation{}, atts...), expired, future)
+
+	for _, alg := range []Algorithm{V1{}, V2{Basis: DefaultBasis(nil)}} {
+		want := alg.Score(Input{Subject: "did:key:zSubject", Attestations: atts, Now: now})
+		got := alg.Score(Input{Subject: "did:key:zSubject", Attestations: bounded, Now: now})
+		if got.Score != want.Score {
+			t.Fatalf("%s: records outside their window should not count: %.2f vs %.2f", alg.Name(), got.Score, want.Score)
+		}
+	}
+	if then := Compute(bounded, nil, nil, now.Add(-20*24*time.Hour)); then.Inputs.DistinctIssuers != 4 {
+		t.Fatalf("the audit was in effect before it expired: %+v", then.Inputs)
+	}
+	ex := Explain(bounded, nil, nil, now).Explain.Attestations
+	if len(ex) != 5 || ex[3].Window != core.WindowExpired || ex[4].Window != core.WindowNotYetValid || ex[0].Window != "" {
+		t.Fatalf("explanation should keep the bounded records in place, marked: %+v", ex)
+	}
+}
+
+// A co-signed attestation is one event: it counts once, under one issuer for
+// diversity, at the issuers' combined weight — not as a record per signer.
+func TestCoSignedCountsOnce(t *testing.T) {
+	now := time.Date(2026, 8, 1, 12, 0, 0, 0, time.UTC)
+	joint := att(core.TypeTaskCompleted, "did:key:zA", now)
+	joint.CoIssuers = []core.CoIssuer{{Issuer: "did:key:zB"}, {Issuer: "did:key:zC"}}
+	var separate []*core.Attestation
+	for _, i := range joint.Issuers() {
+		separate = append(separate, att(core.TypeTaskCompleted, i, now))
+	}
+
+	one := Compute([]*core.Attestation{joint}, nil, nil, now)
+	if one.Inputs.Completions != 1 || one.Inputs.DistinctIssuers != 1 {
+		t.Fatalf("a co-signed completion is one completion by one issuer: %+v", one.Inputs)
+	}
+	if three := Compute(separate, nil, nil, now); one.Score >= three.Score {
+		t.Fatalf("co-signing should not outweigh separate attestations: %.2f vs %.2f", one.Score, three.Score)
+	}
+
+	weights := map[string]float64{"did:key:zA": 0.5, "did:key:zB": 0.5, "did:key:zC": 0}
+	ex := Explain([]*core.Attestation{joint}, weights, nil, now).Explain.Attestations
+	if ex[0].IssuerWeight != 0.75 || len(ex[0].CoIssuers) != 2 || ex[0].Issuer != "did:key:zA" {
+		t.Fatalf("weights combine as 1-(1-0.5)(1-0.5)(1-0): %+v", ex[0])
+	}
+
+	// A co-issuer sharing the subject's owner is set aside; the record is
+	// self-dealing only when every issuer is.
+	ownerOf := map[string]string{"did:key:zSubject": "o", "did:key:zA": "o", "did:key:zB": "o"}
+	ex = Explain([]*core.Attestation{joint}, weights, ownerOf, now).Explain.Attestations
+	if ex[0].SelfDealing || ex[0].IssuerWeight != 0 {
+		t.Fatalf("only zC is independent: %+v", ex[0])
+	}
+	ownerOf["did:key:zC"] = "o"
+	if got := Compute([]*core.Attestation{joint}, nil, ownerOf, now); got.Inputs.Completions != 0 {
+		t.Fatalf("all issuers self-dealing: %+v", got.Inputs)
+	}
+
+	for _, alg := range []Algorithm{V1{}, V2{Basis: DefaultBasis([]string{"did:key:zA"})}} {
+		in := Input{Subject: "did:key:zSubject", Attestations: []*core.Attestation{joint}, Graph: []*core.Attestation{joint}, Now: now}
+		if got := alg.Score(in); got.Inputs.Completions != 1 || got.Inputs.DistinctIssuers != 1 {
+			t.Fatalf("%s: %+v", alg.Name(), got.Inputs)
+		}
+	}
+}
@@ -0,0 +1,427 @@
+package score
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/moltnet/moltnet/core"
+)
+
+// AlgorithmV2 is the version tag emitted with every moltscore/v2 score object.
+const AlgorithmV2 = "moltscore/v2"
+
+// BasisSpec is the spec tag of a v2 score basis document.
+const BasisSpec = "moltnet/score-basis/v2"
+
+// Basis is the explicit, hashed input that pins a moltscore/v2 computation:
+// the trust anchors, propagation parameters, model weights and half-lives. Two
+// parties agree on a v2 score iff they agree on the chain, the day and the
+// basis hash. See spec/moltscore-v2.md §3.
+type Basis struct {
+	Spec         string             `json:"spec"`
+	Anchors      []string           `json:"anchors"`
+	Params       BasisParams        `json:"params"`
+	Weights      BasisWeights       `json:"weights"`
+	TypeWeights  map[string]float64 `json:"type_weights"`
+	HalfLifeDays HalfLives          `json:"half_life_days"`
+	// Corroboration gates negatives: see Corroboration.
+	Corroboration Corroboration `json:"corroboration"`
+}
+
+// BasisParams controls the anchored fixed-point propagation (§4.3).
+type BasisParams struct {
+	Damping    float64 `json:"damping"`
+	Iterations int     `json:"iterations"`
+	Quantum    float64 `json:"quantum"`
+}
+
+// BasisWeights are the subject-score model weights (§4.4).
+type BasisWeights struct {
+	W1       float64 `json:"w1"` // positive pool
+	W2       float64 `json:"w2"` // disputes
+	W3       float64 `json:"w3"` // incidents
+	W4       float64 `json:"w4"` // weighted diversity
+	Baseline float64 `json:"baseline"`
+}
+
+// Corroboration is the rule a dispute or incident must meet to count: at least
+// MinIssuers independent reporters (distinct owners) filing the same type
+// within WindowDays of it. MinIssuers 1 counts every negative, as v1 does.
+type Corroboration struct {
+	MinIssuers int     `json:"min_issuers"`
+	WindowDays float64 `json:"window_days"`
+}
+
+// The reference corroboration rule: one validly-signed false report must not
+// be able to grief an honest agent.
+const (
+	corroborationMinIssuers = 2
+	corroborationWindowDays = 30.0
+)
+
+// HalfLives are the recency half-lives, in days, per signal class.
+type HalfLives struct {
+	Positive float64 `json:"positive"`
+	Dispute  float64 `json:"dispute"`
+	Incident float64 `json:"incident"`
+}
+
+// DefaultBasis returns the reference basis with the given anchor set. The
+// figures match v1 so the two algorithms differ only in how issuers are
+// weighted, not in what a signal is worth.
+func DefaultBasis(anchors []string) Basis {
+	a := append([]string{}, anchors...)
+	sort.Strings(a)
+	return Basis{
+		Spec:    BasisSpec,
+		Anchors: a,
+		Params:  BasisParams{Damping: 0.85, Iterations: 32, Quantum: 1e-9},
+		Weights: BasisWeights{W1: wCompletions, W2: wDisputes, W3: wIncidents, W4: wDiversity, Baseline: baseline},
+		TypeWeights: map[string]float64{
+			core.TypeTaskCompleted:  1.0,
+			core.TypePaymentReceipt: receiptWeight,
+			core.TypeEndorsement:    endorsementWeight,
+		},
+		HalfLifeDays:  HalfLives{Positive: halfLifePositiveDays, Dispute: halfLifeDisputeDays, Incident: halfLifeIncidentDays},
+		Corroboration: Corroboration{MinIssuers: corroborationMinIssuers, WindowDays: corroborationWindowDays},
+	}
+}
+
+// Hash returns the content address of the basis ("blake3:…" over its JCS
+// canonical form). It is carried in every v2 score object.
+func (b Basis) Hash() (string, error) {
+	if b.Anchors == nil {
+		b.Anchors = []string{} // "anchors": [] and an omitted list hash the same
+	}
+	return core.HashCanonical(b)
+}
+
+// ParseBasis decodes a basis document. Fields the document omits take the
+// reference defaults, so a consumer's file may list only its anchors; the hash
+// is always over the complete, effective basis. Anchors are a set and are
+// sorted, so their order in the file does not change the hash.
+//
+// type_weights, when present, replaces the default table rather than merging
+// into it: a type the document leaves out is worth nothing, so the basis says
+// everything that went into the score.
+func ParseBasis(data []byte) (Basis, error) {
+	b := DefaultBasis(nil)
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return Basis{}, fmt.Errorf("basis: %w", err)
+	}
+	if _, ok := fields["type_weights"]; ok {
+		b.TypeWeights = nil
+	}
+	if err := json.Unmarshal(data, &b); err != nil {
+		return Basis{}, fmt.Errorf("basis: %w", err)
+	}
+	if b.TypeWeights == nil {
+		b.TypeWeights = map[string]float64{}
+	}
+	if b.Spec != BasisSpec {
+		return Basis{}, fmt.Errorf("basis: unsupported spec %q (want %s)", b.Spec, BasisSpec)
+	}
+	if b.Params.Damping < 0 || b.Params.Damping >= 1 {
+		return Basis{}, fmt.Errorf("basis: damping must be in [0,1), got %v", b.Params.Damping)
+	}
+	if b.Params.Iterations <= 0 {
+		return Basis{}, fmt.Errorf("basis: iterations must be positive, got %d", b.Params.Iterations)
+	}
+	if b.Corroboration.MinIssuers < 1 || b.Corroboration.WindowDays < 0 {
+		return Basis{}, fmt.Errorf("basis: corroboration needs min_issuers >= 1 and window_days >= 0")
+	}
+	sort.Strings(b.Anchors)
+	return b, nil
+}
+
+// Weights computes the v2 issuer weights over the FULL registry attestation set
+// as an anchored fixed point (§4.2–4.3). ownerOf resolves DIDs to owners for the
+// independence rule, exactly as in Compute; nil disables it. The result maps
+// every node reachable in the graph to a weight in [0,1]; a DID absent from
+// the map has weight 0. A retracted attestation carries no edge mass, nor does
+// one outside its validity window at the start of the day. Delegated
+// attestations are disregarded, as in Compute; V2.Score counts those its
+// Input.Delegations cover.
+func Weights(all []*core.Attestation, ownerOf map[string]string, b Basis, now time.Time) map[string]float64 {
+	return weights(core.Delegations(nil).Filter(all), ownerOf, b, now)
+}
+
+func weights(all []*core.Attestation, ownerOf map[string]string, b Basis, now time.Time) map[string]float64 {
+	day := utcDay(now)
+	all = core.InEffect(core.DropRetracted(all), day)
+	seed := map[string]float64{}
+	for _, a := range b.Anchors {
+		seed[a] = 1.0 / float64(len(b.Anchors))
+	}
+
+	// Step 1: edge mass per ordered pair, accumulated in attestation-hash order.
+	edges := map[string]map[string]float64{} // issuer -> subject -> mass
+	for _, a := range byHash(all) {
+		tw := b.TypeWeights[a.Type]
+		issuers := independentV2(a, ownerOf)
+		if tw <= 0 || len(issuers) == 0 {
+			continue
+		}
+		// A co-signed attestation is one edge's mass, shared among its issuers.
+		m := tw * b.decayV2(a.IssuedAt, day, b.HalfLifeDays.Positive) / float64(len(issuers))
+		for _, i := range issuers {
+			if edges[i] == nil {
+				edges[i] = map[string]float64{}
+			}
+			edges[i][a.Subject] += m
+		}
+	}
+
+	// Node set, out-mass and in-edges, all in DID order.
+	nodeSet := map[string]struct{}{}
+	for a := range seed {
+		nodeSet[a] = struct{}{}
+	}
+	for i, subs := range edges {
+		nodeSet[i] = struct{}{}
+		for s := range subs {
+			nodeSet[s] = struct{}{}
+		}
+	}
+	nodes := sortedKeys(nodeSet)
+	out := map[string]float64{}
+	type inEdge struct {
+		from string
+		mass float64
+	}
+	in := map[string][]inEdge{}
+	for _, i := range nodes {
+		for _, s := range sortedKeys(edges[i]) {
+			m := edges[i][s]
+			out[i] += m
+			in[s] = append(in[s], inEdge{from: i, mass: m})
+		}
+	}
+
+	// Step 2: damped propagation from the anchors, a fixed number of rounds.
+	d := b.Params.Damping
+	w := map[string]float64{}
+	for _, x := range nodes {
+		w[x] = seed[x]
+	}
+	for k := 0; k < b.Params.Iterations; k++ {
+		next := make(map[string]float64, len(nodes))
+		for _, x := range nodes {
+			var sum float64
+			for _, e := range in[x] {
+				if out[e.from] == 0 {
+					continue
+				}
+				sum += w[e.from] * e.mass / out[e.from]
+			}
+			next[x] = (1-d)*seed[x] + d*sum
+		}
+		w = next
+	}
+
+	var max float64
+	for _, x := range nodes {
+		if w[x] > max {
+			max = w[x]
+		}
+	}
+	for _, x := range nodes {
+		if max > 0 {
+			w[x] /= max
+		} else {
+			w[x] = 0
+		}
+	}
+	return w
+}
+
+// ComputeV2 runs the moltscore/v2 subject score (§4.4) over one agent's
+// attestations. weights must come from Weights over the full attestation set
+// under the same basis and day; v2 is not locally recomputable from one chain.
+// Issuers missing from weights count zero — weight is received, never assumed.
+// A retracted attestation does not count (a v2 rule: Compute scores it), and
+// neither does a delegated one, as in Compute.
+// Tagged attestations yield a per-capability vector, as in Compute,
+// under the same weights.
+func ComputeV2(atts []*core.Attestation, weights map[string]float64, ownerOf map[string]string, b Basis, now time.Time) Output {
+	return computeV2(core.Delegations(nil).Filter(atts), weights, ownerOf, b, penalty{}, now)
+}
+
+// penalty is the equivocation term of a v2 score: how many proofs that the
+// subject forked its own issuer chain hold, and their decayed mass. v1 has no
+// such term.
+type penalty struct {
+	n    int
+	mass float64
+}
+
+// equivocationPenalty counts the distinct proofs in eqs against subject that
+// verify, each decayed by decayOf from the later of its two records.
+func equivocationPenalty(eqs []*core.Equivocation, subject string, decayOf func(issuedAt string) float64) penalty {
+	var p penalty
+	seen := map[string]bool{}
+	for _, e := range eqs {
+		if e.Issuer != subject || e.Verify() != nil {
+			continue
+		}
+		h, _ := e.Hash()
+		if seen[h] {
+			continue
+		}
+		seen[h] = true
+		p.n++
+		p.mass += decayOf(max(e.A.IssuedAt, e.B.IssuedAt))
+	}
+	return p
+}
+
+// computeV2 is ComputeV2 with an equivocation penalty, weighted as incidents
+// are (w3).
+func computeV2(atts []*core.Attestation, weights map[string]float64, ownerOf map[string]string, b Basis, eq penalty, now time.Time) Output {
+	atts = core.InEffect(core.DropRetracted(atts), utcDay(now))
+	corr := corroborated(atts, ownerOf, b.Corroboration.MinIssuers, b.Corroboration.WindowDays)
+	out := subjectV2(atts, weights, ownerOf, b, corr, eq, now)
+	out.Capabilities = capabilityScores(atts, func(subset []*core.Attestation) Output {
+		return subjectV2(subset, weights, ownerOf, b, corr, penalty{}, now)
+	})
+	return out
+}
+
+// subjectV2 is the v2 subject score over atts, counting only the negatives in
+// corr.
+func subjectV2(atts []*core.Attestation, weights map[string]float64, ownerOf map[string]string, b Basis, corr map[*core.Attestation]bool, eq penalty, now time.Time) Output {
+	day := utcDay(now)
+	var positive, disputes, incidents float64
+	var in Inputs
+	var pending Pending
+	positiveIssuers := map[string]struct{}{}
+
+	for _, a := range byHash(atts) {
+		issuers := independentV2(a, ownerOf)
+		if len(issuers) == 0 {
+			continue
+		}
+		ws := make([]float64, len(issuers))
+		for i, p := range issuers {
+			ws[i] = weights[p]
+		}
+		iw := combinedWeight(ws)
+		addPositive := func() {
+			positive += iw * b.TypeWeights[a.Type] * b.decayV2(a.IssuedAt, day, b.HalfLifeDays.Positive)
+			positiveIssuers[issuers[0]] = struct{}{}
+		}
+		switch a.Type {
+		case core.TypeTaskCompleted:
+			in.Completions++
+			addPositive()
+		case core.TypeEndorsement:
+			in.Endorsements++
+			addPositive()
+		case core.TypePaymentReceipt:
+			in.Receipts++
+			addPositive()
+		case core.TypeTaskDisputed:
+			if !corr[a] {
+				pending.Disputes++
+				break
+			}
+			in.Disputes++
+			disputes += iw * b.decayV2(a.IssuedAt, day, b.HalfLifeDays.Dispute)
+		case core.TypeIncident:
+			if !corr[a] {
+				pending.Incidents++
+				break
+			}
+			in.Incidents++
+			incidents += iw * b.decayV2(a.IssuedAt, day, b.HalfLifeDays.Incident)
+		}
+	}
+	in.DistinctIssuers = len(positiveIssuers)
+
+	// Weighted diversity: Σ w(issuer), in DID order.
+	var diversity float64
+	for _, i := range sortedKeys(positiveIssuers) {
+		diversity += weights[i]
+	}
+
+	in.Equivocations = eq.n
+	x := b.Weights.W1*math.Log(1+positive) +
+		b.Weights.W4*math.Log(1+diversity) -
+		b.Weights.W2*disputes -
+		b.Weights.W3*(incidents+eq.mass) -
+		b.Weights.Baseline
+
+	basisHash, _ := b.Hash()
+	out := Output{
+		Algorithm:       AlgorithmV2,
+		Score:           math.RoundToEven(1000*sigmoid(x)) / 10,
+		Inputs:          in,
+		ComputedAt:      now.UTC().Format(time.RFC3339),
+		AttestationHead: head(atts),
+		Basis:           basisHash,
+		ComputedForDay:  day.Format(time.DateOnly),
+	}
+	if pending != (Pending{}) {
+		out.Pending = &pending
+	}
+	return out
+}
+
+// decayV2 is decay on the whole-UTC-day clock (§5.3), quantized to the basis
+// quantum (§5.2) so propagation cannot amplify a libm ulp difference.
+func (b Basis) decayV2(issuedAt string, day time.Time, halfLifeDays float64) float64 {
+	t, err := time.Parse(time.RFC3339, issuedAt)
+	if err != nil {
+		return 1.0
+	}
+	ageDays := math.Floor(day.Sub(utcDay(t)).Hours() / 24)
+	if ageDays <= 0 {
+		return 1.0
+	}
+	return quantize(math.Pow(0.5, ageDays/halfLifeDays), b.Params.Quantum)
+}
+
+func quantize(v, q float64) float64 {
+	if q <= 0 {
+		return v
+	}
+	return math.Round(v/q) * q
+}
+
+// utcDay floors t to midnight UTC.
+func utcDay(t time.Time) time.Time {
+	y, m, d := t.UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// byHash returns atts sorted by content hash, ascending (§5.1).
+func byHash(atts []*core.Attestation) []*core.Attestation {
+	type keyed struct {
+		hash string
+		a    *core.Attestation
+	}
+	ks := make([]keyed, 0, len(atts))
+	for _, a := range atts {
+		h, _ := a.Hash()
+		ks = append(ks, keyed{h, a})
+	}
+	sort.SliceStable(ks, func(i, j int) bool { return ks[i].hash < ks[j].hash })
+	out := make([]*core.Attestation, len(ks))
+	for i, k := range ks {
+		out[i] = k.a
+	}
+	return out
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
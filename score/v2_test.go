@@ -0,0 +1,277 @@
+package score
+
+import (
+	"math/rand"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/moltnet/moltnet/core"
+)
+
+func edge(typ, issuer, subject string, at time.Time) *core.Attestation {
+	a := core.NewAttestation(typ, issuer, subject)
+	a.IssuedAt = at.UTC().Format(time.RFC3339)
+	return a
+}
+
+func subjectOf(all []*core.Attestation, did string) []*core.Attestation {
+	var out []*core.Attestation
+	for _, a := range all {
+		if a.Subject == did {
+			out = append(out, a)
+		}
+	}
+	return out
+}
+
+func scoreV2(all []*core.Attestation, did string, b Basis, now time.Time) Output {
+	w := Weights(all, nil, b, now)
+	return ComputeV2(subjectOf(all, did), w, nil, b, now)
+}
+
+// The §1.2 attack: a farm of free keypairs vouching for a target and for each
+// other. With no inbound edge from an anchored node, every farm key converges to
+// weight zero, so the target scores exactly the no-history baseline.
+func TestV2FarmIsWorthless(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	b := DefaultBasis([]string{"did:key:zAnchor"})
+
+	var all []*core.Attestation
+	for i := 0; i < 24; i++ {
+		k := "did:key:zFarm" + strconv.Itoa(i)
+		all = append(all, edge(core.TypeTaskCompleted, k, "did:key:zTarget", now))
+		all = append(all, edge(core.TypeEndorsement, k, "did:key:zFarm"+strconv.Itoa((i+1)%24), now))
+	}
+	// A real agent, vouched for by the anchor.
+	all = append(all, edge(core.TypeTaskCompleted, "did:key:zAnchor", "did:key:zReal", now))
+
+	base := scoreV2(nil, "did:key:zTarget", b, now).Score
+	farm := scoreV2(all, "did:key:zTarget", b, now)
+	real := scoreV2(all, "did:key:zReal", b, now)
+	if farm.Score != base {
+		t.Fatalf("farm should score the baseline: farm=%.1f baseline=%.1f", farm.Score, base)
+	}
+	if real.Score <= farm.Score {
+		t.Fatalf("anchored work should outrank the farm: real=%.1f farm=%.1f", real.Score, farm.Score)
+	}
+	// And the v1 uniform basis is fooled by the same graph.
+	if v1 := Compute(subjectOf(all, "did:key:zTarget"), nil, nil, now).Score; v1 <= real.Score {
+		t.Fatalf("sanity: v1 should overrate the farm: v1=%.1f", v1)
+	}
+}
+
+// Under v2 a single dispute or incident is pending until independent reporters
+// — distinct owners, same type, within the window — corroborate it. v1 counts
+// it as filed.
+func TestV2Corroboration(t *testing.T) {
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	b := DefaultBasis(nil)
+	weights := map[string]float64{"did:key:zA": 1, "did:key:zB": 1, "did:key:zC": 1, "did:key:zD": 1}
+	good := []*core.Attestation{
+		att(core.TypeTaskCompleted, "did:key:zA", now),
+		att(core.TypeTaskCompleted, "did:key:zB", now),
+	}
+	base := ComputeV2(good, weights, nil, b, now)
+	with := func(extra ...*core.Attestation) []*core.Attestation {
+		return append(append([]*core.Attestation{}, good...), extra...)
+	}
+	owners := map[string]string{"did:key:zC": "did:key:zOwnerX", "did:key:zD": "did:key:zOwnerX"}
+
+	for name, c := range map[string]struct {
+		atts    []*core.Attestation
+		ownerOf map[string]string
+	}{
+		"lone incident":  {with(att(core.TypeIncident, "did:key:zC", now)), nil},
+		"same owner":     {with(att(core.TypeIncident, "did:key:zC", now), att(core.TypeIncident, "did:key:zD", now)), owners},
+		"outside window": {with(att(core.TypeIncident, "did:key:zC", now), att(core.TypeIncident, "did:key:zD", now.AddDate(0, 0, -31))), nil},
+		"different type": {with(att(core.TypeIncident, "did:key:zC", now), att(core.TypeTaskDisputed, "did:key:zD", now)), nil},
+	} {
+		out := ComputeV2(c.atts, weights, c.ownerOf, b, now)
+		if out.Score != base.Score || out.Inputs.Incidents != 0 || out.Pending == nil {
+			t.Errorf("%s: uncorroborated negatives must be pending: score %v (base %v) inputs %+v pending %+v",
+				name, out.Score, base.Score, out.Inputs, out.Pending)
+		}
+	}
+
+	out := ComputeV2(with(att(core.TypeIncident, "did:key:zC", now), att(core.TypeIncident, "did:key:zD", now.AddDate(0, 0, -30))), weights, nil, b, now)
+	if out.Score >= base.Score || out.Inputs.Incidents != 2 || out.Pending != nil {
+		t.Fatalf("two independent incidents within 30 days should count: %+v", out)
+	}
+
+	lone := with(att(core.TypeIncident, "did:key:zC", now))
+	if v1 := Compute(lone, nil, nil, now); v1.Inputs.Incidents != 1 || v1.Pending != nil {
+		t.Fatalf("v1 has no corroboration rule: %+v", v1)
+	}
+}
+
+// Under v2 a dispute its issuer retracts stops counting against the subject,
+// here leaving the other uncorroborated. A "retraction" of someone else's
+// dispute is ignored, and v1 applies none.
+func TestV2Retraction(t *testing.T) {
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	b := DefaultBasis(nil)
+	weights := map[string]float64{"did:key:zA": 1, "did:key:zB": 1, "did:key:zC": 1}
+	d1 := att(core.TypeTaskDisputed, "did:key:zA", now.Add(-time.Hour))
+	d2 := att(core.TypeTaskDisputed, "did:key:zB", now.Add(-time.Hour))
+	base := []*core.Attestation{att(core.TypeTaskCompleted, "did:key:zC", now), d1, d2}
+	h1, _ := d1.Hash()
+	retract := func(issuer string) []*core.Attestation {
+		r := att(core.TypeRetraction, issuer, now)
+		r.Body = map[string]any{"retracts": h1, "reason": "filed against the wrong agent"}
+		return append(base[:len(base):len(base)], r)
+	}
+	disputed := ComputeV2(base, weights, nil, b, now)
+	if disputed.Inputs.Disputes != 2 {
+		t.Fatalf("both disputes should count, got %+v", disputed.Inputs)
+	}
+	withdrawn := ComputeV2(retract("did:key:zA"), weights, nil, b, now)
+	if withdrawn.Inputs.Disputes != 0 || withdrawn.Pending == nil || withdrawn.Pending.Disputes != 1 {
+		t.Fatalf("the retracted dispute should not count, nor corroborate the other: %+v %+v", withdrawn.Inputs, withdrawn.Pending)
+	}
+	if withdrawn.Score <= disputed.Score {
+		t.Fatalf("retracting a dispute should raise the score: %.1f -> %.1f", disputed.Score, withdrawn.Score)
+	}
+	if forged := ComputeV2(retract("did:key:zB"), weights, nil, b, now); forged.Score != disputed.Score {
+		t.Fatalf("only the issuer can retract: %.1f vs %.1f", forged.Score, disputed.Score)
+	}
+	if v1 := Compute(retract("did:key:zA"), nil, nil, now); v1.Score != Compute(base, nil, nil, now).Score || v1.Inputs.Disputes != 2 {
+		t.Fatalf("v1 should not apply retractions: %+v", v1.Inputs)
+	}
+}
+
+// Weight is received, never manufactured: an unanchored basis weights nobody,
+// and anchors are normalized to 1.
+func TestV2WeightsFlowFromAnchors(t *testing.T) {
+	now := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	all := []*core.Attestation{
+		edge(core.TypeTaskCompleted, "did:key:zA", "did:key:zB", now),
+		edge(core.TypeTaskCompleted, "did:key:zB", "did:key:zC", now),
+		edge(core.TypeTaskCompleted, "did:key:zX", "did:key:zC", now),
+	}
+	w := Weights(all, nil, DefaultBasis([]string{"did:key:zA"}), now)
+	if w["did:key:zA"] != 1 {
+		t.Fatalf("sole anchor should normalize to 1, got %v", w["did:key:zA"])
+	}
+	if w["did:key:zB"] <= 0 || w["did:key:zC"] <= 0 {
+		t.Fatalf("reachable nodes should carry weight: %v", w)
+	}
+	if w["did:key:zX"] != 0 {
+		t.Fatalf("unreachable issuer must weigh zero, got %v", w["did:key:zX"])
+	}
+	for did, v := range Weights(all, nil, DefaultBasis(nil), now) {
+		if v != 0 {
+			t.Fatalf("no anchors: %s weighs %v, want 0", did, v)
+		}
+	}
+}
+
+// Same graph + same basis + same UTC day → same score, regardless of input
+// order or the time of day the computation ran.
+func TestV2Deterministic(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	day := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	b := DefaultBasis([]string{"did:key:z0", "did:key:z1"})
+	types := []string{core.TypeTaskCompleted, core.TypeEndorsement, core.TypePaymentReceipt, core.TypeTaskDisputed, core.TypeIncident}
+
+	var all []*core.Attestation
+	for i := 0; i < 80; i++ {
+		all = append(all, edge(types[rng.Intn(len(types))],
+			"did:key:z"+strconv.Itoa(rng.Intn(10)), "did:key:z"+strconv.Itoa(rng.Intn(10)),
+			day.AddDate(0, 0, -rng.Intn(400))))
+	}
+	want := scoreV2(all, "did:key:z5", b, day)
+
+	shuffled := append([]*core.Attestation{}, all...)
+	rng.Shuffle(len(shuffled), func(i, j int) { shuffled[i], shuffled[j] = shuffled[j], shuffled[i] })
+	got := scoreV2(shuffled, "did:key:z5", b, day.Add(23*time.Hour+59*time.Minute))
+	if got.Score != want.Score || got.Basis != want.Basis || got.ComputedForDay != want.ComputedForDay {
+		t.Fatalf("not reproducible within a day: %+v vs %+v", got, want)
+	}
+	if want.Algorithm != AlgorithmV2 || want.ComputedForDay != "2026-10-01" {
+		t.Fatalf("unexpected tags: %+v", want)
+	}
+}
+
+// The basis hash names the trust roots: anchor order does not matter, anchor
+// membership does.
+func TestV2BasisHash(t *testing.T) {
+	h1, _ := DefaultBasis([]string{"did:key:zA", "did:key:zB"}).Hash()
+	h2, _ := DefaultBasis([]string{"did:key:zB", "did:key:zA"}).Hash()
+	h3, _ := DefaultBasis([]string{"did:key:zA"}).Hash()
+	if h1 != h2 {
+		t.Fatalf("anchor order changed the basis hash")
+	}
+	if h1 == h3 {
+		t.Fatalf("different anchors must hash differently")
+	}
+}
+
+// A consumer's basis file may list only its anchors: the rest defaults, and the
+// result hashes like the equivalent DefaultBasis.
+func TestParseBasis(t *testing.T) {
+	b, err := ParseBasis([]byte(`{"spec":"moltnet/score-basis/v2","anchors":["did:key:zB","did:key:zA"]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _ := b.Hash()
+	want, _ := DefaultBasis([]string{"did:key:zA", "did:key:zB"}).Hash()
+	if got != want {
+		t.Fatalf("partial basis should hash like the default: %s vs %s", got, want)
+	}
+	// type_weights replaces the default table; it does not merge into it.
+	b, err = ParseBasis([]byte(`{"spec":"moltnet/score-basis/v2","type_weights":{"task.completed":2}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(b.TypeWeights) != 1 || b.TypeWeights[core.TypeTaskCompleted] != 2 {
+		t.Fatalf("type weights should be exactly the document's: %v", b.TypeWeights)
+	}
+	for _, bad := range []string{
+		`{"spec":"moltnet/score-basis/v1"}`,
+		`{"spec":"moltnet/score-basis/v2","params":{"damping":1}}`,
+		`{"spec":"moltnet/score-basis/v2","params":{"iterations":0}}`,
+		`not json`,
+	} {
+		if _, err := ParseBasis([]byte(bad)); err == nil {
+			t.Fatalf("ParseBasis(%s) should fail", bad)
+		}
+	}
+}
+
+// The registry resolves models by name, refuses to let one shadow another, and
+// a labelled v2 model carries its own name in every output.
+func TestRegistry(t *testing.T) {
+	reg := Builtin(nil)
+	if got := reg.Names(); len(got) != 2 || got[0] != AlgorithmV1 || got[1] != AlgorithmV2 {
+		t.Fatalf("builtin models: %v", got)
+	}
+	if err := reg.Register(V1{}); err == nil {
+		t.Fatal("registering a second moltscore/v1 should fail")
+	}
+	strict := DefaultBasis(nil)
+	strict.Corroboration.MinIssuers = 3
+	if err := reg.Register(V2{Label: "acme/strict", Basis: strict}); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	atts := []*core.Attestation{att(core.TypeTaskCompleted, "did:key:zA", now), att(core.TypeIncident, "did:key:zB", now)}
+	in := Input{Subject: "did:key:zSubject", Attestations: atts, Graph: atts, Now: now}
+	v1, _ := reg.Lookup(AlgorithmV1)
+	if got, want := v1.Score(in), Compute(atts, nil, nil, now); got.Score != want.Score || got.Algorithm != AlgorithmV1 {
+		t.Fatalf("v1 via the registry: %+v, want %+v", got, want)
+	}
+	custom, ok := reg.Lookup("acme/strict")
+	if !ok || !custom.Global() {
+		t.Fatalf("custom model not registered as a global model")
+	}
+	out := custom.Score(in)
+	want, _ := strict.Hash()
+	if out.Algorithm != "acme/strict" || out.Basis != want {
+		t.Fatalf("labelled model should carry its name and basis: %+v", out)
+	}
+	if _, ok := reg.Lookup("moltscore/v9"); ok {
+		t.Fatal("unknown model resolved")
+	}
+}
@@ -7,30 +7,95 @@
 package main
 
 import (
+	"bytes"
+	"crypto/elliptic"
+	"encoding/hex"
 	"encoding/json"
 	"log"
+	"math"
+	"math/big"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
 	"github.com/moltnet/moltnet/core"
 	"github.com/moltnet/moltnet/score"
 )
 
+// canonVector pins one RFC 8785 canonicalization. Valid vectors carry input as
+// JSON (number literals kept as written); vectors that must be rejected carry
+// input_text instead, since duplicate keys and lone surrogates would not
+// survive a JSON parser.
 type canonVector struct {
-	Input    any    `json:"input"`
-	Expected string `json:"expected"`
+	Name      string          `json:"name,omitempty"`
+	Input     json.RawMessage `json:"input,omitempty"`
+	InputText string          `json:"input_text,omitempty"`
+	Expected  string          `json:"expected,omitempty"`
+	Error     bool            `json:"error,omitempty"`
 }
 
 type scoreVector struct {
-	Now          string           `json:"now"`
-	Attestations []map[string]any `json:"attestations"`
-	Expected     scoreExpected    `json:"expected"`
+	Now           string             `json:"now"`
+	IssuerWeights map[string]float64 `json:"issuer_weights,omitempty"`
+	OwnerOf       map[string]string  `json:"owner_of,omitempty"`
+	Attestations  []map[string]any   `json:"attestations"`
+	Expected      scoreExpected      `json:"expected"`
 }
 
 type scoreExpected struct {
-	Score  float64      `json:"score"`
-	Inputs score.Inputs `json:"inputs"`
+	Score        float64                          `json:"score"`
+	Inputs       score.Inputs                     `json:"inputs"`
+	Capabilities map[string]score.CapabilityScore `json:"capabilities,omitempty"`
+}
+
+// scoreV2Vector carries the FULL attestation set (v2 weights are global) as
+// complete records, since summation order is by attestation hash.
+type scoreV2Vector struct {
+	Now          string              `json:"now"`
+	Basis        score.Basis         `json:"basis"`
+	Subject      string              `json:"subject"`
+	Attestations []*core.Attestation `json:"attestations"`
+	// Equivocations are proofs against issuers, the subject's counting as a
+	// penalty.
+	Equivocations []*core.Equivocation `json:"equivocations,omitempty"`
+	Expected      scoreV2Expected      `json:"expected"`
+}
+
+type scoreV2Expected struct {
+	Weights        map[string]float64 `json:"weights"`
+	Score          float64            `json:"score"`
+	Inputs         score.Inputs       `json:"inputs"`
+	Basis          string             `json:"basis"`
+	ComputedForDay string             `json:"computed_for_day"`
+	Pending        *score.Pending     `json:"pending,omitempty"`
+}
+
+// ownerPolicyVector pins the M-of-N owner shape: the policy's identifier, the
+// exact bytes members sign, and whether the record's owner signatures meet the
+// threshold.
+type ownerPolicyVector struct {
+	Name           string            `json:"name"`
+	Policy         *core.OwnerPolicy `json:"policy"`
+	PolicyDID      string            `json:"policy_did"`
+	Kind           string            `json:"kind"` // "card" or "rotation"
+	Record         any               `json:"record"`
+	SigningPayload string            `json:"signing_payload"`
+	Valid          bool              `json:"valid"`
+}
+
+// keyVector pins one did:key type: how the DID decodes, and whether sig (hex)
+// verifies over message (UTF-8) for it.
+type keyVector struct {
+	Name      string       `json:"name"`
+	KeyType   core.KeyType `json:"key_type"`
+	DID       string       `json:"did"`
+	PublicKey string       `json:"public_key"` // hex; compressed SEC 1 for EC keys
+	Message   string       `json:"message"`
+	Sig       string       `json:"sig"`
+	Valid     bool         `json:"valid"`
 }
 
 func main() {
@@ -43,11 +108,22 @@ func main() {
 	}
 	var cvs []canonVector
 	for _, in := range inputs {
-		c, err := core.Canonicalize(in)
+		raw, _ := json.Marshal(in)
+		cvs = append(cvs, canonVector{Input: raw})
+	}
+	cvs = append(cvs, rfc8785Vectors()...)
+	for i := range cvs {
+		if cvs[i].Error {
+			if _, err := core.CanonicalizeJSON([]byte(cvs[i].InputText)); err == nil {
+				log.Fatalf("canonical vector %q: should be rejected", cvs[i].Name)
+			}
+			continue
+		}
+		c, err := core.CanonicalizeJSON(cvs[i].Input)
 		if err != nil {
 			log.Fatal(err)
 		}
-		cvs = append(cvs, canonVector{Input: in, Expected: string(c)})
+		cvs[i].Expected = string(c)
 	}
 
 	// --- score vectors (fixed clock so decay is deterministic) ---
@@ -58,6 +134,28 @@ func main() {
 		a.IssuedAt = iso
 		return a
 	}
+	aged := func(typ, issuer string, daysAgo int) *core.Attestation {
+		a := att(typ, issuer)
+		a.IssuedAt = now.AddDate(0, 0, -daysAgo).UTC().Format(time.RFC3339)
+		return a
+	}
+	tagged := func(typ, issuer, capability string) *core.Attestation {
+		a := att(typ, issuer)
+		a.Body = map[string]any{"capability": capability}
+		return a
+	}
+	// bounded is issued 60 days ago with a validity window in days relative to
+	// now; 0 leaves that bound unset.
+	bounded := func(typ, issuer string, notBefore, expires int) *core.Attestation {
+		a := aged(typ, issuer, 60)
+		if notBefore != 0 {
+			a.NotBefore = now.AddDate(0, 0, notBefore).UTC().Format(time.RFC3339)
+		}
+		if expires != 0 {
+			a.ExpiresAt = now.AddDate(0, 0, expires).UTC().Format(time.RFC3339)
+		}
+		return a
+	}
 	scenarios := [][]*core.Attestation{
 		{},
 		{att("task.completed", "did:key:zA")},
@@ -65,17 +163,254 @@ func main() {
 		{att("task.completed", "did:key:zA"), att("incident", "did:key:zB")},
 		{att("self.claim", "did:key:zA"), att("self.claim", "did:key:zA")},
 		{att("task.completed", "did:key:zA"), att("task.completed", "did:key:zA"), att("payment.receipt", "did:key:zB")},
+		{tagged("task.completed", "did:key:zA", "code.review"), tagged("task.completed", "did:key:zB", "code.review"),
+			tagged("task.completed", "did:key:zA", "code.security-audit"), att("task.completed", "did:key:zC")},
+		{tagged("task.completed", "did:key:zA", "code.review"), tagged("task.disputed", "did:key:zB", "code.review"),
+			tagged("endorsement", "did:key:zC", "data.etl"), att("incident", "did:key:zD")},
+		// validity windows: only records in effect at now count
+		{bounded("endorsement", "did:key:zA", 0, 30), bounded("endorsement", "did:key:zB", 0, -1),
+			bounded("task.completed", "did:key:zC", -30, 0), bounded("task.completed", "did:key:zD", 1, 90)},
+		{att("task.completed", "did:key:zA"), att("incident", "did:key:zB"), bounded("incident", "did:key:zC", 0, -10)},
+		{bounded("task.completed", "did:key:zA", -10, 10), bounded("task.completed", "did:key:zB", 0, 0)},
+	}
+	// Co-signed records count once, at the combined weight of the co-issuers
+	// the independence rule keeps, so these carry issuer weights and owners.
+	// zS and zT share the subject's owner; zD is unweighted (0.25).
+	cosigned := func(typ string, issuers ...string) *core.Attestation {
+		a := att(typ, issuers[0])
+		for _, co := range issuers[1:] {
+			a.CoIssuers = append(a.CoIssuers, core.CoIssuer{Issuer: co})
+		}
+		return a
+	}
+	weights := map[string]float64{"did:key:zA": 0.5, "did:key:zB": 0.4, "did:key:zC": 1, "did:key:zS": 1, "did:key:zT": 1}
+	owners := map[string]string{"did:key:zSubject": "did:key:zOwner", "did:key:zS": "did:key:zOwner", "did:key:zT": "did:key:zOwner"}
+	weighted := [][]*core.Attestation{
+		{cosigned("task.completed", "did:key:zA", "did:key:zB"), att("task.completed", "did:key:zA")},
+		{cosigned("task.completed", "did:key:zS", "did:key:zB"), cosigned("endorsement", "did:key:zS", "did:key:zT"),
+			cosigned("payment.receipt", "did:key:zC", "did:key:zD"), cosigned("task.disputed", "did:key:zA", "did:key:zD", "did:key:zB")},
 	}
 	var svs []scoreVector
-	for _, sc := range scenarios {
-		out := score.Compute(sc, nil, nil, now)
+	for i, sc := range append(scenarios, weighted...) {
+		var w map[string]float64
+		var o map[string]string
+		if i >= len(scenarios) {
+			w, o = weights, owners
+		}
+		out := score.Compute(sc, w, o, now)
 		var atts []map[string]any
 		for _, a := range sc {
-			atts = append(atts, map[string]any{
+			m := map[string]any{
 				"type": a.Type, "issuer": a.Issuer, "subject": a.Subject, "issued_at": a.IssuedAt,
-			})
+			}
+			if len(a.Body) > 0 {
+				m["body"] = a.Body
+			}
+			if a.NotBefore != "" {
+				m["not_before"] = a.NotBefore
+			}
+			if a.ExpiresAt != "" {
+				m["expires_at"] = a.ExpiresAt
+			}
+			if len(a.CoIssuers) > 0 {
+				m["co_issuers"] = a.CoIssuers
+			}
+			atts = append(atts, m)
+		}
+		svs = append(svs, scoreVector{Now: iso, IssuerWeights: w, OwnerOf: o, Attestations: atts,
+			Expected: scoreExpected{Score: out.Score, Inputs: out.Inputs, Capabilities: out.Capabilities}})
+	}
+
+	// Fixed keys, for vectors that carry signatures: Ed25519 signing is
+	// deterministic.
+	key := func(b byte) *core.KeyPair {
+		kp, err := core.KeyPairFromHex(hex.EncodeToString(bytes.Repeat([]byte{b}, 32)))
+		if err != nil {
+			log.Fatal(err)
 		}
-		svs = append(svs, scoreVector{Now: iso, Attestations: atts, Expected: scoreExpected{Score: out.Score, Inputs: out.Inputs}})
+		return kp
+	}
+
+	// --- moltscore/v2 vectors (global graph, anchored weights, day clock) ---
+	v2now, _ := time.Parse(time.RFC3339, "2026-01-01T15:30:00Z")
+	v2at := func(typ, issuer, subject string, daysAgo int) *core.Attestation {
+		a := core.NewAttestation(typ, issuer, subject)
+		a.IssuedAt = v2now.AddDate(0, 0, -daysAgo).UTC().Format(time.RFC3339)
+		return a
+	}
+	anchored := score.DefaultBasis([]string{"did:key:zAnchor"})
+	graph := []*core.Attestation{
+		v2at("task.completed", "did:key:zAnchor", "did:key:zA", 3),
+		v2at("endorsement", "did:key:zAnchor", "did:key:zB", 40),
+		v2at("task.completed", "did:key:zA", "did:key:zSubject", 10),
+		v2at("payment.receipt", "did:key:zB", "did:key:zSubject", 200),
+		v2at("task.disputed", "did:key:zA", "did:key:zSubject", 1),
+		v2at("task.completed", "did:key:zFarm1", "did:key:zSubject", 0),
+		v2at("task.completed", "did:key:zFarm2", "did:key:zSubject", 0),
+		v2at("endorsement", "did:key:zFarm1", "did:key:zFarm2", 0),
+	}
+	// The same graph with the anchor's edge to zA lapsed at the start of the
+	// day: it carries no weight to zA.
+	lapsed := append([]*core.Attestation{}, graph...)
+	edge := *graph[0]
+	edge.ExpiresAt = "2026-01-01T00:00:00Z"
+	lapsed[0] = &edge
+	// Two disputes, then the same with zA's retracted by zA: v2 sets it aside,
+	// which leaves zB's uncorroborated.
+	disputed := append(graph[:len(graph):len(graph)], v2at("task.disputed", "did:key:zB", "did:key:zSubject", 20))
+	retraction := v2at("retraction", "did:key:zA", "did:key:zSubject", 0)
+	retracted, _ := graph[4].Hash()
+	retraction.Body = map[string]any{"retracts": retracted, "reason": "filed against the wrong agent"}
+	// A subject that forked its own chain: two signed attestations after the
+	// same prev, and the proof of it.
+	forker := key(9)
+	fork := func(task string) *core.Attestation {
+		a := v2at("task.completed", forker.DID, "did:key:zA", 2)
+		a.Prev = "blake3:" + strings.Repeat("0", 64)
+		a.Body = map[string]any{"task_id": task}
+		if err := a.Sign(forker.Private); err != nil {
+			log.Fatal(err)
+		}
+		return a
+	}
+	forkA, forkB := fork("one"), fork("two")
+	equivocation, err := core.NewEquivocation(forkA, forkB)
+	if err != nil {
+		log.Fatal(err)
+	}
+	forked := append(graph[:len(graph):len(graph)], forkA, forkB,
+		v2at("task.completed", "did:key:zAnchor", forker.DID, 5), v2at("task.completed", "did:key:zA", forker.DID, 5))
+	// A dispute the subject co-signed against itself, outside the window of
+	// zA's: the subject is not an independent reporter, so it stays pending.
+	selfReport := v2at("task.disputed", "did:key:zB", "did:key:zSubject", 100)
+	selfReport.CoIssuers = []core.CoIssuer{{Issuer: "did:key:zSubject"}}
+	selfReported := append(graph[:len(graph):len(graph)], selfReport)
+	v2scenarios := []struct {
+		basis   score.Basis
+		subject string
+		atts    []*core.Attestation
+		eqs     []*core.Equivocation
+	}{
+		{anchored, "did:key:zSubject", nil, nil},
+		{anchored, "did:key:zSubject", graph, nil},
+		{anchored, "did:key:zA", graph, nil},
+		{score.DefaultBasis([]string{"did:key:zAnchor", "did:key:zFarm1"}), "did:key:zSubject", graph, nil},
+		{score.DefaultBasis(nil), "did:key:zSubject", graph, nil},
+		{anchored, "did:key:zSubject", disputed, nil},
+		{anchored, "did:key:zSubject", lapsed, nil},
+		{anchored, "did:key:zSubject", append(disputed[:len(disputed):len(disputed)], retraction), nil},
+		{anchored, forker.DID, forked, []*core.Equivocation{equivocation}},
+		{anchored, "did:key:zSubject", selfReported, nil},
+	}
+	var v2vs []scoreV2Vector
+	for _, sc := range v2scenarios {
+		var subj []*core.Attestation
+		for _, a := range sc.atts {
+			if a.Subject == sc.subject {
+				subj = append(subj, a)
+			}
+		}
+		w := score.Weights(sc.atts, nil, sc.basis, v2now)
+		out := score.V2{Basis: sc.basis}.Score(score.Input{Subject: sc.subject, Attestations: subj, Graph: sc.atts,
+			Equivocations: sc.eqs, Now: v2now})
+		v2vs = append(v2vs, scoreV2Vector{
+			Now: v2now.Format(time.RFC3339), Basis: sc.basis, Subject: sc.subject, Attestations: sc.atts, Equivocations: sc.eqs,
+			Expected: scoreV2Expected{Weights: w, Score: out.Score, Inputs: out.Inputs, Basis: out.Basis, ComputedForDay: out.ComputedForDay, Pending: out.Pending},
+		})
+	}
+
+	// --- owner policy vectors (fixed keys: Ed25519 signing is deterministic) ---
+	m1, m2, m3, outsider := key(1), key(2), key(3), key(4)
+	agent, next := key(5), key(6)
+	policy, err := core.NewOwnerPolicy(2, m1.DID, m2.DID, m3.DID)
+	if err != nil {
+		log.Fatal(err)
+	}
+	policyDID, err := policy.DID()
+	if err != nil {
+		log.Fatal(err)
+	}
+	policyCard := func(signers ...*core.KeyPair) *core.Card {
+		c := core.NewCard(agent.DID, policyDID, "team-agent")
+		c.OwnerPolicy = policy
+		c.CreatedAt = iso
+		if err := c.SignAgent(agent.Private); err != nil {
+			log.Fatal(err)
+		}
+		for _, m := range signers {
+			if err := c.AddOwnerSig(m); err != nil {
+				log.Fatal(err)
+			}
+		}
+		return c
+	}
+	policyRotation := func(signers ...*core.KeyPair) *core.Rotation {
+		r := core.NewRotation(policyDID, agent.DID, next.DID)
+		r.OwnerPolicy = policy
+		r.IssuedAt = iso
+		for _, m := range signers {
+			if err := r.AddOwnerSig(m); err != nil {
+				log.Fatal(err)
+			}
+		}
+		return r
+	}
+	type signed interface {
+		SigningPayload() ([]byte, error)
+		Verify() error
+	}
+	var opvs []ownerPolicyVector
+	for _, v := range []struct {
+		name, kind string
+		rec        signed
+	}{
+		{"card signed by 2 of 3 members", "card", policyCard(m1, m3)},
+		{"card signed by all 3 members", "card", policyCard(m3, m2, m1)},
+		{"card signed by 1 of 3 members", "card", policyCard(m2)},
+		{"card co-signed by a non-member", "card", policyCard(m1, outsider)},
+		{"rotation signed by 2 of 3 members", "rotation", policyRotation(m2, m3)},
+		{"rotation signed by 1 of 3 members", "rotation", policyRotation(m1)},
+	} {
+		payload, err := v.rec.SigningPayload()
+		if err != nil {
+			log.Fatal(err)
+		}
+		opvs = append(opvs, ownerPolicyVector{Name: v.name, Policy: policy, PolicyDID: policyDID, Kind: v.kind,
+			Record: v.rec, SigningPayload: string(payload), Valid: v.rec.Verify() == nil})
+	}
+
+	// --- key type vectors (fixed keys; EC signing is deterministic, RFC 6979) ---
+	var kvs []keyVector
+	msg := `{"spec":"moltnet/attestation/v0.1","type":"task.completed"}`
+	var others []*core.KeyPair
+	for _, typ := range []core.KeyType{core.KeyTypeEd25519, core.KeyTypeP256, core.KeyTypeSecp256k1} {
+		kp, err := core.KeyPairFromHexOf(typ, hex.EncodeToString(bytes.Repeat([]byte{7}, 32)))
+		if err != nil {
+			log.Fatal(err)
+		}
+		sig, err := core.Sign(kp.Private, []byte(msg))
+		if err != nil {
+			log.Fatal(err)
+		}
+		vec := func(name, did, message, sig string) keyVector {
+			return keyVector{Name: name, KeyType: typ, DID: did, PublicKey: core.PublicKeyHex(kp.Public),
+				Message: message, Sig: sig, Valid: core.Verify(did, []byte(message), sig) == nil}
+		}
+		kvs = append(kvs,
+			vec(string(typ)+" signature", kp.DID, msg, sig),
+			vec(string(typ)+" signature over a tampered message", kp.DID, msg+" ", sig))
+		if n := map[core.KeyType]*big.Int{core.KeyTypeP256: elliptic.P256().Params().N,
+			core.KeyTypeSecp256k1: secp256k1.S256().Params().N}[typ]; n != nil {
+			// (r, n − s) is the same signature with s in the upper half of the order.
+			raw, _ := hex.DecodeString(sig)
+			new(big.Int).Sub(n, new(big.Int).SetBytes(raw[32:])).FillBytes(raw[32:])
+			kvs = append(kvs, vec(string(typ)+" signature with a high s", kp.DID, msg, hex.EncodeToString(raw)))
+		}
+		for _, o := range others {
+			other, _ := core.Sign(o.Private, []byte(msg))
+			kvs = append(kvs, vec(string(typ)+" DID with a "+string(o.Type)+" signature", kp.DID, msg, other))
+		}
+		others = append(others, kp)
 	}
 
 	dir := filepath.Join("spec", "conformance")
@@ -84,7 +419,52 @@ func main() {
 	}
 	write(filepath.Join(dir, "canonical_vectors.json"), cvs)
 	write(filepath.Join(dir, "score_vectors.json"), svs)
-	log.Printf("wrote %d canonical + %d score vectors to %s", len(cvs), len(svs), dir)
+	write(filepath.Join(dir, "score_v2_vectors.json"), v2vs)
+	write(filepath.Join(dir, "owner_policy_vectors.json"), opvs)
+	write(filepath.Join(dir, "key_vectors.json"), kvs)
+	log.Printf("wrote %d canonical + %d score + %d score v2 + %d owner policy + %d key vectors to %s", len(cvs), len(svs), len(v2vs), len(opvs), len(kvs), dir)
+}
+
+// rfc8785Vectors covers the RFC 8785 test suite: the sorting example of
+// section 3.2.3, its reference test data (arrays, structures, French and
+// unnormalized Unicode keys, values, weird keys), the IEEE 754 samples of
+// Appendix B, and the inputs a canonicalizer must refuse.
+func rfc8785Vectors() []canonVector {
+	valid := func(name, text string) canonVector {
+		return canonVector{Name: name, Input: json.RawMessage(text)}
+	}
+	invalid := func(name, text string) canonVector {
+		return canonVector{Name: name, InputText: text, Error: true}
+	}
+	// Appendix B, each written with 17 significant digits so the vector checks
+	// the ECMAScript serialization rather than an echo of the input.
+	var samples []string
+	for _, bits := range []uint64{
+		0x0000000000000000, 0x8000000000000000, 0x0000000000000001, 0x8000000000000001,
+		0x7fefffffffffffff, 0xffefffffffffffff, 0x4340000000000000, 0xc340000000000000,
+		0x4430000000000000, 0x44b52d02c7e14af5, 0x44b52d02c7e14af6, 0x44b52d02c7e14af7,
+		0x444b1ae4d6e2ef4e, 0x444b1ae4d6e2ef4f, 0x444b1ae4d6e2ef50, 0x3eb0c6f7a0b5ed8c,
+		0x3eb0c6f7a0b5ed8d, 0x41b3de4355555553, 0x41b3de4355555554, 0x41b3de4355555555,
+		0x41b3de4355555556, 0x41b3de4355555557, 0xbecbf647612f3696, 0x43143ff3c1cb0959,
+	} {
+		samples = append(samples, strconv.FormatFloat(math.Float64frombits(bits), 'e', 16, 64))
+	}
+	return []canonVector{
+		valid("rfc8785 3.2.3 sorting", `{"\u20ac": "Euro Sign", "\r": "Carriage Return", "\ufb33": "Hebrew Letter Dalet With Dagesh", "1": "One", "\ud83d\ude00": "Emoji: Grinning Face", "\u0080": "Control", "\u00f6": "Latin Small Letter O With Diaeresis"}`),
+		valid("rfc8785 arrays", `[56, {"d": true, "10": null, "1": [ ]}]`),
+		valid("rfc8785 french", `{"peach": "This sorting order", "péché": "is wrong according to French", "pêche": "but canonicalization MUST", "sin": "ignore locale"}`),
+		valid("rfc8785 structures", `{"1": {"f": {"f": "hi", "F": 5}, "\n": 56.0}, "10": { }, "": "empty", "a": { }, "111": [ {"e": "yes", "E": "no"} ], "A": { }}`),
+		valid("rfc8785 unicode", `{"Unnormalized Unicode": "A\u030a"}`),
+		valid("rfc8785 values", `{"numbers": [333333333.33333329, 1E30, 4.50, 2e-3, 0.000000000000000000000000001], "string": "\u20ac$\u000F\u000aA'\u0042\u0022\u005c\\\"\/", "literals": [null, true, false]}`),
+		valid("rfc8785 weird", `{"\u20ac": "Euro Sign", "\r": "Carriage Return", "\u000a": "Newline", "1": "One", "\u0080": "Control\u007f", "\ud83d\ude02": "Smiley", "\u00f6": "Latin Small Letter O With Diaeresis", "\ufb33": "Hebrew Letter Dalet With Dagesh", "</script>": "Browser Challenge"}`),
+		valid("rfc8785 appendix B numbers", "["+strings.Join(samples, ", ")+"]"),
+		valid("number spellings", `{"one": [1, 1.0, 1e0, 10E-1, 0.1e1], "zero": [0, -0, 0.0, -0e5], "beyond 2^53": 9007199254740993, "price": 19.90}`),
+		invalid("duplicate key", `{"a": 1, "a": 2}`),
+		invalid("nested duplicate key", `{"body": {"amount": 1, "amount": 100}}`),
+		invalid("lone high surrogate", `{"k": "\ud83d"}`),
+		invalid("lone low surrogate", `["\ude00"]`),
+		invalid("number out of range", `[1e400]`),
+	}
 }
 
 func write(path string, v any) {